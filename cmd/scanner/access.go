@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+)
+
+// runCheckAccess implements `k8s-scanner check-access`, which reports the
+// permissions of the current credentials for every scanner
+func runCheckAccess(args []string) {
+	fs := flag.NewFlagSet("check-access", flag.ExitOnError)
+	var (
+		namespace  string
+		kubeconfig string
+	)
+	fs.StringVar(&namespace, "namespace", "", "Namespace(s) to check: comma-separated list or empty for cluster-wide")
+	fs.StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file (default: $KUBECONFIG or ~/.kube/config)")
+	_ = fs.Parse(args)
+
+	clientset, err := k8s.NewK8sClient(kubeconfig)
+	if err != nil {
+		log.Fatalf("cannot init k8s client: %v", err)
+	}
+
+	results := k8s.CheckAccess(context.Background(), clientset, parseNamespaces(namespace))
+
+	fmt.Printf("%-10s %-7s %-35s %-20s %-8s %s\n", "SCANNER", "VERB", "RESOURCE", "NAMESPACE", "ALLOWED", "REASON")
+	fmt.Println(strings.Repeat("-", 100))
+	missingRequired := false
+	for _, r := range results {
+		resource := r.Resource
+		if r.Group != "" {
+			resource += "." + r.Group
+		}
+		ns := r.Namespace
+		if ns == "" {
+			ns = "(all)"
+		}
+		allowed := "yes"
+		if !r.Allowed {
+			allowed = "no"
+			if r.Required {
+				missingRequired = true
+			}
+		}
+		fmt.Printf("%-10s %-7s %-35s %-20s %-8s %s\n", r.Scanner, r.Verb, resource, trunc(ns, 20), allowed, r.Reason)
+	}
+
+	if missingRequired {
+		fmt.Println("\nThe scan cannot run: required permissions are missing.")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/capacity"
+)
+
+// runCapacity implements `k8s-scanner capacity`, which prints allocatable vs
+// requested resources and pods per node for the whole cluster
+func runCapacity(args []string) {
+	fs := flag.NewFlagSet("capacity", flag.ExitOnError)
+	var (
+		kubeconfig string
+		format     string
+	)
+	fs.StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file (default: $KUBECONFIG or ~/.kube/config)")
+	fs.StringVar(&format, "format", "table", "Output format: json|table")
+	_ = fs.Parse(args)
+
+	clientset, err := k8s.NewK8sClient(kubeconfig)
+	if err != nil {
+		log.Fatalf("cannot init k8s client: %v", err)
+	}
+
+	ov, err := capacity.FetchOverview(context.Background(), clientset)
+	if err != nil {
+		log.Fatalf("failed to build capacity overview: %v", err)
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(ov)
+		return
+	}
+
+	fmt.Printf("Nodes: %d (%d ready)   Pods: %d/%d\n", ov.NodeCount, ov.ReadyNodes, ov.Pods, ov.MaxPods)
+	fmt.Printf("CPU requested:    %s / %s (%.0f%%)\n", capacity.FormatCPU(ov.RequestedCPUMilli), capacity.FormatCPU(ov.AllocatableCPUMilli),
+		capacity.Percent(ov.RequestedCPUMilli, ov.AllocatableCPUMilli))
+	fmt.Printf("Memory requested: %s / %s (%.0f%%)\n\n", capacity.FormatMemory(ov.RequestedMemoryBytes), capacity.FormatMemory(ov.AllocatableMemoryBytes),
+		capacity.Percent(ov.RequestedMemoryBytes, ov.AllocatableMemoryBytes))
+
+	fmt.Printf("%-30s %-6s %-9s %-18s %-20s %-10s %-10s\n", "NODE", "READY", "PODS", "CPU REQ/ALLOC", "MEM REQ/ALLOC", "CPU USED", "MEM USED")
+	for _, n := range ov.Nodes {
+		cpuUsed, memUsed := "-", "-"
+		if n.Usage != nil {
+			cpuUsed = fmt.Sprintf("%.0f%%", capacity.Percent(n.Usage.CPUMilli, n.AllocatableCPUMilli))
+			memUsed = fmt.Sprintf("%.0f%%", capacity.Percent(n.Usage.MemoryBytes, n.AllocatableMemoryBytes))
+		}
+		fmt.Printf("%-30s %-6t %-9s %-18s %-20s %-10s %-10s\n",
+			trunc(n.Name, 30), n.Ready, fmt.Sprintf("%d/%d", n.Pods, n.MaxPods),
+			capacity.FormatCPU(n.RequestedCPUMilli)+"/"+capacity.FormatCPU(n.AllocatableCPUMilli),
+			capacity.FormatMemory(n.RequestedMemoryBytes)+"/"+capacity.FormatMemory(n.AllocatableMemoryBytes),
+			cpuUsed, memUsed)
+	}
+}
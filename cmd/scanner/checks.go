@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ductnn/k8s-scanner/pkg/checks"
+)
+
+// runChecks implements `k8s-scanner checks list`, which documents every
+// check the scanners report
+func runChecks(args []string) {
+	if len(args) == 0 || args[0] != "list" {
+		fmt.Fprintln(os.Stderr, "USAGE:\n  k8s-scanner checks list [--scanner name] [--format table|json]")
+		os.Exit(2)
+	}
+	fs := flag.NewFlagSet("checks list", flag.ExitOnError)
+	var (
+		scannerName string
+		format      string
+	)
+	fs.StringVar(&scannerName, "scanner", "", "Only list the checks of this scanner: "+strings.Join(checks.Scanners(), "|"))
+	fs.StringVar(&format, "format", "table", "Output format: table|json")
+	_ = fs.Parse(args[1:])
+
+	list := checks.All()
+	if scannerName != "" {
+		if !slices.Contains(checks.Scanners(), scannerName) {
+			log.Fatalf("invalid --scanner %q (expected %s)", scannerName, strings.Join(checks.Scanners(), "|"))
+		}
+		list = slices.DeleteFunc(list, func(c checks.Check) bool { return c.Scanner != scannerName })
+	}
+
+	switch strings.ToLower(format) {
+	case "json":
+		b, _ := json.MarshalIndent(list, "", "  ")
+		fmt.Println(string(b))
+	case "table":
+		fmt.Printf("%-8s %-28s %-15s %-9s %-62s %s\n", "ID", "REASON", "SCANNER", "SEVERITY", "DESCRIPTION", "CONFIG")
+		fmt.Println(strings.Repeat("-", 150))
+		for _, c := range list {
+			fmt.Printf("%-8s %-28s %-15s %-9s %-62s %s\n", c.ID, c.Reason, c.Scanner, c.Severity, trunc(c.Description, 62), strings.Join(c.Config, ", "))
+		}
+		fmt.Println("\nScanners other than pods run with the flag of their name (or --profile). Hide checks with --ignore-reasons,")
+		fmt.Println("keep only some with --only-reasons; severities are overridden per namespace with overrides[].severity.")
+		fmt.Println("Policy rules, custom resources and plugins report their own reasons.")
+	default:
+		log.Fatalf("invalid --format %q (expected table|json)", format)
+	}
+}
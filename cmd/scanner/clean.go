@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/audit"
+	"github.com/ductnn/k8s-scanner/pkg/report"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/gc"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/pod"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// cleanKinds are the values accepted by --include, in display order.
+// The pod categories come first, followed by the kinds found by the gc scanner.
+var cleanKinds = []string{
+	pod.CleanEvicted, pod.CleanSucceeded, pod.CleanFailed,
+	"replicasets", "jobs", "configmaps", "secrets", "endpoints",
+}
+
+// gcKinds maps --include values to the gc.Resource kind they select
+var gcKinds = map[string]string{
+	"replicasets": "ReplicaSet",
+	"jobs":        "Job",
+	"configmaps":  "ConfigMap",
+	"secrets":     "Secret",
+	"endpoints":   "Endpoints",
+}
+
+// cleanOptions holds the flags of the clean mode
+type cleanOptions struct {
+	namespace string
+	ignoreNS  string
+	include   string
+	olderThan time.Duration
+	dryRun    bool
+	confirm   bool
+	gc        bool
+	gcOpts    gc.Options
+	outdir    string // directory for the audit files
+	cluster   string
+	auditCM   string // optional namespace/name of the audit ConfigMap
+}
+
+// cleanTarget is a resource selected for deletion
+type cleanTarget struct {
+	kind         string // --include value it matched
+	resourceKind string // Kubernetes kind, for the audit log
+	namespace    string
+	name         string
+	reason       string
+	delete       func(ctx context.Context) error
+}
+
+// parseCleanKinds validates a comma-separated --include list
+func parseCleanKinds(s string) (map[string]bool, error) {
+	kinds := make(map[string]bool)
+	for _, k := range strings.Split(s, ",") {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if k == "" {
+			continue
+		}
+		valid := false
+		for _, known := range cleanKinds {
+			if k == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown kind %q in --include (expected %s)", k, strings.Join(cleanKinds, ","))
+		}
+		kinds[k] = true
+	}
+	return kinds, nil
+}
+
+func handleClean(clientset kubernetes.Interface, opts cleanOptions) {
+	ctx := context.Background()
+
+	include, err := parseCleanKinds(opts.include)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	// --gc selects the kinds the gc scanner finds, except ConfigMaps and
+	// Secrets: controllers may read them through the API, so they are only
+	// deleted when listed in --include
+	if opts.gc {
+		for k := range gcKinds {
+			if k != "configmaps" && k != "secrets" {
+				include[k] = true
+			}
+		}
+	}
+
+	namespaces := parseNamespaces(opts.namespace)
+	ignored := parseIgnoredNamespaces(opts.ignoreNS)
+
+	var targets []cleanTarget
+	var errs []error
+
+	// Finished pods
+	categories := make(map[string]bool)
+	for _, c := range []string{pod.CleanEvicted, pod.CleanSucceeded, pod.CleanFailed} {
+		categories[c] = include[c]
+	}
+	if include[pod.CleanEvicted] || include[pod.CleanSucceeded] || include[pod.CleanFailed] {
+		pods, listErrs, err := pod.FindPodsToClean(ctx, clientset, namespaces, ignored, pod.CleanOptions{
+			Categories: categories,
+			OlderThan:  opts.olderThan,
+		})
+		if err != nil {
+			log.Fatalf("failed to clean pods: %v", err)
+		}
+		errs = append(errs, listErrs...)
+		for _, p := range pods {
+			targets = append(targets, cleanTarget{
+				kind: p.Category, resourceKind: "Pod", namespace: p.Namespace, name: p.Name, reason: p.Reason,
+				delete: func(ctx context.Context) error { return pod.DeletePod(ctx, clientset, p) },
+			})
+		}
+	}
+
+	// Orphaned and unused resources
+	wantGC := false
+	for k := range gcKinds {
+		wantGC = wantGC || include[k]
+	}
+	if wantGC {
+		gcOpts := opts.gcOpts
+		if opts.olderThan > 0 {
+			gcOpts.MinAge = opts.olderThan
+			gcOpts.JobTTL = opts.olderThan
+		}
+		found, err := gc.Find(ctx, clientset, namespaces, ignored, gcOpts)
+		if err != nil {
+			log.Fatalf("failed to find unused resources: %v", err)
+		}
+		for _, r := range found {
+			for k, kind := range gcKinds {
+				if kind == r.Kind && include[k] {
+					targets = append(targets, cleanTarget{
+						kind: k, resourceKind: r.Kind, namespace: r.Namespace, name: r.Name, reason: r.Reason,
+						delete: func(ctx context.Context) error { return gc.Delete(ctx, clientset, r) },
+					})
+				}
+			}
+		}
+	}
+
+	printCleanPlan(targets, opts.dryRun)
+	if len(targets) == 0 || opts.dryRun {
+		printCleanErrors(errs)
+		return
+	}
+
+	if opts.confirm {
+		// Without a terminal nobody can answer: fail rather than report
+		// success without deleting anything
+		if !isTerminal(os.Stdin) {
+			log.Fatalf("cannot ask for confirmation: stdin is not a terminal (use --confirm=false to delete without asking)")
+		}
+		if !confirmClean(len(targets)) {
+			fmt.Println("Aborted, nothing was deleted.")
+			return
+		}
+	}
+
+	auditLog := audit.NewLog(audit.WhoAmI(ctx, clientset), opts.cluster)
+	for _, t := range targets {
+		err := t.delete(ctx)
+		auditLog.Record(t.resourceKind, t.namespace, t.name, t.reason, err)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	fmt.Printf("\nDeleted %d of %d resource(s)\n", auditLog.Deleted(), len(targets))
+
+	// Keep a trace of what the tool deleted
+	base := "clean-audit-" + time.Now().Format("20060102-150405")
+	if opts.cluster != "" {
+		base = report.SanitizeClusterName(opts.cluster) + "-" + base
+	}
+	paths, err := auditLog.WriteFiles(opts.outdir, base)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to write audit log: %w", err))
+	} else {
+		fmt.Printf("Audit log written to %s\n", strings.Join(paths, ", "))
+	}
+	if opts.auditCM != "" {
+		if err := auditLog.WriteConfigMap(ctx, clientset, opts.auditCM); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	printCleanErrors(errs)
+}
+
+// printCleanPlan lists the targets grouped by kind
+func printCleanPlan(targets []cleanTarget, dryRun bool) {
+	if dryRun {
+		fmt.Println("\n=== Dry-run: Resources that would be deleted ===")
+	} else {
+		fmt.Println("\n=== Resources to delete ===")
+	}
+
+	if len(targets) == 0 {
+		fmt.Println("Nothing to clean.")
+		return
+	}
+
+	for _, kind := range cleanKinds {
+		var group []cleanTarget
+		for _, t := range targets {
+			if t.kind == kind {
+				group = append(group, t)
+			}
+		}
+		if len(group) == 0 {
+			continue
+		}
+
+		fmt.Printf("\n%s (%d)\n", kind, len(group))
+		fmt.Println("NAMESPACE | NAME | REASON")
+		fmt.Println(strings.Repeat("-", 60))
+		for _, t := range group {
+			fmt.Printf("%-9s | %-30s | %-18s\n", trunc(t.namespace, 9), trunc(t.name, 30), t.reason)
+		}
+	}
+
+	fmt.Printf("\nTotal: %d resource(s)", len(targets))
+	if dryRun {
+		fmt.Println(" (would be deleted)")
+	} else {
+		fmt.Println()
+	}
+}
+
+// confirmClean asks the user to confirm the deletion on stdin
+func confirmClean(n int) bool {
+	fmt.Printf("Delete %d resource(s)? [y/N]: ", n)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// isTerminal reports whether f is a character device (a terminal)
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func printCleanErrors(errs []error) {
+	if len(errs) == 0 {
+		return
+	}
+	fmt.Println("\n=== Errors ===")
+	for _, err := range errs {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// countGroups are the accepted values of --count-by
+var countGroups = []string{"severity", "namespace", "reason"}
+
+// countResult is the --count output with --format json
+type countResult struct {
+	Total int `json:"total"`
+	// Counts is keyed by severity, namespace or reason (with --count-by)
+	Counts map[string]int `json:"counts,omitempty"`
+}
+
+// printCount prints the number of issues, grouped by --count-by when set.
+// Grouped counts are printed one "<key> <count>" per line; severities are
+// always all listed so scripts can check for "critical 0".
+func printCount(issues []types.Issue, sum map[string]types.SeveritySummary, by, format string) {
+	res := countResult{Total: countIssues(sum)}
+	var keys []string
+	switch by {
+	case "severity":
+		res.Counts = map[string]int{}
+		for _, level := range severity.Levels {
+			keys = append(keys, string(level))
+			res.Counts[string(level)] = 0
+		}
+		for _, s := range sum {
+			res.Counts[string(severity.Critical)] += s.Critical
+			res.Counts[string(severity.High)] += s.High
+			res.Counts[string(severity.Medium)] += s.Medium
+			res.Counts[string(severity.Low)] += s.Low
+			res.Counts[string(severity.Info)] += s.Info
+		}
+	case "namespace":
+		res.Counts = map[string]int{}
+		for ns, s := range sum {
+			res.Counts[ns] = s.Critical + s.High + s.Medium + s.Low + s.Info
+		}
+	case "reason":
+		res.Counts = map[string]int{}
+		for _, is := range issues {
+			res.Counts[is.Reason]++
+		}
+	}
+	if by != "severity" {
+		// Most frequent first
+		for k := range res.Counts {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if res.Counts[keys[i]] != res.Counts[keys[j]] {
+				return res.Counts[keys[i]] > res.Counts[keys[j]]
+			}
+			return keys[i] < keys[j]
+		})
+	}
+
+	if strings.ToLower(format) == "json" {
+		b, _ := json.Marshal(res)
+		fmt.Println(string(b))
+		return
+	}
+	if by == "" {
+		fmt.Println(res.Total)
+		return
+	}
+	for _, k := range keys {
+		fmt.Printf("%s %d\n", k, res.Counts[k])
+	}
+}
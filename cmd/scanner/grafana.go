@@ -0,0 +1,26 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/ductnn/k8s-scanner/pkg/metrics"
+)
+
+// runGrafanaDashboard implements `k8s-scanner grafana-dashboard`, which prints
+// a Grafana dashboard for the exported Prometheus metrics
+func runGrafanaDashboard(args []string) {
+	fs := flag.NewFlagSet("grafana-dashboard", flag.ExitOnError)
+	opts := metrics.DefaultDashboardOptions()
+	fs.StringVar(&opts.Title, "title", opts.Title, "Dashboard title")
+	fs.DurationVar(&opts.StaleAfter, "stale-after", opts.StaleAfter, "Alert when no scan has completed for this long")
+	_ = fs.Parse(args)
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(metrics.GrafanaDashboard(opts)); err != nil {
+		log.Fatalf("failed to write dashboard: %v", err)
+	}
+}
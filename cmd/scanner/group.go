@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ductnn/k8s-scanner/pkg/report"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// issueGroups are the accepted values of --group-by
+var issueGroups = []string{"node", "node-pool", "image"}
+
+// issueNodes lists the nodes hosting issues
+func issueNodes(issues []types.Issue) []string {
+	seen := map[string]bool{}
+	var nodes []string
+	for _, is := range issues {
+		if is.NodeName != "" && !seen[is.NodeName] {
+			seen[is.NodeName] = true
+			nodes = append(nodes, is.NodeName)
+		}
+	}
+	return nodes
+}
+
+// printNodeGroups prints the issues of each node under a header with the
+// node's counts and drain state
+func printNodeGroups(issues []types.Issue, groups []report.NodeGroup) {
+	byNode := map[string][]types.Issue{}
+	for _, is := range issues {
+		node := is.NodeName
+		if node == "" {
+			node = report.NoNode
+		}
+		byNode[node] = append(byNode[node], is)
+	}
+	fmt.Println("\n=== Issues by Node ===")
+	for _, g := range groups {
+		s := g.Summary
+		fmt.Printf("\n%s: %d issue(s) (C:%d H:%d M:%d L:%d I:%d)", g.Label(), g.Issues, s.Critical, s.High, s.Medium, s.Low, s.Info)
+		if g.Node != report.NoNode {
+			fmt.Printf(", %s", g.DrainHint())
+		}
+		fmt.Println()
+		printIssuesTable(byNode[g.Node])
+	}
+}
+
+// printNodePoolGroups prints the issues of each node pool under a header
+// with the pool's nodes, instance types and zones and its top reasons
+func printNodePoolGroups(issues []types.Issue) {
+	byPool := map[string][]types.Issue{}
+	for _, is := range issues {
+		if is.NodeName == "" {
+			continue
+		}
+		pool := is.NodePool
+		if pool == "" {
+			pool = report.UnknownPool
+		}
+		byPool[pool] = append(byPool[pool], is)
+	}
+	fmt.Println("\n=== Issues by Node Pool ===")
+	groups := report.GroupByNodePool(issues)
+	if len(groups) == 0 {
+		fmt.Println("No issues bound to a node.")
+	}
+	for _, g := range groups {
+		s := g.Summary
+		fmt.Printf("\n%s: %d issue(s) on %d node(s) (C:%d H:%d M:%d L:%d I:%d)", g.Pool, g.Issues, g.Nodes, s.Critical, s.High, s.Medium, s.Low, s.Info)
+		if len(g.InstanceTypes) > 0 {
+			fmt.Printf(", %s", strings.Join(g.InstanceTypes, "/"))
+		}
+		if len(g.Zones) > 0 {
+			fmt.Printf(", zones %s", strings.Join(g.Zones, "/"))
+		}
+		fmt.Println()
+		printIssuesTable(byPool[g.Pool])
+	}
+}
+
+// printImageGroups prints one finding per failing image with its affected
+// workloads, then the other issues
+func printImageGroups(issues []types.Issue) {
+	groups, rest := report.GroupByImage(issues)
+	fmt.Println("\n=== Issues by Image ===")
+	if len(groups) == 0 {
+		fmt.Println("No crashing or unpullable images.")
+	}
+	for _, g := range groups {
+		fmt.Printf("\n[%s] %s: %d pod(s) in %d namespace(s), %s\n", strings.ToUpper(string(g.Severity)), g.Image,
+			g.Pods, len(g.Namespaces), strings.Join(g.Reasons, ", "))
+		for _, w := range g.Workloads {
+			fmt.Printf("  - %s\n", w)
+		}
+	}
+	if len(rest) > 0 {
+		fmt.Println("\n=== Other Issues ===")
+		printIssuesTable(rest)
+	}
+}
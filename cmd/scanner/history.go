@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ductnn/k8s-scanner/pkg/report"
+)
+
+// runHistoryArchive implements `k8s-scanner history archive --before <date>`,
+// which bundles old exported reports into a tar.gz to save disk space
+func runHistoryArchive(args []string) {
+	fs := flag.NewFlagSet("history archive", flag.ExitOnError)
+	var outdir, before string
+	fs.StringVar(&outdir, "outdir", ".reports", "Directory of the exported reports")
+	fs.StringVar(&before, "before", "", "Archive reports exported before this date (e.g. 2024-01 or 2024-01-15)")
+	_ = fs.Parse(args)
+	if before == "" {
+		fmt.Fprintln(os.Stderr, "USAGE:\n  k8s-scanner history archive --before 2024-01 [--outdir .reports]")
+		os.Exit(2)
+	}
+
+	at, err := report.ParseBefore(before)
+	if err != nil {
+		log.Fatalf("invalid --before: %v", err)
+	}
+	path, n, err := report.Archive(outdir, at)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if n == 0 {
+		fmt.Printf("No reports exported before %s\n", before)
+		return
+	}
+	fmt.Printf("Archived %d file(s) to %s\n", n, path)
+}
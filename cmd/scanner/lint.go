@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ductnn/k8s-scanner/pkg/lint"
+	"github.com/ductnn/k8s-scanner/pkg/policy"
+	"github.com/ductnn/k8s-scanner/pkg/report"
+	"github.com/ductnn/k8s-scanner/pkg/scanner"
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// runLint implements `k8s-scanner lint`, which runs the spec-based checks
+// against YAML/JSON manifests without connecting to a cluster
+func runLint(args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	var (
+		file       string // manifest file, directory or "-" for stdin
+		format     string // json|table
+		failOn     string // minimum severity that makes the command exit non-zero
+		policyPath string // policy file or directory of custom rules
+	)
+	fs.StringVar(&file, "f", "", "Manifest file, directory (recursive) or '-' for stdin")
+	fs.StringVar(&format, "format", "table", "Output format: json|table")
+	fs.StringVar(&policyPath, "policy", "", "Also evaluate the custom rules of this policy file or directory (any kind, including custom resources)")
+	fs.StringVar(&failOn, "fail-on", "high", "Exit with code 1 if an issue at or above this severity is found: critical|high|medium|low|info|none")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Lint Kubernetes manifests offline (probes, requests/limits, image policy, security context)
+
+USAGE:
+  k8s-scanner lint -f <file|dir|-> [OPTIONS]
+
+OPTIONS:
+`)
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), `
+EXAMPLES:
+  k8s-scanner lint -f manifests/
+  helm template ./chart | k8s-scanner lint -f - --fail-on medium
+  k8s-scanner lint -f manifests/ --policy deploy/examples/policy.yaml
+`)
+	}
+	_ = fs.Parse(args)
+
+	if file == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var failLevel severity.Level // empty never fails
+	if !strings.EqualFold(failOn, "none") {
+		level, err := severity.Parse(failOn)
+		if err != nil {
+			log.Fatalf("invalid --fail-on: %v", err)
+		}
+		failLevel = level
+	}
+
+	opts := lint.Options{Warn: func(err error) { log.Printf("warning: policy: %v", err) }}
+	if policyPath != "" {
+		set, err := policy.Load(policyPath)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		opts.Policy = set
+	}
+
+	issues, err := lint.Lint(file, opts)
+	if err != nil {
+		log.Fatalf("lint failed: %v", err)
+	}
+	types.AssignIDs(issues, "")
+	report.SortIssues(issues)
+	sum := scanner.SummarizeByNamespace(issues)
+
+	switch strings.ToLower(format) {
+	case "json":
+		obj := map[string]any{"issues": issues, "summary": sum}
+		b, _ := json.MarshalIndent(obj, "", "  ")
+		fmt.Println(string(b))
+	default:
+		fmt.Println("\n=== Lint Issues ===")
+		printIssuesTable(issues)
+		fmt.Printf("\nTotal: %d issue(s)\n", len(issues))
+	}
+
+	for _, is := range issues {
+		if severity.AtLeast(is.Severity, failLevel) {
+			os.Exit(1)
+		}
+	}
+}
@@ -1,32 +1,67 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
+	"net/http"
 	"os"
 	"path/filepath"
+	"runtime/debug"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/ductnn/k8s-scanner/pkg/cloud"
+	"github.com/ductnn/k8s-scanner/pkg/config"
+	"github.com/ductnn/k8s-scanner/pkg/crd"
+	"github.com/ductnn/k8s-scanner/pkg/inventory"
 	"github.com/ductnn/k8s-scanner/pkg/k8s"
 	"github.com/ductnn/k8s-scanner/pkg/metrics"
+	"github.com/ductnn/k8s-scanner/pkg/offline"
+	"github.com/ductnn/k8s-scanner/pkg/operator"
+	"github.com/ductnn/k8s-scanner/pkg/plugin"
+	"github.com/ductnn/k8s-scanner/pkg/policy"
+	"github.com/ductnn/k8s-scanner/pkg/registry"
 	"github.com/ductnn/k8s-scanner/pkg/report"
 	"github.com/ductnn/k8s-scanner/pkg/scanner"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/capacity"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/controlplane"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/custom"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/gc"
 	"github.com/ductnn/k8s-scanner/pkg/scanner/pod"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/quota"
+	"github.com/ductnn/k8s-scanner/pkg/snapshot"
+	"github.com/ductnn/k8s-scanner/pkg/tracing"
 	"github.com/ductnn/k8s-scanner/pkg/types"
+	"github.com/ductnn/k8s-scanner/pkg/version"
 
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 )
 
+// streamPageSize is the number of pods fetched per page with --max-memory
+const streamPageSize = 500
+
 func printUsage() {
 	fmt.Fprintf(flag.CommandLine.Output(), `k8s-scanner - Kubernetes cluster issues scanner
 
 USAGE:
   k8s-scanner [OPTIONS]
+  k8s-scanner lint -f <file|dir|-> [OPTIONS]
+  k8s-scanner snapshot create <file> [OPTIONS]
+  k8s-scanner baseline save [OPTIONS]
+  k8s-scanner history [archive --before <date>] [OPTIONS]
+  k8s-scanner serve [--addr localhost:8080] [--interval 10m] [OPTIONS]
+  k8s-scanner checks list [--scanner name] [--format table|json]
 
 OPTIONS:
 `)
@@ -46,10 +81,40 @@ EXAMPLES:
   # Show history of all reports
   k8s-scanner --history
 
+  # List the last week of prod reports as JSON
+  k8s-scanner --history --since 7d --cluster prod --format json
+
+  # Gzip exports on a cron host, and bundle reports older than 2024 into
+  # <outdir>/archive/reports-before-20240101.tar.gz
+  k8s-scanner --export json,html --compress
+  k8s-scanner history archive --before 2024-01
+
+  # Write an export to stdout for a pipeline instead of a file
+  k8s-scanner --export md --output - | gh gist create -
+
+  # Organize exports per cluster and day (reports/prod/2024-01-15/report-093000.json);
+  # --history and --diff read the subdirectories
+  k8s-scanner --cluster-name prod --export json --filename-template "{{.Cluster}}/{{.Date}}/report-{{.Time}}"
+
+  # Also write each namespace its own report (reports/tenants/<namespace>/...)
+  # to hand to the tenant; team-label splits by --team-label instead
+  k8s-scanner --export json,html --split-by namespace
+  k8s-scanner --export html --team-label team --split-by team-label
+
   # Compare two reports (by timestamp or filename)
   k8s-scanner --diff "20251109-210646,20251109-210704"
   k8s-scanner --diff "k8s-report-20251109-210646.json,k8s-report-20251109-210704.json"
 
+  # Check that issues seen in staging are gone in prod (issues are matched
+  # by workload across clusters)
+  k8s-scanner --diff "staging-k8s-report-20251109-210646.json,prod-k8s-report-20251109-211002.json" --cross-cluster
+
+  # Compare an archived CSV export with a new JSON report
+  k8s-scanner --diff "k8s-report-20251109-210646.csv,k8s-report-20251109-210704.json"
+
+  # Write the comparison as a report to paste into a change ticket
+  k8s-scanner --diff "20251109-210646,20251109-210704" --export md,html
+
   # Use custom kubeconfig
   k8s-scanner --kubeconfig /path/to/config
 
@@ -59,6 +124,14 @@ EXAMPLES:
   # Output in JSON format
   k8s-scanner --format json
 
+  # List every check with its ID, default severity and settings
+  k8s-scanner checks list --scanner pods
+
+  # Pick the scanners with a profile: quick (pods only, no events), standard
+  # (adds --topology and --best-practices) or deep (every scanner)
+  k8s-scanner --profile quick
+  k8s-scanner --profile deep --capacity=false
+
   # Enable Prometheus metrics server
   k8s-scanner --metrics --metrics-port 9090
 
@@ -68,56 +141,420 @@ EXAMPLES:
   # Use custom cluster name for output files
   k8s-scanner --cluster-name "production" --export json,html
 
+  # Report every finding instead of the highest priority one per container
+  k8s-scanner --dedup off
+
+  # Only report crash loops and image pull failures
+  k8s-scanner --only-reasons CrashLoopBackOff,ImagePullBackOff
+
+  # Skip transient waiting reasons on busy clusters
+  k8s-scanner --ignore-reasons ContainerCreating,PodInitializing
+
+  # Leave out pods stopped by spot/preemptible node reclaims
+  k8s-scanner --ignore-reasons SpotInterruption
+
+  # Only report pods that have been Pending for more than 5 minutes
+  k8s-scanner --pending-grace 5m
+
+  # Add org-specific checks written as rules
+  k8s-scanner --policy deploy/examples/policy.yaml
+
+  # Run third-party scanners (see deploy/examples/plugins)
+  k8s-scanner --plugins-dir ~/.k8s-scanner/plugins
+
+  # Flag degraded/out-of-sync Argo CD apps and failed Flux reconciliations
+  k8s-scanner --gitops
+
+  # Check Istio sidecars and mTLS settings
+  k8s-scanner --mesh --namespace shop
+
+  # Flag Karpenter NodeClaims that fail to launch and NodePools at their limits
+  k8s-scanner --karpenter
+
+  # Air-gapped environments: contact nothing but the API server, and check
+  # that no report or dashboard loads remote assets
+  k8s-scanner --offline --export html
+  k8s-scanner offline-check
+
+  # Tell a control plane upgrade or a degraded node pool apart (aws/gcloud/az CLI)
+  k8s-scanner --cloud auto
+  k8s-scanner --cloud aks:my-resource-group/prod
+
+  # Explain ImagePullBackOff: missing tag, private registry or wrong architecture
+  k8s-scanner --registry-check
+
+  # Flag quotas more than 80 percent used and namespaces without any quota
+  k8s-scanner --quota --quota-threshold 80 --require-quota
+
+  # Tell capacity shortages (preemption, higher-priority pods) from misconfiguration
+  k8s-scanner --priority
+
+  # Check cluster DNS (CoreDNS/kube-dns) and the control plane components
+  k8s-scanner --dns --control-plane
+
+  # Find the broken admission webhook behind "cannot create pods"
+  k8s-scanner --webhooks
+
+  # Flag workloads that a single node or zone failure would take down
+  k8s-scanner --topology
+
+  # Accept the current findings of an old cluster, then only report new
+  # issues and the accepted ones that were fixed
+  k8s-scanner baseline save
+  k8s-scanner
+
+  # Report per-team issue counts and flag teams with more than 2 critical issues
+  k8s-scanner --team-label team --team-max-critical 2
+
+  # Also export a CycloneDX inventory of every workload and image, healthy or not
+  k8s-scanner --export json,inventory
+
+  # Export a report to send to a vendor, with hashed names and no event messages
+  k8s-scanner --redact --export html,json
+
+  # Give new pods 5 minutes to pull images before ContainerCreating is reported
+  k8s-scanner --startup-grace 5m
+
+  # Report pods still Terminating 10 minutes after their grace period expired
+  k8s-scanner --terminating-margin 10m
+
+  # Report Running pods that have been out of Service endpoints for 15 minutes
+  k8s-scanner --unready-after 15m
+
+  # Also flag busy nodes and namespaces far from their requests (needs metrics-server)
+  k8s-scanner --capacity --node-cpu-threshold 80 --node-memory-threshold 80
+
+  # Load settings (e.g. over-provisioned workload hints) from a config file
+  k8s-scanner --config deploy/examples/config.yaml
+
+  # List issues per node, with whether PodDisruptionBudgets block draining it
+  k8s-scanner --group-by node
+
+  # Show a bad release as one finding per image with the workloads it breaks
+  k8s-scanner --group-by image
+
+  # Trace capacity and eviction issues to node pools (implies --node-context)
+  k8s-scanner --group-by node-pool
+
   # Output only the count of issues
   k8s-scanner --count
 
+  # Fail a pipeline step on any critical issue
+  test "$(k8s-scanner --count-by severity --format json | jq .counts.critical)" -eq 0
+
   # Clean evicted pods and completed jobs (dry-run)
   k8s-scanner --clean --dry-run
 
-  # Clean evicted pods and completed jobs (actually delete)
+  # Clean evicted pods and completed jobs (actually delete, after confirmation)
   k8s-scanner --clean
 
+  # Report orphaned ReplicaSets, expired Jobs, unused ConfigMaps/Secrets and dangling Endpoints
+  k8s-scanner --gc
+
+  # Also delete them when cleaning (preview first with --dry-run); unused
+  # ConfigMaps and Secrets are only deleted when listed in --include
+  k8s-scanner --clean --gc --dry-run
+  k8s-scanner --clean --include configmaps,secrets --namespace shop --dry-run
+
+  # Clean failed pods and orphaned ReplicaSets older than 3 days, without prompting
+  k8s-scanner --clean --include failed,replicasets --older-than 72h --confirm=false
+
+  # Deletions are audited in <outdir>/clean-audit-*.json|csv; also keep a copy in the cluster
+  k8s-scanner --clean --audit-configmap kube-system/k8s-scanner-audit
+
   # Clean pods in specific namespace(s)
   k8s-scanner --clean --namespace "default,test"
 
+  # Write results into the cluster as ScanReport/ClusterIssue resources
+  # (install the CRDs from deploy/crds first)
+  k8s-scanner --crd-report latest
+
+  # Lint manifests offline (no cluster required)
+  k8s-scanner lint -f manifests/
+  kustomize build . | k8s-scanner lint -f -
+
+  # Record a cluster snapshot and scan it offline later
+  k8s-scanner snapshot create snapshot.json
+  k8s-scanner scan --from-snapshot snapshot.json
+
+  # Show allocatable vs requested resources and pods per node
+  k8s-scanner capacity
+
+  # Check which scanners the current credentials are allowed to run
+  k8s-scanner check-access --namespace default
+
+  # Scan a very large cluster (100k+ pods) under a 1 GiB soft memory cap:
+  # pods are streamed page by page instead of being loaded all at once
+  k8s-scanner --max-memory 1Gi --no-events
+
+  # Stream issues as NDJSON while scanning, also appending them to an export file
+  k8s-scanner --format ndjson --export ndjson | jq -c 'select(.severity == "critical")'
+
+  # Print the scanner build and the cluster's Kubernetes version
+  k8s-scanner version
+
+  # Trace the scan phases (pod/event listing, each scanner, export) to an
+  # OpenTelemetry collector over OTLP/HTTP
+  k8s-scanner --otlp-endpoint http://otel-collector:4318 --export json
+
+  # Generate a Grafana dashboard for the Prometheus metrics (--metrics or serve)
+  k8s-scanner grafana-dashboard --stale-after 1h > dashboard.json
+
+  # Serve the scan API and dashboard (http://localhost:8080/ui), scanning every
+  # 10 minutes and on demand
+  k8s-scanner serve --interval 10m --export json
+
+  # Accept remote clients, which must send the token (open the dashboard once
+  # as /ui/?token=<token>)
+  k8s-scanner serve --addr :8080 --token-file /etc/k8s-scanner/token --interval 10m
+  curl -H "Authorization: Bearer $(cat /etc/k8s-scanner/token)" host:8080/api/v1/reports/latest
+
+  # Scan every minute, but only namespaces whose pods or events changed
+  # (with a full rescan every hour)
+  k8s-scanner serve --interval 1m --incremental --full-rescan 1h
+
+  # Also serve the gRPC API (pkg/server/scannerpb/scanner.proto)
+  k8s-scanner serve --interval 10m --export json --grpc-addr localhost:9090
+
+  # Scan one namespace on demand; the result is returned but does not replace
+  # the latest cluster-wide report
+  curl -X POST localhost:8080/api/v1/scan -d '{"namespaces":["shop"],"selector":"app=web"}'
+
+  # Post a daily 9:00 digest (totals, trend, top namespaces) to Slack
+  k8s-scanner serve --interval 10m --digest '0 9 * * *' --notify slack=https://hooks.slack.com/services/T000/B000/XXX
+
+  # Send each team its own digest (teams.notify in the config, or the
+  # scanner.ductnn.io/notify namespace annotation), the rest to a default channel
+  k8s-scanner serve --config config.yaml --digest '0 9 * * *' --notify slack=https://hooks.slack.com/services/T000/B000/XXX
+
+  # Run as an operator that reconciles ScanSchedule resources
+  k8s-scanner --operator
+
 `)
 }
 
 func main() {
+	// Subcommands
+	baselineSave := false
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "lint":
+			runLint(os.Args[2:])
+			return
+		case "snapshot":
+			runSnapshot(os.Args[2:])
+			return
+		case "capacity":
+			runCapacity(os.Args[2:])
+			return
+		case "checks":
+			runChecks(os.Args[2:])
+			return
+		case "check-access":
+			runCheckAccess(os.Args[2:])
+			return
+		case "version":
+			runVersion(os.Args[2:])
+			return
+		case "serve":
+			runServe(os.Args[2:])
+			return
+		case "grafana-dashboard":
+			runGrafanaDashboard(os.Args[2:])
+			return
+		case "offline-check":
+			runOfflineCheck(os.Args[2:])
+			return
+		case "baseline":
+			if len(os.Args) < 3 || os.Args[2] != "save" {
+				fmt.Fprintln(os.Stderr, "USAGE:\n  k8s-scanner baseline save [--baseline file] [OPTIONS]")
+				os.Exit(2)
+			}
+			// "baseline save" runs a regular scan and records its findings
+			baselineSave = true
+			os.Args = append(os.Args[:1], os.Args[3:]...)
+		case "history":
+			if len(os.Args) > 2 && os.Args[2] == "archive" {
+				runHistoryArchive(os.Args[3:])
+				return
+			}
+			// "history" lists reports like --history
+			os.Args = append([]string{os.Args[0], "--history"}, os.Args[2:]...)
+		case "scan":
+			// "scan" is the default command; drop it so the flags below apply
+			os.Args = append(os.Args[:1], os.Args[2:]...)
+		}
+	}
+
 	// Customize help output
 	flag.Usage = printUsage
 	var (
 		namespace        string
-		format           string // json|table  (console output)
-		exportOpt        string // csv,md,html,json  (comma-separated)
-		outdir           string // output directory for exported files
-		restartThreshold int    // threshold for restart count to be considered high severity
-		kubeconfig       string // path to kubeconfig file
-		history          bool   // show history of reports
-		diff             string // compare two reports (format: "old,new" or directory names)
-		metricsPort      int    // port for Prometheus metrics server
-		enableMetrics    bool   // enable Prometheus metrics server
-		ignoreNS         string // comma-separated list of namespaces to ignore
-		clusterName      string // cluster name for output files (auto-detected if not provided)
-		count            bool   // output only the count of issues
-		clean            bool   // clean evicted pods and completed jobs
-		dryRun           bool   // dry-run mode for clean (show what would be deleted without deleting)
+		format           string        // json|table  (console output)
+		exportOpt        string        // csv,md,html,json,ndjson,inventory  (comma-separated)
+		outdir           string        // output directory for exported files
+		output           string        // write the single export here instead of outdir ("-" is stdout)
+		compress         bool          // gzip exported files
+		filenameTemplate string        // name of exported files, may contain subdirectories
+		restartThreshold int           // threshold for restart count to be considered high severity
+		kubeconfig       string        // path to kubeconfig file
+		history          bool          // show history of reports
+		historySince     string        // history: only reports newer than this (e.g. 7d)
+		historyCluster   string        // history: only reports of this cluster
+		diff             string        // compare two reports (format: "old,new" or directory names)
+		crossCluster     bool          // allow diffing reports of different clusters
+		metricsPort      int           // port for Prometheus metrics server
+		enableMetrics    bool          // enable Prometheus metrics server
+		ignoreNS         string        // comma-separated list of namespaces to ignore
+		clusterName      string        // cluster name for output files (auto-detected if not provided)
+		count            bool          // output only the count of issues
+		countBy          string        // count: group by severity|namespace|reason
+		groupBy          string        // console: group issues by node
+		nodeContext      bool          // add node pool, instance type and zone to issues
+		splitBy          string        // exports: also write per-namespace or per-team reports
+		clean            bool          // clean evicted pods and completed jobs
+		dryRun           bool          // dry-run mode for clean (show what would be deleted without deleting)
+		cleanInclude     string        // comma-separated kinds to clean
+		olderThan        time.Duration // only clean resources older than this
+		confirm          bool          // ask before deleting
+		auditConfigMap   string        // namespace/name of a ConfigMap recording clean runs
+		crdReport        string        // name of the ScanReport custom resource to write results into
+		operatorMode     bool          // run as an operator reconciling ScanSchedule resources
+		fromSnapshot     string        // scan a recorded snapshot file instead of the live cluster
+		dedup            string        // pod|container|off: issue aggregation granularity
+		escalateAfter    time.Duration // escalate severity of containers stuck waiting longer than this
+		pendingGrace     time.Duration // only report pods pending longer than this
+		startupGrace     time.Duration // only report ContainerCreating/PodInitializing in pods older than this
+		termMargin       time.Duration // report pods stuck in Terminating longer than this past their deadline
+		unreadyAfter     time.Duration // report Running pods not Ready for longer than this
+		eventMaxAge      time.Duration // ignore older events when picking the last event
+		noEvents         bool          // skip event enrichment
+		scannerTimeout   time.Duration // per-scanner time limit
+		maxMemory        string        // soft memory cap; switches the pod scanner to streaming
+		capacityScan     bool          // enable the metrics-server based capacity scanner
+		capacityOpts     = capacity.DefaultOptions()
+		configPath       string // optional YAML configuration file
+		profileName      string // scanner selection bundle
+		gcScan           bool   // report (or with --clean, delete) orphaned and unused resources
+		gcOpts           = gc.DefaultOptions()
+		quotaScan        bool // report ResourceQuota/LimitRange problems
+		priorityScan     bool // report preempted pods and pods pending behind higher priorities
+		topologyScan     bool // report workloads with every replica on one node or zone
+		bestPractices    bool // lint the pod templates of live workloads
+		dnsScan          bool // check CoreDNS/kube-dns health
+		controlPlaneScan bool // check the control plane components
+		webhookScan      bool // report unavailable failurePolicy=Fail admission webhooks
+		quotaOpts        = quota.DefaultOptions()
+		allowMissingNS   bool              // warn instead of failing on nonexistent --namespace entries
+		quiet            bool              // disable the progress display
+		verbose          bool              // print per-phase timings
+		otlpEndpoint     string            // OTLP/HTTP collector receiving scan traces
+		overrides        []pod.Override    // per-namespace/selector settings from --config
+		customResources  []custom.Resource // custom resources and rules from --config
+		onlyReasons      string            // report only these issue reasons
+		policyPath       string            // policy file or directory of custom rules
+		pluginsDir       string            // directory of external scanner executables
+		gitopsScan       bool              // report Argo CD/Flux sync health
+		meshScan         bool              // report Istio sidecar and mTLS problems
+		karpenterScan    bool              // report Karpenter provisioning failures
+		cloudCluster     string            // managed cluster whose health the cloud provider reports
+		offlineMode      bool              // air-gapped: only the API server is contacted
+		registryCheck    bool              // ask registries why images cannot be pulled
+		registryAuth     string            // token services trusted besides the registries
+		ignoreReasons    string            // never report these issue reasons
+		pprof            bool              // expose /debug/pprof on the metrics server
+		baselineFile     string            // accepted findings hidden from reports
+		noBaseline       bool              // report every finding even if a baseline exists
+		teamLabel        string            // pod/namespace label naming the team owning an issue
+		labelKeys        string            // pod/namespace labels and annotations copied into issues
+		teamMaxCritical  int               // critical issues each team may have (negative: unlimited)
+		teamBudgets      report.TeamBudgets
+		redact           bool // anonymize names in every output
+		redactRules      = report.DefaultRedactRules()
+		rootCauses       map[string]pod.RootCause // custom root causes from --config
 	)
 	flag.StringVar(&namespace, "namespace", "", "Namespace(s) to scan: comma-separated list (e.g., 'ns-1,ns-2') or empty for all")
-	flag.StringVar(&format, "format", "table", "Console output format: json|table")
-	flag.StringVar(&exportOpt, "export", "", "Export report file(s): csv,md,html,json (comma-separated)")
-	flag.StringVar(&outdir, "outdir", ".reports", "Directory to write exported reports")
+	flag.BoolVar(&quiet, "quiet", false, "Do not display scan progress on stderr")
+	flag.BoolVar(&verbose, "verbose", false, "Print each scan phase and how long it took on stderr")
+	flag.BoolVar(&allowMissingNS, "allow-missing-ns", false, "Warn and skip --namespace entries that do not exist instead of failing")
+	flag.StringVar(&format, "format", "table", "Console output format: json|table|ndjson (ndjson streams issues as they are found); with json and ndjson only data is written to stdout, messages go to stderr")
+	flag.StringVar(&exportOpt, "export", "", "Export report file(s): csv,md,html,json,ndjson,inventory (comma-separated); inventory is a CycloneDX list of every scanned workload and image")
+	flag.StringVar(&outdir, "outdir", ".reports", "Directory to write exported reports (with --operator, each ScanSchedule writes to its outdir, or else its name, under this directory)")
+	flag.StringVar(&output, "output", "", "Write the export (a single --export format) to this file instead of --outdir; - writes it to stdout in place of the console output")
+	flag.BoolVar(&compress, "compress", false, "Gzip exported report files (--history and --diff read them; see 'k8s-scanner history archive' for old reports)")
+	flag.StringVar(&filenameTemplate, "filename-template", "", "Go template of exported file names without extension, relative to --outdir; fields .Cluster, .Date, .Time and .Timestamp (default: [cluster-]k8s-report-{{.Timestamp}})")
 	flag.IntVar(&restartThreshold, "restart-threshold", 10, "Restart count threshold for high severity (default: 10)")
 	flag.StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file (default: $KUBECONFIG or ~/.kube/config)")
 	flag.BoolVar(&history, "history", false, "Show history of all reports")
-	flag.StringVar(&diff, "diff", "", "Compare two reports (format: 'old,new' directory names or 'old,new' paths)")
+	flag.StringVar(&historySince, "since", "", "History: only list reports generated within this period (e.g. 7d, 12h)")
+	flag.StringVar(&historyCluster, "cluster", "", "History: only list reports of this cluster")
+	flag.BoolVar(&crossCluster, "cross-cluster", false, "Diff: allow comparing reports of different clusters (issues are then matched by workload)")
+	flag.StringVar(&diff, "diff", "", "Compare two reports (format: 'old,new' directory names or 'old,new' paths; .csv exports are accepted)")
 	flag.BoolVar(&enableMetrics, "metrics", false, "Enable Prometheus metrics server")
 	flag.IntVar(&metricsPort, "metrics-port", 9090, "Port for Prometheus metrics server (default: 9090)")
+	flag.BoolVar(&pprof, "pprof", false, "Also serve /debug/pprof on the metrics server")
 	flag.StringVar(&ignoreNS, "ignore-ns", "", "Comma-separated list of namespaces to ignore (e.g., 'kube-system,kube-public')")
 	flag.StringVar(&clusterName, "cluster-name", "", "Cluster name for output files (auto-detected from kubeconfig if not provided)")
-	flag.BoolVar(&count, "count", false, "Output only the count of issues found")
-	flag.BoolVar(&clean, "clean", false, "Clean evicted pods and completed jobs")
+	flag.BoolVar(&count, "count", false, "Output only the count of issues found (as {\"total\": n} with --format json)")
+	flag.StringVar(&groupBy, "group-by", "", "Group console issues: node (with whether PodDisruptionBudgets would block draining it), node-pool (implies --node-context) or image (one finding per crashing or unpullable image)")
+	flag.BoolVar(&nodeContext, "node-context", false, "Add the node pool, instance type and zone of their node (from the node labels) to issues bound to a node")
+	flag.StringVar(&splitBy, "split-by", "", "Also export one report per tenant in <outdir>/tenants/<tenant>: namespace or team-label (the team of --team-label)")
+	flag.StringVar(&countBy, "count-by", "", "Output issue counts grouped by severity|namespace|reason (implies --count)")
+	flag.BoolVar(&clean, "clean", false, "Clean evicted pods and completed jobs (see --include for other kinds)")
 	flag.BoolVar(&dryRun, "dry-run", false, "Dry-run mode for clean (show what would be deleted without actually deleting)")
+	flag.StringVar(&cleanInclude, "include", "evicted,succeeded", "Clean: kinds to delete: "+strings.Join(cleanKinds, ","))
+	flag.DurationVar(&olderThan, "older-than", 0, "Clean: only delete resources that finished or were created longer ago than this (e.g. 72h)")
+	flag.StringVar(&auditConfigMap, "audit-configmap", "", "Clean: also record the audit log in this ConfigMap (namespace/name)")
+	flag.BoolVar(&confirm, "confirm", true, "Clean: ask for confirmation before deleting; fails when stdin is not a terminal, so use --confirm=false in automation")
+	flag.BoolVar(&operatorMode, "operator", false, "Run as an operator that executes scans declared by ScanSchedule resources")
+	flag.StringVar(&crdReport, "crd-report", "", "Write results to the cluster as a ScanReport (with ClusterIssue objects) of this name")
+	flag.StringVar(&dedup, "dedup", "container", "Issue aggregation: pod (highest priority issue per pod), container (per container) or off (all findings)")
+	flag.DurationVar(&escalateAfter, "escalate-after", 24*time.Hour, "Raise severity by one level for containers stuck in a waiting state longer than this (0 to disable)")
+	flag.DurationVar(&pendingGrace, "pending-grace", 2*time.Minute, "Only report Pending pods older than this grace period")
+	flag.DurationVar(&startupGrace, "startup-grace", scanner.DefaultStartupGrace, "Only report containers waiting in ContainerCreating or PodInitializing once their pod is older than this (negative reports them at once)")
+	flag.DurationVar(&termMargin, "terminating-margin", 5*time.Minute, "Report pods still Terminating this long after their deletion grace period expired")
+	flag.DurationVar(&unreadyAfter, "unready-after", 5*time.Minute, "Report Running pods that have not been Ready for longer than this")
+	flag.DurationVar(&eventMaxAge, "event-max-age", time.Hour, "Ignore events older than this when picking an issue's last event (negative keeps all)")
+	flag.StringVar(&onlyReasons, "only-reasons", "", "Only report these issue reasons or check IDs, comma-separated (e.g. 'CrashLoopBackOff,POD002'; see 'checks list')")
+	flag.StringVar(&ignoreReasons, "ignore-reasons", "", "Never report these issue reasons or check IDs, comma-separated (e.g. 'Completed,POD012')")
+	flag.BoolVar(&noEvents, "no-events", false, "Skip fetching events for faster scans (the LAST EVENT column stays empty)")
+	flag.DurationVar(&scannerTimeout, "scanner-timeout", 0, "Time limit for each scanner; optional scanners (--capacity, --gc) that exceed it are skipped with a warning (0 for no limit)")
+	flag.StringVar(&maxMemory, "max-memory", "", "Soft memory cap (e.g. 512Mi, 2Gi). Sets the Go memory limit and streams pods page by page instead of loading them all; meant for clusters with 100k+ pods")
+	flag.BoolVar(&capacityScan, "capacity", false, "Also flag overloaded nodes and namespaces whose usage is far from their requests (requires metrics-server)")
+	flag.Float64Var(&capacityOpts.NodeCPUPercent, "node-cpu-threshold", capacityOpts.NodeCPUPercent, "Capacity: flag nodes using more than this percentage of allocatable CPU")
+	flag.Float64Var(&capacityOpts.NodeMemoryPercent, "node-memory-threshold", capacityOpts.NodeMemoryPercent, "Capacity: flag nodes using more than this percentage of allocatable memory")
+	flag.Float64Var(&capacityOpts.UsageRatio, "usage-ratio", capacityOpts.UsageRatio, "Capacity: flag namespaces using more than N times, or less than 1/N of, their requests")
+	flag.BoolVar(&gitopsScan, "gitops", false, "Report degraded/out-of-sync Argo CD Applications and Flux Kustomizations/HelmReleases that failed to reconcile")
+	flag.BoolVar(&meshScan, "mesh", false, "Report Istio sidecars that are not ready or missing and DestinationRules conflicting with PeerAuthentication mTLS")
+	flag.BoolVar(&karpenterScan, "karpenter", false, "Report Karpenter NodeClaims that failed to launch, register or drifted and NodePools not ready or at their limits, and add them to the root cause of Pending pods")
+	flag.StringVar(&cloudCluster, "cloud", "", "Report whether the managed control plane is upgrading or node pools are degraded, through the aws, gcloud or az CLI: auto (EKS/GKE from the kubeconfig context), eks:<region>/<cluster>, gke:<project>/<location>/<cluster> or aks:<resource-group>/<cluster>")
+	flag.BoolVar(&offlineMode, "offline", false, "Air-gapped mode: contact nothing but the Kubernetes API server (refuses --registry-check, --cloud, --plugins-dir and --otlp-endpoint; see 'k8s-scanner offline-check')")
+	flag.BoolVar(&registryCheck, "registry-check", false, "Query the registry of images in ImagePullBackOff to tell a missing tag, required credentials and a wrong platform apart")
+	flag.StringVar(&registryAuth, "registry-auth-hosts", "", "With --registry-check, token services to trust besides each registry's own host and "+strings.Join(registry.DefaultAuthHosts, ",")+", comma-separated host[:port] (e.g. gitlab.example.com)")
+	flag.BoolVar(&quotaScan, "quota", false, "Report ResourceQuotas close to exhaustion and pods rejected by a quota or LimitRange")
+	flag.Float64Var(&quotaOpts.Threshold, "quota-threshold", quotaOpts.Threshold, "Quota: flag ResourceQuotas using more than this percentage of a hard limit")
+	flag.BoolVar(&quotaOpts.RequireQuota, "require-quota", false, "Quota: also flag namespaces without a ResourceQuota (implies --quota)")
+	flag.BoolVar(&controlPlaneScan, "control-plane", false, "Check the API server, etcd, scheduler and controller-manager (static pods, /readyz, componentstatuses)")
+	flag.BoolVar(&webhookScan, "webhooks", false, "Report admission webhooks with failurePolicy Fail whose Service has no ready endpoints (they block every matching request)")
+	flag.BoolVar(&dnsScan, "dns", false, "Check that CoreDNS/kube-dns has ready replicas and endpoints and report its recent warning events")
+	flag.BoolVar(&topologyScan, "topology", false, "Report Deployments/StatefulSets with every replica on a single node or in a single zone")
+	flag.BoolVar(&bestPractices, "best-practices", false, "Check the pod templates of Deployments/StatefulSets/DaemonSets for missing probes and resources, mutable image tags and insecure security contexts (as lint does)")
+	flag.BoolVar(&priorityScan, "priority", false, "Report recently preempted pods and pods pending for resources held by higher-priority pods")
+	flag.BoolVar(&gcScan, "gc", false, "Report orphaned ReplicaSets, expired Jobs, unused ConfigMaps/Secrets and dangling Endpoints outside system namespaces (opt out with the scanner.ductnn.io/gc-keep=true annotation); with --clean, delete them (ConfigMaps and Secrets only with --include configmaps,secrets)")
+	flag.DurationVar(&gcOpts.MinAge, "gc-min-age", gcOpts.MinAge, "GC: only report ReplicaSets, ConfigMaps and Secrets older than this")
+	flag.DurationVar(&gcOpts.JobTTL, "gc-job-ttl", gcOpts.JobTTL, "GC: report finished Jobs (without ttlSecondsAfterFinished) older than this")
+	flag.StringVar(&policyPath, "policy", "", "Policy file or directory of custom rules evaluated against pods, workloads and Services (see deploy/examples/policy.yaml)")
+	flag.StringVar(&pluginsDir, "plugins-dir", "", "Run every executable in this directory as an additional scanner (JSON over stdin/stdout, see pkg/plugin)")
+	flag.StringVar(&profileName, "profile", "", "Scanners to run: quick (pods only, no events), standard (adds --topology and --best-practices) or deep (every scanner); other flags override it")
+	flag.StringVar(&configPath, "config", "", "Path to a YAML configuration file (see deploy/examples/config.yaml); flags override it")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", tracing.EndpointFromEnv(), "Send traces of the scan phases to this OTLP/HTTP collector (e.g. http://otel-collector:4318; default: $OTEL_EXPORTER_OTLP_ENDPOINT)")
+	flag.StringVar(&baselineFile, "baseline", "", "Baseline of accepted findings (see 'k8s-scanner baseline save'); only issues missing from it are reported (default: <outdir>/"+report.BaselineFile+" when it exists)")
+	flag.StringVar(&labelKeys, "labels", "", "Pod (or namespace) label and annotation keys to copy into issues, comma-separated (e.g. team,app.kubernetes.io/name,version)")
+	flag.StringVar(&teamLabel, "team-label", "", "Attribute issues to the team named by this pod label (or namespace label) and report each team's compliance with its budget")
+	flag.IntVar(&teamMaxCritical, "team-max-critical", -1, "Teams: critical issues each team may have before it is reported out of compliance (negative: unlimited; per-team budgets go in --config)")
+	flag.BoolVar(&redact, "redact", false, "Anonymize namespaces, pod/workload, container, image, team and node names and issue IDs, and strip event messages in every output, to share reports externally (rules in --config)")
+	flag.BoolVar(&noBaseline, "no-baseline", false, "Report every finding even when a baseline exists")
+	flag.StringVar(&fromSnapshot, "from-snapshot", "", "Scan a snapshot file (see 'k8s-scanner snapshot create') instead of the live cluster")
 	// Check for help flags in arguments before parsing
 	for _, arg := range os.Args[1:] {
 		if arg == "-h" || arg == "--help" || arg == "-help" {
@@ -128,6 +565,143 @@ func main() {
 
 	flag.Parse()
 
+	if configPath != "" {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		setFlags := make(map[string]bool)
+		flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+		fromFile := capacity.DefaultOptions()
+		cfg.Capacity.Apply(&fromFile)
+		// Validated by config.Load
+		overrides, _ = cfg.PodOverrides()
+		rootCauses, _ = cfg.PodRootCauses()
+		customResources = cfg.CustomResources
+		if !setFlags["capacity"] {
+			capacityScan = cfg.Capacity.Enabled
+		}
+		if !setFlags["node-cpu-threshold"] {
+			capacityOpts.NodeCPUPercent = fromFile.NodeCPUPercent
+		}
+		if !setFlags["node-memory-threshold"] {
+			capacityOpts.NodeMemoryPercent = fromFile.NodeMemoryPercent
+		}
+		if !setFlags["usage-ratio"] {
+			capacityOpts.UsageRatio = fromFile.UsageRatio
+		}
+		capacityOpts.OverProvisionRatio = fromFile.OverProvisionRatio
+		capacityOpts.CPUCostPerCoreHour = fromFile.CPUCostPerCoreHour
+		capacityOpts.MemoryCostPerGiBHour = fromFile.MemoryCostPerGiBHour
+		if !setFlags["team-label"] {
+			teamLabel = cfg.Teams.Label
+		}
+		if !setFlags["labels"] {
+			labelKeys = strings.Join(cfg.Labels, ",")
+		}
+		teamBudgets = cfg.Teams.TeamBudgets()
+		if cfg.Redact != nil {
+			redactRules = *cfg.Redact
+		}
+		if !setFlags["profile"] {
+			profileName = cfg.Profile
+		}
+	}
+	if profileName != "" {
+		values, err := config.LookupProfile(profileName)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		setFlags := make(map[string]bool)
+		flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+		for name, value := range values {
+			// Snapshots hold no workloads or metrics for the other scanners
+			if setFlags[name] || (fromSnapshot != "" && name != "no-events") {
+				continue
+			}
+			if err := flag.Set(name, value); err != nil {
+				log.Fatalf("profile %s: %v", profileName, err)
+			}
+		}
+	}
+	// Human messages (exported files, metrics server) go to stderr when
+	// stdout carries data for another program
+	msgOut := os.Stdout
+	if machineFormat(format) || output == "-" {
+		msgOut = os.Stderr
+	}
+	nameTemplate, err := report.ParseFilenameTemplate(filenameTemplate)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if output != "" {
+		if len(parseExports(exportOpt)) != 1 {
+			log.Fatalf("--output requires exactly one --export format")
+		}
+		if compress {
+			log.Fatalf("--compress cannot be combined with --output")
+		}
+	}
+	if splitBy != "" {
+		splitBy = strings.ToLower(splitBy)
+		if !slices.Contains(report.SplitModes, splitBy) {
+			log.Fatalf("invalid --split-by %q (expected %s)", splitBy, strings.Join(report.SplitModes, "|"))
+		}
+		if exportOpt == "" || output != "" {
+			log.Fatalf("--split-by requires --export to files (without --output)")
+		}
+		if splitBy == report.SplitTeam && teamLabel == "" {
+			log.Fatalf("--split-by team-label requires --team-label")
+		}
+	}
+	var redactor *report.Redactor
+	if redact {
+		if slices.Contains(parseExports(exportOpt), report.ExportInventory) {
+			log.Fatalf("--redact cannot be combined with --export inventory")
+		}
+		var err error
+		if redactor, err = report.NewRedactor(redactRules); err != nil {
+			log.Fatalf("%v", err)
+		}
+	}
+	if teamMaxCritical >= 0 {
+		teamBudgets.Default.MaxCritical = &teamMaxCritical
+	}
+
+	if offlineMode {
+		setFlags := make(map[string]bool)
+		flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+		// Plugins are arbitrary executables that the HTTP guard does not cover
+		if registryCheck || cloudCluster != "" || pluginsDir != "" || setFlags["otlp-endpoint"] {
+			log.Fatalf("--offline cannot be combined with --registry-check, --cloud, --plugins-dir or --otlp-endpoint")
+		}
+		// $OTEL_EXPORTER_OTLP_ENDPOINT may be set for the whole environment
+		otlpEndpoint = ""
+		apiServer := ""
+		if fromSnapshot == "" {
+			if cfg, err := k8s.NewRestConfig(kubeconfig); err == nil {
+				apiServer = cfg.Host
+			}
+		}
+		offline.Enable(apiServer)
+	}
+
+	groupBy = strings.ToLower(groupBy)
+	if groupBy != "" && !slices.Contains(issueGroups, groupBy) {
+		log.Fatalf("invalid --group-by %q (expected %s)", groupBy, strings.Join(issueGroups, "|"))
+	}
+	if groupBy == "node-pool" {
+		nodeContext = true
+	}
+	if countBy != "" {
+		countBy = strings.ToLower(countBy)
+		if !slices.Contains(countGroups, countBy) {
+			log.Fatalf("invalid --count-by %q (expected %s)", countBy, strings.Join(countGroups, "|"))
+		}
+		count = true
+	}
+
 	// Suppress Kubernetes client logs when using --count flag
 	if count {
 		// Redirect klog output to discard to suppress verbose client logs
@@ -136,10 +710,23 @@ func main() {
 		klog.LogToStderr(false)
 	}
 
+	// Tracing is a no-op unless an OTLP endpoint is configured
+	ctx := context.Background()
+	tracer := newTracer(otlpEndpoint)
+	ctx = tracing.WithTracer(ctx, tracer)
+	ctx, rootSpan := tracing.Start(ctx, "k8s-scanner")
+
+	// Long-running modes stop on SIGINT/SIGTERM once the current scan is done
+	var shutdown context.Context
+	if enableMetrics || operatorMode {
+		shutdown = notifyShutdown()
+	}
+
 	// Initialize and start metrics server if enabled
+	var metricsSrv *http.Server
 	if enableMetrics {
 		metrics.Init()
-		go metrics.StartServer(metricsPort)
+		metricsSrv = metrics.StartServer(metricsPort, pprof, msgOut)
 	}
 
 	// Handle history flag
@@ -148,136 +735,608 @@ func main() {
 		if err != nil {
 			log.Fatalf("failed to list history: %v", err)
 		}
+		filter := report.HistoryFilter{Cluster: historyCluster}
+		if historySince != "" {
+			since, err := report.ParseAge(historySince)
+			if err != nil {
+				log.Fatalf("invalid --since: %v", err)
+			}
+			filter.Since = time.Now().Add(-since)
+		}
+		reports = report.FilterHistory(reports, filter)
+		if strings.ToLower(format) == "json" {
+			b, _ := json.MarshalIndent(reports, "", "  ")
+			fmt.Println(string(b))
+			return
+		}
 		report.PrintHistory(reports)
 		return
 	}
 
 	// Handle diff flag
 	if diff != "" {
-		handleDiff(diff, outdir)
+		handleDiff(diff, outdir, parseExports(exportOpt), crossCluster, format, msgOut)
 		return
 	}
 
-	clientset, err := k8s.NewK8sClient(kubeconfig)
-	if err != nil {
-		log.Fatalf("cannot init k8s client: %v", err)
+	// The default baseline is optional, an explicit --baseline must exist
+	var baseline *report.BaselineFilter
+	if !baselineSave && !noBaseline {
+		path := baselineFile
+		if path == "" {
+			path = filepath.Join(outdir, report.BaselineFile)
+		}
+		b, err := report.LoadBaseline(path)
+		switch {
+		case err == nil:
+			baseline = report.NewBaselineFilter(b)
+		case baselineFile != "" || !errors.Is(err, fs.ErrNotExist):
+			log.Fatalf("%v", err)
+		}
 	}
-
-	// Handle clean flag
-	if clean {
-		handleClean(clientset, namespace, ignoreNS, dryRun)
-		return
+	if baselineSave && baselineFile == "" {
+		baselineFile = filepath.Join(outdir, report.BaselineFile)
 	}
 
-	// Auto-detect cluster name if not provided
-	if clusterName == "" {
-		detected, err := k8s.GetCurrentContext(kubeconfig)
-		if err == nil && detected != "" {
-			clusterName = detected
+	// Parse ignored namespaces
+	ignoredNamespaces := parseIgnoredNamespaces(ignoreNS)
+
+	// Parse namespace flag (comma-separated list)
+	namespacesToScan := parseNamespaces(namespace)
+	reasons := pod.ReasonFilter{Only: splitList(onlyReasons), Ignore: splitList(ignoreReasons)}
+	var policySet *policy.Set
+	if policyPath != "" {
+		set, err := policy.Load(policyPath)
+		if err != nil {
+			log.Fatalf("%v", err)
 		}
+		policySet = set
+	}
+
+	dedupMode, err := pod.ParseDedupMode(dedup)
+	if err != nil {
+		log.Fatalf("%v", err)
 	}
 
 	var issues []types.Issue
+	var overview *capacity.Overview       // cluster capacity section of exported reports
+	var bom *inventory.BOM                // asset inventory, with --export inventory
+	var drainBlockers map[string][]string // PDBs blocking the drain of nodes with issues
+	// Issues per node are shown with --group-by node and in HTML reports
+	nodeGroups := groupBy == "node" || slices.Contains(parseExports(exportOpt), report.ExportHTML)
+	var meta report.Meta      // scope and timing of the scan
+	var streamed *issueStream // set when issues were streamed as ndjson
+	var streamedSummary map[string]types.SeveritySummary
+	scanTime := time.Now()
+
+	if fromSnapshot != "" {
+		if clean || operatorMode || crdReport != "" || capacityScan || gcScan || priorityScan || topologyScan || bestPractices || dnsScan || controlPlaneScan || webhookScan || quotaScan || quotaOpts.RequireQuota || gitopsScan || meshScan || karpenterScan || cloudCluster != "" || pluginsDir != "" {
+			log.Fatalf("--from-snapshot cannot be combined with --clean, --operator, --crd-report, --capacity, --gc, --priority, --topology, --best-practices, --dns, --control-plane, --webhooks, --quota, --gitops, --mesh, --karpenter, --cloud or --plugins-dir")
+		}
 
-	// Parse ignored namespaces
-	ignoredNamespaces := parseIgnoredNamespaces(ignoreNS)
+		if len(customResources) > 0 {
+			log.Printf("warning: customResources from --config are not scanned with --from-snapshot")
+		}
 
-	// Parse namespace flag (comma-separated list)
-	var namespacesToScan []string
-	if namespace != "" {
-		for _, ns := range strings.Split(namespace, ",") {
-			ns = strings.TrimSpace(ns)
-			if ns != "" {
-				namespacesToScan = append(namespacesToScan, ns)
+		snap, err := snapshot.Load(fromSnapshot)
+		if err != nil {
+			log.Fatalf("failed to load snapshot: %v", err)
+		}
+		if clusterName == "" {
+			clusterName = snap.Cluster
+		}
+
+		pods := pod.FilterIgnoredNamespaces(snap.PodsIn(namespacesToScan), ignoredNamespaces)
+		createdAt, _ := time.Parse(time.RFC3339, snap.CreatedAt)
+		eventMap := pod.EventMap{}
+		if !noEvents {
+			eventMap = pod.BuildEventMapFromEvents(snap.Events, pod.EventOptions{MaxAge: max(eventMaxAge, 0), Now: createdAt})
+		}
+		scanStart := time.Now()
+		snapIssues := pod.ScanPodList(pods, eventMap, pod.ScanOptions{
+			RestartThreshold:  int32(restartThreshold),
+			Dedup:             dedupMode,
+			EscalateAfter:     escalateAfter,
+			PendingGrace:      pendingGrace,
+			StartupGrace:      startupGrace,
+			TerminatingMargin: termMargin,
+			UnreadyAfter:      unreadyAfter,
+			Overrides:         overrides,
+			RootCauses:        rootCauses,
+			Reasons:           reasons,
+			Now:               createdAt,
+		})
+		pod.AnnotateNodeConditions(snapIssues, pod.NodeConditionsFromNodes(snap.Nodes))
+		pod.ExplainPending(snapIssues, pods, snap.Nodes)
+		// Snapshots do not record the autoscaler status, only its events
+		pod.ExplainAutoscaler(snapIssues, eventMap, nil)
+		pod.TagSpotInterruptions(snapIssues, pods, snap.Nodes)
+		snapIssues = reasons.Filter(snapIssues)
+		if nodeContext {
+			pod.AnnotateNodeContext(snapIssues, snap.Nodes)
+		}
+		pod.AnnotateImpactedServices(snapIssues, pods, snap.Services)
+		pod.AnnotateTeams(snapIssues, pods, nil, teamLabel)
+		pod.AnnotateLabels(snapIssues, pods, nil, splitList(labelKeys))
+		if registryCheck {
+			// The registry is queried now, not when the snapshot was taken
+			pod.AnnotateImagePull(ctx, snapIssues, pods, snap.Nodes, newRegistryClient(registryAuth))
+		}
+		if policySet.Targets("Pod") {
+			// Snapshots only record pods, so other kinds are not evaluated
+			for i := range pods {
+				found, errs := policySet.EvaluateObject("Pod", &pods[i])
+				snapIssues = append(snapIssues, reasons.Filter(found)...)
+				for _, err := range errs {
+					log.Printf("warning: policy: %v", err)
+				}
+			}
+		}
+		issues = append(issues, snapIssues...)
+		meta = report.Meta{
+			Cluster:           clusterName,
+			ScannerVersion:    version.Version,
+			ScannerCommit:     version.Get().Commit,
+			Source:            fromSnapshot,
+			StartedAt:         scanStart.Format(time.RFC3339),
+			DurationMS:        time.Since(scanStart).Milliseconds(),
+			Namespaces:        namespacesToScan,
+			IgnoredNamespaces: parseNamespaces(ignoreNS),
+		}
+		if meta.Namespaces == nil {
+			meta.Namespaces = []string{}
+		}
+		if exportOpt != "" && len(snap.Nodes) > 0 {
+			overview = capacity.BuildOverview(snap.Nodes, snap.Pods, nil)
+		}
+		if slices.Contains(parseExports(exportOpt), report.ExportInventory) {
+			bom = inventory.Build(clusterName, pods, namespacesToScan, ignoredNamespaces, createdAt)
+		}
+	} else {
+		clientset, err := k8s.NewK8sClient(kubeconfig)
+		if err != nil {
+			log.Fatalf("cannot init k8s client: %v", err)
+		}
+
+		// Auto-detect cluster name if not provided
+		if clusterName == "" {
+			detected, err := k8s.GetCurrentContext(kubeconfig)
+			if err == nil && detected != "" {
+				clusterName = detected
+			}
+		}
+
+		namespacesToScan = checkNamespaces(clientset, namespacesToScan, allowMissingNS)
+		if len(namespacesToScan) == 0 && namespace != "" {
+			log.Fatalf("none of the requested namespaces exist")
+		}
+
+		// Handle clean flag
+		if clean {
+			handleClean(clientset, cleanOptions{
+				namespace: strings.Join(namespacesToScan, ","),
+				ignoreNS:  ignoreNS,
+				include:   cleanInclude,
+				olderThan: olderThan,
+				dryRun:    dryRun,
+				confirm:   confirm,
+				gc:        gcScan,
+				gcOpts:    gcOpts,
+				outdir:    outdir,
+				cluster:   clusterName,
+				auditCM:   auditConfigMap,
+			})
+			return
+		}
+
+		// Handle operator mode
+		if operatorMode {
+			runOperator(shutdown, clientset, kubeconfig, clusterName, outdir, nameTemplate)
+			shutdownHTTP(metricsSrv)
+			return
+		}
+
+		var capacityCfg *capacity.Options
+		if capacityScan {
+			capacityCfg = &capacityOpts
+		}
+		var quotaCfg *quota.Options
+		if quotaScan || quotaOpts.RequireQuota {
+			quotaOpts.EventMaxAge = max(eventMaxAge, 0)
+			quotaCfg = &quotaOpts
+		}
+		var dnsCfg *controlplane.DNSOptions
+		if dnsScan {
+			dnsCfg = &controlplane.DNSOptions{}
+		}
+		var gcCfg *gc.Options
+		if gcScan {
+			gcCfg = &gcOpts
+		}
+		var plugins []plugin.Plugin
+		if pluginsDir != "" {
+			if plugins, err = plugin.Discover(pluginsDir); err != nil {
+				log.Fatalf("%v", err)
+			}
+			if len(plugins) == 0 {
+				log.Printf("warning: no executable plugins found in %s", pluginsDir)
+			}
+		}
+
+		var dyn dynamic.Interface
+		if len(customResources) > 0 || gitopsScan || meshScan || karpenterScan {
+			if dyn, err = k8s.NewDynamicClient(kubeconfig); err != nil {
+				log.Fatalf("%v", err)
+			}
+		}
+
+		var managedCluster *cloud.Cluster
+		if cloudCluster != "" {
+			kubeContext, _ := k8s.GetCurrentContext(kubeconfig)
+			c, err := cloud.Parse(cloudCluster, kubeContext)
+			if err != nil {
+				log.Fatalf("invalid --cloud: %v", err)
 			}
+			managedCluster = &c
+		}
+
+		var registryClient *registry.Client
+		if registryCheck {
+			registryClient = newRegistryClient(registryAuth)
 		}
+
+		podPageSize := 0
+		if maxMemory != "" {
+			limit, err := resource.ParseQuantity(maxMemory)
+			if err != nil {
+				log.Fatalf("invalid --max-memory %q: %v", maxMemory, err)
+			}
+			debug.SetMemoryLimit(limit.Value())
+			podPageSize = streamPageSize
+		}
+
+		progress := newProgressPrinter(quiet || count, verbose)
+		var onProgress pod.ProgressFunc
+		if progress != nil {
+			onProgress = progress.Update
+		}
+
+		// With --format ndjson issues are written out as they are found
+		// instead of being held until the scan completes
+		var streamFn func([]types.Issue)
+		if strings.ToLower(format) == "ndjson" && !baselineSave && output == "" && !count && splitBy == "" {
+			stream, err := newIssueStream(outdir, clusterName, reportBase(nameTemplate, clusterName, scanTime), parseExports(exportOpt), crdReport != "", redactor)
+			if err != nil {
+				log.Fatalf("%v", err)
+			}
+			defer stream.Close()
+			streamFn = stream.Write
+			streamed = stream
+		}
+
+		res, err := scanner.Run(ctx, scanner.Options{
+			Client:            clientset,
+			Cluster:           clusterName,
+			Namespaces:        namespacesToScan,
+			IgnoredNamespaces: parseNamespaces(ignoreNS),
+			RestartThreshold:  int32(restartThreshold),
+			Overrides:         overrides,
+			RootCauses:        rootCauses,
+			Reasons:           reasons,
+			Dedup:             dedupMode,
+			EscalateAfter:     escalateAfter,
+			PendingGrace:      pendingGrace,
+			StartupGrace:      startupGrace,
+			TerminatingMargin: termMargin,
+			UnreadyAfter:      unreadyAfter,
+			NoEvents:          noEvents,
+			EventMaxAge:       eventMaxAge,
+			Registry:          registryClient,
+			Capacity:          capacityCfg,
+			GC:                gcCfg,
+			Quota:             quotaCfg,
+			Priority:          priorityScan,
+			Topology:          topologyScan,
+			BestPractices:     bestPractices,
+			DNS:               dnsCfg,
+			ControlPlane:      controlPlaneScan,
+			Webhooks:          webhookScan,
+			Policy:            policySet,
+			CustomResources:   customResources,
+			GitOps:            gitopsScan,
+			Mesh:              meshScan,
+			Karpenter:         karpenterScan,
+			Cloud:             managedCluster,
+			Dynamic:           dyn,
+			Plugins:           plugins,
+			Kubeconfig:        kubeconfig,
+			Preflight:         true,
+			Progress:          onProgress,
+			ScannerTimeout:    scannerTimeout,
+			PodPageSize:       podPageSize,
+			Stream:            streamFn,
+			Baseline:          baseline,
+			TeamLabel:         teamLabel,
+			TeamBudgets:       teamBudgets,
+			LabelKeys:         splitList(labelKeys),
+			NodeContext:       nodeContext,
+		})
+		if progress != nil {
+			progress.Done(res.Timings, time.Duration(res.Meta.DurationMS)*time.Millisecond)
+		}
+		if err != nil {
+			log.Fatalf("scan failed: %v", err)
+		}
+		for _, w := range res.Warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+		}
+		if l := res.Meta.APILatency; verbose && l != nil {
+			fmt.Fprintf(os.Stderr, "API latency: p50 %.0fms, p95 %.0fms over %d requests\n", l.P50MS, l.P95MS, l.Requests)
+		}
+
+		issues = append(issues, res.Issues...)
+		meta = res.Meta
+		if streamed != nil {
+			if err := streamed.Close(); err != nil {
+				log.Fatalf("failed to write ndjson export: %v", err)
+			}
+			issues = append(issues, streamed.retained...)
+			streamedSummary = res.Summary
+		}
+
+		// Best effort: the overview needs cluster-wide node and pod access
+		if exportOpt != "" {
+			overview, _ = capacity.FetchOverview(ctx, clientset)
+		}
+		if nodeGroups {
+			if drainBlockers, err = capacity.FetchDrainBlockers(ctx, clientset, issueNodes(issues)); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: drain check skipped: %v\n", err)
+			}
+		}
+		if slices.Contains(parseExports(exportOpt), report.ExportInventory) {
+			if bom, err = inventory.Fetch(ctx, clientset, clusterName, namespacesToScan, ignoredNamespaces); err != nil {
+				log.Fatalf("%v", err)
+			}
+		}
+	}
+
+	// Stable IDs and order for console output and exports
+	types.AssignIDs(issues, clusterName)
+	report.SortIssues(issues)
+
+	if baselineSave {
+		if err := report.SaveBaseline(baselineFile, clusterName, issues); err != nil {
+			log.Fatalf("%v", err)
+		}
+		fmt.Fprintf(msgOut, "Baseline saved to %s: %d issues accepted\n", baselineFile, len(issues))
+		return
+	}
+	// scanner.Run applies the baseline itself, snapshots are filtered here
+	if baseline != nil && meta.Baseline == nil {
+		issues = baseline.Apply(issues)
+		meta.Baseline = baseline.Info()
+	}
+	if teamLabel != "" && meta.Teams == nil {
+		counts := map[string]types.SeveritySummary{}
+		report.CountByTeam(counts, issues)
+		meta.Teams = report.CheckTeams(counts, teamBudgets)
 	}
 
-	pods, _ := pod.ScanPods(clientset, namespacesToScan, int32(restartThreshold), ignoredNamespaces)
-	// deploys, _ := scanner.ScanDeploymentsNS(clientset, namespace)
-	// jobs, _ := scanner.ScanJobsNS(clientset, namespace)
-	// crons, _ := scanner.ScanCronJobsNS(clientset, namespace)
+	if nodeGroups && streamed == nil {
+		meta.Nodes = report.GroupByNode(issues, drainBlockers)
+	}
 
-	issues = append(issues, pods...)
-	// issues = append(issues, deploys...)
-	// issues = append(issues, jobs...)
-	// issues = append(issues, crons...)
+	// Correlate with the previous report to compute how long issues have persisted
+	// (streamed issues were already aged as they were written)
+	if streamed == nil {
+		previous, _ := report.LatestReport(outdir, clusterName)
+		report.TrackIssueAge(issues, previous)
+	}
+
+	// Anonymize every output of the run (streamed issues already were)
+	if redactor != nil {
+		if streamed == nil {
+			redactor.Issues(issues)
+		}
+		redactor.Meta(&meta)
+		redactor.Overview(overview)
+	}
 
 	// Summary
 	sum := scanner.SummarizeByNamespace(issues)
+	if streamed != nil {
+		// Only some of the streamed issues may have been retained
+		sum = streamedSummary
+		if redactor != nil {
+			sum = redactor.Summary(sum)
+		}
+	}
 
 	// Export metrics if enabled
 	if enableMetrics {
 		metrics.ExportSummary(sum)
+		metrics.ExportIssues(issues)
+		metrics.SetBuildInfo(version.Get(), meta.KubernetesVersion)
+		metrics.ExportAPILatency(meta.APILatency)
+		metrics.ExportTeams(meta.Teams)
+	}
+
+	// Write results back into the cluster as custom resources
+	if crdReport != "" {
+		writeCRDReport(kubeconfig, crdReport, clusterName, namespacesToScan, issues, sum)
 	}
 
 	// If count flag is set, output only the count and exit immediately
 	if count {
-		// Output only the number to stdout (no newline issues, just the number)
-		fmt.Print(len(issues))
-		fmt.Println() // Add newline after the number
+		printCount(issues, sum, countBy, format)
 		return
 	}
 
 	// Console output
-	switch strings.ToLower(format) {
+	consoleFormat := strings.ToLower(format)
+	if output == "-" {
+		consoleFormat = "none"
+	}
+	switch consoleFormat {
+	case "none":
+	case "ndjson":
+		if streamed == nil {
+			if err := report.NewNDJSONWriter(os.Stdout).Write(issues); err != nil {
+				log.Fatalf("failed to write ndjson: %v", err)
+			}
+		}
 	case "json":
-		obj := map[string]any{"issues": issues, "summary": sum}
+		obj := map[string]any{"meta": meta, "issues": issues, "summary": sum}
+		switch groupBy {
+		case "image":
+			obj["images"], _ = report.GroupByImage(issues)
+		case "node-pool":
+			obj["node_pools"] = report.GroupByNodePool(issues)
+		}
 		b, _ := json.MarshalIndent(obj, "", "  ")
 		fmt.Println(string(b))
 	default:
-		fmt.Println("\n=== Issues (table) ===")
-		printIssuesTable(issues)
+		switch groupBy {
+		case "node":
+			printNodeGroups(issues, meta.Nodes)
+		case "node-pool":
+			printNodePoolGroups(issues)
+		case "image":
+			printImageGroups(issues)
+		default:
+			fmt.Println("\n=== Issues (table) ===")
+			printIssuesTable(issues)
+		}
 		fmt.Println("\n=== Summary by Namespace ===")
 		printSummaryTable(sum)
+		printTopOffenders(report.BuildTopOffenders(issues, report.TopN))
+		if len(meta.Teams) > 0 {
+			fmt.Println("\n=== Team Compliance ===")
+			printTeamsTable(meta.Teams)
+		}
+		if meta.Baseline != nil {
+			printBaseline(meta.Baseline)
+		}
+		if meta.Managed != nil {
+			printManaged(meta.Managed)
+		}
+	}
+
+	// Export to a single file or stdout
+	if exportOpt != "" && output != "" {
+		if err := writeOutput(output, parseExports(exportOpt)[0], issues, sum, overview, &meta, bom); err != nil {
+			log.Fatalf("export failed: %v", err)
+		}
+		if output != "-" {
+			fmt.Fprintf(msgOut, "\nExported to %s\n", output)
+		}
 	}
 
 	// Export files
-	if exportOpt != "" {
+	if exportOpt != "" && output == "" {
+		_, exportSpan := tracing.Start(ctx, "export")
 		kinds := parseExports(exportOpt)
+		base := reportBase(nameTemplate, clusterName, scanTime)
+		exportSpan.SetAttr("report.formats", strings.Join(stringify(kinds), ","))
 
-		// Add timestamp to filename: [cluster-name]-k8s-report-YYYYMMDD-HHMMSS
-		now := time.Now()
-		timestamp := fmt.Sprintf("%s-%s",
-			now.Format("20060102"), // YYYYMMDD
-			now.Format("150405"))   // HHMMSS
-
-		// Build base filename with optional cluster name prefix
-		var base string
-		if clusterName != "" {
-			// Sanitize cluster name for filename (remove invalid characters)
-			sanitized := sanitizeClusterName(clusterName)
-			base = fmt.Sprintf("%s-k8s-report-%s", sanitized, timestamp)
-		} else {
-			base = fmt.Sprintf("k8s-report-%s", timestamp)
+		// The ndjson export was already written while streaming
+		toWrite := kinds
+		if streamed != nil {
+			toWrite = withoutKind(kinds, report.ExportNDJSON)
 		}
-
-		if err := report.WriteAll(outdir, base, issues, sum, kinds); err != nil {
+		if err := report.WriteAll(outdir, base, issues, sum, overview, &meta, toWrite); err != nil {
 			log.Fatalf("export failed: %v", err)
 		}
-		fmt.Printf("\nExported to %s: %s.%s\n", outdir, base, strings.Join(stringify(kinds), ","))
+		if bom != nil {
+			if err := inventory.Save(filepath.Join(outdir, inventoryName(base)), bom); err != nil {
+				log.Fatalf("export failed: %v", err)
+			}
+		}
+		var tenantBases []string
+		if splitBy != "" {
+			// The inventory is cluster-wide: tenants get their issues only
+			if tenantBases, err = report.WriteTenants(outdir, base, splitBy, issues, &meta, withoutKind(kinds, report.ExportInventory)); err != nil {
+				log.Fatalf("export failed: %v", err)
+			}
+		}
+		if compress {
+			for _, b := range tenantBases {
+				for _, k := range withoutKind(kinds, report.ExportInventory) {
+					if err := report.GzipFile(filepath.Join(outdir, b+"."+string(k))); err != nil {
+						log.Fatalf("export failed: %v", err)
+					}
+				}
+			}
+			// Including the ndjson export written while streaming
+			for _, k := range withoutKind(kinds, report.ExportInventory) {
+				if err := report.GzipFile(filepath.Join(outdir, base+"."+string(k))); err != nil {
+					log.Fatalf("export failed: %v", err)
+				}
+			}
+			if bom != nil {
+				if err := report.GzipFile(filepath.Join(outdir, inventoryName(base))); err != nil {
+					log.Fatalf("export failed: %v", err)
+				}
+			}
+		}
+		exportSpan.End()
+		var files []string
+		if reports := withoutKind(kinds, report.ExportInventory); len(reports) > 0 {
+			files = append(files, base+"."+strings.Join(stringify(reports), ","))
+		}
+		if bom != nil {
+			files = append(files, inventoryName(base))
+		}
+		if len(tenantBases) > 0 {
+			files = append(files, fmt.Sprintf("%d tenant reports in %s", len(tenantBases), report.TenantsDir))
+		}
+		if compress {
+			files = append(files, "gzipped")
+		}
+		fmt.Fprintf(msgOut, "\nExported to %s: %s\n", outdir, strings.Join(files, ", "))
 	}
 
+	rootSpan.End()
+	flushTraces(tracer)
+
 	// Keep program running if metrics server is enabled
 	if enableMetrics {
-		fmt.Println("\nMetrics server is running. Press Ctrl+C to stop.")
-		select {} // Block forever to keep metrics server running
+		fmt.Fprintln(msgOut, "\nMetrics server is running. Press Ctrl+C to stop.")
+		<-shutdown.Done()
+		shutdownHTTP(metricsSrv)
+	}
+}
+
+// writeOutput writes one export format to path, or to stdout when path is "-"
+func writeOutput(path string, kind report.ExportKind, issues []types.Issue, sum map[string]types.SeveritySummary, overview *capacity.Overview, meta *report.Meta, bom *inventory.BOM) error {
+	var b []byte
+	if kind == report.ExportInventory {
+		var buf bytes.Buffer
+		if err := inventory.Write(&buf, bom); err != nil {
+			return err
+		}
+		b = buf.Bytes()
+	} else {
+		var err error
+		if b, err = report.Render(kind, issues, sum, overview, meta); err != nil {
+			return err
+		}
+	}
+	if path == "-" {
+		_, err := os.Stdout.Write(b)
+		return err
 	}
+	if err := report.EnsureDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
 }
 
 func parseExports(s string) []report.ExportKind {
 	var out []report.ExportKind
 	for _, p := range strings.Split(s, ",") {
-		p = strings.TrimSpace(p)
-		switch strings.ToLower(p) {
-		case "json":
-			out = append(out, report.ExportJSON)
-		case "csv":
-			out = append(out, report.ExportCSV)
-		case "md", "markdown":
-			out = append(out, report.ExportMD)
-		case "html":
-			out = append(out, report.ExportHTML)
+		if k, ok := report.ParseExportKind(p); ok {
+			out = append(out, k)
 		}
 	}
 	return out
@@ -292,21 +1351,71 @@ func stringify(k []report.ExportKind) []string {
 }
 
 func printIssuesTable(issues []types.Issue) {
-	fmt.Println("TIME                | NAMESPACE | KIND | NAME | SEV | STATUS | REASON | NODE | RESTARTS")
-	fmt.Println(strings.Repeat("-", 120))
+	fmt.Println("TIME                | NAMESPACE | KIND | NAME | CONTAINER | SEV | STATUS | REASON | NODE | RESTARTS")
+	fmt.Println(strings.Repeat("-", 135))
 	for _, is := range issues {
-		fmt.Printf("%-19s | %-9s | %-4s | %-20s | %-4s | %-12s | %-18s | %-10s | %-3d\n",
-			trunc(is.Timestamp, 19), trunc(is.Namespace, 9), trunc(is.Kind, 4), trunc(is.Name, 20),
-			strings.ToUpper(trunc(is.Severity, 4)), trunc(is.PodStatus, 12), trunc(is.Reason, 18),
+		fmt.Printf("%-19s | %-9s | %-4s | %-20s | %-12s | %-4s | %-12s | %-18s | %-10s | %-3d\n",
+			trunc(is.Timestamp, 19), trunc(is.Namespace, 9), trunc(is.Kind, 4), trunc(is.Name, 20), trunc(is.Container, 12),
+			strings.ToUpper(trunc(string(is.Severity), 4)), trunc(is.PodStatus, 12), trunc(is.Reason, 18),
 			trunc(is.NodeName, 10), is.RestartCount)
 	}
 }
 
+func printTopOffenders(top report.TopOffenders) {
+	if top.Empty() {
+		return
+	}
+	fmt.Printf("\n=== Top %d ===\n", report.TopN)
+	for _, l := range top.Lists() {
+		if len(l.Entries) == 0 {
+			continue
+		}
+		fmt.Printf("%s:\n", l.Title)
+		for i, o := range l.Entries {
+			fmt.Printf("  %2d. %-50s %d\n", i+1, o.Name, o.Count)
+		}
+	}
+}
+
 func printSummaryTable(sum map[string]types.SeveritySummary) {
-	fmt.Println("NAMESPACE | CRITICAL | HIGH | MEDIUM | LOW")
-	fmt.Println("-------------------------------------------")
-	for ns, s := range sum {
-		fmt.Printf("%-9s | %-8d | %-4d | %-6d | %-3d\n", ns, s.Critical, s.High, s.Medium, s.Low)
+	fmt.Println("NAMESPACE | CRITICAL | HIGH | MEDIUM | LOW | INFO")
+	fmt.Println("--------------------------------------------------")
+	for _, ns := range report.SortedNamespaces(sum) {
+		s := sum[ns]
+		fmt.Printf("%-9s | %-8d | %-4d | %-6d | %-3d | %-4d\n", ns, s.Critical, s.High, s.Medium, s.Low, s.Info)
+	}
+}
+
+func printTeamsTable(teams []report.TeamStatus) {
+	fmt.Println("TEAM                 | CRITICAL | HIGH | MEDIUM | LOW | COMPLIANT | VIOLATIONS")
+	fmt.Println(strings.Repeat("-", 100))
+	for _, t := range teams {
+		fmt.Printf("%-20s | %-8d | %-4d | %-6d | %-3d | %-9t | %s\n",
+			trunc(t.Team, 20), t.Summary.Critical, t.Summary.High, t.Summary.Medium, t.Summary.Low, t.Compliant, strings.Join(t.Violations, "; "))
+	}
+}
+
+// printBaseline reports what the baseline hid and which accepted issues are gone
+func printBaseline(b *report.BaselineInfo) {
+	fmt.Printf("\n=== Baseline (%s, saved %s) ===\n", b.File, b.CreatedAt)
+	fmt.Printf("%d accepted issues still present (hidden), %d resolved\n", b.Accepted, len(b.Resolved))
+	for _, is := range b.Resolved {
+		name := is.Namespace + "/" + is.Name
+		if is.Container != "" {
+			name += "/" + is.Container
+		}
+		fmt.Printf("  resolved: %s %s (%s)\n", is.Kind, name, is.Reason)
+	}
+}
+
+func printManaged(s *cloud.Status) {
+	fmt.Printf("\n=== Managed Cluster (%s %s, %s %s) ===\n", strings.ToUpper(s.Provider), s.Cluster, s.State, s.Version)
+	if len(s.Context) == 0 {
+		fmt.Println("Control plane and node pools are healthy")
+		return
+	}
+	for _, c := range s.Context {
+		fmt.Printf("  %s\n", c)
 	}
 }
 
@@ -331,20 +1440,87 @@ func parseIgnoredNamespaces(ignoreNS string) map[string]bool {
 	return ignored
 }
 
-func sanitizeClusterName(name string) string {
-	// Replace invalid filename characters with hyphens
-	invalid := []string{"/", "\\", ":", "*", "?", "\"", "<", ">", "|", " "}
-	sanitized := name
-	for _, char := range invalid {
-		sanitized = strings.ReplaceAll(sanitized, char, "-")
+// parseNamespaces splits a comma-separated namespace list, dropping empty entries
+func parseNamespaces(namespace string) []string {
+	return splitList(namespace)
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// checkNamespaces validates the requested namespaces against the cluster. A
+// nonexistent namespace is fatal unless allowMissing is set, in which case it
+// is dropped with a warning
+func checkNamespaces(client kubernetes.Interface, namespaces []string, allowMissing bool) []string {
+	missing, err := k8s.ValidateNamespaces(context.Background(), client, namespaces)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if len(missing) == 0 {
+		return namespaces
+	}
+	if !allowMissing {
+		for _, m := range missing[1:] {
+			fmt.Fprintf(os.Stderr, "error: %s\n", m)
+		}
+		log.Fatalf("%s (use --allow-missing-ns to skip it)", missing[0])
+	}
+
+	drop := make(map[string]bool, len(missing))
+	for _, m := range missing {
+		fmt.Fprintf(os.Stderr, "warning: %s, skipping it\n", m)
+		drop[m.Name] = true
+	}
+	var kept []string
+	for _, ns := range namespaces {
+		if !drop[ns] {
+			kept = append(kept, ns)
+		}
+	}
+	return kept
+}
+
+// reportBase is the export file name without extension, by default
+// [cluster-name-]k8s-report-YYYYMMDD-HHMMSS
+func reportBase(tmpl *report.FilenameTemplate, clusterName string, now time.Time) string {
+	// Sanitize cluster name for filename (remove invalid characters)
+	base, err := tmpl.Name(report.SanitizeClusterName(clusterName), now)
+	if err != nil {
+		log.Fatalf("%v", err)
 	}
-	// Remove consecutive hyphens
-	for strings.Contains(sanitized, "--") {
-		sanitized = strings.ReplaceAll(sanitized, "--", "-")
+	return base
+}
+
+// inventoryName is the inventory file name for a report base name. Report
+// history ignores .cdx.json files.
+func inventoryName(base string) string {
+	return strings.Replace(base, "k8s-report-", "k8s-inventory-", 1) + ".cdx.json"
+}
+
+// newRegistryClient returns a registry client that also trusts the token
+// services of --registry-auth-hosts
+func newRegistryClient(authHosts string) *registry.Client {
+	c := registry.NewClient()
+	c.AuthHosts = append(slices.Clone(c.AuthHosts), splitList(authHosts)...)
+	return c
+}
+
+// countIssues totals a per-namespace summary
+func countIssues(sum map[string]types.SeveritySummary) int {
+	total := 0
+	for _, s := range sum {
+		total += s.Critical + s.High + s.Medium + s.Low + s.Info
 	}
-	// Remove leading/trailing hyphens
-	sanitized = strings.Trim(sanitized, "-")
-	return sanitized
+	return total
 }
 
 func findReportFile(outdir, timestamp string) string {
@@ -355,23 +1531,26 @@ func findReportFile(outdir, timestamp string) string {
 		return ""
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		fileName := entry.Name()
-		if !strings.HasSuffix(fileName, ".json") {
-			continue
-		}
-		// Check if filename ends with the timestamp pattern
-		if strings.HasSuffix(fileName, fmt.Sprintf("k8s-report-%s.json", timestamp)) {
-			return filepath.Join(outdir, fileName)
+	// JSON reports are preferred; CSV exports are used when only they remain
+	for _, ext := range []string{".json", ".json.gz", ".csv", ".csv.gz"} {
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			fileName := entry.Name()
+			if !strings.HasSuffix(fileName, ext) {
+				continue
+			}
+			// Check if filename ends with the timestamp pattern
+			if strings.HasSuffix(fileName, fmt.Sprintf("k8s-report-%s%s", timestamp, ext)) {
+				return filepath.Join(outdir, fileName)
+			}
 		}
 	}
 	return ""
 }
 
-func handleDiff(diffArg string, outdir string) {
+func handleDiff(diffArg string, outdir string, exports []report.ExportKind, crossCluster bool, format string, msgOut io.Writer) {
 	parts := strings.Split(diffArg, ",")
 	if len(parts) != 2 {
 		log.Fatalf("diff requires exactly 2 arguments separated by comma (e.g., '20251109-210646,20251109-210704' or 'k8s-report-20251109-210646.json,k8s-report-20251109-210704.json')")
@@ -383,7 +1562,7 @@ func handleDiff(diffArg string, outdir string) {
 	// If paths don't contain slashes, assume they're timestamp identifiers or filenames
 	if !strings.Contains(oldPath, string(filepath.Separator)) && !strings.Contains(oldPath, "/") {
 		// Check if it's just a timestamp (e.g., "20251109-143022") or full filename
-		if !strings.HasSuffix(oldPath, ".json") {
+		if !isReportFile(oldPath) {
 			// Try to find matching report file (could be with or without cluster name prefix)
 			// First try with cluster prefix pattern, then without
 			matched := findReportFile(outdir, oldPath)
@@ -401,7 +1580,7 @@ func handleDiff(diffArg string, outdir string) {
 
 	if !strings.Contains(newPath, string(filepath.Separator)) && !strings.Contains(newPath, "/") {
 		// Check if it's just a timestamp (e.g., "20251109-143022") or full filename
-		if !strings.HasSuffix(newPath, ".json") {
+		if !isReportFile(newPath) {
 			// Try to find matching report file (could be with or without cluster name prefix)
 			matched := findReportFile(outdir, newPath)
 			if matched != "" {
@@ -417,80 +1596,95 @@ func handleDiff(diffArg string, outdir string) {
 	}
 
 	// Load reports
-	oldReport, err := report.LoadReport(oldPath)
+	oldReport, err := loadDiffReport(oldPath)
 	if err != nil {
 		log.Fatalf("failed to load old report from %s: %v", oldPath, err)
 	}
 
-	newReport, err := report.LoadReport(newPath)
+	newReport, err := loadDiffReport(newPath)
 	if err != nil {
 		log.Fatalf("failed to load new report from %s: %v", newPath, err)
 	}
 
-	// Compare and display
-	result := report.DiffReports(oldReport, newReport)
-	report.PrintDiff(result, oldReport, newReport)
-}
-
-func handleClean(clientset *kubernetes.Clientset, namespace string, ignoreNS string, dryRun bool) {
-	// Parse ignored namespaces
-	ignoredNamespaces := parseIgnoredNamespaces(ignoreNS)
-
-	// Parse namespace flag (comma-separated list)
-	var namespacesToScan []string
-	if namespace != "" {
-		for _, ns := range strings.Split(namespace, ",") {
-			ns = strings.TrimSpace(ns)
-			if ns != "" {
-				namespacesToScan = append(namespacesToScan, ns)
+	// Reports of different clusters only make sense to compare on purpose
+	// (e.g. staging vs prod); reports without metadata get the cluster of
+	// their file name so they are matched accordingly
+	oldCluster, newCluster := report.ClusterOf(oldPath, oldReport), report.ClusterOf(newPath, newReport)
+	if oldCluster != "" && newCluster != "" && oldCluster != newCluster {
+		if !crossCluster {
+			log.Fatalf("reports are from different clusters (%s, %s); use --cross-cluster to compare them", oldCluster, newCluster)
+		}
+		for _, r := range []struct {
+			data    *report.ReportData
+			cluster string
+		}{{oldReport, oldCluster}, {newReport, newCluster}} {
+			if r.data.Meta == nil {
+				r.data.Meta = &report.Meta{Cluster: r.cluster}
 			}
 		}
 	}
 
-	// Clean pods
-	result, err := pod.CleanPods(clientset, namespacesToScan, ignoredNamespaces, dryRun)
-	if err != nil {
-		log.Fatalf("failed to clean pods: %v", err)
+	// Compare and display
+	result := report.DiffReports(oldReport, newReport)
+	if strings.ToLower(format) == "json" {
+		b, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(b))
+	} else {
+		report.PrintDiff(result, oldReport, newReport)
 	}
 
-	// Display results
-	if dryRun {
-		fmt.Println("\n=== Dry-run: Pods that would be deleted ===")
-	} else {
-		fmt.Println("\n=== Cleaned Pods ===")
+	if len(exports) > 0 {
+		base := "k8s-diff-" + time.Now().Format("20060102-150405")
+		if err := report.WriteDiff(outdir, base, result, oldReport, newReport, exports); err != nil {
+			log.Fatalf("export failed: %v", err)
+		}
+		fmt.Fprintf(msgOut, "\nExported to %s: %s.%s\n", outdir, base, strings.Join(stringify(exports), ","))
 	}
+}
 
-	if len(result.DeletedPods) == 0 {
-		fmt.Println("No pods to clean.")
-		return
+// machineFormat reports whether a console format is meant for other
+// programs, so nothing else may be written to stdout
+func machineFormat(format string) bool {
+	f := strings.ToLower(format)
+	return f == "json" || f == "ndjson"
+}
+
+// isReportFile tells file names from timestamps in --diff arguments
+func isReportFile(name string) bool {
+	name = strings.TrimSuffix(name, ".gz")
+	return strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".csv")
+}
+
+// loadDiffReport loads a JSON report, or a CSV export for reports archived
+// in that form; either may be gzipped
+func loadDiffReport(path string) (*report.ReportData, error) {
+	if strings.EqualFold(filepath.Ext(strings.TrimSuffix(path, ".gz")), ".csv") {
+		return report.LoadCSVReport(path)
 	}
+	return report.LoadReport(path)
+}
 
-	// Print table header
-	fmt.Println("NAMESPACE | NAME | REASON | SEVERITY")
-	fmt.Println(strings.Repeat("-", 60))
+func writeCRDReport(kubeconfig string, name string, clusterName string, namespaces []string, issues []types.Issue, sum map[string]types.SeveritySummary) {
+	dyn, err := k8s.NewDynamicClient(kubeconfig)
+	if err != nil {
+		log.Fatalf("cannot init dynamic client: %v", err)
+	}
 
-	// Print each pod
-	for _, p := range result.DeletedPods {
-		fmt.Printf("%-9s | %-20s | %-15s | %-8s\n",
-			trunc(p.Namespace, 9),
-			trunc(p.Name, 20),
-			trunc(p.Reason, 15),
-			strings.ToUpper(p.Severity))
+	client := crd.NewClient(dyn)
+	if err := client.WriteReport(context.Background(), name, clusterName, namespaces, issues, sum); err != nil {
+		log.Fatalf("failed to write custom resources: %v", err)
 	}
+}
 
-	// Print summary
-	fmt.Printf("\nTotal: %d pod(s)", len(result.DeletedPods))
-	if dryRun {
-		fmt.Println(" (would be deleted)")
-	} else {
-		fmt.Println(" (deleted)")
+func runOperator(ctx context.Context, clientset kubernetes.Interface, kubeconfig, clusterName, outdir string, nameTemplate *report.FilenameTemplate) {
+	dyn, err := k8s.NewDynamicClient(kubeconfig)
+	if err != nil {
+		log.Fatalf("cannot init dynamic client: %v", err)
 	}
 
-	// Print errors if any
-	if len(result.Errors) > 0 {
-		fmt.Println("\n=== Errors ===")
-		for _, err := range result.Errors {
-			fmt.Printf("Error: %v\n", err)
-		}
+	fmt.Println("Operator mode: reconciling ScanSchedule resources. Press Ctrl+C to stop.")
+	op := operator.New(clientset, crd.NewClient(dyn), clusterName, outdir, nameTemplate, 30*time.Second)
+	if err := op.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatalf("operator stopped: %v", err)
 	}
 }
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/notify"
+	"github.com/ductnn/k8s-scanner/pkg/offline"
+	"github.com/ductnn/k8s-scanner/pkg/report"
+	"github.com/ductnn/k8s-scanner/pkg/server"
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// runOfflineCheck implements `k8s-scanner offline-check`, which renders
+// every document the scanner produces (reports, diff reports, the dashboard
+// and digests) and fails if one of them loads a remote asset, to certify
+// the build for air-gapped environments
+func runOfflineCheck(args []string) {
+	fs := flag.NewFlagSet("offline-check", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	docs, err := offlineDocuments()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	failed := false
+	for _, d := range docs {
+		remote := offline.RemoteAssets(d.content)
+		if len(remote) == 0 {
+			fmt.Printf("ok    %s\n", d.name)
+			continue
+		}
+		failed = true
+		for _, u := range remote {
+			fmt.Printf("FAIL  %s loads %s\n", d.name, u)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+	fmt.Println("\nNo remote assets: with --offline only the Kubernetes API server is contacted")
+}
+
+type offlineDocument struct {
+	name    string
+	content []byte
+}
+
+// offlineDocuments renders a sample of every document format
+func offlineDocuments() ([]offlineDocument, error) {
+	now := time.Now()
+	issues := []types.Issue{{
+		Namespace: "shop", Kind: "Pod", Name: "web-0", Container: "web", Reason: "CrashLoopBackOff",
+		Severity: severity.High, RootCause: "Container exited with code 1", Suggestion: "kubectl logs web-0 -n shop --previous",
+		NodeName: "node-1", NodePool: "pool-a", InstanceType: "m5.large", Zone: "zone-a", Timestamp: now.Format(time.RFC3339),
+	}}
+	types.AssignIDs(issues, "offline-check")
+	summary := map[string]types.SeveritySummary{"shop": {High: 1}}
+	meta := &report.Meta{Cluster: "offline-check", Source: "cluster", StartedAt: now.Format(time.RFC3339), Namespaces: []string{}}
+
+	var docs []offlineDocument
+	for _, k := range []report.ExportKind{report.ExportHTML, report.ExportMD} {
+		b, err := report.Render(k, issues, summary, nil, meta)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, offlineDocument{name: string(k) + " report", content: b})
+	}
+
+	// Diff reports are only written to files
+	dir, err := os.MkdirTemp("", "k8s-scanner-offline-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	oldReport := &report.ReportData{GeneratedAt: now.Add(-time.Hour).Format(time.RFC3339), Summary: map[string]types.SeveritySummary{}, Meta: meta}
+	newReport := &report.ReportData{GeneratedAt: now.Format(time.RFC3339), Issues: issues, Summary: summary, Meta: meta}
+	kinds := []report.ExportKind{report.ExportHTML, report.ExportMD}
+	if err := report.WriteDiff(dir, "diff", report.DiffReports(oldReport, newReport), oldReport, newReport, kinds); err != nil {
+		return nil, err
+	}
+	for _, k := range kinds {
+		b, err := os.ReadFile(filepath.Join(dir, "diff."+string(k)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read diff report: %w", err)
+		}
+		docs = append(docs, offlineDocument{name: string(k) + " diff report", content: b})
+	}
+
+	err = fs.WalkDir(server.UI(), ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := fs.ReadFile(server.UI(), path)
+		docs = append(docs, offlineDocument{name: "dashboard " + path, content: b})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dashboard files: %w", err)
+	}
+
+	msg := notify.BuildDigest(newReport, nil, notify.DefaultTopOffenders, now).Message()
+	docs = append(docs, offlineDocument{name: "digest notification", content: []byte(msg.Title + "\n" + msg.Text)})
+	return docs, nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ductnn/k8s-scanner/pkg/offline"
+)
+
+// Every rendered report, diff report, dashboard file and digest must load
+// nothing from the network
+func TestOfflineDocuments(t *testing.T) {
+	docs, err := offlineDocuments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make(map[string]bool, len(docs))
+	for _, d := range docs {
+		names[d.name] = true
+		if len(d.content) == 0 {
+			t.Errorf("%s is empty", d.name)
+		}
+		if remote := offline.RemoteAssets(d.content); len(remote) > 0 {
+			t.Errorf("%s loads %q", d.name, remote)
+		}
+	}
+	for _, want := range []string{"html report", "md report", "html diff report", "md diff report", "dashboard index.html", "digest notification"} {
+		if !names[want] {
+			t.Errorf("%s was not rendered", want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/scanner"
+)
+
+// progressPrinter renders scan progress on stderr. On a terminal it redraws a
+// single status line; with --verbose it also prints how long each stage took.
+// Scanners run concurrently, so several stages may be in progress at once.
+type progressPrinter struct {
+	out     io.Writer
+	tty     bool
+	verbose bool
+
+	started  map[string]time.Time
+	lastDraw time.Time
+}
+
+// newProgressPrinter returns nil when there is nothing to display: with
+// --quiet, or when stderr is not a terminal and --verbose is off
+func newProgressPrinter(quiet, verbose bool) *progressPrinter {
+	if quiet {
+		return nil
+	}
+	tty := isTerminal(os.Stderr)
+	if !tty && !verbose {
+		return nil
+	}
+	return &progressPrinter{out: os.Stderr, tty: tty, verbose: verbose, started: make(map[string]time.Time)}
+}
+
+// Update is a pod.ProgressFunc
+func (p *progressPrinter) Update(stage string, done, total int) {
+	now := time.Now()
+	if _, ok := p.started[stage]; !ok {
+		p.started[stage] = now
+		if !p.tty {
+			fmt.Fprintf(p.out, "%s...\n", stage)
+		}
+	}
+
+	if done >= total {
+		p.clearLine()
+		if p.verbose {
+			fmt.Fprintf(p.out, "  %s took %s\n", stage, roundDuration(now.Sub(p.started[stage])))
+		}
+		delete(p.started, stage)
+		return
+	}
+	if !p.tty || now.Sub(p.lastDraw) < 100*time.Millisecond {
+		return
+	}
+	p.lastDraw = now
+	fmt.Fprintf(p.out, "\r\033[K%s: %d/%d", stage, done, total)
+}
+
+// Done clears the status line and, with --verbose, prints the phase timings
+func (p *progressPrinter) Done(timings []scanner.PhaseTiming, elapsed time.Duration) {
+	p.clearLine()
+	if !p.verbose || len(timings) == 0 {
+		return
+	}
+	parts := make([]string, 0, len(timings))
+	for _, t := range timings {
+		parts = append(parts, fmt.Sprintf("%s %s", t.Phase, roundDuration(t.Duration)))
+	}
+	fmt.Fprintf(p.out, "scan finished in %s (%s)\n", roundDuration(elapsed), strings.Join(parts, ", "))
+}
+
+func (p *progressPrinter) clearLine() {
+	if p.tty {
+		fmt.Fprint(p.out, "\r\033[K")
+	}
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	if d < time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(10 * time.Millisecond)
+}
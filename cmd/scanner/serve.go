@@ -0,0 +1,305 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/config"
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+	"github.com/ductnn/k8s-scanner/pkg/metrics"
+	"github.com/ductnn/k8s-scanner/pkg/notify"
+	"github.com/ductnn/k8s-scanner/pkg/offline"
+	"github.com/ductnn/k8s-scanner/pkg/report"
+	"github.com/ductnn/k8s-scanner/pkg/scanner"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/controlplane"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/pod"
+	"github.com/ductnn/k8s-scanner/pkg/server"
+	"github.com/ductnn/k8s-scanner/pkg/tracing"
+	"github.com/ductnn/k8s-scanner/pkg/version"
+
+	"google.golang.org/grpc"
+)
+
+// runServe implements `k8s-scanner serve`, a long-running HTTP server that
+// scans on demand (POST /api/v1/scan) and optionally on an interval
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	var (
+		addr             string
+		grpcAddr         string
+		tokenFile        string
+		interval         time.Duration
+		namespace        string
+		ignoreNS         string
+		kubeconfig       string
+		clusterName      string
+		restartThreshold int
+		onlyReasons      string
+		ignoreReasons    string
+		outdir           string
+		exportOpt        string
+		splitBy          string
+		noEvents         bool
+		scannerTimeout   time.Duration
+		otlpEndpoint     string
+		pprof            bool
+		incremental      bool
+		fullRescan       time.Duration
+		dnsScan          bool
+		dnsLookup        bool
+		teamLabel        string
+		labelKeys        string
+		configPath       string
+		teamMaxCritical  int
+		digestSpec       string
+		notifySpecs      string
+		digestTop        int
+		compress         bool
+		filenameTemplate string
+		offlineMode      bool
+	)
+	fs.StringVar(&addr, "addr", "localhost:8080", "Address to serve the HTTP API and /metrics on; a non-loopback address such as :8080 requires --token-file")
+	fs.StringVar(&grpcAddr, "grpc-addr", "", "Also serve the gRPC API (GetLatestReport, ListReports, Diff, TriggerScan) on this address, e.g. localhost:9090; a non-loopback address requires --token-file")
+	fs.StringVar(&tokenFile, "token-file", "", "Require 'Authorization: Bearer <token>' on every endpoint but /healthz and /readyz, with the token read from this file (default: $"+server.TokenEnv+")")
+	fs.DurationVar(&interval, "interval", 0, "Also scan on this interval (0 scans only when triggered)")
+	fs.StringVar(&namespace, "namespace", "", "Namespace(s) to scan: comma-separated list or empty for all")
+	fs.StringVar(&ignoreNS, "ignore-ns", "", "Comma-separated list of namespaces to ignore")
+	fs.StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file (default: $KUBECONFIG or ~/.kube/config)")
+	fs.StringVar(&clusterName, "cluster-name", "", "Cluster name for issue IDs and report files (auto-detected if not provided)")
+	fs.IntVar(&restartThreshold, "restart-threshold", 10, "Restart count threshold for high severity")
+	fs.StringVar(&onlyReasons, "only-reasons", "", "Only report these issue reasons or check IDs, comma-separated")
+	fs.StringVar(&ignoreReasons, "ignore-reasons", "", "Never report these issue reasons or check IDs, comma-separated")
+	fs.StringVar(&outdir, "outdir", ".reports", "Directory to write exported reports")
+	fs.StringVar(&exportOpt, "export", "", "Report file(s) to write after each scan: csv,md,html,json,ndjson (comma-separated)")
+	fs.BoolVar(&compress, "compress", false, "Gzip exported report files")
+	fs.StringVar(&filenameTemplate, "filename-template", "", "Go template of exported file names, as for scan (default: [cluster-]k8s-report-{{.Timestamp}})")
+	fs.BoolVar(&noEvents, "no-events", false, "Skip fetching events for faster scans")
+	fs.DurationVar(&scannerTimeout, "scanner-timeout", 0, "Time limit for each scanner (0 for no limit)")
+	fs.StringVar(&otlpEndpoint, "otlp-endpoint", tracing.EndpointFromEnv(), "Send traces of each scan to this OTLP/HTTP collector (default: $OTEL_EXPORTER_OTLP_ENDPOINT)")
+	fs.BoolVar(&pprof, "pprof", false, "Also serve /debug/pprof")
+	fs.BoolVar(&incremental, "incremental", false, "With --interval, watch pods and events and only rescan namespaces that changed since the previous cycle")
+	fs.DurationVar(&fullRescan, "full-rescan", time.Hour, "With --incremental, rescan every namespace this often")
+	fs.BoolVar(&dnsScan, "dns", false, "Check CoreDNS/kube-dns health on every scan")
+	fs.BoolVar(&dnsLookup, "dns-lookup", false, "With --dns, also resolve "+controlplane.DefaultLookupName+" from the server pod to catch cluster-wide resolution failures")
+	fs.StringVar(&splitBy, "split-by", "", "Also export one report per tenant in <outdir>/tenants/<tenant>: namespace or team-label (the team of --team-label)")
+	fs.StringVar(&teamLabel, "team-label", "", "Attribute issues to the team named by this pod label (or namespace label) and export per-team metrics")
+	fs.StringVar(&configPath, "config", "", "Configuration file; serve uses its teams (label, budgets and notify routes) and labels")
+	fs.StringVar(&labelKeys, "labels", "", "Pod (or namespace) label and annotation keys to copy into issues, comma-separated")
+	fs.IntVar(&teamMaxCritical, "team-max-critical", -1, "Critical issues each team may have before k8s_scanner_team_compliant drops to 0 (negative: unlimited)")
+	fs.StringVar(&digestSpec, "digest", "", "Send a digest of the latest scan (totals, trend since the previous digest, top namespaces) on this cron schedule, e.g. '0 9 * * *', @daily or @weekly")
+	fs.StringVar(&notifySpecs, "notify", "", "Digest notifiers, comma-separated: slack=<incoming webhook url>, webhook=<url> (the digest is posted as JSON) or email=<a@x;b@y> (SMTP_ADDR and SMTP_FROM must be set); the default route when teams.notify is configured")
+	fs.IntVar(&digestTop, "digest-top", notify.DefaultTopOffenders, "Number of namespaces listed in each digest")
+	fs.BoolVar(&offlineMode, "offline", false, "Air-gapped mode: contact nothing but the Kubernetes API server (refuses --digest and --otlp-endpoint)")
+	_ = fs.Parse(args)
+
+	if offlineMode {
+		setFlags := make(map[string]bool)
+		fs.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+		if digestSpec != "" || setFlags["otlp-endpoint"] {
+			log.Fatalf("--offline cannot be combined with --digest or --otlp-endpoint")
+		}
+		otlpEndpoint = ""
+	}
+
+	var teamBudgets report.TeamBudgets
+	var teamRoutes map[string][]notify.Notifier
+	if configPath != "" {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		setFlags := make(map[string]bool)
+		fs.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+		if !setFlags["team-label"] {
+			teamLabel = cfg.Teams.Label
+		}
+		if !setFlags["labels"] {
+			labelKeys = strings.Join(cfg.Labels, ",")
+		}
+		teamBudgets = cfg.Teams.TeamBudgets()
+		// Validated by config.Load
+		teamRoutes, _ = notify.ParseTeamRoutes(cfg.Teams.Notify)
+	}
+	if slices.Contains(parseExports(exportOpt), report.ExportInventory) {
+		log.Fatalf("--export inventory is only supported by scan")
+	}
+	nameTemplate, err := report.ParseFilenameTemplate(filenameTemplate)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if compress && exportOpt == "" {
+		log.Fatalf("--compress requires --export")
+	}
+	if splitBy != "" {
+		splitBy = strings.ToLower(splitBy)
+		if !slices.Contains(report.SplitModes, splitBy) {
+			log.Fatalf("invalid --split-by %q (expected %s)", splitBy, strings.Join(report.SplitModes, "|"))
+		}
+		if exportOpt == "" {
+			log.Fatalf("--split-by requires --export")
+		}
+		if splitBy == report.SplitTeam && teamLabel == "" {
+			log.Fatalf("--split-by team-label requires --team-label")
+		}
+	}
+
+	token, err := server.LoadToken(tokenFile)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	// Scan triggers and reports are never served to remote clients without a token
+	if token == "" {
+		if !server.IsLoopback(addr) {
+			log.Fatalf("--addr %s accepts remote clients: set --token-file or $%s", addr, server.TokenEnv)
+		}
+		if grpcAddr != "" && !server.IsLoopback(grpcAddr) {
+			log.Fatalf("--grpc-addr %s accepts remote clients: set --token-file or $%s", grpcAddr, server.TokenEnv)
+		}
+	}
+
+	var digestOpts *server.DigestOptions
+	if digestSpec != "" {
+		schedule, err := notify.ParseSchedule(digestSpec)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		digestOpts = &server.DigestOptions{Schedule: schedule, Top: digestTop, Teams: teamRoutes}
+		for _, spec := range splitList(notifySpecs) {
+			n, err := notify.Parse(spec)
+			if err != nil {
+				log.Fatalf("%v", err)
+			}
+			digestOpts.Notifiers = append(digestOpts.Notifiers, n)
+		}
+		if len(digestOpts.Notifiers) == 0 && len(teamRoutes) == 0 {
+			log.Fatalf("--digest requires --notify or teams.notify in --config")
+		}
+	}
+
+	clientset, err := k8s.NewK8sClient(kubeconfig)
+	if err != nil {
+		log.Fatalf("cannot init k8s client: %v", err)
+	}
+	if offlineMode {
+		// NewK8sClient succeeded, so the config loads
+		cfg, _ := k8s.NewRestConfig(kubeconfig)
+		offline.Enable(cfg.Host)
+	}
+	if clusterName == "" {
+		if detected, err := k8s.GetCurrentContext(kubeconfig); err == nil {
+			clusterName = detected
+		}
+	}
+
+	var dnsOpts *controlplane.DNSOptions
+	if dnsScan || dnsLookup {
+		dnsOpts = &controlplane.DNSOptions{Lookup: dnsLookup}
+	}
+
+	if teamMaxCritical >= 0 {
+		teamBudgets.Default.MaxCritical = &teamMaxCritical
+	}
+
+	var incrementalOpts *server.IncrementalOptions
+	if incremental {
+		if interval <= 0 {
+			log.Fatalf("--incremental requires --interval")
+		}
+		incrementalOpts = &server.IncrementalOptions{FullRescanEvery: fullRescan}
+	}
+
+	metrics.Init()
+	srv := server.New(server.Config{
+		Scan: scanner.Options{
+			Client:            clientset,
+			Cluster:           clusterName,
+			Namespaces:        parseNamespaces(namespace),
+			IgnoredNamespaces: parseNamespaces(ignoreNS),
+			RestartThreshold:  int32(restartThreshold),
+			Reasons:           pod.ReasonFilter{Only: splitList(onlyReasons), Ignore: splitList(ignoreReasons)},
+			NoEvents:          noEvents,
+			ScannerTimeout:    scannerTimeout,
+			DNS:               dnsOpts,
+			TeamLabel:         teamLabel,
+			TeamBudgets:       teamBudgets,
+			LabelKeys:         splitList(labelKeys),
+			Preflight:         true,
+		},
+		Outdir:       outdir,
+		Export:       parseExports(exportOpt),
+		NameTemplate: nameTemplate,
+		Compress:     compress,
+		SplitBy:      splitBy,
+		Tracer:       newTracer(otlpEndpoint),
+		Incremental:  incrementalOpts,
+		OnScan: func(res scanner.Result) {
+			metrics.ExportSummary(res.Summary)
+			metrics.ExportIssues(res.Issues)
+			metrics.SetBuildInfo(version.Get(), res.Meta.KubernetesVersion)
+			metrics.ExportAPILatency(res.Meta.APILatency)
+			metrics.ExportTeams(res.Meta.Teams)
+		},
+	})
+
+	shutdown := notifyShutdown()
+	var scheduled sync.WaitGroup
+	if interval > 0 {
+		scheduled.Go(func() { srv.RunEvery(shutdown, interval) })
+	}
+	if digestOpts != nil {
+		if interval <= 0 {
+			log.Printf("warning: --digest without --interval only summarizes scans triggered through the API")
+		}
+		scheduled.Go(func() { srv.RunDigests(shutdown, *digestOpts) })
+	}
+
+	var grpcSrv *grpc.Server
+	if grpcAddr != "" {
+		lis, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			log.Fatalf("cannot listen on --grpc-addr: %v", err)
+		}
+		grpcSrv = srv.GRPC(token)
+		log.Printf("serving the gRPC API on %s", grpcAddr)
+		go func() {
+			if err := grpcSrv.Serve(lis); err != nil {
+				log.Fatalf("gRPC server error: %v", err)
+			}
+		}()
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", srv.Handler())
+	metrics.Register(mux, pprof)
+	handler := http.Handler(mux)
+	if token != "" {
+		handler = server.RequireToken(token, mux)
+	}
+	httpSrv := &http.Server{Addr: addr, Handler: handler}
+
+	// Shutdown waits for triggered scans that are still running
+	stopped := make(chan struct{})
+	go func() {
+		<-shutdown.Done()
+		if grpcSrv != nil {
+			grpcSrv.GracefulStop()
+		}
+		shutdownHTTP(httpSrv)
+		close(stopped)
+	}()
+
+	log.Printf("serving the scan API on %s (POST /api/v1/scan, dashboard at /ui, /metrics, /healthz, /readyz)", addr)
+	if err := httpSrv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("server error: %v", err)
+	}
+	<-stopped
+	scheduled.Wait()
+	log.Printf("serve: stopped")
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// shutdownTimeout bounds how long HTTP servers wait for in-flight requests
+// (including triggered scans) when stopping
+const shutdownTimeout = 30 * time.Second
+
+// notifyShutdown returns a context that is cancelled on the first SIGINT or
+// SIGTERM, letting long-running modes finish the scan in progress, flush
+// their output and stop. A second signal exits at once with 128+signal.
+func notifyShutdown() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan os.Signal, 2)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-ch
+		fmt.Fprintf(os.Stderr, "received %s, shutting down (send it again to exit immediately)\n", sig)
+		cancel()
+		sig = <-ch
+		os.Exit(signalExitCode(sig))
+	}()
+	return ctx
+}
+
+// signalExitCode is the shell convention for a process killed by sig
+func signalExitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 1
+}
+
+// shutdownHTTP stops srv, waiting for active requests to complete
+func shutdownHTTP(srv *http.Server) {
+	if srv == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("failed to stop HTTP server %s: %v", srv.Addr, err)
+	}
+}
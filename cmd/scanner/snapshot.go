@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+	"github.com/ductnn/k8s-scanner/pkg/snapshot"
+)
+
+// runSnapshot implements `k8s-scanner snapshot create <file>`, which records
+// pods, events and nodes so they can be scanned later with --from-snapshot
+func runSnapshot(args []string) {
+	if len(args) == 0 || args[0] != "create" {
+		fmt.Fprintln(os.Stderr, "USAGE:\n  k8s-scanner snapshot create <file> [--namespace ns-1,ns-2] [--kubeconfig path]")
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet("snapshot create", flag.ExitOnError)
+	var (
+		namespace   string
+		kubeconfig  string
+		clusterName string
+	)
+	fs.StringVar(&namespace, "namespace", "", "Namespace(s) to record: comma-separated list or empty for all")
+	fs.StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file (default: $KUBECONFIG or ~/.kube/config)")
+	fs.StringVar(&clusterName, "cluster-name", "", "Cluster name stored in the snapshot (auto-detected from kubeconfig if not provided)")
+
+	// Allow the file argument before or after the flags
+	rest := args[1:]
+	var path string
+	if len(rest) > 0 && rest[0] != "" && rest[0][0] != '-' {
+		path, rest = rest[0], rest[1:]
+	}
+	_ = fs.Parse(rest)
+	if path == "" {
+		path = fs.Arg(0)
+	}
+	if path == "" {
+		log.Fatalf("snapshot create requires an output file")
+	}
+
+	clientset, err := k8s.NewK8sClient(kubeconfig)
+	if err != nil {
+		log.Fatalf("cannot init k8s client: %v", err)
+	}
+	if clusterName == "" {
+		clusterName, _ = k8s.GetCurrentContext(kubeconfig)
+	}
+
+	snap, err := snapshot.Create(context.Background(), clientset, parseNamespaces(namespace), clusterName)
+	if err != nil {
+		log.Fatalf("failed to create snapshot: %v", err)
+	}
+	if err := snap.Save(path); err != nil {
+		log.Fatalf("failed to write snapshot: %v", err)
+	}
+	fmt.Printf("Snapshot written to %s: %d pod(s), %d event(s), %d node(s)\n", path, len(snap.Pods), len(snap.Events), len(snap.Nodes))
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ductnn/k8s-scanner/pkg/report"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// issueStream writes issue batches as NDJSON to stdout (and the ndjson
+// export, if requested) while the scan is running. Issues are only kept in
+// memory when another export or the CRD report still needs them.
+type issueStream struct {
+	stdout   *report.NDJSONWriter
+	file     *os.File
+	export   *report.NDJSONWriter
+	ages     report.AgeTracker
+	redactor *report.Redactor
+	retain   bool
+	retained []types.Issue
+	err      error
+}
+
+// newIssueStream creates the stream; redactor is optional and anonymizes
+// every batch once it has been aged
+func newIssueStream(outdir, cluster, base string, kinds []report.ExportKind, crdReport bool, redactor *report.Redactor) (*issueStream, error) {
+	// Load the previous report before this scan adds files to outdir
+	previous, _ := report.LatestReport(outdir, cluster)
+	s := &issueStream{
+		stdout:   report.NewNDJSONWriter(os.Stdout),
+		ages:     report.NewAgeTracker(previous),
+		redactor: redactor,
+		retain:   crdReport,
+	}
+
+	for _, k := range kinds {
+		if k != report.ExportNDJSON {
+			s.retain = true
+			continue
+		}
+		if s.file != nil {
+			continue
+		}
+		path := filepath.Join(outdir, base+".ndjson")
+		if err := report.EnsureDir(filepath.Dir(path)); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+		}
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ndjson export: %w", err)
+		}
+		s.file = f
+		s.export = report.NewNDJSONWriter(f)
+	}
+	return s, nil
+}
+
+// Write emits one batch; it is passed to scanner.Options.Stream
+func (s *issueStream) Write(batch []types.Issue) {
+	s.ages.Track(batch)
+	if s.redactor != nil {
+		s.redactor.Issues(batch)
+	}
+	if err := s.stdout.Write(batch); err != nil && s.err == nil {
+		s.err = err
+	}
+	if s.export != nil {
+		if err := s.export.Write(batch); err != nil && s.err == nil {
+			s.err = err
+		}
+	}
+	if s.retain {
+		s.retained = append(s.retained, batch...)
+	}
+}
+
+// Close closes the ndjson export and returns the first write error
+func (s *issueStream) Close() error {
+	if s.file != nil {
+		if err := s.file.Close(); err != nil && s.err == nil {
+			s.err = err
+		}
+		s.file = nil
+	}
+	return s.err
+}
+
+// withoutKind returns kinds with k removed
+func withoutKind(kinds []report.ExportKind, k report.ExportKind) []report.ExportKind {
+	var out []report.ExportKind
+	for _, v := range kinds {
+		if v != k {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/tracing"
+)
+
+// newTracer returns a tracer exporting to endpoint, or nil when it is empty
+func newTracer(endpoint string) *tracing.Tracer {
+	if endpoint == "" {
+		return nil
+	}
+	return tracing.NewTracer(tracing.NewExporter(endpoint))
+}
+
+// flushTraces exports the recorded spans; a collector that is down only
+// produces a warning
+func flushTraces(tracer *tracing.Tracer) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := tracer.Flush(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+	"github.com/ductnn/k8s-scanner/pkg/scanner"
+	"github.com/ductnn/k8s-scanner/pkg/version"
+)
+
+// runVersion implements `k8s-scanner version`
+func runVersion(args []string) {
+	fs := flag.NewFlagSet("version", flag.ExitOnError)
+	var (
+		clientOnly bool
+		output     string
+		kubeconfig string
+	)
+	fs.BoolVar(&clientOnly, "client", false, "Only print the scanner version, without contacting the cluster")
+	fs.StringVar(&output, "output", "text", "Output format: text|json")
+	fs.StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file (default: $KUBECONFIG or ~/.kube/config)")
+	_ = fs.Parse(args)
+
+	info := version.Get()
+	serverVersion := ""
+	serverErr := ""
+	if !clientOnly {
+		if clientset, err := k8s.NewK8sClient(kubeconfig); err != nil {
+			serverErr = err.Error()
+		} else if serverVersion = scanner.ServerVersion(clientset); serverVersion == "" {
+			serverErr = "unable to reach the API server"
+		}
+	}
+
+	switch strings.ToLower(output) {
+	case "json":
+		obj := map[string]any{"client": info}
+		if serverVersion != "" {
+			obj["server"] = map[string]string{"kubernetes_version": serverVersion}
+		}
+		b, _ := json.MarshalIndent(obj, "", "  ")
+		fmt.Println(string(b))
+	case "text":
+		fmt.Printf("Version:    %s\n", info.Version)
+		if info.Commit != "" {
+			fmt.Printf("Commit:     %s\n", info.Commit)
+		}
+		fmt.Printf("Go:         %s\n", info.GoVersion)
+		if info.ClientGoVersion != "" {
+			fmt.Printf("client-go:  %s\n", info.ClientGoVersion)
+		}
+		if !clientOnly {
+			if serverVersion != "" {
+				fmt.Printf("Kubernetes: %s\n", serverVersion)
+			} else {
+				fmt.Printf("Kubernetes: unknown (%s)\n", serverErr)
+			}
+		}
+	default:
+		log.Fatalf("unknown output format %q (want text or json)", output)
+	}
+}
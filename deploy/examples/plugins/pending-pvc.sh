@@ -0,0 +1,27 @@
+#!/bin/sh
+# Example k8s-scanner plugin (see pkg/plugin): reports PersistentVolumeClaims
+# stuck in Pending. Requires kubectl and jq.
+#
+# Usage: k8s-scanner --plugins-dir deploy/examples/plugins
+set -eu
+
+request=$(cat)
+namespaces=$(echo "$request" | jq -r '.namespaces | join(" ")')
+
+list() {
+	if [ -z "$namespaces" ]; then
+		kubectl get pvc --all-namespaces -o json
+	else
+		for ns in $namespaces; do kubectl get pvc -n "$ns" -o json; done
+	fi
+}
+
+list | jq -s '{issues: [.[].items[] | select(.status.phase == "Pending") | {
+	kind: "PersistentVolumeClaim",
+	namespace: .metadata.namespace,
+	name: .metadata.name,
+	severity: "medium",
+	reason: "PVCPending",
+	root_cause: "PVC chưa được bind — StorageClass không tồn tại hoặc không provision được volume.",
+	in_state_since: .metadata.creationTimestamp
+}]}'
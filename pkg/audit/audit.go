@@ -0,0 +1,169 @@
+// Package audit records the resources deleted by the clean mode so that
+// deletions made by the tool can be traced back to who ran it, when and why.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// LastCleanAnnotation is set on the audit ConfigMap to the summary of the latest run
+const LastCleanAnnotation = "scanner.ductnn.io/last-clean"
+
+// Entry is a single deletion
+type Entry struct {
+	Time      string `json:"time"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"`
+	Result    string `json:"result"` // deleted or failed
+	Error     string `json:"error,omitempty"`
+}
+
+// Log is the audit record of one clean run
+type Log struct {
+	StartedAt string  `json:"started_at"`
+	Actor     string  `json:"actor"`
+	Cluster   string  `json:"cluster,omitempty"`
+	Entries   []Entry `json:"entries"`
+}
+
+// NewLog starts an audit log for the given actor and cluster
+func NewLog(actor string, cluster string) *Log {
+	return &Log{
+		StartedAt: time.Now().Format(time.RFC3339),
+		Actor:     actor,
+		Cluster:   cluster,
+		Entries:   make([]Entry, 0),
+	}
+}
+
+// Record appends the outcome of a deletion; err is nil when it succeeded
+func (l *Log) Record(kind, namespace, name, reason string, err error) {
+	e := Entry{
+		Time:      time.Now().Format(time.RFC3339),
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Reason:    reason,
+		Result:    "deleted",
+	}
+	if err != nil {
+		e.Result = "failed"
+		e.Error = err.Error()
+	}
+	l.Entries = append(l.Entries, e)
+}
+
+// Deleted returns the number of successful deletions
+func (l *Log) Deleted() int {
+	n := 0
+	for _, e := range l.Entries {
+		if e.Result == "deleted" {
+			n++
+		}
+	}
+	return n
+}
+
+// WhoAmI returns the Kubernetes identity of the client, falling back to the
+// local OS user when SelfSubjectReview is not available
+func WhoAmI(ctx context.Context, client kubernetes.Interface) string {
+	review, err := client.AuthenticationV1().SelfSubjectReviews().Create(ctx, &authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if err == nil && review.Status.UserInfo.Username != "" {
+		return review.Status.UserInfo.Username
+	}
+	if u, err := user.Current(); err == nil {
+		return "local:" + u.Username
+	}
+	return "unknown"
+}
+
+// WriteFiles writes the log as <basename>.json and <basename>.csv into outdir
+// and returns the paths written
+func (l *Log) WriteFiles(outdir string, basename string) ([]string, error) {
+	if err := os.MkdirAll(outdir, 0o755); err != nil {
+		return nil, err
+	}
+
+	jsonPath := filepath.Join(outdir, basename+".json")
+	b, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(jsonPath, b, 0o644); err != nil {
+		return nil, err
+	}
+
+	csvPath := filepath.Join(outdir, basename+".csv")
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	_ = w.Write([]string{"time", "actor", "cluster", "kind", "namespace", "name", "reason", "result", "error"})
+	for _, e := range l.Entries {
+		_ = w.Write([]string{e.Time, l.Actor, l.Cluster, e.Kind, e.Namespace, e.Name, e.Reason, e.Result, e.Error})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(csvPath, buf.Bytes(), 0o644); err != nil {
+		return nil, err
+	}
+
+	return []string{jsonPath, csvPath}, nil
+}
+
+// WriteConfigMap stores the log in the ConfigMap "namespace/name" (created if
+// missing) under a key named after the run time, and annotates the ConfigMap
+// with a one-line summary of the run
+func (l *Log) WriteConfigMap(ctx context.Context, client kubernetes.Interface, ref string) error {
+	namespace, name, ok := strings.Cut(ref, "/")
+	if !ok || namespace == "" || name == "" {
+		return fmt.Errorf("invalid ConfigMap reference %q (expected namespace/name)", ref)
+	}
+
+	b, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	key := "clean-" + strings.NewReplacer(":", "", "-", "").Replace(l.StartedAt) + ".json"
+	summary := fmt.Sprintf("%s by %s: %d of %d deleted", l.StartedAt, l.Actor, l.Deleted(), len(l.Entries))
+
+	cms := client.CoreV1().ConfigMaps(namespace)
+	cm, err := cms.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		cm = &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		cm.Data = map[string]string{key: string(b)}
+		cm.Annotations = map[string]string{LastCleanAnnotation: summary}
+		_, err = cms.Create(ctx, cm, metav1.CreateOptions{})
+	} else if err == nil {
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		if cm.Annotations == nil {
+			cm.Annotations = map[string]string{}
+		}
+		cm.Data[key] = string(b)
+		cm.Annotations[LastCleanAnnotation] = summary
+		_, err = cms.Update(ctx, cm, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write audit ConfigMap %s: %w", ref, err)
+	}
+	return nil
+}
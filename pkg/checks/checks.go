@@ -0,0 +1,181 @@
+// Package checks is the catalog of the findings reported by the scanners,
+// used to document them (`k8s-scanner checks list`) and to identify them
+// independently of their reason.
+package checks
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+)
+
+// Check is one kind of finding
+type Check struct {
+	// ID is stable across releases, e.g. POD001
+	ID string `json:"id"`
+	// Reason is the Issue.Reason of the finding
+	Reason string `json:"reason"`
+	// Scanner reporting it; "pods" always runs, the others are enabled by
+	// the flag of the same name (best-practices also runs in lint)
+	Scanner     string `json:"scanner"`
+	Description string `json:"description"`
+	// Severity is the default severity, before overrides and escalation
+	Severity severity.Level `json:"severity"`
+	// Config lists the flags and config file keys tuning the check
+	Config []string `json:"config,omitempty"`
+}
+
+// Scanner names of the catalog
+const (
+	ScannerPods          = "pods"
+	ScannerCapacity      = "capacity"
+	ScannerGC            = "gc"
+	ScannerQuota         = "quota"
+	ScannerPriority      = "priority"
+	ScannerTopology      = "topology"
+	ScannerBestPractices = "best-practices"
+	ScannerDNS           = "dns"
+	ScannerControlPlane  = "control-plane"
+	ScannerWebhooks      = "webhooks"
+	ScannerGitOps        = "gitops"
+	ScannerMesh          = "mesh"
+	ScannerKarpenter     = "karpenter"
+)
+
+var catalog = []Check{
+	// Pods
+	{ID: "POD001", Reason: "CrashLoopBackOff", Scanner: ScannerPods, Description: "Container keeps crashing after it starts", Config: []string{"--escalate-after", "overrides[].severity"}},
+	{ID: "POD002", Reason: "ImagePullBackOff", Scanner: ScannerPods, Description: "Image cannot be pulled and the kubelet backs off", Config: []string{"--registry-check", "--escalate-after"}},
+	{ID: "POD003", Reason: "ErrImagePull", Scanner: ScannerPods, Description: "Last image pull failed", Config: []string{"--registry-check"}},
+	{ID: "POD004", Reason: "OOMKilled", Scanner: ScannerPods, Description: "Container was killed for exceeding its memory limit"},
+	{ID: "POD005", Reason: "Error", Scanner: ScannerPods, Description: "Container exited with a non-zero code"},
+	{ID: "POD006", Reason: "Evicted", Scanner: ScannerPods, Description: "Pod was evicted by the kubelet under node pressure"},
+	{ID: "POD007", Reason: "Pending", Scanner: ScannerPods, Description: "Pod is not scheduled or not started past its grace period", Config: []string{"--pending-grace", "overrides[].pendingGrace"}},
+	{ID: "POD008", Reason: "HighRestartCount", Scanner: ScannerPods, Description: "Container restarted more often than the threshold", Config: []string{"--restart-threshold", "overrides[].restartThreshold"}},
+	{ID: "POD009", Reason: "TerminatingStuck", Scanner: ScannerPods, Description: "Pod is still Terminating long after its grace period", Config: []string{"--terminating-margin"}},
+	{ID: "POD010", Reason: "ContainerNotReady", Scanner: ScannerPods, Description: "Running container fails its readiness probe", Config: []string{"--unready-after"}},
+	{ID: "POD011", Reason: "ReadinessGatesNotReady", Scanner: ScannerPods, Description: "Pod readiness gates are not satisfied", Config: []string{"--unready-after"}},
+	{ID: "POD012", Reason: "ContainerCreating", Scanner: ScannerPods, Description: "Container is stuck being created past the startup grace", Config: []string{"--startup-grace"}},
+	{ID: "POD013", Reason: "CreateContainerConfigError", Scanner: ScannerPods, Description: "Container config references a missing ConfigMap, Secret or key"},
+	{ID: "POD014", Reason: "CreateContainerError", Scanner: ScannerPods, Description: "Container runtime failed to create the container"},
+	{ID: "POD015", Reason: "InvalidImageName", Scanner: ScannerPods, Description: "Image reference cannot be parsed"},
+	{ID: "POD016", Reason: "RunContainerError", Scanner: ScannerPods, Description: "Container runtime failed to start the container"},
+	{ID: "POD017", Reason: "ImageInspectError", Scanner: ScannerPods, Description: "Pulled image cannot be inspected on the node"},
+	{ID: "POD018", Reason: "StartError", Scanner: ScannerPods, Description: "Container terminated because its process could not start"},
+	{ID: "POD019", Reason: "LivenessProbeFailed", Scanner: ScannerPods, Description: "Liveness probe fails and the kubelet restarts the container", Config: []string{"--event-max-age"}},
+	{ID: "POD020", Reason: "ReadinessProbeFailed", Scanner: ScannerPods, Description: "Readiness probe fails and the pod leaves its Services", Config: []string{"--event-max-age"}},
+	{ID: "POD021", Reason: "StartupProbeFailed", Scanner: ScannerPods, Description: "Startup probe fails before the app finishes starting", Config: []string{"--event-max-age"}},
+	{ID: "POD022", Reason: "FailedMount", Scanner: ScannerPods, Description: "Volume cannot be mounted: missing Secret/ConfigMap, CSI or other error", Config: []string{"--event-max-age"}},
+	{ID: "POD023", Reason: "FailedAttachVolume", Scanner: ScannerPods, Description: "Volume cannot be attached, e.g. still attached to another node", Config: []string{"--event-max-age"}},
+	{ID: "POD024", Reason: "NodeShutdown", Scanner: ScannerPods, Description: "Pod was stopped by a graceful shutdown of its node"},
+	{ID: "POD025", Reason: "SpotInterruption", Scanner: ScannerPods, Description: "Pod issue caused by the reclaim of a spot or preemptible node", Config: []string{"--ignore-reasons"}},
+
+	// Scheduling priority
+	{ID: "PRI001", Reason: "PendingBehindHigherPriority", Scanner: ScannerPriority, Description: "Pod is pending for resources held by higher-priority pods", Config: []string{"--pending-grace"}},
+	{ID: "PRI002", Reason: "Preempted", Scanner: ScannerPriority, Description: "Pod was recently preempted by a higher-priority pod", Config: []string{"--event-max-age"}},
+
+	// Capacity
+	{ID: "CAP001", Reason: "NodeHighCPU", Scanner: ScannerCapacity, Description: "Node uses most of its allocatable CPU", Config: []string{"--node-cpu-threshold", "capacity.nodeCPUPercent"}},
+	{ID: "CAP002", Reason: "NodeHighMemory", Scanner: ScannerCapacity, Description: "Node uses most of its allocatable memory", Config: []string{"--node-memory-threshold", "capacity.nodeMemoryPercent"}},
+	{ID: "CAP003", Reason: "NamespaceUsageAboveRequests", Scanner: ScannerCapacity, Description: "Namespace uses far more than it requests", Config: []string{"--usage-ratio", "capacity.usageRatio"}},
+	{ID: "CAP004", Reason: "NamespaceUsageBelowRequests", Scanner: ScannerCapacity, Description: "Namespace uses far less than it requests", Config: []string{"--usage-ratio", "capacity.usageRatio"}},
+	{ID: "CAP005", Reason: "OverProvisioned", Scanner: ScannerCapacity, Description: "Workload requests several times its actual usage", Config: []string{"capacity.overProvisioned"}},
+
+	// Best practices and security of pod specs
+	{ID: "BP001", Reason: "MissingLivenessProbe", Scanner: ScannerBestPractices, Description: "Container has no liveness probe"},
+	{ID: "BP002", Reason: "MissingReadinessProbe", Scanner: ScannerBestPractices, Description: "Container has no readiness probe"},
+	{ID: "BP003", Reason: "MissingResourceRequests", Scanner: ScannerBestPractices, Description: "Container sets no CPU or memory requests"},
+	{ID: "BP004", Reason: "MissingResourceLimits", Scanner: ScannerBestPractices, Description: "Container sets no CPU or memory limits"},
+	{ID: "BP005", Reason: "ImageTagLatest", Scanner: ScannerBestPractices, Description: "Image uses the latest tag or no tag"},
+	{ID: "SEC001", Reason: "RunAsRootAllowed", Scanner: ScannerBestPractices, Description: "Container may run as root (runAsNonRoot not set)"},
+	{ID: "SEC002", Reason: "PrivilegeEscalationAllowed", Scanner: ScannerBestPractices, Description: "Container allows privilege escalation"},
+	{ID: "SEC003", Reason: "PrivilegedContainer", Scanner: ScannerBestPractices, Description: "Container runs privileged"},
+
+	// Quotas
+	{ID: "QTA001", Reason: "QuotaExhausted", Scanner: ScannerQuota, Description: "ResourceQuota has reached a hard limit"},
+	{ID: "QTA002", Reason: "QuotaNearlyExhausted", Scanner: ScannerQuota, Description: "ResourceQuota is close to a hard limit", Config: []string{"--quota-threshold"}},
+	{ID: "QTA003", Reason: "LimitRangeRejected", Scanner: ScannerQuota, Description: "Pods were rejected by a ResourceQuota or LimitRange"},
+	{ID: "QTA004", Reason: "MissingResourceQuota", Scanner: ScannerQuota, Description: "Namespace has no ResourceQuota", Config: []string{"--require-quota"}},
+
+	// Control plane, admission and cluster DNS
+	{ID: "CP001", Reason: "ControlPlaneDegraded", Scanner: ScannerControlPlane, Description: "API server, etcd, scheduler or controller-manager is unhealthy"},
+	{ID: "CP002", Reason: "WebhookUnavailable", Scanner: ScannerWebhooks, Description: "Admission webhook with failurePolicy Fail has no ready endpoints"},
+	{ID: "DNS001", Reason: "DNSUnavailable", Scanner: ScannerDNS, Description: "CoreDNS/kube-dns has no ready replicas or endpoints"},
+	{ID: "DNS002", Reason: "DNSDegraded", Scanner: ScannerDNS, Description: "Some CoreDNS/kube-dns replicas are not ready"},
+	{ID: "DNS003", Reason: "DNSWarningEvents", Scanner: ScannerDNS, Description: "CoreDNS/kube-dns has recent warning events", Config: []string{"--event-max-age"}},
+	{ID: "DNS004", Reason: "DNSResolutionFailed", Scanner: ScannerDNS, Description: "Test lookup of a cluster name failed", Config: []string{"serve --dns-lookup"}},
+
+	// Topology spread
+	{ID: "TOP001", Reason: "ReplicasOnSingleNode", Scanner: ScannerTopology, Description: "Every replica of a workload runs on one node"},
+	{ID: "TOP002", Reason: "ReplicasInSingleZone", Scanner: ScannerTopology, Description: "Every replica of a workload runs in one zone"},
+
+	// GitOps
+	{ID: "GIT001", Reason: "ArgoAppDegraded", Scanner: ScannerGitOps, Description: "Argo CD Application health is Degraded"},
+	{ID: "GIT002", Reason: "ArgoSyncFailed", Scanner: ScannerGitOps, Description: "Argo CD Application sync failed"},
+	{ID: "GIT003", Reason: "ArgoAppOutOfSync", Scanner: ScannerGitOps, Description: "Argo CD Application is out of sync"},
+	{ID: "GIT004", Reason: "FluxReconcileFailed", Scanner: ScannerGitOps, Description: "Flux Kustomization or HelmRelease failed to reconcile"},
+
+	// Karpenter
+	{ID: "KRP001", Reason: "KarpenterLaunchFailed", Scanner: ScannerKarpenter, Description: "NodeClaim failed to launch its instance"},
+	{ID: "KRP002", Reason: "KarpenterNodeNotRegistered", Scanner: ScannerKarpenter, Description: "NodeClaim instance launched but its node never joined"},
+	{ID: "KRP003", Reason: "KarpenterNodeDrifted", Scanner: ScannerKarpenter, Description: "NodeClaim drifted from its NodePool and awaits replacement"},
+	{ID: "KRP004", Reason: "KarpenterNodePoolNotReady", Scanner: ScannerKarpenter, Description: "NodePool is not ready to provision nodes"},
+	{ID: "KRP005", Reason: "KarpenterNodePoolLimitReached", Scanner: ScannerKarpenter, Description: "NodePool usage reached its limits"},
+
+	// Service mesh
+	{ID: "MESH001", Reason: "IstioSidecarNotReady", Scanner: ScannerMesh, Description: "Istio sidecar of a pod is not ready"},
+	{ID: "MESH002", Reason: "IstioSidecarMissing", Scanner: ScannerMesh, Description: "Pod of an injected namespace has no Istio sidecar"},
+	{ID: "MESH003", Reason: "IstioMTLSConflict", Scanner: ScannerMesh, Description: "DestinationRule TLS mode conflicts with PeerAuthentication mTLS"},
+
+	// Housekeeping
+	{ID: "GC001", Reason: "OrphanedReplicaSet", Scanner: ScannerGC, Description: "ReplicaSet has no owner", Config: []string{"--gc-min-age"}},
+	{ID: "GC002", Reason: "ExpiredJob", Scanner: ScannerGC, Description: "Finished Job without ttlSecondsAfterFinished is kept", Config: []string{"--gc-job-ttl"}},
+	{ID: "GC003", Reason: "UnusedConfigMap", Scanner: ScannerGC, Description: "ConfigMap is referenced by no pod", Config: []string{"--gc-min-age"}},
+	{ID: "GC004", Reason: "UnusedSecret", Scanner: ScannerGC, Description: "Secret is referenced by no pod or ServiceAccount", Config: []string{"--gc-min-age"}},
+	{ID: "GC005", Reason: "DanglingEndpoints", Scanner: ScannerGC, Description: "Endpoints object has no Service"},
+}
+
+// byReason indexes the catalog by reason
+var byReason = func() map[string]int {
+	m := make(map[string]int, len(catalog))
+	for i := range catalog {
+		catalog[i].Severity = severity.FromReason(catalog[i].Reason)
+		m[catalog[i].Reason] = i
+	}
+	return m
+}()
+
+// All returns every check, grouped by scanner
+func All() []Check {
+	out := make([]Check, len(catalog))
+	copy(out, catalog)
+	return out
+}
+
+// Lookup finds a check by ID (case-insensitive) or reason
+func Lookup(idOrReason string) (Check, bool) {
+	if i, ok := byReason[idOrReason]; ok {
+		return catalog[i], true
+	}
+	for _, c := range catalog {
+		if strings.EqualFold(c.ID, idOrReason) {
+			return c, true
+		}
+	}
+	return Check{}, false
+}
+
+// Scanners lists the scanners of the catalog, sorted
+func Scanners() []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, c := range catalog {
+		if !seen[c.Scanner] {
+			seen[c.Scanner] = true
+			out = append(out, c.Scanner)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,355 @@
+// Package cloud reads the health of managed Kubernetes control planes and
+// node pools (EKS, GKE, AKS) from the cloud provider, so a scan taken while
+// the control plane upgrades or a node pool is degraded says so. The
+// provider CLI (aws, gcloud, az) is run with the credentials it is
+// configured with.
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/cmderr"
+)
+
+// Providers of managed clusters
+const (
+	EKS = "eks"
+	GKE = "gke"
+	AKS = "aks"
+)
+
+// Cluster identifies a managed cluster at its cloud provider
+type Cluster struct {
+	Provider string
+	// Name is the name of the cluster at the provider
+	Name string
+	// Location is the AWS region or the GKE zone or region
+	Location string
+	// Project is the GCP project of GKE clusters
+	Project string
+	// ResourceGroup is the Azure resource group of AKS clusters
+	ResourceGroup string
+}
+
+// Status is the health of a managed cluster as reported by its provider
+type Status struct {
+	Provider string `json:"provider"`
+	Cluster  string `json:"cluster"`
+	// State is the provider's state of the control plane (ACTIVE,
+	// UPDATING, RUNNING, RECONCILING, Succeeded, Upgrading...)
+	State     string     `json:"state"`
+	Version   string     `json:"version,omitempty"`
+	Upgrading bool       `json:"upgrading,omitempty"`
+	Degraded  bool       `json:"degraded,omitempty"`
+	Message   string     `json:"message,omitempty"`
+	NodePools []NodePool `json:"node_pools,omitempty"`
+	// Context lists what may explain the findings of the scan, such as
+	// "control plane upgrading" or "node pool ng-1 degraded"
+	Context []string `json:"context,omitempty"`
+}
+
+// NodePool is the state of a managed node group, node pool or agent pool
+type NodePool struct {
+	Name      string `json:"name"`
+	State     string `json:"state"`
+	Version   string `json:"version,omitempty"`
+	Upgrading bool   `json:"upgrading,omitempty"`
+	Degraded  bool   `json:"degraded,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+var (
+	// eksContextPattern matches the context names written by aws eks update-kubeconfig
+	eksContextPattern = regexp.MustCompile(`^arn:aws[\w-]*:eks:([^:]+):\d+:cluster/(.+)$`)
+	// gkeContextPattern matches the context names written by gcloud container clusters get-credentials
+	gkeContextPattern = regexp.MustCompile(`^gke_([^_]+)_([^_]+)_(.+)$`)
+)
+
+// Parse reads a cluster given as eks:<region>/<cluster>,
+// gke:<project>/<location>/<cluster> or aks:<resource-group>/<cluster>.
+// With "auto" the EKS or GKE cluster is read from the kubeconfig context
+// name; AKS contexts do not name the resource group.
+func Parse(spec, kubeContext string) (Cluster, error) {
+	if spec == "auto" {
+		if m := eksContextPattern.FindStringSubmatch(kubeContext); m != nil {
+			return Cluster{Provider: EKS, Location: m[1], Name: m[2]}, nil
+		}
+		if m := gkeContextPattern.FindStringSubmatch(kubeContext); m != nil {
+			return Cluster{Provider: GKE, Project: m[1], Location: m[2], Name: m[3]}, nil
+		}
+		return Cluster{}, fmt.Errorf("cannot detect the managed cluster of kubeconfig context %q: use eks:<region>/<cluster>, gke:<project>/<location>/<cluster> or aks:<resource-group>/<cluster>", kubeContext)
+	}
+
+	provider, path, _ := strings.Cut(spec, ":")
+	parts := strings.Split(path, "/")
+	switch {
+	case slices.Contains(parts, ""):
+	case provider == EKS && len(parts) == 2:
+		return Cluster{Provider: EKS, Location: parts[0], Name: parts[1]}, nil
+	case provider == GKE && len(parts) == 3:
+		return Cluster{Provider: GKE, Project: parts[0], Location: parts[1], Name: parts[2]}, nil
+	case provider == AKS && len(parts) == 2:
+		return Cluster{Provider: AKS, ResourceGroup: parts[0], Name: parts[1]}, nil
+	}
+	return Cluster{}, fmt.Errorf("invalid managed cluster %q (expected auto, eks:<region>/<cluster>, gke:<project>/<location>/<cluster> or aks:<resource-group>/<cluster>)", spec)
+}
+
+// Check asks the provider for the state of the control plane and node
+// pools of the cluster
+func (c Cluster) Check(ctx context.Context) (*Status, error) {
+	var (
+		st  *Status
+		err error
+	)
+	switch c.Provider {
+	case EKS:
+		st, err = checkEKS(ctx, c)
+	case GKE:
+		st, err = checkGKE(ctx, c)
+	case AKS:
+		st, err = checkAKS(ctx, c)
+	default:
+		return nil, fmt.Errorf("unsupported cloud provider %q", c.Provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	st.Provider, st.Cluster = c.Provider, c.Name
+	st.Context = summarize(st)
+	return st, nil
+}
+
+// summarize lists the upgrades and degradations of a cluster
+func summarize(st *Status) []string {
+	var out []string
+	switch {
+	case st.Degraded:
+		out = append(out, "control plane degraded ("+detail(st.State, st.Message)+")")
+	case st.Upgrading:
+		out = append(out, "control plane upgrading ("+detail(st.State, st.Message)+")")
+	}
+	for _, np := range st.NodePools {
+		switch {
+		case np.Degraded:
+			out = append(out, fmt.Sprintf("node pool %s degraded (%s)", np.Name, detail(np.State, np.Message)))
+		case np.Upgrading:
+			out = append(out, fmt.Sprintf("node pool %s upgrading (%s)", np.Name, detail(np.State, np.Message)))
+		}
+	}
+	return out
+}
+
+func detail(state, msg string) string {
+	if msg == "" {
+		return state
+	}
+	return state + ": " + msg
+}
+
+// EKS: aws eks describe-cluster, list-nodegroups and describe-nodegroup
+
+type eksHealth struct {
+	Issues []struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"issues"`
+}
+
+func (h eksHealth) message() string {
+	msgs := make([]string, 0, len(h.Issues))
+	for _, is := range h.Issues {
+		msgs = append(msgs, detail(is.Code, is.Message))
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func checkEKS(ctx context.Context, c Cluster) (*Status, error) {
+	var cluster struct {
+		Cluster struct {
+			Status  string    `json:"status"`
+			Version string    `json:"version"`
+			Health  eksHealth `json:"health"`
+		} `json:"cluster"`
+	}
+	region := []string{"--region", c.Location, "--output", "json"}
+	if err := runJSON(ctx, &cluster, "aws", append([]string{"eks", "describe-cluster", "--name", c.Name}, region...)...); err != nil {
+		return nil, err
+	}
+	cl := cluster.Cluster
+	st := &Status{
+		State:     cl.Status,
+		Version:   cl.Version,
+		Upgrading: cl.Status == "UPDATING",
+		Degraded:  cl.Status == "FAILED" || len(cl.Health.Issues) > 0,
+		Message:   cl.Health.message(),
+	}
+
+	var groups struct {
+		Nodegroups []string `json:"nodegroups"`
+	}
+	if err := runJSON(ctx, &groups, "aws", append([]string{"eks", "list-nodegroups", "--cluster-name", c.Name}, region...)...); err != nil {
+		return nil, err
+	}
+	for _, name := range groups.Nodegroups {
+		var ng struct {
+			Nodegroup struct {
+				Status  string    `json:"status"`
+				Version string    `json:"version"`
+				Health  eksHealth `json:"health"`
+			} `json:"nodegroup"`
+		}
+		if err := runJSON(ctx, &ng, "aws", append([]string{"eks", "describe-nodegroup", "--cluster-name", c.Name, "--nodegroup-name", name}, region...)...); err != nil {
+			return nil, err
+		}
+		g := ng.Nodegroup
+		st.NodePools = append(st.NodePools, NodePool{
+			Name:      name,
+			State:     g.Status,
+			Version:   g.Version,
+			Upgrading: g.Status == "UPDATING",
+			Degraded:  g.Status == "DEGRADED" || strings.HasSuffix(g.Status, "_FAILED") || len(g.Health.Issues) > 0,
+			Message:   g.Health.message(),
+		})
+	}
+	return st, nil
+}
+
+// GKE: gcloud container clusters describe
+
+func checkGKE(ctx context.Context, c Cluster) (*Status, error) {
+	var cluster struct {
+		Status               string `json:"status"`
+		StatusMessage        string `json:"statusMessage"`
+		CurrentMasterVersion string `json:"currentMasterVersion"`
+		NodePools            []struct {
+			Name          string `json:"name"`
+			Status        string `json:"status"`
+			StatusMessage string `json:"statusMessage"`
+			Version       string `json:"version"`
+		} `json:"nodePools"`
+	}
+	if err := runJSON(ctx, &cluster, "gcloud", "container", "clusters", "describe", c.Name,
+		"--project", c.Project, "--location", c.Location, "--format", "json"); err != nil {
+		return nil, err
+	}
+	st := &Status{
+		State:     cluster.Status,
+		Version:   cluster.CurrentMasterVersion,
+		Upgrading: cluster.Status == "RECONCILING",
+		Degraded:  cluster.Status == "DEGRADED" || cluster.Status == "ERROR",
+		Message:   cluster.StatusMessage,
+	}
+	for _, np := range cluster.NodePools {
+		st.NodePools = append(st.NodePools, NodePool{
+			Name:      np.Name,
+			State:     np.Status,
+			Version:   np.Version,
+			Upgrading: np.Status == "RECONCILING",
+			Degraded:  np.Status == "RUNNING_WITH_ERROR" || np.Status == "ERROR",
+			Message:   np.StatusMessage,
+		})
+	}
+	return st, nil
+}
+
+// AKS: az aks show
+
+type aksPowerState struct {
+	Code string `json:"code"`
+}
+
+func checkAKS(ctx context.Context, c Cluster) (*Status, error) {
+	var cluster struct {
+		ProvisioningState        string        `json:"provisioningState"`
+		PowerState               aksPowerState `json:"powerState"`
+		KubernetesVersion        string        `json:"kubernetesVersion"`
+		CurrentKubernetesVersion string        `json:"currentKubernetesVersion"`
+		AgentPoolProfiles        []struct {
+			Name                       string        `json:"name"`
+			ProvisioningState          string        `json:"provisioningState"`
+			PowerState                 aksPowerState `json:"powerState"`
+			OrchestratorVersion        string        `json:"orchestratorVersion"`
+			CurrentOrchestratorVersion string        `json:"currentOrchestratorVersion"`
+		} `json:"agentPoolProfiles"`
+	}
+	if err := runJSON(ctx, &cluster, "az", "aks", "show", "--resource-group", c.ResourceGroup, "--name", c.Name, "--output", "json"); err != nil {
+		return nil, err
+	}
+	st := &Status{
+		State:     cluster.ProvisioningState,
+		Version:   firstNonEmpty(cluster.CurrentKubernetesVersion, cluster.KubernetesVersion),
+		Upgrading: aksUpgrading(cluster.ProvisioningState, cluster.CurrentKubernetesVersion, cluster.KubernetesVersion),
+		Degraded:  cluster.ProvisioningState == "Failed" || cluster.PowerState.Code == "Stopped",
+	}
+	if cluster.PowerState.Code == "Stopped" {
+		st.Message = "cluster stopped"
+	}
+	for _, ap := range cluster.AgentPoolProfiles {
+		np := NodePool{
+			Name:      ap.Name,
+			State:     ap.ProvisioningState,
+			Version:   firstNonEmpty(ap.CurrentOrchestratorVersion, ap.OrchestratorVersion),
+			Upgrading: aksUpgrading(ap.ProvisioningState, ap.CurrentOrchestratorVersion, ap.OrchestratorVersion),
+			Degraded:  ap.ProvisioningState == "Failed" || ap.PowerState.Code == "Stopped",
+		}
+		if ap.PowerState.Code == "Stopped" {
+			np.Message = "agent pool stopped"
+		}
+		st.NodePools = append(st.NodePools, np)
+	}
+	return st, nil
+}
+
+// aksUpgrading tells if an AKS cluster or agent pool is being upgraded: it
+// is Upgrading, or still runs an older version than the one requested
+func aksUpgrading(state, current, target string) bool {
+	return state == "Upgrading" || state == "Updating" || (current != "" && target != "" && current != target)
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// cliTimeout bounds each provider CLI call
+const cliTimeout = 30 * time.Second
+
+// runJSON runs a provider CLI and decodes its JSON output into out
+func runJSON(ctx context.Context, out any, name string, args ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, cliTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Do not wait for children still holding stdout once the CLI is killed
+	cmd.WaitDelay = time.Second
+	command := name + " " + strings.Join(args[:min(len(args), 3)], " ")
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return fmt.Errorf("%s CLI not found in PATH: %w", name, err)
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("%s: %w", command, ctx.Err())
+		}
+		return cmderr.Wrap(command, err, stderr.String())
+	}
+	if err := json.Unmarshal(stdout.Bytes(), out); err != nil {
+		return fmt.Errorf("%s returned invalid JSON: %w", command, err)
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+// Package cmderr reports failures of external commands (plugins, cloud
+// CLIs) with what they printed on stderr.
+package cmderr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Wrap returns the error of command what that exited with err. The last
+// line of stderr is appended: commands print the error there, and the
+// whole output would make the message unreadable.
+func Wrap(what string, err error, stderr string) error {
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return fmt.Errorf("%s failed: %w: %s", what, err, LastLine(msg))
+	}
+	return fmt.Errorf("%s failed: %w", what, err)
+}
+
+// LastLine returns the last line of s
+func LastLine(s string) string {
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
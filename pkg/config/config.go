@@ -0,0 +1,209 @@
+// Package config loads the optional k8s-scanner YAML configuration file.
+// Command-line flags that are set explicitly take precedence over the file.
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/checks"
+	"github.com/ductnn/k8s-scanner/pkg/notify"
+	"github.com/ductnn/k8s-scanner/pkg/report"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/capacity"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/custom"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/pod"
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+)
+
+// Config is the root of the configuration file
+type Config struct {
+	// Profile selects the scanners (see Profiles) like --profile
+	Profile  string   `json:"profile,omitempty"`
+	Capacity Capacity `json:"capacity"`
+	// Overrides tune the pod scanner per namespace or label selector; when
+	// several match a pod they apply in order, later entries winning
+	Overrides []Override `json:"overrides,omitempty"`
+	// CustomResources are scanned through the dynamic client with the rules
+	// declared for each of them
+	CustomResources []custom.Resource `json:"customResources,omitempty"`
+	// Teams attributes issues to teams and sets their issue budgets
+	Teams Teams `json:"teams"`
+	// RootCauses explain more pod reasons (e.g. DeadlineExceeded), or
+	// replace the built-in explanation, keyed by reason or check ID
+	RootCauses map[string]pod.RootCause `json:"rootCauses,omitempty"`
+	// Labels are pod (or namespace) label and annotation keys copied into
+	// issues, e.g. app.kubernetes.io/name
+	Labels []string `json:"labels,omitempty"`
+	// Redact sets how --redact anonymizes reports (default: hash every
+	// name and strip event messages)
+	Redact *report.RedactRules `json:"redact,omitempty"`
+}
+
+// Teams configures the per-team compliance section of reports
+type Teams struct {
+	// Label of pods (or, failing that, their namespace) naming the owning team
+	Label string `json:"label,omitempty"`
+	// Default applies to teams without an entry in Budgets
+	Default report.TeamBudget            `json:"default"`
+	Budgets map[string]report.TeamBudget `json:"budgets,omitempty"`
+	// Notify routes the digests of each team's issues to its own notifiers
+	// (slack=<url>, webhook=<url> or email=<address>) instead of --notify
+	Notify map[string][]string `json:"notify,omitempty"`
+}
+
+// TeamBudgets converts the budgets for scanner.Options
+func (t Teams) TeamBudgets() report.TeamBudgets {
+	return report.TeamBudgets{Default: t.Default, Teams: t.Budgets}
+}
+
+// Override adjusts thresholds and severities for matching pods
+type Override struct {
+	Namespaces       []string          `json:"namespaces,omitempty"`
+	Selector         string            `json:"selector,omitempty"`
+	RestartThreshold int32             `json:"restartThreshold,omitempty"`
+	PendingGrace     string            `json:"pendingGrace,omitempty"`
+	Severity         map[string]string `json:"severity,omitempty"`
+}
+
+// Capacity configures the metrics-server based capacity scanner
+type Capacity struct {
+	Enabled           bool            `json:"enabled"`
+	NodeCPUPercent    float64         `json:"nodeCPUPercent,omitempty"`
+	NodeMemoryPercent float64         `json:"nodeMemoryPercent,omitempty"`
+	UsageRatio        float64         `json:"usageRatio,omitempty"`
+	OverProvisioned   OverProvisioned `json:"overProvisioned"`
+}
+
+// OverProvisioned configures the over-provisioned workload hints
+type OverProvisioned struct {
+	Enabled bool `json:"enabled"`
+	// Ratio of requests to usage above which a workload is reported (default 4)
+	Ratio float64 `json:"ratio,omitempty"`
+	// Optional prices used to estimate the monthly waste
+	CPUCostPerCoreHour   float64 `json:"cpuCostPerCoreHour,omitempty"`
+	MemoryCostPerGiBHour float64 `json:"memoryCostPerGiBHour,omitempty"`
+}
+
+// DefaultOverProvisionRatio is used when overProvisioned.ratio is not set
+const DefaultOverProvisionRatio = 4
+
+// Load reads and validates a configuration file. Unknown fields are rejected
+// so typos do not silently disable a setting.
+func Load(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	var cfg Config
+	if err := yaml.UnmarshalStrict(b, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if _, err := cfg.PodOverrides(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	if _, err := cfg.PodRootCauses(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	if cfg.Profile != "" {
+		if _, err := LookupProfile(cfg.Profile); err != nil {
+			return nil, fmt.Errorf("invalid config %s: %w", path, err)
+		}
+	}
+	if err := custom.Validate(cfg.CustomResources); err != nil {
+		return nil, fmt.Errorf("invalid config %s: customResources: %w", path, err)
+	}
+	if _, err := notify.ParseTeamRoutes(cfg.Teams.Notify); err != nil {
+		return nil, fmt.Errorf("invalid config %s: teams.notify: %w", path, err)
+	}
+	if cfg.Redact != nil {
+		if _, err := report.NewRedactor(*cfg.Redact); err != nil {
+			return nil, fmt.Errorf("invalid config %s: redact: %w", path, err)
+		}
+	}
+	return &cfg, nil
+}
+
+// PodRootCauses converts the root causes for the pod scanner, keyed by reason
+func (c *Config) PodRootCauses() (map[string]pod.RootCause, error) {
+	if len(c.RootCauses) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]pod.RootCause, len(c.RootCauses))
+	for reason, rc := range c.RootCauses {
+		if strings.TrimSpace(rc.RootCause) == "" && strings.TrimSpace(rc.Suggestion) == "" {
+			return nil, fmt.Errorf("rootCauses: %s: rootCause or suggestion is required", reason)
+		}
+		if c, ok := checks.Lookup(reason); ok {
+			reason = c.Reason
+		}
+		out[reason] = rc
+	}
+	return out, nil
+}
+
+// PodOverrides converts the overrides for the pod scanner
+func (c *Config) PodOverrides() ([]pod.Override, error) {
+	var out []pod.Override
+	for i, o := range c.Overrides {
+		po := pod.Override{
+			Namespaces:       o.Namespaces,
+			RestartThreshold: o.RestartThreshold,
+		}
+		if o.Selector != "" {
+			sel, err := labels.Parse(o.Selector)
+			if err != nil {
+				return nil, fmt.Errorf("overrides[%d]: invalid selector %q: %w", i, o.Selector, err)
+			}
+			po.Selector = sel
+		}
+		if o.PendingGrace != "" {
+			d, err := time.ParseDuration(o.PendingGrace)
+			if err != nil {
+				return nil, fmt.Errorf("overrides[%d]: invalid pendingGrace %q: %w", i, o.PendingGrace, err)
+			}
+			po.PendingGrace = d
+		}
+		for reason, sev := range o.Severity {
+			level, err := severity.Parse(sev)
+			if err != nil {
+				return nil, fmt.Errorf("overrides[%d]: %s: %w", i, reason, err)
+			}
+			if po.Severity == nil {
+				po.Severity = make(map[string]severity.Level)
+			}
+			// Check IDs stand for their reason
+			if c, ok := checks.Lookup(reason); ok {
+				reason = c.Reason
+			}
+			po.Severity[reason] = level
+		}
+		out = append(out, po)
+	}
+	return out, nil
+}
+
+// Apply copies the capacity settings present in the file onto opts
+func (c Capacity) Apply(opts *capacity.Options) {
+	if c.NodeCPUPercent > 0 {
+		opts.NodeCPUPercent = c.NodeCPUPercent
+	}
+	if c.NodeMemoryPercent > 0 {
+		opts.NodeMemoryPercent = c.NodeMemoryPercent
+	}
+	if c.UsageRatio > 0 {
+		opts.UsageRatio = c.UsageRatio
+	}
+	if c.OverProvisioned.Enabled {
+		opts.OverProvisionRatio = c.OverProvisioned.Ratio
+		if opts.OverProvisionRatio == 0 {
+			opts.OverProvisionRatio = DefaultOverProvisionRatio
+		}
+		opts.CPUCostPerCoreHour = c.OverProvisioned.CPUCostPerCoreHour
+		opts.MemoryCostPerGiBHour = c.OverProvisioned.MemoryCostPerGiBHour
+	}
+}
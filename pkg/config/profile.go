@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// optionalScanners are the flags enabling the scanners beyond pods
+var optionalScanners = []string{
+	"capacity", "gc", "quota", "priority", "topology", "best-practices", "dns",
+	"control-plane", "webhooks", "gitops", "mesh", "karpenter", "registry-check",
+}
+
+// Profiles bundle the scanner selection of a scan as the values of its
+// command-line flags
+var Profiles = map[string]map[string]string{
+	// quick scans pods only, without events
+	"quick": profile("false", map[string]string{"no-events": "true"}),
+	// standard is the default scan plus the workload checks
+	"standard": profile("false", map[string]string{"no-events": "false", "topology": "true", "best-practices": "true"}),
+	// deep runs every scanner that needs no extra configuration
+	"deep": profile("true", map[string]string{"no-events": "false"}),
+}
+
+func profile(optional string, flags map[string]string) map[string]string {
+	out := make(map[string]string, len(optionalScanners)+len(flags))
+	for _, name := range optionalScanners {
+		out[name] = optional
+	}
+	for name, value := range flags {
+		out[name] = value
+	}
+	return out
+}
+
+// ProfileNames lists the profiles, sorted
+func ProfileNames() []string {
+	names := make([]string, 0, len(Profiles))
+	for name := range Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupProfile returns the flag values of a profile
+func LookupProfile(name string) (map[string]string, error) {
+	p, ok := Profiles[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q (expected %s)", name, strings.Join(ProfileNames(), "|"))
+	}
+	return p, nil
+}
@@ -0,0 +1,225 @@
+package crd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// Group is the API group of the scanner custom resources
+	Group = "scanner.ductnn.io"
+	// Version is the API version of the scanner custom resources
+	Version = "v1alpha1"
+
+	// ReportLabel links a ClusterIssue to the ScanReport it belongs to
+	ReportLabel = Group + "/report"
+)
+
+var (
+	// ScanReportGVR identifies the ScanReport resource
+	ScanReportGVR = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "scanreports"}
+	// ClusterIssueGVR identifies the ClusterIssue resource
+	ClusterIssueGVR = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "clusterissues"}
+)
+
+// Client manages ScanReport and ClusterIssue custom resources
+type Client struct {
+	dyn dynamic.Interface
+}
+
+// NewClient creates a Client on top of a dynamic client
+func NewClient(dyn dynamic.Interface) *Client {
+	return &Client{dyn: dyn}
+}
+
+// WriteReport creates or updates the named ScanReport and replaces its ClusterIssues
+// with the given issues. Issues that are no longer present are deleted.
+func (c *Client) WriteReport(ctx context.Context, name string, cluster string, namespaces []string, issues []types.Issue, summary map[string]types.SeveritySummary) error {
+	report := buildScanReport(name, cluster, namespaces, issues, summary)
+	saved, err := c.upsert(ctx, ScanReportGVR, report)
+	if err != nil {
+		return fmt.Errorf("failed to write ScanReport %s: %w", name, err)
+	}
+
+	owner := metav1.OwnerReference{
+		APIVersion: Group + "/" + Version,
+		Kind:       "ScanReport",
+		Name:       saved.GetName(),
+		UID:        saved.GetUID(),
+	}
+
+	keep := make(map[string]bool, len(issues))
+	for _, issue := range issues {
+		obj := buildClusterIssue(name, issue, owner)
+		keep[obj.GetName()] = true
+		if _, err := c.upsert(ctx, ClusterIssueGVR, obj); err != nil {
+			return fmt.Errorf("failed to write ClusterIssue %s: %w", obj.GetName(), err)
+		}
+	}
+
+	// Remove issues from previous runs that are resolved now
+	existing, err := c.ListIssues(ctx, name)
+	if err != nil {
+		return err
+	}
+	for _, obj := range existing {
+		if keep[obj.GetName()] {
+			continue
+		}
+		err := c.dyn.Resource(ClusterIssueGVR).Delete(ctx, obj.GetName(), metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete ClusterIssue %s: %w", obj.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+// ListReports returns all ScanReport resources
+func (c *Client) ListReports(ctx context.Context) ([]unstructured.Unstructured, error) {
+	list, err := c.dyn.Resource(ScanReportGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ScanReports: %w", err)
+	}
+	return list.Items, nil
+}
+
+// ListIssues returns the ClusterIssue resources belonging to the named ScanReport
+func (c *Client) ListIssues(ctx context.Context, report string) ([]unstructured.Unstructured, error) {
+	list, err := c.dyn.Resource(ClusterIssueGVR).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", ReportLabel, report),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ClusterIssues: %w", err)
+	}
+	return list.Items, nil
+}
+
+// DeleteReport deletes the named ScanReport together with its ClusterIssues
+func (c *Client) DeleteReport(ctx context.Context, name string) error {
+	err := c.dyn.Resource(ClusterIssueGVR).DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", ReportLabel, name),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete ClusterIssues: %w", err)
+	}
+	err = c.dyn.Resource(ScanReportGVR).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete ScanReport %s: %w", name, err)
+	}
+	return nil
+}
+
+// upsert creates the object, or updates it in place if it already exists
+func (c *Client) upsert(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	res := c.dyn.Resource(gvr)
+	current, err := res.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return res.Create(ctx, obj, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+	obj.SetResourceVersion(current.GetResourceVersion())
+	return res.Update(ctx, obj, metav1.UpdateOptions{})
+}
+
+func buildScanReport(name string, cluster string, namespaces []string, issues []types.Issue, summary map[string]types.SeveritySummary) *unstructured.Unstructured {
+	var totals types.SeveritySummary
+	nsSummary := make(map[string]any, len(summary))
+	for ns, s := range summary {
+		totals.Critical += s.Critical
+		totals.High += s.High
+		totals.Medium += s.Medium
+		totals.Low += s.Low
+		totals.Info += s.Info
+		nsSummary[ns] = severityMap(s)
+	}
+
+	nsList := make([]any, 0, len(namespaces))
+	for _, ns := range namespaces {
+		nsList = append(nsList, ns)
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"cluster":    cluster,
+			"namespaces": nsList,
+		},
+		"status": map[string]any{
+			"generatedAt": time.Now().UTC().Format(time.RFC3339),
+			"issueCount":  int64(len(issues)),
+			"totals":      severityMap(totals),
+			"summary":     nsSummary,
+		},
+	}}
+	obj.SetAPIVersion(Group + "/" + Version)
+	obj.SetKind("ScanReport")
+	obj.SetName(name)
+	return obj
+}
+
+func buildClusterIssue(report string, issue types.Issue, owner metav1.OwnerReference) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"id":            issue.ID,
+			"kind":          issue.Kind,
+			"namespace":     issue.Namespace,
+			"name":          issue.Name,
+			"container":     issue.Container,
+			"severity":      issue.Severity,
+			"reason":        issue.Reason,
+			"rootCause":     issue.RootCause,
+			"suggestion":    issue.Suggestion,
+			"podStatus":     issue.PodStatus,
+			"nodeName":      issue.NodeName,
+			"nodeCondition": issue.NodeCondition,
+			"priorityClass": issue.PriorityClass,
+			"restartCount":  int64(issue.RestartCount),
+			"lastEvent":     issue.LastEvent,
+			"detectedAt":    issue.Timestamp,
+		},
+	}}
+	if len(issue.ImpactedServices) > 0 {
+		// Unstructured content only holds []any, not []string
+		services := make([]any, len(issue.ImpactedServices))
+		for i, s := range issue.ImpactedServices {
+			services[i] = s
+		}
+		obj.Object["spec"].(map[string]any)["impactedServices"] = services
+	}
+	obj.SetAPIVersion(Group + "/" + Version)
+	obj.SetKind("ClusterIssue")
+	obj.SetName(issueObjectName(report, issue))
+	obj.SetLabels(map[string]string{ReportLabel: report})
+	obj.SetOwnerReferences([]metav1.OwnerReference{owner})
+	return obj
+}
+
+// issueObjectName derives a stable, DNS-compatible object name for an issue
+func issueObjectName(report string, issue types.Issue) string {
+	id := issue.ID
+	if id == "" {
+		id = types.Fingerprint("", issue)
+	}
+	return fmt.Sprintf("%s-%s", report, id)
+}
+
+func severityMap(s types.SeveritySummary) map[string]any {
+	return map[string]any{
+		"critical": int64(s.Critical),
+		"high":     int64(s.High),
+		"medium":   int64(s.Medium),
+		"low":      int64(s.Low),
+		"info":     int64(s.Info),
+	}
+}
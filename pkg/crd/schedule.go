@@ -0,0 +1,117 @@
+package crd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ScanScheduleGVR identifies the ScanSchedule resource
+var ScanScheduleGVR = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "scanschedules"}
+
+// ScanSchedule declares a recurring scan reconciled by the operator
+type ScanSchedule struct {
+	Name   string
+	Spec   ScanScheduleSpec
+	Status ScanScheduleStatus
+	// Err is set when the spec cannot be decoded; the schedule is not run
+	Err error
+}
+
+// ScanScheduleSpec is the desired scan configuration. Durations are Go
+// durations (e.g. 10m); empty ones keep the defaults of the scan command.
+type ScanScheduleSpec struct {
+	Namespaces        []string `json:"namespaces,omitempty"`
+	IgnoreNamespaces  []string `json:"ignoreNamespaces,omitempty"`
+	Interval          string   `json:"interval,omitempty"`
+	RestartThreshold  int32    `json:"restartThreshold,omitempty"`
+	EscalateAfter     string   `json:"escalateAfter,omitempty"`
+	PendingGrace      string   `json:"pendingGrace,omitempty"`
+	StartupGrace      string   `json:"startupGrace,omitempty"`
+	TerminatingMargin string   `json:"terminatingMargin,omitempty"`
+	UnreadyAfter      string   `json:"unreadyAfter,omitempty"`
+	EventMaxAge       string   `json:"eventMaxAge,omitempty"`
+	ScannerTimeout    string   `json:"scannerTimeout,omitempty"`
+	OnlyReasons       []string `json:"onlyReasons,omitempty"`
+	IgnoreReasons     []string `json:"ignoreReasons,omitempty"`
+	NoEvents          bool     `json:"noEvents,omitempty"`
+	Export            []string `json:"export,omitempty"`
+	// Outdir is relative to the operator's reports directory (default: the
+	// schedule name)
+	Outdir     string `json:"outdir,omitempty"`
+	ReportName string `json:"reportName,omitempty"`
+	Suspend    bool   `json:"suspend,omitempty"`
+}
+
+// ScanScheduleStatus records the outcome of the last scan
+type ScanScheduleStatus struct {
+	LastScanTime   string `json:"lastScanTime,omitempty"`
+	LastIssueCount int64  `json:"lastIssueCount,omitempty"`
+	LastError      string `json:"lastError,omitempty"`
+}
+
+// IntervalDuration parses the schedule interval, defaulting to one hour
+func (s ScanScheduleSpec) IntervalDuration() (time.Duration, error) {
+	if s.Interval == "" {
+		return time.Hour, nil
+	}
+	d, err := time.ParseDuration(s.Interval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval %q: %w", s.Interval, err)
+	}
+	if d < time.Minute {
+		return 0, fmt.Errorf("interval %q is shorter than 1m", s.Interval)
+	}
+	return d, nil
+}
+
+// ListSchedules returns all ScanSchedule resources. A schedule whose spec
+// cannot be decoded is returned with Err set rather than failing the list.
+func (c *Client) ListSchedules(ctx context.Context) ([]ScanSchedule, error) {
+	list, err := c.dyn.Resource(ScanScheduleGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ScanSchedules: %w", err)
+	}
+
+	schedules := make([]ScanSchedule, 0, len(list.Items))
+	for _, item := range list.Items {
+		sched := ScanSchedule{Name: item.GetName()}
+		if spec, ok := item.Object["spec"].(map[string]any); ok {
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(spec, &sched.Spec); err != nil {
+				sched.Err = fmt.Errorf("invalid spec: %w", err)
+			}
+		}
+		if status, ok := item.Object["status"].(map[string]any); ok {
+			_ = runtime.DefaultUnstructuredConverter.FromUnstructured(status, &sched.Status)
+		}
+		schedules = append(schedules, sched)
+	}
+	return schedules, nil
+}
+
+// UpdateScheduleStatus writes the status of the named ScanSchedule
+func (c *Client) UpdateScheduleStatus(ctx context.Context, name string, status ScanScheduleStatus) error {
+	res := c.dyn.Resource(ScanScheduleGVR)
+	obj, err := res.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get ScanSchedule %s: %w", name, err)
+	}
+
+	statusMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+	if err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedMap(obj.Object, statusMap, "status"); err != nil {
+		return err
+	}
+
+	if _, err := res.Update(ctx, obj, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update ScanSchedule %s: %w", name, err)
+	}
+	return nil
+}
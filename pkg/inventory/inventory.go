@@ -0,0 +1,293 @@
+// Package inventory lists the scanned workloads, their images and namespaces,
+// healthy or not, as a CycloneDX bill of materials for security teams
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/registry"
+	"github.com/ductnn/k8s-scanner/pkg/version"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
+)
+
+// SpecVersion is the CycloneDX version of the exported BOM
+const SpecVersion = "1.5"
+
+// BOM is a CycloneDX bill of materials. Only the fields the inventory uses
+// are modeled.
+type BOM struct {
+	BOMFormat    string       `json:"bomFormat"`
+	SpecVersion  string       `json:"specVersion"`
+	SerialNumber string       `json:"serialNumber"`
+	Version      int          `json:"version"`
+	Metadata     Metadata     `json:"metadata"`
+	Components   []Component  `json:"components"`
+	Dependencies []Dependency `json:"dependencies"`
+}
+
+// Metadata describes the cluster the BOM was taken from
+type Metadata struct {
+	Timestamp string `json:"timestamp"`
+	Tools     struct {
+		Components []Component `json:"components"`
+	} `json:"tools"`
+	Component *Component `json:"component,omitempty"`
+	// Properties lists the scanned namespaces as k8s:namespace
+	Properties []Property `json:"properties,omitempty"`
+}
+
+// Component is a workload ("application") or an image ("container")
+type Component struct {
+	Type    string `json:"type"`
+	BOMRef  string `json:"bom-ref,omitempty"`
+	Group   string `json:"group,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	// PURL is the package URL of an image (pkg:oci/...)
+	PURL       string     `json:"purl,omitempty"`
+	Hashes     []Hash     `json:"hashes,omitempty"`
+	Properties []Property `json:"properties,omitempty"`
+}
+
+// Hash is a digest of a component
+type Hash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+// Property is a name/value annotation; names may repeat
+type Property struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Dependency records the images a workload runs
+type Dependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// Fetch lists the pods and namespaces in scope and builds the inventory.
+// Namespaces are best effort: without list access only the namespaces
+// running pods are recorded.
+func Fetch(ctx context.Context, client kubernetes.Interface, cluster string, namespaces []string, ignored map[string]bool) (*BOM, error) {
+	scopes := namespaces
+	if len(scopes) == 0 {
+		scopes = []string{metav1.NamespaceAll}
+	}
+	var pods []v1.Pod
+	for _, ns := range scopes {
+		list, err := client.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods: %w", err)
+		}
+		pods = append(pods, list.Items...)
+	}
+
+	names := namespaces
+	if len(namespaces) == 0 {
+		if list, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{}); err == nil {
+			for _, ns := range list.Items {
+				names = append(names, ns.Name)
+			}
+		}
+	}
+	return Build(cluster, pods, names, ignored, time.Now()), nil
+}
+
+// workload is a top-level owner of pods
+type workload struct {
+	kind, namespace, name string
+	pods                  int
+	images                map[string]bool
+}
+
+// Build creates the inventory of pods. namespaces lists namespaces to record
+// even when they run no pods; those in ignored are skipped.
+func Build(cluster string, pods []v1.Pod, namespaces []string, ignored map[string]bool, now time.Time) *BOM {
+	bom := &BOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  SpecVersion,
+		SerialNumber: "urn:uuid:" + string(uuid.NewUUID()),
+		Version:      1,
+		Metadata:     Metadata{Timestamp: now.UTC().Format(time.RFC3339)},
+		Components:   []Component{},
+		Dependencies: []Dependency{},
+	}
+	bom.Metadata.Tools.Components = []Component{{Type: "application", Name: "k8s-scanner", Version: version.Version}}
+	if cluster != "" {
+		bom.Metadata.Component = &Component{Type: "platform", Name: cluster}
+	}
+
+	nsSet := map[string]bool{}
+	for _, ns := range namespaces {
+		if !ignored[ns] {
+			nsSet[ns] = true
+		}
+	}
+	workloads := map[string]*workload{}
+	images := map[string]Component{}
+	for _, p := range pods {
+		if ignored[p.Namespace] {
+			continue
+		}
+		nsSet[p.Namespace] = true
+		kind, name := ownerOf(p)
+		ref := "workload:" + p.Namespace + "/" + kind + "/" + name
+		w := workloads[ref]
+		if w == nil {
+			w = &workload{kind: kind, namespace: p.Namespace, name: name, images: map[string]bool{}}
+			workloads[ref] = w
+		}
+		w.pods++
+
+		digests := imageDigests(p)
+		for _, c := range append(append([]v1.Container{}, p.Spec.InitContainers...), p.Spec.Containers...) {
+			img := imageComponent(c.Image, digests[c.Name])
+			if _, ok := images[img.BOMRef]; !ok {
+				images[img.BOMRef] = img
+			}
+			w.images[img.BOMRef] = true
+		}
+	}
+
+	for _, ns := range sortedKeys(nsSet) {
+		bom.Metadata.Properties = append(bom.Metadata.Properties, Property{Name: "k8s:namespace", Value: ns})
+	}
+	for _, ref := range sortedKeys(workloads) {
+		w := workloads[ref]
+		bom.Components = append(bom.Components, Component{
+			Type:   "application",
+			BOMRef: ref,
+			Group:  w.namespace,
+			Name:   w.name,
+			Properties: []Property{
+				{Name: "k8s:kind", Value: w.kind},
+				{Name: "k8s:namespace", Value: w.namespace},
+				{Name: "k8s:pods", Value: strconv.Itoa(w.pods)},
+			},
+		})
+		bom.Dependencies = append(bom.Dependencies, Dependency{Ref: ref, DependsOn: sortedKeys(w.images)})
+	}
+	for _, ref := range sortedKeys(images) {
+		bom.Components = append(bom.Components, images[ref])
+	}
+	return bom
+}
+
+// ownerOf resolves the top-level workload of a pod. ReplicaSets created by a
+// Deployment are reported as the Deployment.
+func ownerOf(p v1.Pod) (kind, name string) {
+	for _, ref := range p.OwnerReferences {
+		if ref.Controller == nil || !*ref.Controller {
+			continue
+		}
+		if ref.Kind == "ReplicaSet" {
+			if hash := p.Labels["pod-template-hash"]; hash != "" && strings.HasSuffix(ref.Name, "-"+hash) {
+				return "Deployment", strings.TrimSuffix(ref.Name, "-"+hash)
+			}
+		}
+		return ref.Kind, ref.Name
+	}
+	return "Pod", p.Name
+}
+
+// imageDigests maps container names to the digest of the image they run, as
+// resolved by the kubelet (e.g. docker.io/library/nginx@sha256:...)
+func imageDigests(p v1.Pod) map[string]string {
+	out := map[string]string{}
+	for _, statuses := range [][]v1.ContainerStatus{p.Status.InitContainerStatuses, p.Status.ContainerStatuses} {
+		for _, cs := range statuses {
+			if _, digest, ok := strings.Cut(cs.ImageID, "@"); ok {
+				out[cs.Name] = digest
+			}
+		}
+	}
+	return out
+}
+
+// imageComponent describes an image. Images are identified by digest when
+// one is known, so the same tag pulled at different times is listed twice.
+func imageComponent(image, digest string) Component {
+	ref, err := registry.ParseReference(image)
+	if err != nil {
+		return Component{Type: "container", BOMRef: "image:" + image, Name: image}
+	}
+	if digest == "" {
+		digest = ref.Digest
+	}
+	repo := ref.Registry + "/" + ref.Repository
+	c := Component{
+		Type:    "container",
+		BOMRef:  "image:" + repo + ":" + ref.Tag,
+		Name:    repo,
+		Version: ref.Tag,
+	}
+
+	// pkg:oci/<name>@<digest>?repository_url=<repo>&tag=<tag>
+	name := ref.Repository[strings.LastIndex(ref.Repository, "/")+1:]
+	purl := "pkg:oci/" + strings.ToLower(name)
+	q := url.Values{"repository_url": {repo}}
+	if ref.Tag != "" {
+		q.Set("tag", ref.Tag)
+	}
+	if digest != "" {
+		c.BOMRef = "image:" + repo + "@" + digest
+		purl += "@" + url.PathEscape(digest)
+		if alg, content, ok := strings.Cut(digest, ":"); ok && alg == "sha256" {
+			c.Hashes = []Hash{{Alg: "SHA-256", Content: content}}
+		}
+	}
+	c.PURL = purl + "?" + q.Encode()
+	return c
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Save writes the BOM as indented JSON
+func Save(path string, bom *BOM) error {
+	var buf bytes.Buffer
+	if err := Write(&buf, bom); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write inventory: %w", err)
+	}
+	return nil
+}
+
+// Write encodes the BOM as indented JSON to w
+func Write(w io.Writer, bom *BOM) error {
+	// Package URLs are kept readable (no \u0026)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(bom); err != nil {
+		return fmt.Errorf("failed to encode inventory: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package k8s
+
+import (
+	"context"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Permission is an API permission a scanner needs
+type Permission struct {
+	Scanner       string
+	Verb          string
+	Group         string
+	Resource      string
+	ClusterScoped bool
+	// Required permissions abort the scan when missing; the others only
+	// disable the scanner that needs them
+	Required bool
+}
+
+// Permissions lists what each scanner needs
+var Permissions = []Permission{
+	{Scanner: "pods", Verb: "list", Resource: "pods", Required: true},
+	{Scanner: "events", Verb: "list", Resource: "events"},
+	{Scanner: "nodes", Verb: "list", Resource: "nodes", ClusterScoped: true},
+	{Scanner: "services", Verb: "list", Resource: "services"},
+	{Scanner: "capacity", Verb: "list", Group: "metrics.k8s.io", Resource: "nodes", ClusterScoped: true},
+	{Scanner: "capacity", Verb: "list", Group: "metrics.k8s.io", Resource: "pods"},
+	{Scanner: "gc", Verb: "list", Group: "apps", Resource: "deployments"},
+	{Scanner: "gc", Verb: "list", Group: "apps", Resource: "replicasets"},
+	{Scanner: "gc", Verb: "list", Group: "apps", Resource: "statefulsets"},
+	{Scanner: "gc", Verb: "list", Group: "apps", Resource: "daemonsets"},
+	{Scanner: "gc", Verb: "list", Group: "batch", Resource: "jobs"},
+	{Scanner: "gc", Verb: "list", Group: "batch", Resource: "cronjobs"},
+	{Scanner: "gc", Verb: "list", Resource: "configmaps"},
+	{Scanner: "gc", Verb: "list", Resource: "secrets"},
+	{Scanner: "gc", Verb: "list", Resource: "services"},
+	{Scanner: "gc", Verb: "list", Resource: "endpoints"},
+	{Scanner: "gc", Verb: "list", Resource: "serviceaccounts"},
+	{Scanner: "gc", Verb: "list", Group: "networking.k8s.io", Resource: "ingresses"},
+	{Scanner: "quota", Verb: "list", Resource: "resourcequotas"},
+	{Scanner: "quota", Verb: "list", Resource: "events"},
+	{Scanner: "topology", Verb: "list", Group: "apps", Resource: "deployments"},
+	{Scanner: "topology", Verb: "list", Group: "apps", Resource: "statefulsets"},
+	{Scanner: "best-practices", Verb: "list", Group: "apps", Resource: "deployments"},
+	{Scanner: "best-practices", Verb: "list", Group: "apps", Resource: "statefulsets"},
+	{Scanner: "best-practices", Verb: "list", Group: "apps", Resource: "daemonsets"},
+	{Scanner: "webhooks", Verb: "list", Group: "admissionregistration.k8s.io", Resource: "validatingwebhookconfigurations", ClusterScoped: true},
+	{Scanner: "webhooks", Verb: "list", Group: "admissionregistration.k8s.io", Resource: "mutatingwebhookconfigurations", ClusterScoped: true},
+	{Scanner: "karpenter", Verb: "list", Group: "karpenter.sh", Resource: "nodeclaims", ClusterScoped: true},
+	{Scanner: "karpenter", Verb: "list", Group: "karpenter.sh", Resource: "nodepools", ClusterScoped: true},
+	{Scanner: "drain", Verb: "list", Group: "policy", Resource: "poddisruptionbudgets"},
+	{Scanner: "clean", Verb: "delete", Resource: "pods"},
+}
+
+// AccessResult is the outcome of a SelfSubjectAccessReview for one permission
+type AccessResult struct {
+	Permission
+	Namespace string
+	Allowed   bool
+	Reason    string
+}
+
+// CheckAccess asks the API server, through SelfSubjectAccessReviews, whether
+// the current credentials hold the permissions of the given scanners (all
+// scanners when none are given) in each namespace (cluster-wide when empty)
+func CheckAccess(ctx context.Context, client kubernetes.Interface, namespaces []string, scanners ...string) []AccessResult {
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+	wanted := make(map[string]bool, len(scanners))
+	for _, s := range scanners {
+		wanted[s] = true
+	}
+
+	var results []AccessResult
+	for _, p := range Permissions {
+		if len(wanted) > 0 && !wanted[p.Scanner] {
+			continue
+		}
+		nsList := namespaces
+		if p.ClusterScoped {
+			nsList = []string{""}
+		}
+		for _, ns := range nsList {
+			results = append(results, review(ctx, client, p, ns))
+		}
+	}
+	return results
+}
+
+// Allowed reports whether every check of the scanner passed
+func Allowed(results []AccessResult, scanner string) bool {
+	for _, r := range results {
+		if r.Scanner == scanner && !r.Allowed {
+			return false
+		}
+	}
+	return true
+}
+
+func review(ctx context.Context, client kubernetes.Interface, p Permission, namespace string) AccessResult {
+	res := AccessResult{Permission: p, Namespace: namespace}
+	ssar := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      p.Verb,
+				Group:     p.Group,
+				Resource:  p.Resource,
+			},
+		},
+	}
+	out, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, ssar, metav1.CreateOptions{})
+	if err != nil {
+		res.Reason = err.Error()
+		return res
+	}
+	res.Allowed = out.Status.Allowed
+	res.Reason = out.Status.Reason
+	if out.Status.EvaluationError != "" {
+		res.Reason = out.Status.EvaluationError
+	}
+	return res
+}
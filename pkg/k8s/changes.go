@@ -0,0 +1,181 @@
+package k8s
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ChangeWatcher watches pods and pod events and tracks the latest
+// resourceVersion seen in each namespace, so periodic scans can re-evaluate
+// only the namespaces that changed since the previous cycle
+type ChangeWatcher struct {
+	client     kubernetes.Interface
+	namespaces []string
+
+	mu      sync.Mutex
+	seen    map[string]string // namespace -> resourceVersion of its latest change
+	scanned map[string]string // namespace -> resourceVersion at the last Changes call
+	lost    bool              // changes may have been missed; a full rescan is needed
+}
+
+// watchRetryDelay is how long to wait before re-opening a failed watch
+const watchRetryDelay = 5 * time.Second
+
+// NewChangeWatcher creates a watcher for the given namespaces (empty means
+// all namespaces)
+func NewChangeWatcher(client kubernetes.Interface, namespaces []string) *ChangeWatcher {
+	return &ChangeWatcher{
+		client:     client,
+		namespaces: namespaces,
+		seen:       make(map[string]string),
+		scanned:    make(map[string]string),
+	}
+}
+
+// Start opens the watches and keeps them running until ctx is cancelled.
+// It returns once the starting resourceVersions are known, so a scan run
+// afterwards cannot miss changes.
+func (w *ChangeWatcher) Start(ctx context.Context) error {
+	namespaces := w.namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	type source struct {
+		list  func(context.Context, metav1.ListOptions) (string, error)
+		watch func(context.Context, metav1.ListOptions) (watch.Interface, error)
+	}
+	var sources []source
+	for _, ns := range namespaces {
+		pods := w.client.CoreV1().Pods(ns)
+		events := w.client.CoreV1().Events(ns)
+		sources = append(sources,
+			source{
+				list: func(ctx context.Context, opts metav1.ListOptions) (string, error) {
+					l, err := pods.List(ctx, opts)
+					if err != nil {
+						return "", err
+					}
+					return l.ResourceVersion, nil
+				},
+				watch: pods.Watch,
+			},
+			source{
+				list: func(ctx context.Context, opts metav1.ListOptions) (string, error) {
+					opts.FieldSelector = "involvedObject.kind=Pod"
+					l, err := events.List(ctx, opts)
+					if err != nil {
+						return "", err
+					}
+					return l.ResourceVersion, nil
+				},
+				watch: func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+					opts.FieldSelector = "involvedObject.kind=Pod"
+					return events.Watch(ctx, opts)
+				},
+			},
+		)
+	}
+
+	rvs := make([]string, len(sources))
+	for i, src := range sources {
+		rv, err := src.list(ctx, metav1.ListOptions{Limit: 1})
+		if err != nil {
+			return err
+		}
+		rvs[i] = rv
+	}
+	for i, src := range sources {
+		go w.run(ctx, rvs[i], src.list, src.watch)
+	}
+	return nil
+}
+
+// run keeps one watch open, resuming from the last resourceVersion seen.
+// When the watch cannot be resumed some changes may have been lost, so the
+// next Changes call asks for a full rescan.
+func (w *ChangeWatcher) run(ctx context.Context, rv string,
+	list func(context.Context, metav1.ListOptions) (string, error),
+	open func(context.Context, metav1.ListOptions) (watch.Interface, error)) {
+	for ctx.Err() == nil {
+		if rv == "" {
+			w.markLost()
+			var err error
+			if rv, err = list(ctx, metav1.ListOptions{Limit: 1}); err != nil {
+				sleep(ctx, watchRetryDelay)
+				continue
+			}
+		}
+
+		wi, err := open(ctx, metav1.ListOptions{ResourceVersion: rv, AllowWatchBookmarks: true})
+		if err != nil {
+			if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+				rv = ""
+			}
+			sleep(ctx, watchRetryDelay)
+			continue
+		}
+		for ev := range wi.ResultChan() {
+			if ev.Type == watch.Error {
+				// Usually "too old resource version": relist
+				rv = ""
+				break
+			}
+			obj, err := meta.Accessor(ev.Object)
+			if err != nil {
+				continue
+			}
+			rv = obj.GetResourceVersion()
+			if ev.Type != watch.Bookmark {
+				w.record(obj.GetNamespace(), rv)
+			}
+		}
+		wi.Stop()
+	}
+}
+
+func (w *ChangeWatcher) record(namespace, rv string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.seen[namespace] = rv
+}
+
+func (w *ChangeWatcher) markLost() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lost = true
+}
+
+// Changes returns the namespaces whose resourceVersion moved since the
+// previous call, and whether changes may have been missed (in which case
+// every namespace should be rescanned)
+func (w *ChangeWatcher) Changes() (namespaces []string, all bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for ns, rv := range w.seen {
+		if w.scanned[ns] != rv {
+			namespaces = append(namespaces, ns)
+			w.scanned[ns] = rv
+		}
+	}
+	all, w.lost = w.lost, false
+	sort.Strings(namespaces)
+	return namespaces, all
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -39,30 +40,47 @@ func GetCurrentContext(kubeconfigPath string) (string, error) {
 	return context.Cluster, nil
 }
 
-// NewK8sClient creates a Kubernetes client with the following priority:
-// 1. kubeconfigPath parameter (if provided)
-// 2. KUBECONFIG environment variable
-// 3. Default ~/.kube/config (or %USERPROFILE%\.kube\config on Windows)
-func NewK8sClient(kubeconfigPath string) (*kubernetes.Clientset, error) {
+// NewRestConfig builds a REST config with the following priority:
+// 1. In-cluster config (when running inside a pod)
+// 2. kubeconfigPath parameter (if provided)
+// 3. KUBECONFIG environment variable
+// 4. Default ~/.kube/config (or %USERPROFILE%\.kube\config on Windows)
+func NewRestConfig(kubeconfigPath string) (*rest.Config, error) {
 	// Detect running inside or outside cluster
 	config, err := rest.InClusterConfig()
-	if err != nil {
-		// Running locally → use kubeconfig
-		var kubeconfig string
+	if err == nil {
+		return config, nil
+	}
+
+	// Running locally → use kubeconfig
+	var kubeconfig string
+
+	// Priority: flag > env var > default
+	if kubeconfigPath != "" {
+		kubeconfig = kubeconfigPath
+	} else if kubeconfig = os.Getenv("KUBECONFIG"); kubeconfig == "" {
+		// Default to ~/.kube/config (works on Windows, Linux, macOS)
+		home, _ := os.UserHomeDir()
+		kubeconfig = filepath.Join(home, ".kube", "config")
+	}
 
-		// Priority: flag > env var > default
-		if kubeconfigPath != "" {
-			kubeconfig = kubeconfigPath
-		} else if kubeconfig = os.Getenv("KUBECONFIG"); kubeconfig == "" {
-			// Default to ~/.kube/config (works on Windows, Linux, macOS)
-			home, _ := os.UserHomeDir()
-			kubeconfig = filepath.Join(home, ".kube", "config")
-		}
+	return clientcmd.BuildConfigFromFlags("", kubeconfig)
+}
 
-		config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
-		if err != nil {
-			return nil, err
-		}
+// NewK8sClient creates a Kubernetes client using NewRestConfig
+func NewK8sClient(kubeconfigPath string) (*kubernetes.Clientset, error) {
+	config, err := NewRestConfig(kubeconfigPath)
+	if err != nil {
+		return nil, err
 	}
 	return kubernetes.NewForConfig(config)
 }
+
+// NewDynamicClient creates a dynamic client used for custom resources
+func NewDynamicClient(kubeconfigPath string) (dynamic.Interface, error) {
+	config, err := NewRestConfig(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+	return dynamic.NewForConfig(config)
+}
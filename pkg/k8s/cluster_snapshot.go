@@ -0,0 +1,324 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ductnn/k8s-scanner/pkg/tracing"
+
+	v1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ClusterSnapshot caches the objects several scanners need (pods, events,
+// nodes, services and namespaces) so each is listed at most once per scan. Objects are
+// fetched lazily on first use; a ClusterSnapshot is safe for concurrent use.
+type ClusterSnapshot struct {
+	client     kubernetes.Interface
+	namespaces []string
+
+	mu         sync.Mutex
+	pods       *cached[[]v1.Pod]
+	nodes      *cached[[]v1.Node]
+	services   *cached[[]v1.Service]
+	nsList     *cached[[]v1.Namespace]
+	events     map[string]*cached[[]v1.Event]
+	eventsV1   *bool
+	podListErr map[string]error
+}
+
+// cached is the memoized result of a List call
+type cached[T any] struct {
+	once  sync.Once
+	items T
+	err   error
+}
+
+func (c *cached[T]) get(fetch func() (T, error)) (T, error) {
+	c.once.Do(func() { c.items, c.err = fetch() })
+	return c.items, c.err
+}
+
+// NewClusterSnapshot returns an empty cache for the given namespaces (all
+// namespaces when empty)
+func NewClusterSnapshot(client kubernetes.Interface, namespaces []string) *ClusterSnapshot {
+	var scoped []string
+	for _, ns := range namespaces {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			scoped = append(scoped, ns)
+		}
+	}
+	return &ClusterSnapshot{
+		client:     client,
+		namespaces: scoped,
+		pods:       &cached[[]v1.Pod]{},
+		nodes:      &cached[[]v1.Node]{},
+		services:   &cached[[]v1.Service]{},
+		nsList:     &cached[[]v1.Namespace]{},
+		events:     make(map[string]*cached[[]v1.Event]),
+	}
+}
+
+// Client is the API client the snapshot reads from, for scanners that need
+// objects the snapshot does not cache
+func (s *ClusterSnapshot) Client() kubernetes.Interface {
+	return s.client
+}
+
+// ScopedNamespaces returns the namespaces the snapshot was created for
+// (empty means all namespaces)
+func (s *ClusterSnapshot) ScopedNamespaces() []string {
+	return s.namespaces
+}
+
+// Pods returns the pods of the scoped namespaces. When scanning a list of
+// namespaces, the ones whose pods cannot be listed are skipped and returned
+// as the second value; the error is only set when a cluster-wide List fails.
+func (s *ClusterSnapshot) Pods(ctx context.Context) ([]v1.Pod, []error, error) {
+	pods, err := s.pods.get(func() ([]v1.Pod, error) {
+		ctx, span := tracing.Start(ctx, "list pods")
+		defer span.End()
+		span.SetAttr("k8s.namespaces", len(s.namespaces))
+
+		s.podListErr = make(map[string]error)
+		if len(s.namespaces) == 0 {
+			list, err := s.client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				span.RecordError(err)
+				return nil, err
+			}
+			span.SetAttr("k8s.pods", len(list.Items))
+			return list.Items, nil
+		}
+		var pods []v1.Pod
+		for _, ns := range s.namespaces {
+			list, err := s.client.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				s.podListErr[ns] = fmt.Errorf("failed to list pods in %s: %w", ns, err)
+				span.RecordError(s.podListErr[ns])
+				continue
+			}
+			pods = append(pods, list.Items...)
+		}
+		span.SetAttr("k8s.pods", len(pods))
+		return pods, nil
+	})
+	var listErrs []error
+	for _, ns := range s.namespaces {
+		if e := s.podListErr[ns]; e != nil {
+			listErrs = append(listErrs, e)
+		}
+	}
+	return pods, listErrs, err
+}
+
+// PodsIn returns the cached pods of one namespace ("" for all). Unlike Pods
+// it fails when some of the requested pods could not be listed, for callers
+// that must not act on partial data.
+func (s *ClusterSnapshot) PodsIn(ctx context.Context, namespace string) ([]v1.Pod, error) {
+	pods, listErrs, err := s.Pods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if namespace == "" {
+		if len(listErrs) > 0 {
+			return nil, listErrs[0]
+		}
+		return pods, nil
+	}
+	if err := s.podListErr[namespace]; err != nil {
+		return nil, err
+	}
+	var out []v1.Pod
+	for _, p := range pods {
+		if p.Namespace == namespace {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+// Nodes returns all nodes of the cluster
+func (s *ClusterSnapshot) Nodes(ctx context.Context) ([]v1.Node, error) {
+	return s.nodes.get(func() ([]v1.Node, error) {
+		ctx, span := tracing.Start(ctx, "list nodes")
+		defer span.End()
+		list, err := s.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		span.SetAttr("k8s.nodes", len(list.Items))
+		return list.Items, nil
+	})
+}
+
+// Services returns the Services of the scoped namespaces. Namespaces whose
+// Services cannot be listed are skipped; the error is only set when a
+// cluster-wide List fails.
+func (s *ClusterSnapshot) Services(ctx context.Context) ([]v1.Service, error) {
+	return s.services.get(func() ([]v1.Service, error) {
+		ctx, span := tracing.Start(ctx, "list services")
+		defer span.End()
+		if len(s.namespaces) == 0 {
+			list, err := s.client.CoreV1().Services("").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				span.RecordError(err)
+				return nil, err
+			}
+			return list.Items, nil
+		}
+		var services []v1.Service
+		for _, ns := range s.namespaces {
+			list, err := s.client.CoreV1().Services(ns).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				span.RecordError(err)
+				continue
+			}
+			services = append(services, list.Items...)
+		}
+		return services, nil
+	})
+}
+
+// NamespaceObjects returns all namespaces of the cluster
+func (s *ClusterSnapshot) NamespaceObjects(ctx context.Context) ([]v1.Namespace, error) {
+	return s.nsList.get(func() ([]v1.Namespace, error) {
+		list, err := s.client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
+}
+
+// eventPageSize is the number of events requested per List call
+const eventPageSize = 500
+
+// PodEvents returns the events about pods in a namespace, paging through
+// them with a field selector. events.k8s.io/v1 is used when the server
+// serves it and the events are converted to core/v1, keeping their series
+// counts; older servers (or tokens without access to that group) fall back
+// to core/v1 events.
+func (s *ClusterSnapshot) PodEvents(ctx context.Context, namespace string) ([]v1.Event, error) {
+	s.mu.Lock()
+	entry, ok := s.events[namespace]
+	if !ok {
+		entry = &cached[[]v1.Event]{}
+		s.events[namespace] = entry
+	}
+	if s.eventsV1 == nil {
+		available := eventsV1Available(s.client)
+		s.eventsV1 = &available
+	}
+	useV1 := *s.eventsV1
+	s.mu.Unlock()
+
+	return entry.get(func() ([]v1.Event, error) {
+		ctx, span := tracing.Start(ctx, "list events")
+		defer span.End()
+		span.SetAttr("k8s.namespace", namespace)
+
+		events, err := func() ([]v1.Event, error) {
+			if useV1 {
+				span.SetAttr("k8s.api", "events.k8s.io/v1")
+				events, err := listEventsV1(ctx, s.client, namespace)
+				if !apierrors.IsForbidden(err) && !apierrors.IsNotFound(err) {
+					return events, err
+				}
+			}
+			span.SetAttr("k8s.api", "v1")
+			return listEvents(ctx, s.client, namespace)
+		}()
+		span.SetAttr("k8s.events", len(events))
+		span.RecordError(err)
+		return events, err
+	})
+}
+
+// eventsV1Available reports whether the API server serves events.k8s.io/v1
+// (Kubernetes 1.19+), which carries deduplicated event series
+func eventsV1Available(client kubernetes.Interface) bool {
+	resources, err := client.Discovery().ServerResourcesForGroupVersion(eventsv1.SchemeGroupVersion.String())
+	if err != nil {
+		return false
+	}
+	for _, r := range resources.APIResources {
+		if r.Name == "events" {
+			return true
+		}
+	}
+	return false
+}
+
+func listEvents(ctx context.Context, client kubernetes.Interface, namespace string) ([]v1.Event, error) {
+	var events []v1.Event
+	listOpts := metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=Pod",
+		Limit:         eventPageSize,
+	}
+	for {
+		list, err := client.CoreV1().Events(namespace).List(ctx, listOpts)
+		if err != nil {
+			return events, err
+		}
+		events = append(events, list.Items...)
+		if list.Continue == "" {
+			return events, nil
+		}
+		listOpts.Continue = list.Continue
+	}
+}
+
+func listEventsV1(ctx context.Context, client kubernetes.Interface, namespace string) ([]v1.Event, error) {
+	var events []v1.Event
+	listOpts := metav1.ListOptions{
+		FieldSelector: "regarding.kind=Pod",
+		Limit:         eventPageSize,
+	}
+	for {
+		list, err := client.EventsV1().Events(namespace).List(ctx, listOpts)
+		if err != nil {
+			return events, err
+		}
+		for i := range list.Items {
+			events = append(events, ToCoreEvent(&list.Items[i]))
+		}
+		if list.Continue == "" {
+			return events, nil
+		}
+		listOpts.Continue = list.Continue
+	}
+}
+
+// ToCoreEvent converts an events.k8s.io/v1 event to its core/v1 form
+func ToCoreEvent(ev *eventsv1.Event) v1.Event {
+	out := v1.Event{
+		ObjectMeta:          ev.ObjectMeta,
+		InvolvedObject:      ev.Regarding,
+		Reason:              ev.Reason,
+		Message:             ev.Note,
+		Type:                ev.Type,
+		Count:               ev.DeprecatedCount,
+		FirstTimestamp:      ev.DeprecatedFirstTimestamp,
+		LastTimestamp:       ev.DeprecatedLastTimestamp,
+		EventTime:           ev.EventTime,
+		Action:              ev.Action,
+		ReportingController: ev.ReportingController,
+		ReportingInstance:   ev.ReportingInstance,
+		Source:              ev.DeprecatedSource,
+	}
+	if ev.Related != nil {
+		related := *ev.Related
+		out.Related = &related
+	}
+	if ev.Series != nil {
+		out.Series = &v1.EventSeries{Count: ev.Series.Count, LastObservedTime: ev.Series.LastObservedTime}
+	}
+	return out
+}
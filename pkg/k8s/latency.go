@@ -0,0 +1,72 @@
+package k8s
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	clientmetrics "k8s.io/client-go/tools/metrics"
+)
+
+// LatencyRecorder collects the latency of the API server read requests
+// (List and Get) made with a context returned by WithLatencyRecorder. It
+// relies on client-go's request metrics hook, so only clients talking to a
+// real API server are measured. A LatencyRecorder is safe for concurrent use.
+type LatencyRecorder struct {
+	mu      sync.Mutex
+	samples []time.Duration
+}
+
+type latencyKey struct{}
+
+var registerLatency sync.Once
+
+// WithLatencyRecorder returns a context whose API requests are recorded by r.
+// client-go accepts a single metrics registration per process: if another
+// library registered first, nothing is recorded.
+func WithLatencyRecorder(ctx context.Context, r *LatencyRecorder) context.Context {
+	registerLatency.Do(func() {
+		clientmetrics.Register(clientmetrics.RegisterOpts{RequestLatency: latencyObserver{}})
+	})
+	return context.WithValue(ctx, latencyKey{}, r)
+}
+
+// latencyObserver forwards client-go request latencies to the recorder of
+// the request's context
+type latencyObserver struct{}
+
+func (latencyObserver) Observe(ctx context.Context, verb string, _ url.URL, latency time.Duration) {
+	r, ok := ctx.Value(latencyKey{}).(*LatencyRecorder)
+	if !ok || verb != http.MethodGet {
+		return
+	}
+	r.mu.Lock()
+	r.samples = append(r.samples, latency)
+	r.mu.Unlock()
+}
+
+// Count returns the number of requests recorded
+func (r *LatencyRecorder) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.samples)
+}
+
+// Percentile returns the p-th percentile (0-100) of the recorded latencies,
+// or 0 when nothing was recorded
+func (r *LatencyRecorder) Percentile(p float64) time.Duration {
+	r.mu.Lock()
+	sorted := slices.Clone(r.samples)
+	r.mu.Unlock()
+	if len(sorted) == 0 {
+		return 0
+	}
+	slices.Sort(sorted)
+	// Nearest rank
+	i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
@@ -0,0 +1,131 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// MissingNamespace is a requested namespace that does not exist in the cluster
+type MissingNamespace struct {
+	Name string
+	// Suggestions are existing namespaces with a similar name, closest first
+	Suggestions []string
+}
+
+func (m MissingNamespace) String() string {
+	if len(m.Suggestions) == 0 {
+		return fmt.Sprintf("namespace %q not found", m.Name)
+	}
+	return fmt.Sprintf("namespace %q not found (did you mean %s?)", m.Name, quoteJoin(m.Suggestions))
+}
+
+// ValidateNamespaces returns the namespaces that do not exist in the cluster.
+// When the credentials cannot list namespaces each one is looked up with a Get
+// instead (without suggestions); namespaces that cannot be read at all are
+// assumed to exist, since the scan itself will report the permission error
+func ValidateNamespaces(ctx context.Context, client kubernetes.Interface, namespaces []string) ([]MissingNamespace, error) {
+	if len(namespaces) == 0 {
+		return nil, nil
+	}
+
+	list, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err == nil {
+		existing := make([]string, 0, len(list.Items))
+		known := make(map[string]bool, len(list.Items))
+		for _, ns := range list.Items {
+			existing = append(existing, ns.Name)
+			known[ns.Name] = true
+		}
+		var missing []MissingNamespace
+		for _, ns := range namespaces {
+			if !known[ns] {
+				missing = append(missing, MissingNamespace{Name: ns, Suggestions: closeMatches(ns, existing, 3)})
+			}
+		}
+		return missing, nil
+	}
+	if !apierrors.IsForbidden(err) {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	var missing []MissingNamespace
+	for _, ns := range namespaces {
+		_, err := client.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
+		switch {
+		case err == nil, apierrors.IsForbidden(err):
+		case apierrors.IsNotFound(err):
+			missing = append(missing, MissingNamespace{Name: ns})
+		default:
+			return nil, fmt.Errorf("failed to get namespace %s: %w", ns, err)
+		}
+	}
+	return missing, nil
+}
+
+// closeMatches returns up to max candidates within a small edit distance of
+// name, or containing it, ordered by distance then name
+func closeMatches(name string, candidates []string, max int) []string {
+	type match struct {
+		name string
+		dist int
+	}
+	limit := len(name) / 3
+	if limit < 2 {
+		limit = 2
+	}
+
+	var matches []match
+	for _, c := range candidates {
+		d := levenshtein(name, c)
+		if d <= limit || strings.Contains(c, name) || (strings.Contains(name, c) && len(c) > 2) {
+			matches = append(matches, match{name: c, dist: d})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].dist != matches[j].dist {
+			return matches[i].dist < matches[j].dist
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	var out []string
+	for i := 0; i < len(matches) && i < max; i++ {
+		out = append(out, matches[i].name)
+	}
+	return out
+}
+
+// levenshtein is the edit distance between a and b
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func quoteJoin(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = fmt.Sprintf("%q", n)
+	}
+	return strings.Join(quoted, ", ")
+}
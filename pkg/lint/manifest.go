@@ -0,0 +1,218 @@
+package lint
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ductnn/k8s-scanner/pkg/policy"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/spec"
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// Options configures Lint
+type Options struct {
+	// Policy, when set, also evaluates these rules against every document,
+	// including custom resources
+	Policy *policy.Set
+	// Warn, when set, receives the rules that failed to evaluate
+	Warn func(error)
+}
+
+// LintPath lints a manifest file, a directory (recursively) or "-" for stdin
+func LintPath(path string) ([]types.Issue, error) {
+	return Lint(path, Options{})
+}
+
+// Lint is LintPath with options
+func Lint(path string, opts Options) ([]types.Issue, error) {
+	if path == "-" {
+		return lintReader(os.Stdin, opts)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if !info.IsDir() {
+		return lintFile(path, opts)
+	}
+
+	var issues []types.Issue
+	err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isManifest(p) {
+			return nil
+		}
+		fileIssues, err := lintFile(p, opts)
+		if err != nil {
+			return err
+		}
+		issues = append(issues, fileIssues...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// LintReader lints every YAML/JSON document read from r
+func LintReader(r io.Reader) ([]types.Issue, error) {
+	return lintReader(r, Options{})
+}
+
+func lintReader(r io.Reader, opts Options) ([]types.Issue, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	decode := scheme.Codecs.UniversalDeserializer().Decode
+
+	var issues []types.Issue
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest: %w", err)
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		if opts.Policy.Len() > 0 {
+			issues = append(issues, evaluatePolicy(doc, opts)...)
+		}
+
+		obj, _, err := decode(doc, nil, nil)
+		if err != nil {
+			// Skip documents that are not built-in Kubernetes kinds (CRDs, kustomize files, ...)
+			continue
+		}
+		issues = append(issues, lintObject(obj)...)
+	}
+	return issues, nil
+}
+
+func lintFile(path string, opts Options) ([]types.Issue, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	issues, err := lintReader(f, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return issues, nil
+}
+
+// lintObject extracts the pod template of a workload and runs the spec checks
+func lintObject(obj runtime.Object) []types.Issue {
+	switch o := obj.(type) {
+	case *v1.Pod:
+		return spec.CheckPodSpec(workload("Pod", o.Namespace, o.Name), o.Spec)
+	case *appsv1.Deployment:
+		return spec.CheckPodSpec(workload("Deployment", o.Namespace, o.Name), o.Spec.Template.Spec)
+	case *appsv1.StatefulSet:
+		return spec.CheckPodSpec(workload("StatefulSet", o.Namespace, o.Name), o.Spec.Template.Spec)
+	case *appsv1.DaemonSet:
+		return spec.CheckPodSpec(workload("DaemonSet", o.Namespace, o.Name), o.Spec.Template.Spec)
+	case *appsv1.ReplicaSet:
+		return spec.CheckPodSpec(workload("ReplicaSet", o.Namespace, o.Name), o.Spec.Template.Spec)
+	case *batchv1.Job:
+		return spec.CheckPodSpec(workload("Job", o.Namespace, o.Name), o.Spec.Template.Spec)
+	case *batchv1.CronJob:
+		return spec.CheckPodSpec(workload("CronJob", o.Namespace, o.Name), o.Spec.JobTemplate.Spec.Template.Spec)
+	case *v1.List:
+		var issues []types.Issue
+		decode := scheme.Codecs.UniversalDeserializer().Decode
+		for _, item := range o.Items {
+			inner, _, err := decode(item.Raw, nil, nil)
+			if err != nil {
+				continue
+			}
+			issues = append(issues, lintObject(inner)...)
+		}
+		return issues
+	}
+	return nil
+}
+
+// evaluatePolicy runs the policy rules against a document of any kind; the
+// items of a List are evaluated one by one
+func evaluatePolicy(doc []byte, opts Options) []types.Issue {
+	b, err := utilyaml.ToJSON(doc)
+	if err != nil {
+		return nil
+	}
+	var obj map[string]any
+	if err := utiljson.Unmarshal(b, &obj); err != nil {
+		return nil
+	}
+	return evaluateObject(obj, opts)
+}
+
+func evaluateObject(obj map[string]any, opts Options) []types.Issue {
+	kind, _ := obj["kind"].(string)
+	if items, ok := obj["items"].([]any); ok && strings.HasSuffix(kind, "List") {
+		var issues []types.Issue
+		for _, item := range items {
+			if m, ok := item.(map[string]any); ok {
+				issues = append(issues, evaluateObject(m, opts)...)
+			}
+		}
+		return issues
+	}
+
+	issues, errs := opts.Policy.Evaluate(kind, obj)
+	for i := range issues {
+		// Same default as the built-in checks (see workload)
+		if issues[i].Namespace == "" {
+			issues[i].Namespace = "default"
+		}
+	}
+	if opts.Warn != nil {
+		for _, err := range errs {
+			opts.Warn(err)
+		}
+	}
+	return issues
+}
+
+func workload(kind, namespace, name string) spec.Workload {
+	if namespace == "" {
+		namespace = "default"
+	}
+	return spec.Workload{Kind: kind, Namespace: namespace, Name: name}
+}
+
+func isManifest(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// SeverityAtLeast reports whether severity is at or above min
+//
+// Deprecated: use severity.AtLeast. This wrapper will be removed in the next release.
+func SeverityAtLeast(sev, min string) bool {
+	return severity.AtLeast(severity.Level(sev), severity.Level(min))
+}
@@ -1,11 +1,17 @@
 package metrics
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	httppprof "net/http/pprof"
+	"sync/atomic"
 	"time"
 
+	"github.com/ductnn/k8s-scanner/pkg/report"
 	"github.com/ductnn/k8s-scanner/pkg/types"
+	"github.com/ductnn/k8s-scanner/pkg/version"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
@@ -19,6 +25,14 @@ var (
 		[]string{"namespace", "severity"},
 	)
 
+	IssuesByReason = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "k8s_issues_by_reason",
+			Help: "Number of Kubernetes issues by namespace, severity and reason.",
+		},
+		[]string{"namespace", "severity", "reason"},
+	)
+
 	NamespaceCount = prometheus.NewGauge(
 		prometheus.GaugeOpts{
 			Name: "k8s_scanner_namespace_count",
@@ -32,12 +46,55 @@ var (
 			Help: "Unix timestamp of last scanner run.",
 		},
 	)
+
+	BuildInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "k8s_scanner_build_info",
+			Help: "Always 1; labels carry the scanner build and the scanned cluster's Kubernetes version.",
+		},
+		[]string{"version", "commit", "go_version", "kubernetes_version"},
+	)
+
+	APILatency = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "k8s_scanner_api_latency_seconds",
+			Help: "Latency of the API server read requests of the last scan, by quantile.",
+		},
+		[]string{"quantile"},
+	)
+
+	TeamIssues = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "k8s_scanner_team_issues",
+			Help: "Number of Kubernetes issues by owning team and severity.",
+		},
+		[]string{"team", "severity"},
+	)
+
+	TeamCompliant = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "k8s_scanner_team_compliant",
+			Help: "1 when the team is within its issue budget, 0 otherwise.",
+		},
+		[]string{"team"},
+	)
 )
 
 func Init() {
 	prometheus.MustRegister(IssuesTotal)
+	prometheus.MustRegister(IssuesByReason)
 	prometheus.MustRegister(NamespaceCount)
 	prometheus.MustRegister(LastRunTimestamp)
+	prometheus.MustRegister(BuildInfo)
+	prometheus.MustRegister(APILatency)
+	prometheus.MustRegister(TeamIssues)
+	prometheus.MustRegister(TeamCompliant)
+}
+
+// SetBuildInfo publishes the build info metric for the given server version
+func SetBuildInfo(info version.Info, kubernetesVersion string) {
+	BuildInfo.Reset()
+	BuildInfo.WithLabelValues(info.Version, info.Commit, info.GoVersion, kubernetesVersion).Set(1)
 }
 
 func ExportSummary(sum map[string]types.SeveritySummary) {
@@ -50,21 +107,97 @@ func ExportSummary(sum map[string]types.SeveritySummary) {
 		IssuesTotal.WithLabelValues(ns, "high").Set(float64(s.High))
 		IssuesTotal.WithLabelValues(ns, "medium").Set(float64(s.Medium))
 		IssuesTotal.WithLabelValues(ns, "low").Set(float64(s.Low))
+		IssuesTotal.WithLabelValues(ns, "info").Set(float64(s.Info))
 	}
 
 	NamespaceCount.Set(float64(len(sum)))
 	LastRunTimestamp.Set(float64(time.Now().Unix()))
+	ready.Store(true)
+}
+
+// ExportAPILatency publishes the API latency of the last scan; scans that
+// made no measurable request (snapshots) clear it
+func ExportAPILatency(l *report.APILatency) {
+	APILatency.Reset()
+	if l == nil {
+		return
+	}
+	APILatency.WithLabelValues("0.5").Set(l.P50MS / 1000)
+	APILatency.WithLabelValues("0.95").Set(l.P95MS / 1000)
 }
 
-// StartServer starts the Prometheus metrics HTTP server
-func StartServer(port int) {
+// ExportTeams publishes the per-team issue counts and budget compliance
+func ExportTeams(teams []report.TeamStatus) {
+	TeamIssues.Reset()
+	TeamCompliant.Reset()
+	for _, t := range teams {
+		TeamIssues.WithLabelValues(t.Team, "critical").Set(float64(t.Summary.Critical))
+		TeamIssues.WithLabelValues(t.Team, "high").Set(float64(t.Summary.High))
+		TeamIssues.WithLabelValues(t.Team, "medium").Set(float64(t.Summary.Medium))
+		TeamIssues.WithLabelValues(t.Team, "low").Set(float64(t.Summary.Low))
+		TeamIssues.WithLabelValues(t.Team, "info").Set(float64(t.Summary.Info))
+		compliant := 0.0
+		if t.Compliant {
+			compliant = 1
+		}
+		TeamCompliant.WithLabelValues(t.Team).Set(compliant)
+	}
+}
+
+// ExportIssues publishes the issue counts per reason
+func ExportIssues(issues []types.Issue) {
+	IssuesByReason.Reset()
+	for _, is := range issues {
+		IssuesByReason.WithLabelValues(is.Namespace, string(is.Severity), is.Reason).Inc()
+	}
+}
+
+// Handler serves the registered metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ready is set once the first scan has been exported
+var ready atomic.Bool
+
+// Register adds /metrics, /healthz and /readyz to mux, and /debug/pprof when
+// pprof is set. /readyz fails until the first scan has completed.
+func Register(mux *http.ServeMux, pprof bool) {
+	mux.Handle("/metrics", Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !ready.Load() {
+			http.Error(w, "no scan has completed yet", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	if pprof {
+		mux.HandleFunc("/debug/pprof/", httppprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", httppprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", httppprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", httppprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", httppprof.Trace)
+	}
+}
+
+// StartServer starts the Prometheus metrics HTTP server in the background,
+// with health endpoints and optionally pprof. Stop it with Shutdown. Status
+// messages are written to out.
+func StartServer(port int, pprof bool, out io.Writer) *http.Server {
 	mux := http.NewServeMux()
-	mux.Handle("/metrics", promhttp.Handler())
+	Register(mux, pprof)
 
 	addr := fmt.Sprintf(":%d", port)
-	fmt.Printf("Prometheus metrics server running at http://localhost%s/metrics\n", addr)
+	srv := &http.Server{Addr: addr, Handler: mux}
+	fmt.Fprintf(out, "Prometheus metrics server running at http://localhost%s/metrics\n", addr)
 
-	if err := http.ListenAndServe(addr, mux); err != nil {
-		fmt.Printf("Metrics server error: %v\n", err)
-	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(out, "Metrics server error: %v\n", err)
+		}
+	}()
+	return srv
 }
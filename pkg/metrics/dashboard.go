@@ -0,0 +1,199 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+)
+
+// DashboardOptions configures the generated Grafana dashboard
+type DashboardOptions struct {
+	// Title of the dashboard
+	Title string
+	// StaleAfter is how long after the last scan the freshness alert fires
+	StaleAfter time.Duration
+}
+
+// DefaultDashboardOptions returns the options used by `k8s-scanner grafana-dashboard`
+func DefaultDashboardOptions() DashboardOptions {
+	return DashboardOptions{Title: "Kubernetes Scanner", StaleAfter: 2 * time.Hour}
+}
+
+// GrafanaDashboard builds a Grafana dashboard (JSON model) for the metrics
+// exported by the scanner. Queries use a $datasource variable so it can be
+// imported into any Grafana with a Prometheus data source, and a $namespace
+// variable to narrow every panel.
+func GrafanaDashboard(opts DashboardOptions) map[string]any {
+	stale := int(opts.StaleAfter.Seconds())
+	nsFilter := `namespace=~"$namespace"`
+
+	panels := []map[string]any{
+		statPanel(1, "Critical issues", 0, 0, fmt.Sprintf(`sum(k8s_issues_total{severity="critical",%s})`, nsFilter),
+			"none", thresholds(0, "green", 1, "red")),
+		statPanel(2, "High issues", 6, 0, fmt.Sprintf(`sum(k8s_issues_total{severity="high",%s})`, nsFilter),
+			"none", thresholds(0, "green", 1, "orange")),
+		statPanel(3, "Namespaces with issues", 12, 0, "k8s_scanner_namespace_count",
+			"none", thresholds(0, "green")),
+		statPanel(4, "Time since last scan", 18, 0, "time() - k8s_scanner_last_run_timestamp",
+			"s", thresholds(0, "green", stale, "red")),
+
+		timeseriesPanel(5, "Issues by severity", 0, 4, 12,
+			target(fmt.Sprintf(`sum by (severity) (k8s_issues_total{%s})`, nsFilter), "{{severity}}")),
+		timeseriesPanel(6, "Issues by namespace", 12, 4, 12,
+			target(fmt.Sprintf(`sum by (namespace) (k8s_issues_total{%s})`, nsFilter), "{{namespace}}")),
+
+		{
+			"id":         7,
+			"type":       "bargauge",
+			"title":      "Top reasons",
+			"datasource": datasource(),
+			"gridPos":    gridPos(0, 12, 12, 9),
+			"options":    map[string]any{"orientation": "horizontal", "displayMode": "gradient", "showUnfilled": true},
+			"targets": []map[string]any{
+				instant(fmt.Sprintf(`topk(10, sum by (reason) (k8s_issues_by_reason{%s}))`, nsFilter), "{{reason}}"),
+			},
+		},
+		{
+			"id":         8,
+			"type":       "table",
+			"title":      "Issues by namespace and severity",
+			"datasource": datasource(),
+			"gridPos":    gridPos(12, 12, 12, 9),
+			"targets": []map[string]any{
+				instant(fmt.Sprintf(`sum by (namespace, severity) (k8s_issues_total{%s}) > 0`, nsFilter), ""),
+			},
+			"transformations": []map[string]any{
+				{"id": "labelsToFields", "options": map[string]any{"mode": "columns", "valueLabel": "severity"}},
+				{"id": "merge"},
+				{"id": "organize", "options": map[string]any{"excludeByName": map[string]any{"Time": true}}},
+			},
+		},
+
+		timeseriesPanel(9, "Issues by reason", 0, 21, 12,
+			target(fmt.Sprintf(`sum by (reason) (k8s_issues_by_reason{%s})`, nsFilter), "{{reason}}")),
+		freshnessPanel(10, 12, 21, stale),
+	}
+
+	return map[string]any{
+		"title":         opts.Title,
+		"uid":           "k8s-scanner",
+		"tags":          []string{"kubernetes", "k8s-scanner"},
+		"timezone":      "browser",
+		"schemaVersion": 39,
+		"refresh":       "1m",
+		"time":          map[string]any{"from": "now-7d", "to": "now"},
+		"templating": map[string]any{
+			"list": []map[string]any{
+				{
+					"name":  "datasource",
+					"label": "Data source",
+					"type":  "datasource",
+					"query": "prometheus",
+				},
+				{
+					"name":       "namespace",
+					"label":      "Namespace",
+					"type":       "query",
+					"datasource": datasource(),
+					"query":      "label_values(k8s_issues_total, namespace)",
+					"refresh":    2,
+					"multi":      true,
+					"includeAll": true,
+					"allValue":   ".*",
+					"current":    map[string]any{"text": "All", "value": "$__all"},
+				},
+			},
+		},
+		"panels": panels,
+	}
+}
+
+// freshnessPanel graphs the age of the last scan with a legacy alert that
+// fires when no scan has completed for stale seconds
+func freshnessPanel(id, x, y, stale int) map[string]any {
+	p := timeseriesPanel(id, "Last scan age", x, y, 12, target("time() - k8s_scanner_last_run_timestamp", "age"))
+	p["fieldConfig"] = map[string]any{
+		"defaults": map[string]any{
+			"unit":       "s",
+			"thresholds": thresholds(0, "green", stale, "red"),
+			"custom":     map[string]any{"thresholdsStyle": map[string]any{"mode": "line"}},
+		},
+	}
+	p["alert"] = map[string]any{
+		"name":      "k8s-scanner has not run recently",
+		"message":   fmt.Sprintf("No k8s-scanner run has completed in the last %s.", time.Duration(stale)*time.Second),
+		"frequency": "5m",
+		"for":       "5m",
+		"conditions": []map[string]any{{
+			"type":      "query",
+			"query":     map[string]any{"params": []string{"A", "10m", "now"}},
+			"reducer":   map[string]any{"type": "last", "params": []string{}},
+			"evaluator": map[string]any{"type": "gt", "params": []int{stale}},
+			"operator":  map[string]any{"type": "and"},
+		}},
+		"noDataState":         "alerting",
+		"executionErrorState": "alerting",
+	}
+	return p
+}
+
+func statPanel(id int, title string, x, y int, expr, unit string, steps map[string]any) map[string]any {
+	return map[string]any{
+		"id":         id,
+		"type":       "stat",
+		"title":      title,
+		"datasource": datasource(),
+		"gridPos":    gridPos(x, y, 6, 4),
+		"targets":    []map[string]any{instant(expr, "")},
+		"fieldConfig": map[string]any{
+			"defaults": map[string]any{"unit": unit, "thresholds": steps},
+		},
+		"options": map[string]any{"colorMode": "background", "reduceOptions": map[string]any{"calcs": []string{"lastNotNull"}}},
+	}
+}
+
+func timeseriesPanel(id int, title string, x, y, w int, t map[string]any) map[string]any {
+	return map[string]any{
+		"id":         id,
+		"type":       "timeseries",
+		"title":      title,
+		"datasource": datasource(),
+		"gridPos":    gridPos(x, y, w, 8),
+		"targets":    []map[string]any{t},
+	}
+}
+
+func target(expr, legend string) map[string]any {
+	return map[string]any{"refId": "A", "expr": expr, "legendFormat": legend, "datasource": datasource()}
+}
+
+func instant(expr, legend string) map[string]any {
+	t := target(expr, legend)
+	t["instant"] = true
+	t["format"] = "table"
+	if legend != "" {
+		t["format"] = "time_series"
+	}
+	return t
+}
+
+func datasource() map[string]any {
+	return map[string]any{"type": "prometheus", "uid": "${datasource}"}
+}
+
+func gridPos(x, y, w, h int) map[string]any {
+	return map[string]any{"x": x, "y": y, "w": w, "h": h}
+}
+
+// thresholds builds threshold steps from value/color pairs; the first value
+// is the base step
+func thresholds(pairs ...any) map[string]any {
+	var steps []map[string]any
+	for i := 0; i+1 < len(pairs); i += 2 {
+		var value any = pairs[i]
+		if i == 0 {
+			value = nil
+		}
+		steps = append(steps, map[string]any{"value": value, "color": pairs[i+1]})
+	}
+	return map[string]any{"mode": "absolute", "steps": steps}
+}
@@ -0,0 +1,175 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/report"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// DefaultTopOffenders is the number of namespaces listed in a digest
+const DefaultTopOffenders = 5
+
+// Digest summarizes the latest scan for a periodic notification, with the
+// trend since the previous digest
+type Digest struct {
+	Cluster string `json:"cluster,omitempty"`
+	// Owner is the team or namespace the digest was routed to (team:<name>
+	// or namespace:<name>); empty for the default notifiers
+	Owner       string `json:"owner,omitempty"`
+	GeneratedAt string `json:"generated_at"`
+	// ScannedAt is when the summarized scan ran
+	ScannedAt string                `json:"scanned_at"`
+	Totals    types.SeveritySummary `json:"totals"`
+	// Since is when the previous digest was sent; the trend fields are
+	// only set when there was one
+	Since         string                 `json:"since,omitempty"`
+	Previous      *types.SeveritySummary `json:"previous,omitempty"`
+	NewIssues     int                    `json:"new_issues"`
+	ResolvedCount int                    `json:"resolved_issues"`
+	TopOffenders  []Offender             `json:"top_offenders"`
+	// IDs of the issues, to compute the next digest's trend; they are
+	// saved but not sent
+	IDs []string `json:"ids,omitempty"`
+}
+
+// Offender is a namespace with many issues
+type Offender struct {
+	Namespace string                `json:"namespace"`
+	Summary   types.SeveritySummary `json:"summary"`
+}
+
+// BuildDigest summarizes a report. previous is the last digest sent (nil
+// for the first one); top is the number of namespaces listed.
+func BuildDigest(data *report.ReportData, previous *Digest, top int, now time.Time) *Digest {
+	d := &Digest{
+		GeneratedAt:  now.Format(time.RFC3339),
+		ScannedAt:    data.GeneratedAt,
+		TopOffenders: []Offender{},
+		IDs:          make([]string, 0, len(data.Issues)),
+	}
+	if data.Meta != nil {
+		d.Cluster = data.Meta.Cluster
+	}
+	for _, s := range data.Summary {
+		d.Totals.Critical += s.Critical
+		d.Totals.High += s.High
+		d.Totals.Medium += s.Medium
+		d.Totals.Low += s.Low
+		d.Totals.Info += s.Info
+	}
+
+	current := make(map[string]bool, len(data.Issues))
+	for _, is := range data.Issues {
+		current[is.ID] = true
+		d.IDs = append(d.IDs, is.ID)
+	}
+	if previous != nil {
+		d.Since = previous.GeneratedAt
+		d.Previous = &previous.Totals
+		seen := make(map[string]bool, len(previous.IDs))
+		for _, id := range previous.IDs {
+			seen[id] = true
+			if !current[id] {
+				d.ResolvedCount++
+			}
+		}
+		for id := range current {
+			if !seen[id] {
+				d.NewIssues++
+			}
+		}
+	}
+
+	for _, ns := range report.SortedNamespaces(data.Summary) {
+		s := data.Summary[ns]
+		if s.Critical+s.High+s.Medium+s.Low > 0 {
+			d.TopOffenders = append(d.TopOffenders, Offender{Namespace: ns, Summary: s})
+		}
+	}
+	sort.SliceStable(d.TopOffenders, func(i, j int) bool {
+		a, b := d.TopOffenders[i].Summary, d.TopOffenders[j].Summary
+		if a.Critical != b.Critical {
+			return a.Critical > b.Critical
+		}
+		if a.High != b.High {
+			return a.High > b.High
+		}
+		return a.Medium+a.Low > b.Medium+b.Low
+	})
+	if len(d.TopOffenders) > top {
+		d.TopOffenders = d.TopOffenders[:top]
+	}
+	return d
+}
+
+// Message renders the digest for the notifiers
+func (d *Digest) Message() Message {
+	title := "k8s-scanner digest"
+	if d.Cluster != "" {
+		title += " — " + d.Cluster
+	}
+	if d.Owner != "" {
+		title += " (" + d.Owner + ")"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Scan of %s: %d critical, %d high, %d medium, %d low\n",
+		d.ScannedAt, d.Totals.Critical, d.Totals.High, d.Totals.Medium, d.Totals.Low)
+	if d.Previous != nil {
+		fmt.Fprintf(&sb, "Since %s: critical %s, high %s; %d new, %d resolved\n", d.Since,
+			trend(d.Previous.Critical, d.Totals.Critical), trend(d.Previous.High, d.Totals.High), d.NewIssues, d.ResolvedCount)
+	}
+	if len(d.TopOffenders) > 0 {
+		sb.WriteString("Top namespaces:\n")
+		for _, o := range d.TopOffenders {
+			fmt.Fprintf(&sb, "  %-30s %d critical, %d high, %d other\n", o.Namespace, o.Summary.Critical, o.Summary.High, o.Summary.Medium+o.Summary.Low)
+		}
+	}
+	payload := *d
+	payload.IDs = nil
+	return Message{Kind: "digest", Title: title, Text: strings.TrimRight(sb.String(), "\n"), Data: &payload}
+}
+
+// trend formats a change like "3 → 5 (+2)"
+func trend(before, after int) string {
+	return fmt.Sprintf("%d → %d (%+d)", before, after, after-before)
+}
+
+// LoadDigest reads the digest saved by SaveDigest; it returns nil without
+// error when there is none
+func LoadDigest(path string) (*Digest, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read digest: %w", err)
+	}
+	var d Digest
+	if err := json.Unmarshal(b, &d); err != nil {
+		return nil, fmt.Errorf("failed to parse digest %s: %w", path, err)
+	}
+	return &d, nil
+}
+
+// SaveDigest records the digest so the next one reports the trend since it
+func SaveDigest(path string, d *Digest) error {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("failed to encode digest: %w", err)
+	}
+	if err := report.EnsureDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return fmt.Errorf("failed to write digest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+// Email sends messages as plain-text mail through an SMTP server
+type Email struct {
+	To []string
+	// Addr is the SMTP server as host:port
+	Addr string
+	From string
+	// Username and Password, when set, authenticate with PLAIN auth
+	Username string
+	Password string
+}
+
+// emailFromEnv configures an Email notifier for the recipients from
+// SMTP_ADDR, SMTP_FROM, SMTP_USERNAME and SMTP_PASSWORD. Missing settings are
+// reported by Send so that configs naming email routes load anywhere.
+func emailFromEnv(spec, to string) (*Email, error) {
+	e := &Email{
+		Addr:     os.Getenv("SMTP_ADDR"),
+		From:     os.Getenv("SMTP_FROM"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+	}
+	for _, addr := range strings.Split(to, ";") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			if !strings.Contains(addr, "@") {
+				return nil, fmt.Errorf("invalid notifier %q: %q is not an email address", spec, addr)
+			}
+			e.To = append(e.To, addr)
+		}
+	}
+	if len(e.To) == 0 {
+		return nil, fmt.Errorf("invalid notifier %q: no recipient", spec)
+	}
+	return e, nil
+}
+
+// Name implements Notifier
+func (e *Email) Name() string { return "email" }
+
+// Send implements Notifier. The context is not used: net/smtp does not
+// support cancellation.
+func (e *Email) Send(ctx context.Context, m Message) error {
+	if e.Addr == "" || e.From == "" {
+		return fmt.Errorf("SMTP_ADDR and SMTP_FROM must be set to send email")
+	}
+	var auth smtp.Auth
+	if e.Username != "" {
+		host, _, err := net.SplitHostPort(e.Addr)
+		if err != nil {
+			return fmt.Errorf("invalid SMTP address %q: %w", e.Addr, err)
+		}
+		auth = smtp.PlainAuth("", e.Username, e.Password, host)
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "From: %s\r\nTo: %s\r\nSubject: %s\r\n", e.From, strings.Join(e.To, ", "), mime.QEncoding.Encode("utf-8", m.Title))
+	sb.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	sb.WriteString(strings.ReplaceAll(m.Text, "\n", "\r\n") + "\r\n")
+	if err := smtp.SendMail(e.Addr, auth, e.From, e.To, []byte(sb.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
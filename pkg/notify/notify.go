@@ -0,0 +1,115 @@
+// Package notify sends scan results to chat, webhook and email endpoints. It
+// is used by `k8s-scanner serve` for scheduled digests.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Message is what a Notifier sends
+type Message struct {
+	// Kind identifies the message type (e.g. "digest") for webhook consumers
+	Kind  string `json:"kind"`
+	Title string `json:"title"`
+	// Text is the human-readable body, used by chat notifiers
+	Text string `json:"text"`
+	// Data is the structured payload posted by the webhook notifier
+	Data any `json:"data,omitempty"`
+}
+
+// Notifier delivers messages to one endpoint
+type Notifier interface {
+	// Name identifies the notifier in logs
+	Name() string
+	Send(ctx context.Context, m Message) error
+}
+
+// sendTimeout bounds each delivery
+const sendTimeout = 30 * time.Second
+
+// Parse creates a notifier from a "type=url" spec, where type is slack (an
+// incoming webhook) or webhook (the message posted as JSON), or from an
+// "email=<address>[;<address>...]" spec sent through the SMTP server set in
+// SMTP_ADDR and SMTP_FROM
+func Parse(spec string) (Notifier, error) {
+	kind, url, ok := strings.Cut(strings.TrimSpace(spec), "=")
+	if !ok || url == "" {
+		return nil, fmt.Errorf("invalid notifier %q (expected slack=<url>, webhook=<url> or email=<address>)", spec)
+	}
+	if strings.ToLower(kind) == "email" {
+		return emailFromEnv(spec, url)
+	}
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("invalid notifier %q: url must start with http:// or https://", spec)
+	}
+	switch strings.ToLower(kind) {
+	case "slack":
+		return &Slack{URL: url}, nil
+	case "webhook":
+		return &Webhook{URL: url}, nil
+	}
+	return nil, fmt.Errorf("invalid notifier %q: unknown type %q (expected slack, webhook or email)", spec, kind)
+}
+
+// Webhook posts messages as JSON
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+// Name implements Notifier
+func (w *Webhook) Name() string { return "webhook" }
+
+// Send implements Notifier
+func (w *Webhook) Send(ctx context.Context, m Message) error {
+	return postJSON(ctx, w.Client, w.URL, m)
+}
+
+// Slack posts messages to a Slack incoming webhook
+type Slack struct {
+	URL    string
+	Client *http.Client
+}
+
+// Name implements Notifier
+func (s *Slack) Name() string { return "slack" }
+
+// Send implements Notifier
+func (s *Slack) Send(ctx context.Context, m Message) error {
+	text := "*" + m.Title + "*\n```\n" + m.Text + "\n```"
+	return postJSON(ctx, s.Client, s.URL, map[string]string{"text": text})
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, body any) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("notification rejected: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
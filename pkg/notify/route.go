@@ -0,0 +1,85 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// NotifyAnnotation on a namespace lists the notifiers (comma-separated
+// specs, see Parse) receiving the issues of the namespace, ahead of the
+// routes of their team
+const NotifyAnnotation = "scanner.ductnn.io/notify"
+
+// ParseAll creates a notifier from every spec
+func ParseAll(specs []string) ([]Notifier, error) {
+	var out []Notifier
+	for _, spec := range specs {
+		n, err := Parse(spec)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// ParseTeamRoutes creates the notifiers of each team
+func ParseTeamRoutes(routes map[string][]string) (map[string][]Notifier, error) {
+	out := make(map[string][]Notifier, len(routes))
+	for team, specs := range routes {
+		notifiers, err := ParseAll(specs)
+		if err != nil {
+			return nil, fmt.Errorf("team %s: %w", team, err)
+		}
+		out[team] = notifiers
+	}
+	return out, nil
+}
+
+// NamespaceRoutes reads the NotifyAnnotation of namespaces. Namespaces with
+// an invalid annotation are left out and reported in the errors.
+func NamespaceRoutes(namespaces []v1.Namespace) (map[string][]Notifier, []error) {
+	out := map[string][]Notifier{}
+	var errs []error
+	for _, ns := range namespaces {
+		value := strings.TrimSpace(ns.Annotations[NotifyAnnotation])
+		if value == "" {
+			continue
+		}
+		notifiers, err := ParseAll(strings.Split(value, ","))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("namespace %s: %w", ns.Name, err))
+			continue
+		}
+		out[ns.Name] = notifiers
+	}
+	return out, errs
+}
+
+// Route is a destination of notifications
+type Route struct {
+	// Key is "" for the default notifiers, team:<name> or namespace:<name>
+	Key       string
+	Notifiers []Notifier
+}
+
+// Router picks where the notifications about an issue go: the notifiers of
+// its namespace, else of its team, else the default ones
+type Router struct {
+	Default    []Notifier
+	Teams      map[string][]Notifier
+	Namespaces map[string][]Notifier
+}
+
+// RouteOf returns the route of an issue of the given namespace and team
+func (r *Router) RouteOf(namespace, team string) Route {
+	if n, ok := r.Namespaces[namespace]; ok {
+		return Route{Key: "namespace:" + namespace, Notifiers: n}
+	}
+	if n, ok := r.Teams[team]; ok && team != "" {
+		return Route{Key: "team:" + team, Notifiers: n}
+	}
+	return Route{Notifiers: r.Default}
+}
@@ -0,0 +1,61 @@
+// Package offline backs the air-gapped mode (--offline): the scanner then
+// only talks to the Kubernetes API server, and the reports, dashboard and
+// notifications it renders load nothing from the network.
+package offline
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+)
+
+// ErrBlocked is returned for the outbound requests made in offline mode
+var ErrBlocked = errors.New("outbound request blocked by --offline")
+
+// Enable makes every request sent through http.DefaultTransport fail with
+// ErrBlocked, unless it goes to the API server (a URL or host, may be
+// empty). The registry, notifier and tracing clients use that transport;
+// integrations that run a CLI or a plugin are refused by the flags instead.
+func Enable(apiServer string) {
+	host := apiServer
+	if u, err := url.Parse(apiServer); err == nil && u.Host != "" {
+		host = u.Hostname()
+	}
+	http.DefaultTransport = &guard{next: http.DefaultTransport, allowed: host}
+}
+
+// guard is the RoundTripper installed by Enable
+type guard struct {
+	next    http.RoundTripper
+	allowed string
+}
+
+func (g *guard) RoundTrip(req *http.Request) (*http.Response, error) {
+	if g.allowed != "" && req.URL.Hostname() == g.allowed {
+		return g.next.RoundTrip(req)
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, fmt.Errorf("%s %s://%s: %w", req.Method, req.URL.Scheme, req.URL.Host, ErrBlocked)
+}
+
+// remoteAssetPattern finds what a browser or mail client fetches when it
+// renders a document: src attributes, <link href>, CSS url() and @import,
+// and fetch() calls, when they point to another host
+var remoteAssetPattern = regexp.MustCompile(`(?i)(?:\bsrc\s*=\s*|<link\b[^>]*\bhref\s*=\s*|url\(\s*|@import\s+(?:url\(\s*)?|fetch\(\s*)["'` + "`" + `]?((?:[a-z][a-z0-9+.-]*:)?//[^"'` + "`" + `\s)>]+)`)
+
+// RemoteAssets returns the remote URLs a rendered report, page or message
+// would load, sorted and without duplicates. Plain links (<a href>) are
+// only followed on click and are not reported.
+func RemoteAssets(doc []byte) []string {
+	var urls []string
+	for _, m := range remoteAssetPattern.FindAllSubmatch(doc, -1) {
+		urls = append(urls, string(m[1]))
+	}
+	slices.Sort(urls)
+	return slices.Compact(urls)
+}
@@ -0,0 +1,69 @@
+package offline
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+)
+
+func TestRemoteAssets(t *testing.T) {
+	doc := []byte(`<html><head>
+<link rel="stylesheet" href="https://cdn.example.com/style.css">
+<script src="//cdn.example.com/chart.js"></script>
+<style>@import url("https://fonts.example.com/font.css"); body { background: url(https://img.example.com/bg.png) }</style>
+</head><body>
+<img src='http://img.example.com/logo.png'>
+<img src="data:image/png;base64,AAAA">
+<script src="app.js"></script>
+<a href="https://docs.example.com/runbook">runbook</a>
+<script>fetch("/api/v1/reports/latest"); fetch(` + "`https://api.example.com/x`" + `)</script>
+<script src="//cdn.example.com/chart.js"></script>
+</body></html>`)
+	want := []string{
+		"//cdn.example.com/chart.js",
+		"http://img.example.com/logo.png",
+		"https://api.example.com/x",
+		"https://cdn.example.com/style.css",
+		"https://fonts.example.com/font.css",
+		"https://img.example.com/bg.png",
+	}
+	if got := RemoteAssets(doc); !slices.Equal(got, want) {
+		t.Errorf("RemoteAssets() =\n%q\nwant\n%q", got, want)
+	}
+	if got := RemoteAssets([]byte("# Report\n\n| a | b |\n|---|---|\n[link](https://example.com)\n")); len(got) != 0 {
+		t.Errorf("RemoteAssets(markdown link) = %q, want none", got)
+	}
+}
+
+func TestEnable(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer api.Close()
+	saved := http.DefaultTransport
+	defer func() { http.DefaultTransport = saved }()
+
+	Enable(api.URL)
+
+	resp, err := http.Get(api.URL + "/version")
+	if err != nil {
+		t.Fatalf("request to the API server failed: %v", err)
+	}
+	resp.Body.Close()
+
+	u, _ := url.Parse(api.URL)
+	for _, target := range []string{
+		"https://registry-1.docker.io/v2/",
+		"http://hooks.slack.com/services/T/B/X",
+		"http://localhost:" + u.Port() + "/version",
+	} {
+		resp, err := http.Post(target, "application/json", nil)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if !errors.Is(err, ErrBlocked) {
+			t.Errorf("request to %s: got %v, want ErrBlocked", target, err)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/crd"
+	"github.com/ductnn/k8s-scanner/pkg/report"
+	"github.com/ductnn/k8s-scanner/pkg/scanner"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/capacity"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/pod"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// Operator reconciles ScanSchedule resources by running scans on their interval
+type Operator struct {
+	client      kubernetes.Interface
+	crdClient   *crd.Client
+	clusterName string
+	reportsDir  string
+	names       *report.FilenameTemplate
+	resync      time.Duration
+	lastRun     map[string]time.Time
+}
+
+// New creates an Operator. Schedules export their reports under
+// reportsDir, named by names (nil uses report.DefaultFilenameTemplate), and
+// resync is how often ScanSchedules are re-listed.
+func New(client kubernetes.Interface, crdClient *crd.Client, clusterName, reportsDir string, names *report.FilenameTemplate, resync time.Duration) *Operator {
+	if names == nil {
+		names, _ = report.ParseFilenameTemplate("")
+	}
+	return &Operator{
+		client:      client,
+		crdClient:   crdClient,
+		clusterName: clusterName,
+		reportsDir:  reportsDir,
+		names:       names,
+		resync:      resync,
+		lastRun:     make(map[string]time.Time),
+	}
+}
+
+// Run reconciles schedules until the context is cancelled. Cancellation
+// takes effect between reconciles, so a scan in progress completes and its
+// status is recorded.
+func (o *Operator) Run(ctx context.Context) error {
+	ticker := time.NewTicker(o.resync)
+	defer ticker.Stop()
+
+	for {
+		if err := o.reconcile(context.WithoutCancel(ctx)); err != nil {
+			log.Printf("operator: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// reconcile runs every schedule that is due
+func (o *Operator) reconcile(ctx context.Context) error {
+	schedules, err := o.crdClient.ListSchedules(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, sched := range schedules {
+		if sched.Err != nil {
+			if sched.Status.LastError != sched.Err.Error() {
+				o.updateStatus(ctx, sched.Name, crd.ScanScheduleStatus{LastError: sched.Err.Error()})
+			}
+			continue
+		}
+		if sched.Spec.Suspend {
+			continue
+		}
+
+		interval, err := sched.Spec.IntervalDuration()
+		if err != nil {
+			o.updateStatus(ctx, sched.Name, crd.ScanScheduleStatus{LastError: err.Error()})
+			continue
+		}
+
+		if !o.isDue(sched, interval, now) {
+			continue
+		}
+
+		o.lastRun[sched.Name] = now
+		status := o.runSchedule(ctx, sched)
+		o.updateStatus(ctx, sched.Name, status)
+	}
+	return nil
+}
+
+// isDue reports whether the schedule should run now, using the recorded
+// status so that restarts of the operator don't trigger an immediate rescan
+func (o *Operator) isDue(sched crd.ScanSchedule, interval time.Duration, now time.Time) bool {
+	last, ok := o.lastRun[sched.Name]
+	if !ok && sched.Status.LastScanTime != "" {
+		if t, err := time.Parse(time.RFC3339, sched.Status.LastScanTime); err == nil {
+			last = t
+		}
+	}
+	return now.Sub(last) >= interval
+}
+
+// runSchedule performs one scan for the schedule and returns the resulting status
+func (o *Operator) runSchedule(ctx context.Context, sched crd.ScanSchedule) crd.ScanScheduleStatus {
+	status := crd.ScanScheduleStatus{LastScanTime: time.Now().UTC().Format(time.RFC3339)}
+
+	opts, err := o.scanOptions(sched.Spec)
+	if err != nil {
+		status.LastError = err.Error()
+		return status
+	}
+	outdir, err := o.outdir(sched)
+	if err != nil {
+		status.LastError = err.Error()
+		return status
+	}
+
+	res, err := scanner.Run(ctx, opts)
+	if err != nil {
+		status.LastError = fmt.Sprintf("scan failed: %v", err)
+		return status
+	}
+	for _, w := range res.Warnings {
+		log.Printf("operator: schedule %s: %s", sched.Name, w)
+	}
+	issues, sum := res.Issues, res.Summary
+	status.LastIssueCount = int64(len(issues))
+
+	reportName := sched.Spec.ReportName
+	if reportName == "" {
+		reportName = sched.Name
+	}
+	if err := o.crdClient.WriteReport(ctx, reportName, o.clusterName, sched.Spec.Namespaces, issues, sum); err != nil {
+		status.LastError = err.Error()
+		return status
+	}
+
+	if kinds := exportKinds(sched.Spec.Export); len(kinds) > 0 {
+		previous, _ := report.LatestReport(outdir, o.clusterName)
+		report.TrackIssueAge(issues, previous)
+		base, err := o.names.Name(report.SanitizeClusterName(o.clusterName), time.Now())
+		if err != nil {
+			status.LastError = fmt.Sprintf("export failed: %v", err)
+			return status
+		}
+		overview, _ := capacity.FetchOverview(ctx, o.client)
+		if err := report.WriteAll(outdir, base, issues, sum, overview, &res.Meta, kinds); err != nil {
+			status.LastError = fmt.Sprintf("export failed: %v", err)
+		}
+	}
+
+	log.Printf("operator: schedule %s found %d issue(s)", sched.Name, len(issues))
+	return status
+}
+
+// scanOptions maps the spec of a schedule to scan options
+func (o *Operator) scanOptions(spec crd.ScanScheduleSpec) (scanner.Options, error) {
+	opts := scanner.Options{
+		Client:            o.client,
+		Cluster:           o.clusterName,
+		Namespaces:        spec.Namespaces,
+		IgnoredNamespaces: spec.IgnoreNamespaces,
+		RestartThreshold:  spec.RestartThreshold,
+		Reasons:           pod.ReasonFilter{Only: spec.OnlyReasons, Ignore: spec.IgnoreReasons},
+		NoEvents:          spec.NoEvents,
+		// Same default as the scan command's --escalate-after
+		EscalateAfter: 24 * time.Hour,
+		Preflight:     true,
+	}
+	durations := []struct {
+		field string
+		value string
+		dst   *time.Duration
+	}{
+		{"escalateAfter", spec.EscalateAfter, &opts.EscalateAfter},
+		{"pendingGrace", spec.PendingGrace, &opts.PendingGrace},
+		{"startupGrace", spec.StartupGrace, &opts.StartupGrace},
+		{"terminatingMargin", spec.TerminatingMargin, &opts.TerminatingMargin},
+		{"unreadyAfter", spec.UnreadyAfter, &opts.UnreadyAfter},
+		{"eventMaxAge", spec.EventMaxAge, &opts.EventMaxAge},
+		{"scannerTimeout", spec.ScannerTimeout, &opts.ScannerTimeout},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			return opts, fmt.Errorf("invalid %s %q: %w", d.field, d.value, err)
+		}
+		*d.dst = v
+	}
+	return opts, nil
+}
+
+// outdir resolves the outdir of a schedule, which must stay inside the
+// reports directory of the operator. Each schedule defaults to its own
+// subdirectory so that schedules don't share report history.
+func (o *Operator) outdir(sched crd.ScanSchedule) (string, error) {
+	dir := sched.Spec.Outdir
+	if dir == "" {
+		return filepath.Join(o.reportsDir, sched.Name), nil
+	}
+	if !filepath.IsLocal(dir) {
+		return "", fmt.Errorf("invalid outdir %q: must be a relative path inside the reports directory", dir)
+	}
+	return filepath.Join(o.reportsDir, dir), nil
+}
+
+func (o *Operator) updateStatus(ctx context.Context, name string, status crd.ScanScheduleStatus) {
+	if err := o.crdClient.UpdateScheduleStatus(ctx, name, status); err != nil {
+		log.Printf("operator: %v", err)
+	}
+}
+
+func exportKinds(formats []string) []report.ExportKind {
+	var out []report.ExportKind
+	for _, f := range formats {
+		if k, ok := report.ParseExportKind(f); ok {
+			out = append(out, k)
+		}
+	}
+	return out
+}
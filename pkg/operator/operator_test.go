@@ -0,0 +1,53 @@
+package operator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/crd"
+)
+
+func TestOutdir(t *testing.T) {
+	o := New(nil, nil, "prod", "/reports", nil, time.Minute)
+	tests := []struct {
+		outdir string
+		want   string
+	}{
+		{"", "/reports/nightly"},
+		{"team-a", "/reports/team-a"},
+		{"team-a/prod", "/reports/team-a/prod"},
+		{"./team-a/../team-b", "/reports/team-b"},
+	}
+	for _, tt := range tests {
+		got, err := o.outdir(crd.ScanSchedule{Name: "nightly", Spec: crd.ScanScheduleSpec{Outdir: tt.outdir}})
+		if err != nil || got != filepath.FromSlash(tt.want) {
+			t.Errorf("outdir %q = %q, %v, want %q", tt.outdir, got, err, tt.want)
+		}
+	}
+	for _, outdir := range []string{"/etc", "..", "../other", "team-a/../../etc"} {
+		if got, err := o.outdir(crd.ScanSchedule{Name: "nightly", Spec: crd.ScanScheduleSpec{Outdir: outdir}}); err == nil {
+			t.Errorf("outdir %q = %q, want an error", outdir, got)
+		}
+	}
+}
+
+func TestScanOptions(t *testing.T) {
+	o := New(nil, nil, "prod", "/reports", nil, time.Minute)
+	opts, err := o.scanOptions(crd.ScanScheduleSpec{Namespaces: []string{"shop"}, RestartThreshold: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Cluster != "prod" || len(opts.Namespaces) != 1 || opts.RestartThreshold != 5 || opts.EscalateAfter != 24*time.Hour {
+		t.Errorf("options = %+v", opts)
+	}
+
+	opts, err = o.scanOptions(crd.ScanScheduleSpec{EscalateAfter: "0"})
+	if err != nil || opts.EscalateAfter != 0 {
+		t.Errorf("escalateAfter 0 = %v, %v, want disabled", opts.EscalateAfter, err)
+	}
+	if _, err := o.scanOptions(crd.ScanScheduleSpec{EscalateAfter: "soon"}); err == nil || !strings.Contains(err.Error(), "invalid escalateAfter") {
+		t.Errorf("error = %v, want invalid escalateAfter", err)
+	}
+}
@@ -0,0 +1,143 @@
+// Package plugin runs external scanners shipped as executables.
+//
+// A plugin is any executable file in the plugins directory. For each scan it
+// is started once, receives a Request as JSON on stdin and must print a
+// Response as JSON on stdout before exiting with status 0. Anything written
+// to stderr is included in the error when the plugin fails. The cluster is
+// reached through the kubeconfig passed in the request (and $KUBECONFIG).
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/cmderr"
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// APIVersion identifies the request/response format
+const APIVersion = "scanner.ductnn.io/plugin/v1"
+
+// Request is written to the plugin's stdin
+type Request struct {
+	APIVersion string `json:"apiVersion"`
+	// Cluster is the cluster name used in the report
+	Cluster string `json:"cluster,omitempty"`
+	// Kubeconfig is the kubeconfig path given to the scanner ("" for the default)
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	// Namespaces to scan; empty means all namespaces
+	Namespaces        []string `json:"namespaces"`
+	IgnoredNamespaces []string `json:"ignoredNamespaces,omitempty"`
+}
+
+// Response is read from the plugin's stdout
+type Response struct {
+	Issues   []types.Issue `json:"issues"`
+	Warnings []string      `json:"warnings,omitempty"`
+}
+
+// Plugin is an executable scanner
+type Plugin struct {
+	// Name is the file name without extension
+	Name string
+	Path string
+}
+
+// Discover returns the executable files of dir, sorted by name. A missing
+// directory has no plugins.
+func Discover(dir string) ([]Plugin, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read plugins directory %s: %w", dir, err)
+	}
+	var plugins []Plugin
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || info.Mode()&0o111 == 0 {
+			continue
+		}
+		plugins = append(plugins, Plugin{
+			Name: strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())),
+			Path: filepath.Join(dir, e.Name()),
+		})
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins, nil
+}
+
+// Run executes the plugin. The plugin is killed when ctx is done. Issues
+// without a severity get the default one for their reason, issues with an
+// unknown severity fail the plugin, and issues without a kind are
+// attributed to the plugin.
+func (p Plugin) Run(ctx context.Context, req Request) (Response, error) {
+	req.APIVersion = APIVersion
+	if req.Namespaces == nil {
+		req.Namespaces = []string{}
+	}
+	in, err := json.Marshal(req)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, p.Path)
+	cmd.Stdin = bytes.NewReader(in)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Do not wait for children still holding stdout once the plugin is killed
+	cmd.WaitDelay = time.Second
+	cmd.Env = os.Environ()
+	if req.Kubeconfig != "" {
+		cmd.Env = append(cmd.Env, "KUBECONFIG="+req.Kubeconfig)
+	}
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return Response{}, fmt.Errorf("plugin %s: %w", p.Name, ctx.Err())
+		}
+		return Response{}, cmderr.Wrap("plugin "+p.Name, err, stderr.String())
+	}
+
+	var resp Response
+	dec := json.NewDecoder(&stdout)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&resp); err != nil {
+		return Response{}, fmt.Errorf("plugin %s returned an invalid response: %w", p.Name, err)
+	}
+	now := time.Now().Format(time.RFC3339)
+	for i := range resp.Issues {
+		is := &resp.Issues[i]
+		if is.Reason == "" {
+			return Response{}, fmt.Errorf("plugin %s returned an issue without a reason", p.Name)
+		}
+		if is.Severity == "" {
+			is.Severity = severity.FromReason(is.Reason)
+		} else if !is.Severity.Valid() {
+			// Unknown levels would be missing from every summary
+			return Response{}, fmt.Errorf("plugin %s returned an issue with invalid severity %q", p.Name, is.Severity)
+		}
+		if is.Kind == "" {
+			is.Kind = p.Name
+		}
+		if is.Timestamp == "" {
+			is.Timestamp = now
+		}
+		// IDs are assigned by the scanner
+		is.ID = ""
+	}
+	return resp, nil
+}
@@ -0,0 +1,95 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+)
+
+// writePlugin writes an executable shell script to dir
+func writePlugin(t *testing.T, dir, name, script string) Plugin {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return Plugin{Name: strings.TrimSuffix(name, filepath.Ext(name)), Path: path}
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "vendor-crd.sh", "")
+	writePlugin(t, dir, "argo", "")
+	writePlugin(t, dir, ".hidden", "")
+	if err := os.WriteFile(filepath.Join(dir, "README"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "lib"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins, err := Discover(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range plugins {
+		names = append(names, p.Name)
+	}
+	if strings.Join(names, ",") != "argo,vendor-crd" {
+		t.Errorf("plugins = %v, want argo, vendor-crd", names)
+	}
+
+	if plugins, err := Discover(filepath.Join(dir, "missing")); err != nil || len(plugins) != 0 {
+		t.Errorf("missing directory = %v, %v, want no plugins", plugins, err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	p := writePlugin(t, t.TempDir(), "vendor.sh", `cat > /dev/null
+echo '{"issues":[
+  {"namespace":"shop","name":"web","reason":"CrashLoopBackOff","id":"forged"},
+  {"namespace":"shop","name":"db","reason":"VendorCheck","severity":"HIGH","kind":"Database"}
+],"warnings":["partial"]}'
+`)
+	resp, err := p.Run(context.Background(), Request{Cluster: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Issues) != 2 || len(resp.Warnings) != 1 {
+		t.Fatalf("response = %+v, want 2 issues and a warning", resp)
+	}
+	web, db := resp.Issues[0], resp.Issues[1]
+	if web.Severity != severity.FromReason("CrashLoopBackOff") || web.Kind != "vendor" || web.ID != "" || web.Timestamp == "" {
+		t.Errorf("web = %+v, want the default severity, the plugin as kind, no ID and a timestamp", web)
+	}
+	if db.Severity != severity.High || db.Kind != "Database" {
+		t.Errorf("db = %+v, want high severity and kind Database", db)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"unknown severity", `echo '{"issues":[{"namespace":"shop","name":"web","reason":"X","severity":"urgent"}]}'`, `invalid severity "urgent"`},
+		{"no reason", `echo '{"issues":[{"namespace":"shop","name":"web"}]}'`, "without a reason"},
+		{"unknown field", `echo '{"findings":[]}'`, "invalid response"},
+		{"not json", `echo 'done'`, "invalid response"},
+		{"exit status", "echo 'loading' >&2\necho 'cannot reach vendor API' >&2\nexit 3", "plugin vendor failed: exit status 3: cannot reach vendor API"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := writePlugin(t, t.TempDir(), "vendor", "cat > /dev/null\n"+tt.script+"\n")
+			if _, err := p.Run(context.Background(), Request{}); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
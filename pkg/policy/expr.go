@@ -0,0 +1,99 @@
+package policy
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/ext"
+)
+
+// costLimit bounds the work of one evaluation, so a rule cannot stall the
+// scan on a large object (e.g. nested list macros over every container)
+const costLimit = 1_000_000
+
+// celEnv declares `object` for every expression, as a dynamic value: the
+// object is decoded from JSON and its fields depend on the kind
+var celEnv = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("object", cel.DynType),
+		cel.CrossTypeNumericComparisons(true),
+		ext.Strings(),
+	)
+})
+
+// Expression is a compiled CEL expression (https://github.com/google/cel-spec),
+// with the cel-go string extensions
+type Expression struct {
+	src string
+	prg cel.Program
+}
+
+// Compile parses and checks an expression
+func Compile(src string) (*Expression, error) {
+	env, err := celEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+	ast, iss := env.Compile(src)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", src, iss.Err())
+	}
+	prg, err := env.Program(ast, cel.CostLimit(costLimit))
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", src, err)
+	}
+	return &Expression{src: src, prg: prg}, nil
+}
+
+// String returns the source of the expression
+func (e *Expression) String() string {
+	return e.src
+}
+
+// Eval evaluates the expression with the given variables and returns its
+// value as a Go value
+func (e *Expression) Eval(vars map[string]any) (any, error) {
+	out, _, err := e.prg.Eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	return native(out), nil
+}
+
+// native converts a CEL value to a Go value, with lists as []any and maps
+// as map[any]any
+func native(v ref.Val) any {
+	switch v := v.(type) {
+	case traits.Lister:
+		var out []any
+		for it := v.Iterator(); it.HasNext() == types.True; {
+			out = append(out, native(it.Next()))
+		}
+		return out
+	case traits.Mapper:
+		out := map[any]any{}
+		for it := v.Iterator(); it.HasNext() == types.True; {
+			k := it.Next()
+			out[native(k)] = native(v.Get(k))
+		}
+		return out
+	}
+	return v.Value()
+}
+
+// EvalBool evaluates an expression that must return a bool
+func (e *Expression) EvalBool(vars map[string]any) (bool, error) {
+	v, err := e.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression %q returned %T, expected bool", e.src, v)
+	}
+	return b, nil
+}
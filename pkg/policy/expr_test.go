@@ -0,0 +1,136 @@
+package policy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	object := map[string]any{
+		"metadata": map[string]any{
+			"name":      "web",
+			"namespace": "prod-shop",
+			"labels":    map[string]any{"team": "payments"},
+		},
+		"spec": map[string]any{
+			"replicas": int64(1),
+			"containers": []any{
+				map[string]any{"name": "web", "image": "registry.example.com/web:1.2"},
+				map[string]any{"name": "proxy", "image": "docker.io/envoy:1.30"},
+			},
+		},
+		"status": map[string]any{"ratio": 0.5},
+	}
+	tests := []struct {
+		expr string
+		want any
+	}{
+		{`object.metadata.name == "web"`, true},
+		{`object.metadata["namespace"].startsWith("prod")`, true},
+		{`"team" in object.metadata.labels`, true},
+		{`has(object.metadata.annotations)`, false},
+		{`!has(object.metadata.labels) || !("team" in object.metadata.labels)`, false},
+		{`object.spec.replicas + 1 == 2`, true},
+		{`object.spec.replicas * 3 - 1`, int64(2)},
+		{`object.spec.replicas < 2 && object.status.ratio < 1`, true},
+		{`object.status.ratio == 0.5`, true},
+		{`true ? 1 : 2`, int64(1)},
+		{`object.spec.replicas > 1 ? "ha" : "single"`, "single"},
+		{`1 < 2 == true`, true},
+		{`size(object.spec.containers)`, int64(2)},
+		{`object.spec.containers[1].name`, "proxy"},
+		{`object.spec.containers.exists(c, !c.image.startsWith("registry.example.com/"))`, true},
+		{`object.spec.containers.all(c, c.image.contains(":"))`, true},
+		{`object.spec.containers.exists_one(c, c.name == "web")`, true},
+		{`object.spec.containers.filter(c, c.name != "web").map(c, c.name)`, []any{"proxy"}},
+		{`object.spec.containers[0].image.matches("^registry\\.example\\.com/")`, true},
+		{`object.metadata.name.upperAscii()`, "WEB"},
+		{`object.metadata.namespace.split("-")[1]`, "shop"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			got, err := e.Eval(map[string]any{"object": object})
+			if err != nil {
+				t.Fatalf("Eval: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`object.`,
+		`object.metadata.name ==`,
+		`(object.spec.replicas`,
+		`unknown.field`,
+		`"a" + 1`,
+		`size()`,
+	} {
+		if _, err := Compile(expr); err == nil {
+			t.Errorf("Compile(%q) succeeded, want an error", expr)
+		} else if !strings.Contains(err.Error(), "invalid expression") {
+			t.Errorf("Compile(%q) error %q does not name the expression", expr, err)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	object := map[string]any{"spec": map[string]any{"replicas": int64(1)}}
+	for _, expr := range []string{
+		`object.status.phase == "Running"`,
+		`object.spec.replicas.startsWith("1")`,
+		`object.spec.containers[0].name == "web"`,
+	} {
+		e, err := Compile(expr)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", expr, err)
+		}
+		if _, err := e.EvalBool(map[string]any{"object": object}); err == nil {
+			t.Errorf("EvalBool(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestEvalBoolType(t *testing.T) {
+	e, err := Compile(`object.spec.replicas`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.EvalBool(map[string]any{"object": map[string]any{"spec": map[string]any{"replicas": int64(3)}}})
+	if err == nil || !strings.Contains(err.Error(), "expected bool") {
+		t.Errorf("EvalBool error = %v, want expected bool", err)
+	}
+}
+
+func TestEvaluateRules(t *testing.T) {
+	set, err := NewSet([]Rule{
+		{Name: "single-replica", Kinds: []string{"Deployment"}, Expression: `has(object.spec.replicas) && object.spec.replicas < 2`, Severity: "high"},
+		{Name: "broken", Expression: `object.status.phase == "Running"`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := map[string]any{
+		"metadata": map[string]any{"name": "web", "namespace": "shop"},
+		"spec":     map[string]any{"replicas": int64(1)},
+	}
+	issues, errs := set.Evaluate("Deployment", obj)
+	if len(issues) != 1 || issues[0].Reason != "single-replica" || issues[0].Severity != "high" || issues[0].Namespace != "shop" {
+		t.Errorf("issues = %+v, want one single-replica issue", issues)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "rule broken on Deployment shop/web") {
+		t.Errorf("errs = %v, want the broken rule", errs)
+	}
+	if issues, _ := set.Evaluate("Pod", obj); len(issues) != 0 {
+		t.Errorf("rule for Deployment applied to a Pod: %+v", issues)
+	}
+}
@@ -0,0 +1,213 @@
+// Package policy evaluates user-defined rules against Kubernetes objects, so
+// platform teams can add org-specific checks without forking the scanner.
+//
+// A policy file lists rules whose expression is true for objects that
+// violate them:
+//
+//	rules:
+//	  - name: team-label
+//	    kinds: [Pod, Deployment]
+//	    expression: '!has(object.metadata.labels) || !("team" in object.metadata.labels)'
+//	    reason: MissingTeamLabel
+//	    severity: low
+//	    message: Workload không có label team
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// Rule is one check as written in a policy file
+type Rule struct {
+	Name string `json:"name"`
+	// Kinds the rule applies to (e.g. Pod, Deployment); empty means every kind
+	Kinds []string `json:"kinds,omitempty"`
+	// Expression returns true when the object violates the rule. The object
+	// is available as `object`.
+	Expression string `json:"expression"`
+	// Reason of the issues created (default: the rule name)
+	Reason   string `json:"reason,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	// Message becomes the issue's root cause
+	Message    string `json:"message,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// File is the content of a policy file
+type File struct {
+	Rules []Rule `json:"rules"`
+}
+
+// compiledRule is a validated Rule
+type compiledRule struct {
+	Rule
+	expr     *Expression
+	severity severity.Level
+}
+
+// Set is a list of compiled rules
+type Set struct {
+	rules []compiledRule
+}
+
+// Load reads a policy file, or every .yaml/.yml/.json file of a directory
+func Load(path string) (*Set, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy %s: %w", path, err)
+	}
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy directory %s: %w", path, err)
+		}
+		files = files[:0]
+		for _, e := range entries {
+			switch strings.ToLower(filepath.Ext(e.Name())) {
+			case ".yaml", ".yml", ".json":
+				if !e.IsDir() {
+					files = append(files, filepath.Join(path, e.Name()))
+				}
+			}
+		}
+	}
+
+	var rules []Rule
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy %s: %w", f, err)
+		}
+		var pf File
+		if err := yaml.UnmarshalStrict(b, &pf); err != nil {
+			return nil, fmt.Errorf("failed to parse policy %s: %w", f, err)
+		}
+		rules = append(rules, pf.Rules...)
+	}
+	set, err := NewSet(rules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", path, err)
+	}
+	return set, nil
+}
+
+// NewSet compiles rules
+func NewSet(rules []Rule) (*Set, error) {
+	set := &Set{}
+	for i, r := range rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("rules[%d]: name is required", i)
+		}
+		expr, err := Compile(r.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", r.Name, err)
+		}
+		level := severity.Medium
+		if r.Severity != "" {
+			if level, err = severity.Parse(r.Severity); err != nil {
+				return nil, fmt.Errorf("rule %s: %w", r.Name, err)
+			}
+		}
+		if r.Reason == "" {
+			r.Reason = r.Name
+		}
+		set.rules = append(set.rules, compiledRule{Rule: r, expr: expr, severity: level})
+	}
+	return set, nil
+}
+
+// Len returns the number of rules
+func (s *Set) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.rules)
+}
+
+// Targets reports whether any rule applies to kind
+func (s *Set) Targets(kind string) bool {
+	if s == nil {
+		return false
+	}
+	for _, r := range s.rules {
+		if r.appliesTo(kind) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r compiledRule) appliesTo(kind string) bool {
+	return len(r.Kinds) == 0 || slices.ContainsFunc(r.Kinds, func(k string) bool { return strings.EqualFold(k, kind) })
+}
+
+// Evaluate runs the rules for kind against obj (the object as decoded from
+// JSON) and returns an issue per violated rule. Rules that fail to evaluate
+// are returned as errors and do not stop the others.
+func (s *Set) Evaluate(kind string, obj map[string]any) ([]types.Issue, []error) {
+	if s == nil {
+		return nil, nil
+	}
+	var (
+		issues []types.Issue
+		errs   []error
+	)
+	vars := map[string]any{"object": obj}
+	meta, _ := obj["metadata"].(map[string]any)
+	namespace, _ := meta["namespace"].(string)
+	name, _ := meta["name"].(string)
+	timestamp := time.Now().Format(time.RFC3339)
+
+	for _, r := range s.rules {
+		if !r.appliesTo(kind) {
+			continue
+		}
+		violated, err := r.expr.EvalBool(vars)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %s on %s %s/%s: %w", r.Name, kind, namespace, name, err))
+			continue
+		}
+		if !violated {
+			continue
+		}
+		rootCause := r.Message
+		if rootCause == "" {
+			rootCause = "Vi phạm policy " + r.Name + "."
+		}
+		issues = append(issues, types.Issue{
+			Kind:       kind,
+			Namespace:  namespace,
+			Name:       name,
+			Severity:   r.severity,
+			Reason:     r.Reason,
+			RootCause:  rootCause,
+			Suggestion: r.Suggestion,
+			Timestamp:  timestamp,
+		})
+	}
+	return issues, errs
+}
+
+// EvaluateObject is Evaluate for a typed object
+func (s *Set) EvaluateObject(kind string, obj runtime.Object) ([]types.Issue, []error) {
+	if !s.Targets(kind) {
+		return nil, nil
+	}
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, []error{fmt.Errorf("failed to convert %s: %w", kind, err)}
+	}
+	return s.Evaluate(kind, u)
+}
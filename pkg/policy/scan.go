@@ -0,0 +1,146 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+)
+
+// lister lists the objects of one kind in a namespace ("" for all)
+type lister func(ctx context.Context, client kubernetes.Interface, namespace string) ([]runtime.Object, error)
+
+// listers are the kinds a live scan evaluates besides pods, which come
+// from the shared snapshot
+var listers = map[string]lister{
+	"Deployment": func(ctx context.Context, c kubernetes.Interface, ns string) ([]runtime.Object, error) {
+		l, err := c.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objects(l.Items), nil
+	},
+	"StatefulSet": func(ctx context.Context, c kubernetes.Interface, ns string) ([]runtime.Object, error) {
+		l, err := c.AppsV1().StatefulSets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objects(l.Items), nil
+	},
+	"DaemonSet": func(ctx context.Context, c kubernetes.Interface, ns string) ([]runtime.Object, error) {
+		l, err := c.AppsV1().DaemonSets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objects(l.Items), nil
+	},
+	"Job": func(ctx context.Context, c kubernetes.Interface, ns string) ([]runtime.Object, error) {
+		l, err := c.BatchV1().Jobs(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objects(l.Items), nil
+	},
+	"CronJob": func(ctx context.Context, c kubernetes.Interface, ns string) ([]runtime.Object, error) {
+		l, err := c.BatchV1().CronJobs(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objects(l.Items), nil
+	},
+	"Service": func(ctx context.Context, c kubernetes.Interface, ns string) ([]runtime.Object, error) {
+		l, err := c.CoreV1().Services(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objects(l.Items), nil
+	},
+}
+
+// objects converts a list of typed items to runtime objects
+func objects[T any, PT interface {
+	*T
+	runtime.Object
+}](items []T) []runtime.Object {
+	out := make([]runtime.Object, len(items))
+	for i := range items {
+		out[i] = PT(&items[i])
+	}
+	return out
+}
+
+// ScanCluster evaluates the rules against the pods of the snapshot and the
+// workloads and Services of its namespaces. Kinds that cannot be listed and
+// rules that fail to evaluate are returned as warnings.
+func ScanCluster(ctx context.Context, cs *k8s.ClusterSnapshot, ignoredNamespaces map[string]bool, set *Set) ([]types.Issue, []string, error) {
+	var (
+		issues   []types.Issue
+		warnings []string
+		evalErrs []error
+	)
+
+	if set.Targets("Pod") {
+		pods, _, err := cs.Pods(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range pods {
+			if ignoredNamespaces[pods[i].Namespace] {
+				continue
+			}
+			found, errs := set.EvaluateObject("Pod", &pods[i])
+			issues = append(issues, found...)
+			evalErrs = append(evalErrs, errs...)
+		}
+	}
+
+	namespaces := cs.ScopedNamespaces()
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	for _, kind := range []string{"Deployment", "StatefulSet", "DaemonSet", "Job", "CronJob", "Service"} {
+		if !set.Targets(kind) {
+			continue
+		}
+		for _, ns := range namespaces {
+			objs, err := listers[kind](ctx, cs.Client(), ns)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, nil, ctx.Err()
+				}
+				warnings = append(warnings, fmt.Sprintf("policy: failed to list %ss: %v", kind, err))
+				continue
+			}
+			for _, obj := range objs {
+				if m, ok := obj.(metav1.Object); ok && ignoredNamespaces[m.GetNamespace()] {
+					continue
+				}
+				found, errs := set.EvaluateObject(kind, obj)
+				issues = append(issues, found...)
+				evalErrs = append(evalErrs, errs...)
+			}
+		}
+	}
+	return issues, append(warnings, evalWarnings(evalErrs)...), nil
+}
+
+// maxEvalWarnings bounds how many evaluation errors become warnings, so a
+// broken rule does not produce one per object
+const maxEvalWarnings = 5
+
+func evalWarnings(errs []error) []string {
+	var out []string
+	for i, err := range errs {
+		if i == maxEvalWarnings {
+			out = append(out, fmt.Sprintf("policy: %d more rule evaluation error(s)", len(errs)-i))
+			break
+		}
+		out = append(out, "policy: "+err.Error())
+	}
+	return out
+}
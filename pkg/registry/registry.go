@@ -0,0 +1,408 @@
+// Package registry queries container registries (Docker Registry HTTP API
+// v2 / OCI distribution) to explain why an image cannot be pulled: the
+// image or tag does not exist, the registry requires credentials, or the
+// image has no variant for the node's platform.
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Status is the outcome of a manifest lookup
+type Status string
+
+const (
+	Found            Status = "Found"
+	NotFound         Status = "NotFound"
+	AuthRequired     Status = "AuthRequired"
+	PlatformMismatch Status = "PlatformMismatch"
+)
+
+// Platform is the OS/architecture of a node
+type Platform struct {
+	OS           string
+	Architecture string
+}
+
+func (p Platform) String() string {
+	return p.OS + "/" + p.Architecture
+}
+
+// Result describes an image on its registry
+type Result struct {
+	Status Status
+	// Platforms lists the platforms of a multi-arch image
+	Platforms []string
+}
+
+// Reference is a parsed image name
+type Reference struct {
+	Registry   string
+	Repository string
+	// Tag or Digest identifies the manifest; Digest wins when both are set
+	Tag    string
+	Digest string
+}
+
+// String returns registry/repository:tag
+func (r Reference) String() string {
+	s := r.Registry + "/" + r.Repository
+	if r.Digest != "" {
+		return s + "@" + r.Digest
+	}
+	return s + ":" + r.Tag
+}
+
+func (r Reference) ref() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+// ParseReference parses an image as written in a pod spec, applying the
+// docker.io, library/ and latest defaults
+func ParseReference(image string) (Reference, error) {
+	if image == "" || strings.ContainsAny(image, " \t") {
+		return Reference{}, fmt.Errorf("invalid image reference %q", image)
+	}
+	ref := Reference{Registry: "docker.io"}
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+	}
+	if i := strings.Index(name, "/"); i >= 0 {
+		host := name[:i]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			ref.Registry = host
+			name = name[i+1:]
+		}
+	}
+	if ref.Registry == "docker.io" && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	if name == "" {
+		return Reference{}, fmt.Errorf("invalid image reference %q", image)
+	}
+	ref.Repository = name
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+	return ref, nil
+}
+
+// manifestTypes are accepted when fetching manifests; indexes come first so
+// multi-arch images are returned as such
+var manifestTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// Client looks manifests up anonymously and caches the results
+type Client struct {
+	// HTTP is the client used for requests (default: http.DefaultClient)
+	HTTP *http.Client
+	// Timeout bounds each lookup, token request included
+	Timeout time.Duration
+	// AuthHosts are the token services trusted for any registry, besides
+	// the registry's own host (e.g. auth.docker.io). The realm of a
+	// WWW-Authenticate challenge is only requested on these hosts, so a
+	// registry cannot make the scanner fetch arbitrary URLs.
+	AuthHosts []string
+
+	mu    sync.Mutex
+	cache map[string]result
+}
+
+type result struct {
+	res Result
+	err error
+}
+
+// DefaultTimeout is the lookup timeout of NewClient
+const DefaultTimeout = 5 * time.Second
+
+// DefaultAuthHosts are the token services of public registries hosted
+// apart from the registry
+var DefaultAuthHosts = []string{"auth.docker.io"}
+
+// NewClient returns a Client with DefaultTimeout and DefaultAuthHosts
+func NewClient() *Client {
+	return &Client{Timeout: DefaultTimeout, AuthHosts: DefaultAuthHosts}
+}
+
+// Check looks image up on its registry. When platform is set and the image
+// is multi-arch, the variants are matched against it.
+func (c *Client) Check(ctx context.Context, image string, platform Platform) (Result, error) {
+	key := image + "|" + platform.String()
+	c.mu.Lock()
+	if r, ok := c.cache[key]; ok {
+		c.mu.Unlock()
+		return r.res, r.err
+	}
+	c.mu.Unlock()
+
+	res, err := c.check(ctx, image, platform)
+	// Cancellation says nothing about the image
+	if ctx.Err() == nil {
+		c.mu.Lock()
+		if c.cache == nil {
+			c.cache = map[string]result{}
+		}
+		c.cache[key] = result{res, err}
+		c.mu.Unlock()
+	}
+	return res, err
+}
+
+func (c *Client) check(ctx context.Context, image string, platform Platform) (Result, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return Result{}, err
+	}
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	host := ref.Registry
+	if host == "docker.io" {
+		host = "registry-1.docker.io"
+	}
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, ref.Repository, ref.ref())
+
+	// HEAD requests do not count against Docker Hub's pull rate limit
+	resp, err := c.do(ctx, http.MethodHead, manifestURL, "")
+	if err != nil {
+		return Result{}, err
+	}
+	token := ""
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		var status Status
+		token, status, err = c.token(ctx, host, challenge)
+		if err != nil {
+			return Result{}, err
+		}
+		if status != "" {
+			return Result{Status: status}, nil
+		}
+		if resp, err = c.do(ctx, http.MethodHead, manifestURL, token); err != nil {
+			return Result{}, err
+		}
+	}
+	resp.Body.Close()
+	if res, ok := statusResult(resp); !ok {
+		return Result{}, fmt.Errorf("registry %s returned %s", ref.Registry, resp.Status)
+	} else if res.Status != Found {
+		return res, nil
+	}
+
+	mediaType, _, _ := strings.Cut(resp.Header.Get("Content-Type"), ";")
+	if platform.Architecture == "" || !strings.Contains(mediaType, "index") && !strings.Contains(mediaType, "manifest.list") {
+		// A single-platform manifest only names its platform in the config
+		// blob, which is not fetched
+		return Result{Status: Found}, nil
+	}
+	if resp, err = c.do(ctx, http.MethodGet, manifestURL, token); err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+	if res, ok := statusResult(resp); !ok {
+		return Result{}, fmt.Errorf("registry %s returned %s", ref.Registry, resp.Status)
+	} else if res.Status != Found {
+		return res, nil
+	}
+
+	var index struct {
+		MediaType string `json:"mediaType"`
+		Manifests []struct {
+			Platform *struct {
+				OS           string `json:"os"`
+				Architecture string `json:"architecture"`
+			} `json:"platform"`
+		} `json:"manifests"`
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to read manifest of %s: %w", ref, err)
+	}
+	if err := json.Unmarshal(body, &index); err != nil {
+		return Result{}, fmt.Errorf("failed to decode manifest of %s: %w", ref, err)
+	}
+	res := Result{Status: Found}
+	matched := false
+	for _, m := range index.Manifests {
+		if m.Platform == nil || m.Platform.OS == "unknown" {
+			// Attestations
+			continue
+		}
+		p := Platform{OS: m.Platform.OS, Architecture: m.Platform.Architecture}
+		res.Platforms = append(res.Platforms, p.String())
+		if p.Architecture == platform.Architecture && (platform.OS == "" || p.OS == platform.OS) {
+			matched = true
+		}
+	}
+	if !matched && len(res.Platforms) > 0 {
+		res.Status = PlatformMismatch
+	}
+	return res, nil
+}
+
+// statusResult maps the status code of a manifest request; ok is false for
+// unexpected codes
+func statusResult(resp *http.Response) (Result, bool) {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return Result{Status: Found}, true
+	case http.StatusNotFound:
+		return Result{Status: NotFound}, true
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return Result{Status: AuthRequired}, true
+	}
+	return Result{}, false
+}
+
+// do requests a manifest
+func (c *Client) do(ctx context.Context, method, u, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestTypes, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach registry: %w", err)
+	}
+	return resp, nil
+}
+
+// token requests an anonymous bearer token for a WWW-Authenticate
+// challenge of the registry at host. A registry that refuses anonymous
+// access returns AuthRequired.
+func (c *Client) token(ctx context.Context, host, challenge string) (string, Status, error) {
+	scheme, params := parseChallenge(challenge)
+	if !strings.EqualFold(scheme, "bearer") || params["realm"] == "" {
+		// Basic auth: credentials are required
+		return "", AuthRequired, nil
+	}
+	u, err := url.Parse(params["realm"])
+	if err != nil {
+		return "", "", fmt.Errorf("invalid token realm %q: %w", params["realm"], err)
+	}
+	if err := c.checkRealm(host, u); err != nil {
+		return "", "", err
+	}
+	q := u.Query()
+	for _, k := range []string{"service", "scope"} {
+		if params[k] != "" {
+			q.Set(k, params[k])
+		}
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", "", err
+	}
+	// Redirects must not take the request off the trusted hosts either
+	hc := *c.httpClient()
+	hc.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return c.checkRealm(host, req.URL)
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to reach token service: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return "", AuthRequired, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("token service returned %s", resp.Status)
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", "", fmt.Errorf("failed to decode token: %w", err)
+	}
+	if body.Token == "" {
+		body.Token = body.AccessToken
+	}
+	if body.Token == "" {
+		return "", "", errors.New("token service returned no token")
+	}
+	return body.Token, "", nil
+}
+
+// checkRealm accepts a token URL of the registry at host: a plain https
+// URL on the registry itself or on one of AuthHosts
+func (c *Client) checkRealm(host string, u *url.URL) error {
+	if u.Scheme != "https" || u.User != nil {
+		return fmt.Errorf("token realm %q of registry %s is not a plain https URL", u.Redacted(), host)
+	}
+	if !strings.EqualFold(u.Host, host) && !slices.ContainsFunc(c.AuthHosts, func(h string) bool { return strings.EqualFold(h, u.Host) }) {
+		return fmt.Errorf("token realm %q is not on registry %s or a trusted auth host", u.Redacted(), host)
+	}
+	return nil
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTP != nil {
+		return c.HTTP
+	}
+	return http.DefaultClient
+}
+
+// parseChallenge parses `Bearer realm="...",service="...",scope="..."`
+func parseChallenge(h string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(h), " ")
+	params := map[string]string{}
+	for rest != "" {
+		var kv string
+		// Values are quoted and may contain commas (scope lists)
+		key, after, ok := strings.Cut(strings.TrimLeft(rest, " ,"), "=")
+		if !ok {
+			break
+		}
+		if strings.HasPrefix(after, `"`) {
+			end := strings.Index(after[1:], `"`)
+			if end < 0 {
+				kv, rest = after[1:], ""
+			} else {
+				kv, rest = after[1:end+1], after[end+2:]
+			}
+		} else {
+			kv, rest, _ = strings.Cut(after, ",")
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = kv
+	}
+	return scheme, params
+}
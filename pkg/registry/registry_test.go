@@ -0,0 +1,129 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		image string
+		want  Reference
+	}{
+		{"nginx", Reference{Registry: "docker.io", Repository: "library/nginx", Tag: "latest"}},
+		{"nginx:1.27", Reference{Registry: "docker.io", Repository: "library/nginx", Tag: "1.27"}},
+		{"bitnami/redis:7", Reference{Registry: "docker.io", Repository: "bitnami/redis", Tag: "7"}},
+		{"ghcr.io/org/app:v1", Reference{Registry: "ghcr.io", Repository: "org/app", Tag: "v1"}},
+		{"localhost:5000/app", Reference{Registry: "localhost:5000", Repository: "app", Tag: "latest"}},
+		{"registry.example.com:443/team/app@sha256:abc", Reference{Registry: "registry.example.com:443", Repository: "team/app", Digest: "sha256:abc"}},
+		{"app:v1@sha256:abc", Reference{Registry: "docker.io", Repository: "library/app", Tag: "v1", Digest: "sha256:abc"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseReference(tt.image)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseReference(%q) = %+v, %v, want %+v", tt.image, got, err, tt.want)
+		}
+	}
+	for _, image := range []string{"", "bad image", "ghcr.io/"} {
+		if _, err := ParseReference(image); err == nil {
+			t.Errorf("ParseReference(%q) succeeded, want an error", image)
+		}
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull,push"`)
+	if scheme != "Bearer" || params["realm"] != "https://auth.docker.io/token" || params["service"] != "registry.docker.io" || params["scope"] != "repository:library/nginx:pull,push" {
+		t.Errorf("parseChallenge = %q, %v", scheme, params)
+	}
+}
+
+// registry serves a manifest behind a token service on the same host,
+// whose realm and token endpoint are set by the test
+type registry struct {
+	srv   *httptest.Server
+	realm string
+	token http.HandlerFunc
+}
+
+func newRegistry(t *testing.T) *registry {
+	r := &registry{}
+	r.srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path == "/token":
+			r.token(w, req)
+		case req.Header.Get("Authorization") != "Bearer secret":
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+r.realm+`",service="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		case strings.HasSuffix(req.URL.Path, "/manifests/v1"):
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(r.srv.Close)
+	r.realm = r.srv.URL + "/token"
+	r.token = func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"token":"secret"}`))
+	}
+	return r
+}
+
+func (r *registry) check(image string) (Result, error) {
+	c := &Client{HTTP: r.srv.Client(), AuthHosts: DefaultAuthHosts}
+	host := strings.TrimPrefix(r.srv.URL, "https://")
+	return c.Check(context.Background(), host+"/"+image, Platform{})
+}
+
+func TestCheck(t *testing.T) {
+	r := newRegistry(t)
+	if res, err := r.check("app:v1"); err != nil || res.Status != Found {
+		t.Errorf("app:v1 = %+v, %v, want Found", res, err)
+	}
+	if res, err := r.check("app:v2"); err != nil || res.Status != NotFound {
+		t.Errorf("app:v2 = %+v, %v, want NotFound", res, err)
+	}
+
+	r.token = func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusUnauthorized) }
+	if res, err := r.check("private:v1"); err != nil || res.Status != AuthRequired {
+		t.Errorf("private:v1 = %+v, %v, want AuthRequired", res, err)
+	}
+}
+
+func TestCheckUntrustedRealm(t *testing.T) {
+	tests := []struct {
+		name  string
+		realm func(r *registry) string
+		want  string
+	}{
+		{"other host", func(*registry) string { return "https://attacker.example.com/token" }, "not on registry"},
+		{"plain http", func(r *registry) string { return strings.Replace(r.srv.URL, "https://", "http://", 1) + "/token" }, "not a plain https URL"},
+		{"userinfo", func(r *registry) string {
+			return strings.Replace(r.srv.URL, "https://", "https://user:pass@", 1) + "/token"
+		}, "not a plain https URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRegistry(t)
+			r.realm = tt.realm(r)
+			r.token = func(w http.ResponseWriter, _ *http.Request) { t.Error("token service called") }
+			if _, err := r.check("app:v1"); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckTokenRedirect(t *testing.T) {
+	r := newRegistry(t)
+	r.token = func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, "https://attacker.example.com/token?"+url.Values{"service": {"test"}}.Encode(), http.StatusFound)
+	}
+	if _, err := r.check("app:v1"); err == nil || !strings.Contains(err.Error(), "not on registry") {
+		t.Errorf("error = %v, want the redirect refused", err)
+	}
+}
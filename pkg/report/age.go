@@ -0,0 +1,131 @@
+package report
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// LatestReport loads the most recent JSON report of cluster in outdir, so
+// clusters sharing an outdir do not age each other's issues. An empty
+// cluster matches any report. It returns nil without error when there is no
+// previous report.
+func LatestReport(outdir, cluster string) (*ReportData, error) {
+	reports, err := ListHistory(outdir)
+	if err != nil {
+		return nil, nil
+	}
+	reports = FilterHistory(reports, HistoryFilter{Cluster: cluster})
+	if len(reports) == 0 {
+		return nil, nil
+	}
+	return LoadReport(reports[0].Path)
+}
+
+// TrackIssueAge sets FirstSeen/LastSeen on issues by correlating them with the
+// previous report. Issues that were not in the previous report start now.
+func TrackIssueAge(issues []types.Issue, previous *ReportData) {
+	NewAgeTracker(previous).Track(issues)
+}
+
+// AgeTracker remembers when the issues of a previous report were first seen,
+// so streamed batches of issues can be aged without reloading it
+type AgeTracker map[string]string
+
+// NewAgeTracker indexes the previous report (which may be nil)
+func NewAgeTracker(previous *ReportData) AgeTracker {
+	firstSeen := make(AgeTracker)
+	if previous != nil {
+		for _, issue := range previous.Issues {
+			seen := issue.FirstSeen
+			if seen == "" {
+				seen = issue.Timestamp
+			}
+			firstSeen[issueKey(issue)] = seen
+		}
+	}
+	return firstSeen
+}
+
+// Track sets FirstSeen/LastSeen on issues
+func (t AgeTracker) Track(issues []types.Issue) {
+	for i := range issues {
+		issues[i].LastSeen = issues[i].Timestamp
+		if seen, ok := t[issueKey(issues[i])]; ok && seen != "" {
+			issues[i].FirstSeen = seen
+		} else {
+			issues[i].FirstSeen = issues[i].Timestamp
+		}
+	}
+}
+
+// IssueAge returns how long an issue has persisted, based on FirstSeen/LastSeen
+func IssueAge(issue types.Issue) time.Duration {
+	first, err := time.Parse(time.RFC3339, issue.FirstSeen)
+	if err != nil {
+		return 0
+	}
+	last, err := time.Parse(time.RFC3339, issue.LastSeen)
+	if err != nil {
+		last = time.Now()
+	}
+	return last.Sub(first)
+}
+
+// StateDuration returns how long the issue's container has been in its
+// current state at scan time, or 0 when unknown
+func StateDuration(issue types.Issue) time.Duration {
+	since, err := time.Parse(time.RFC3339, issue.InStateSince)
+	if err != nil {
+		return 0
+	}
+	at, err := time.Parse(time.RFC3339, issue.Timestamp)
+	if err != nil {
+		at = time.Now()
+	}
+	return at.Sub(since)
+}
+
+// FormatAge renders a duration as a compact age such as 3d4h or 25m
+func FormatAge(d time.Duration) string {
+	if d < time.Minute {
+		return "new"
+	}
+	d = d.Round(time.Minute)
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd%dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+// ParseAge parses a duration that may be given in days, such as 7d or 1d12h
+func ParseAge(s string) (time.Duration, error) {
+	rest := strings.TrimSpace(s)
+	var days time.Duration
+	if d, after, ok := strings.Cut(rest, "d"); ok {
+		n, err := strconv.Atoi(d)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q (e.g. 7d, 12h, 1d12h)", s)
+		}
+		days = time.Duration(n) * 24 * time.Hour
+		if after == "" {
+			return days, nil
+		}
+		rest = after
+	}
+	d, err := time.ParseDuration(rest)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q (e.g. 7d, 12h, 1d12h)", s)
+	}
+	return days + d, nil
+}
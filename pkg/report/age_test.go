@@ -0,0 +1,84 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+func TestTrackIssueAge(t *testing.T) {
+	previous := &ReportData{Issues: []types.Issue{
+		{Namespace: "shop", Kind: "Pod", Name: "web", Timestamp: "2024-05-01T10:00:00Z", FirstSeen: "2024-04-28T08:00:00Z"},
+		// Reports written before age tracking have no FirstSeen
+		{Namespace: "shop", Kind: "Pod", Name: "api", Timestamp: "2024-05-01T10:00:00Z"},
+		{Namespace: "shop", Kind: "Pod", Name: "gone", Timestamp: "2024-05-01T10:00:00Z", FirstSeen: "2024-04-01T00:00:00Z"},
+	}}
+	issues := []types.Issue{
+		{Namespace: "shop", Kind: "Pod", Name: "web", Timestamp: "2024-05-02T10:00:00Z"},
+		{Namespace: "shop", Kind: "Pod", Name: "api", Timestamp: "2024-05-02T10:00:00Z"},
+		{Namespace: "shop", Kind: "Pod", Name: "new", Timestamp: "2024-05-02T10:00:00Z"},
+	}
+	TrackIssueAge(issues, previous)
+
+	want := map[string]string{
+		"web": "2024-04-28T08:00:00Z",
+		"api": "2024-05-01T10:00:00Z",
+		"new": "2024-05-02T10:00:00Z",
+	}
+	for _, is := range issues {
+		if is.FirstSeen != want[is.Name] {
+			t.Errorf("%s: FirstSeen = %s, want %s", is.Name, is.FirstSeen, want[is.Name])
+		}
+		if is.LastSeen != is.Timestamp {
+			t.Errorf("%s: LastSeen = %s, want the scan time %s", is.Name, is.LastSeen, is.Timestamp)
+		}
+	}
+	if got := IssueAge(issues[0]); got != 4*24*time.Hour+2*time.Hour {
+		t.Errorf("IssueAge = %v, want 4d2h", got)
+	}
+
+	// Without a previous report every issue is new
+	fresh := []types.Issue{{Namespace: "shop", Kind: "Pod", Name: "web", Timestamp: "2024-05-02T10:00:00Z"}}
+	TrackIssueAge(fresh, nil)
+	if fresh[0].FirstSeen != fresh[0].Timestamp || IssueAge(fresh[0]) != 0 {
+		t.Errorf("without a previous report: %+v, want first seen now", fresh[0])
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                            "new",
+		59 * time.Second:             "new",
+		25 * time.Minute:             "25m",
+		3*time.Hour + 5*time.Minute:  "3h5m",
+		24 * time.Hour:               "1d0h",
+		3*24*time.Hour + 4*time.Hour: "3d4h",
+		2*time.Hour + 59*time.Minute + 40*time.Second: "3h0m",
+	}
+	for d, want := range tests {
+		if got := FormatAge(d); got != want {
+			t.Errorf("FormatAge(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := map[string]time.Duration{
+		"7d":    7 * 24 * time.Hour,
+		"12h":   12 * time.Hour,
+		"1d12h": 36 * time.Hour,
+		"90m":   90 * time.Minute,
+		" 2d ":  48 * time.Hour,
+	}
+	for s, want := range tests {
+		if got, err := ParseAge(s); err != nil || got != want {
+			t.Errorf("ParseAge(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "d", "-1d", "xd", "1dx", "-2h", "7days"} {
+		if _, err := ParseAge(s); err == nil {
+			t.Errorf("ParseAge(%q) succeeded, want an error", s)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package report
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ArchiveDir is the directory of the reports directory holding archives
+const ArchiveDir = "archive"
+
+// GzipFile compresses path to path.gz and removes the original
+func GzipFile(path string) (err error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	defer in.Close()
+	out, err := os.Create(path + ".gz")
+	if err != nil {
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("failed to compress %s: %w", path, cerr)
+		}
+	}()
+	zw := gzip.NewWriter(out)
+	zw.Name = filepath.Base(path)
+	if _, err := io.Copy(zw, in); err != nil {
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	in.Close()
+	return os.Remove(path)
+}
+
+// readReportFile reads a report, decompressing it when its name ends in .gz
+func readReportFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if !strings.HasSuffix(path, ".gz") {
+		return io.ReadAll(f)
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// exportTimestamp matches the timestamp in the names of exported files
+var exportTimestamp = regexp.MustCompile(`k8s-(?:report|inventory|diff)-(\d{8}-\d{6})\.`)
+
+// Archive bundles the exported files of outdir written before the given time
+// into a tar.gz in outdir/archive and removes them, so history only lists
+// recent reports. Files in subdirectories (see --filename-template) whose
+// names carry no timestamp are dated by modification time. It returns the
+// archive path and the number of files archived; nothing is written when no
+// file is old enough.
+func Archive(outdir string, before time.Time) (string, int, error) {
+	if _, err := os.ReadDir(outdir); err != nil {
+		return "", 0, fmt.Errorf("failed to read reports directory: %w", err)
+	}
+	var files []string
+	err := filepath.WalkDir(outdir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path == filepath.Join(outdir, ArchiveDir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		name, _ := filepath.Rel(outdir, path)
+		var at time.Time
+		if m := exportTimestamp.FindStringSubmatch(entry.Name()); m != nil {
+			at, err = time.ParseInLocation("20060102-150405", m[1], time.Local)
+			if err != nil {
+				return nil
+			}
+		} else if name != entry.Name() && isExportFile(entry.Name()) {
+			info, err := entry.Info()
+			if err != nil {
+				return nil
+			}
+			at = info.ModTime()
+		} else {
+			return nil
+		}
+		if at.Before(before) {
+			files = append(files, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read reports directory: %w", err)
+	}
+	if len(files) == 0 {
+		return "", 0, nil
+	}
+
+	dir := filepath.Join(outdir, ArchiveDir)
+	if err := EnsureDir(dir); err != nil {
+		return "", 0, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("reports-before-%s.tar.gz", before.Format("20060102")))
+	if _, err := os.Stat(path); err == nil {
+		return "", 0, fmt.Errorf("archive %s already exists", path)
+	}
+	if err := writeArchive(path, outdir, files); err != nil {
+		os.Remove(path)
+		return "", 0, err
+	}
+	for _, name := range files {
+		if err := os.Remove(filepath.Join(outdir, name)); err != nil {
+			return path, 0, fmt.Errorf("failed to remove archived report: %w", err)
+		}
+	}
+	return path, len(files), nil
+}
+
+// isExportFile reports whether name has the extension of an exported file
+func isExportFile(name string) bool {
+	name = strings.TrimSuffix(name, ".gz")
+	for _, k := range []ExportKind{ExportJSON, ExportNDJSON, ExportCSV, ExportMD, ExportHTML} {
+		if strings.HasSuffix(name, "."+string(k)) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeArchive(path, outdir string, files []string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer out.Close()
+	zw := gzip.NewWriter(out)
+	tw := tar.NewWriter(zw)
+	for _, name := range files {
+		if err := addToArchive(tw, outdir, name); err != nil {
+			return fmt.Errorf("failed to archive %s: %w", name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+func addToArchive(tw *tar.Writer, outdir, name string) error {
+	path := filepath.Join(outdir, name)
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	hdr.Name = filepath.ToSlash(name)
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// ParseBefore parses the --before of an archive: a month (2024-01), a day
+// (2024-01-15) or an RFC 3339 time, in local time
+func ParseBefore(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q (expected 2024-01, 2024-01-15 or an RFC 3339 time)", s)
+}
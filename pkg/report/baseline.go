@@ -0,0 +1,125 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// BaselineFile is the name of the baseline in the reports directory
+const BaselineFile = "baseline.json"
+
+// Baseline is the set of findings accepted with `k8s-scanner baseline save`.
+// Scans then only report issues that are not in it, which makes the scanner
+// usable on clusters with a long tail of known problems.
+type Baseline struct {
+	// File is the path the baseline was loaded from
+	File      string        `json:"-"`
+	CreatedAt string        `json:"created_at"`
+	Cluster   string        `json:"cluster,omitempty"`
+	Issues    []types.Issue `json:"issues"`
+}
+
+// BaselineInfo describes the baseline applied to a report
+type BaselineInfo struct {
+	File      string `json:"file"`
+	CreatedAt string `json:"created_at"`
+	// Accepted counts the baseline issues still present, which were hidden
+	Accepted int `json:"accepted"`
+	// Resolved lists the baseline issues that are gone
+	Resolved []types.Issue `json:"resolved"`
+}
+
+// SaveBaseline writes issues as the accepted baseline of cluster
+func SaveBaseline(path, cluster string, issues []types.Issue) error {
+	b := Baseline{
+		CreatedAt: time.Now().Format(time.RFC3339),
+		Cluster:   cluster,
+		Issues:    issues,
+	}
+	if b.Issues == nil {
+		b.Issues = []types.Issue{}
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := EnsureDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// LoadBaseline reads a baseline written by SaveBaseline
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	b.File = path
+	return &b, nil
+}
+
+// BaselineFilter hides the issues of a baseline and remembers which of them
+// were seen, so the ones that disappeared can be reported as resolved. It can
+// be applied to a whole scan or to successive streamed batches.
+type BaselineFilter struct {
+	baseline *Baseline
+	accepted map[string]bool
+	seen     map[string]bool
+}
+
+// NewBaselineFilter indexes the baseline
+func NewBaselineFilter(b *Baseline) *BaselineFilter {
+	f := &BaselineFilter{
+		baseline: b,
+		accepted: make(map[string]bool, len(b.Issues)),
+		seen:     map[string]bool{},
+	}
+	for _, issue := range b.Issues {
+		f.accepted[issueKey(issue)] = true
+	}
+	return f
+}
+
+// Apply returns the issues that are not in the baseline
+func (f *BaselineFilter) Apply(issues []types.Issue) []types.Issue {
+	kept := make([]types.Issue, 0, len(issues))
+	for _, issue := range issues {
+		key := issueKey(issue)
+		if f.accepted[key] {
+			f.seen[key] = true
+			continue
+		}
+		kept = append(kept, issue)
+	}
+	return kept
+}
+
+// Info summarizes the filtered issues once every issue went through Apply
+func (f *BaselineFilter) Info() *BaselineInfo {
+	info := &BaselineInfo{
+		File:      f.baseline.File,
+		CreatedAt: f.baseline.CreatedAt,
+		Accepted:  len(f.seen),
+		Resolved:  []types.Issue{},
+	}
+	for _, issue := range f.baseline.Issues {
+		if !f.seen[issueKey(issue)] {
+			info.Resolved = append(info.Resolved, issue)
+		}
+	}
+	SortIssues(info.Resolved)
+	return info
+}
@@ -0,0 +1,117 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// csvRequired are the columns needed to match issues across reports
+var csvRequired = []string{"namespace", "name", "reason"}
+
+// LoadCSVReport reads a CSV export (or a third-party CSV with the same column
+// names) as a report. Columns are matched by header name, so exports from
+// older versions with fewer columns load too; kind defaults to Pod. CSV
+// exports carry no issue IDs or generation time: the file modification time
+// is used instead.
+func LoadCSVReport(path string) (*ReportData, error) {
+	data, err := readReportFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report file: %w", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse report CSV %s: %w", path, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("failed to parse report CSV %s: no header", path)
+	}
+
+	col := map[string]int{}
+	for i, h := range rows[0] {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, name := range csvRequired {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("failed to parse report CSV %s: missing column %q", path, name)
+		}
+	}
+
+	rep := &ReportData{Issues: []types.Issue{}, Summary: map[string]types.SeveritySummary{}}
+	if info, err := os.Stat(path); err == nil {
+		rep.GeneratedAt = info.ModTime().Format(time.RFC3339)
+	}
+	for n, row := range rows[1:] {
+		get := func(name string) string {
+			if i, ok := col[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		is := types.Issue{
+			Kind:          get("kind"),
+			Namespace:     get("namespace"),
+			Name:          get("name"),
+			Container:     get("container"),
+			PodStatus:     get("pod_status"),
+			Reason:        get("reason"),
+			CheckID:       get("check_id"),
+			RootCause:     get("root_cause"),
+			Suggestion:    get("suggestion"),
+			Timestamp:     get("timestamp"),
+			NodeName:      get("node_name"),
+			NodeCondition: get("node_condition"),
+			NodePool:      get("node_pool"),
+			InstanceType:  get("instance_type"),
+			Zone:          get("zone"),
+			Team:          get("team"),
+			LastEvent:     get("last_event"),
+			FirstSeen:     get("first_seen"),
+		}
+		if is.Kind == "" {
+			is.Kind = "Pod"
+		}
+		if s := get("impacted_services"); s != "" {
+			is.ImpactedServices = strings.Split(s, ";")
+		}
+		if s := get("labels"); s != "" {
+			is.Labels = map[string]string{}
+			for _, pair := range strings.Split(s, ";") {
+				if k, v, ok := strings.Cut(pair, "="); ok {
+					is.Labels[k] = v
+				}
+			}
+		}
+		if s := get("restart_count"); s != "" {
+			restarts, err := strconv.ParseInt(s, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse report CSV %s: line %d: invalid restart_count %q", path, n+2, s)
+			}
+			is.RestartCount = int32(restarts)
+		}
+		if s := get("severity"); s != "" {
+			level, err := severity.Parse(s)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse report CSV %s: line %d: %w", path, n+2, err)
+			}
+			is.Severity = level
+		} else {
+			is.Severity = severity.FromReason(is.Reason)
+		}
+		rep.Issues = append(rep.Issues, is)
+
+		rep.Summary[is.Namespace] = addSeverity(rep.Summary[is.Namespace], is.Severity, 1)
+	}
+	return rep, nil
+}
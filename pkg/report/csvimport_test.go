@@ -0,0 +1,137 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// writeCSV writes content to a file of a temporary directory
+func writeCSV(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "report.csv")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadCSVReport(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want []types.Issue
+	}{
+		{
+			name: "export",
+			csv: "\xEF\xBB\xBFtimestamp,namespace,kind,name,container,severity,reason,restart_count,impacted_services,labels\n" +
+				"2024-01-01T00:00:00Z,shop,Pod,web-0,app,high,CrashLoopBackOff,12,web;web-canary,app=web;tier=front\n",
+			want: []types.Issue{{
+				Timestamp: "2024-01-01T00:00:00Z", Namespace: "shop", Kind: "Pod", Name: "web-0", Container: "app",
+				Severity: severity.High, Reason: "CrashLoopBackOff", RestartCount: 12,
+				ImpactedServices: []string{"web", "web-canary"}, Labels: map[string]string{"app": "web", "tier": "front"},
+			}},
+		},
+		{
+			name: "third-party columns in any order and case",
+			csv:  "Reason, NAME ,Namespace,extra\nOOMKilled,batch-0,jobs,ignored\n",
+			want: []types.Issue{{Namespace: "jobs", Kind: "Pod", Name: "batch-0", Reason: "OOMKilled", Severity: severity.FromReason("OOMKilled")}},
+		},
+		{
+			name: "short rows",
+			csv:  "namespace,name,reason,container\nshop,web-0,Evicted\n",
+			want: []types.Issue{{Namespace: "shop", Kind: "Pod", Name: "web-0", Reason: "Evicted", Severity: severity.Medium}},
+		},
+		{
+			name: "header only",
+			csv:  "namespace,name,reason\n",
+			want: []types.Issue{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rep, err := LoadCSVReport(writeCSV(t, tt.csv))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rep.Issues) != len(tt.want) {
+				t.Fatalf("got %d issues, want %d: %+v", len(rep.Issues), len(tt.want), rep.Issues)
+			}
+			for i, want := range tt.want {
+				got := rep.Issues[i]
+				if got.Namespace != want.Namespace || got.Kind != want.Kind || got.Name != want.Name || got.Container != want.Container ||
+					got.Reason != want.Reason || got.Severity != want.Severity || got.RestartCount != want.RestartCount ||
+					strings.Join(got.ImpactedServices, ";") != strings.Join(want.ImpactedServices, ";") ||
+					FormatLabels(got.Labels, ";") != FormatLabels(want.Labels, ";") {
+					t.Errorf("issue %d = %+v, want %+v", i, got, want)
+				}
+			}
+			if rep.GeneratedAt == "" {
+				t.Error("GeneratedAt is empty, want the file modification time")
+			}
+		})
+	}
+}
+
+func TestLoadCSVReportSummary(t *testing.T) {
+	rep, err := LoadCSVReport(writeCSV(t, "namespace,name,reason,severity\nshop,a,CrashLoopBackOff,\nshop,b,Evicted,critical\nbatch,c,OOMKilled,\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]types.SeveritySummary{"shop": {Critical: 1, High: 1}, "batch": {Medium: 1}}
+	for ns, w := range want {
+		if rep.Summary[ns] != w {
+			t.Errorf("summary of %s = %+v, want %+v", ns, rep.Summary[ns], w)
+		}
+	}
+}
+
+func TestLoadCSVReportErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want string
+	}{
+		{"empty file", "", "no header"},
+		{"missing namespace", "name,reason\nweb-0,Evicted\n", `missing column "namespace"`},
+		{"missing reason", "namespace,name,severity\nshop,web-0,high\n", `missing column "reason"`},
+		{"unterminated quote", "namespace,name,reason\nshop,\"web-0,Evicted\n", "failed to parse report CSV"},
+		{"invalid severity", "namespace,name,reason,severity\nshop,web-0,Evicted,urgent\n", `line 2: invalid severity "urgent"`},
+		{"invalid restart count", "namespace,name,reason,restart_count\nshop,web-0,Evicted,1\nshop,web-1,Evicted,many\n", `line 3: invalid restart_count "many"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadCSVReport(writeCSV(t, tt.csv))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+	if _, err := LoadCSVReport(filepath.Join(t.TempDir(), "missing.csv")); err == nil {
+		t.Error("missing file: no error")
+	}
+}
+
+// A CSV export and the JSON report it was written from match in a diff
+func TestLoadCSVReportRoundTrip(t *testing.T) {
+	issues := []types.Issue{
+		{Namespace: "shop", Kind: "Pod", Name: "web-0", Container: "app", Severity: severity.High, Reason: "CrashLoopBackOff", RestartCount: 3},
+		{Namespace: "shop", Kind: "Deployment", Name: "api", Container: "api", Severity: severity.Medium, Reason: "ImageTagLatest"},
+	}
+	data, err := csvReport(issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep, err := LoadCSVReport(writeCSV(t, string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := DiffReports(rep, &ReportData{Issues: issues})
+	if len(diff.NewIssues)+len(diff.ResolvedIssues)+len(diff.ChangedIssues) != 0 {
+		t.Errorf("diff against the exported issues = %+v, want none", diff)
+	}
+}
@@ -2,28 +2,110 @@ package report
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 
+	"github.com/ductnn/k8s-scanner/pkg/severity"
 	"github.com/ductnn/k8s-scanner/pkg/types"
 )
 
-// IssueKey creates a unique key for an issue for comparison
+// issueKey returns the key used to match an issue across reports: its stable
+// ID, or a key computed from its identity for reports written before IDs existed
 func issueKey(issue types.Issue) string {
-	return fmt.Sprintf("%s/%s/%s", issue.Namespace, issue.Kind, issue.Name)
+	if issue.ID != "" {
+		return issue.ID
+	}
+	return identityKey(issue)
+}
+
+func identityKey(issue types.Issue) string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s", issue.Namespace, issue.Kind, issue.Name, issue.Container, types.CheckKey(issue))
+}
+
+// workloadKey matches an issue across clusters, where pod names differ by
+// their generated suffixes and IDs by the cluster name
+func workloadKey(issue types.Issue) string {
+	name := issue.Name
+	if issue.Kind == "Pod" {
+		name = workloadName(name)
+	}
+	return fmt.Sprintf("%s/%s/%s/%s/%s", issue.Namespace, issue.Kind, name, issue.Container, types.CheckKey(issue))
+}
+
+// generatedSuffix matches the random parts Kubernetes appends to names:
+// the 5 characters of a pod of a ReplicaSet, DaemonSet or Job and the
+// pod-template-hash of a ReplicaSet
+var generatedSuffix = regexp.MustCompile(`(-[bcdfghjklmnpqrstvwxz2456789]{6,10})?-[bcdfghjklmnpqrstvwxz2456789]{5}$`)
+
+// workloadName strips the generated suffixes of a pod name (web-7d9f8b6c5d-x2x4z
+// becomes web). StatefulSet ordinals are kept, they are the same everywhere.
+func workloadName(pod string) string {
+	if name := generatedSuffix.ReplaceAllString(pod, ""); name != "" {
+		return name
+	}
+	return pod
+}
+
+// hasIDs reports whether every issue has a stable ID
+func hasIDs(issues []types.Issue) bool {
+	for _, issue := range issues {
+		if issue.ID == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// displayName formats an issue as namespace/kind/name[/container]
+func displayName(issue types.Issue) string {
+	name := fmt.Sprintf("%s/%s/%s", issue.Namespace, issue.Kind, issue.Name)
+	if issue.Container != "" {
+		name += "/" + issue.Container
+	}
+	return name
 }
 
 // DiffResult contains the differences between two reports
 type DiffResult struct {
-	NewIssues      []types.Issue
-	ResolvedIssues []types.Issue
-	ChangedIssues  []IssueChange
+	// OldCluster and NewCluster are set when the reports come from different
+	// clusters (e.g. staging and prod); issues are then matched by
+	// namespace/kind/workload instead of by ID
+	OldCluster     string        `json:"old_cluster,omitempty"`
+	NewCluster     string        `json:"new_cluster,omitempty"`
+	NewIssues      []types.Issue `json:"new_issues"`
+	ResolvedIssues []types.Issue `json:"resolved_issues"`
+	ChangedIssues  []IssueChange `json:"changed_issues"`
+	// Namespaces lists the namespaces whose issue counts changed
+	Namespaces []NamespaceDelta `json:"namespace_deltas"`
+}
+
+// NamespaceDelta is the change of a namespace's issue counts between two
+// reports
+type NamespaceDelta struct {
+	Namespace string `json:"namespace"`
+	// Delta is the new count minus the old one, per severity
+	Delta types.SeveritySummary `json:"delta"`
+}
+
+// CrossCluster reports whether the compared reports come from different clusters
+func (r *DiffResult) CrossCluster() bool {
+	return r.OldCluster != ""
+}
+
+// reportCluster returns the cluster a report was taken from, if recorded
+func reportCluster(r *ReportData) string {
+	if r.Meta == nil {
+		return ""
+	}
+	return r.Meta.Cluster
 }
 
 // IssueChange represents a change in an issue between two reports
 type IssueChange struct {
-	OldIssue types.Issue
-	NewIssue types.Issue
-	Changes  []string // List of what changed
+	OldIssue types.Issue `json:"old_issue"`
+	NewIssue types.Issue `json:"new_issue"`
+	Changes  []string    `json:"changes"` // List of what changed
 }
 
 // DiffReports compares two reports and returns the differences
@@ -32,18 +114,30 @@ func DiffReports(oldReport, newReport *ReportData) *DiffResult {
 		NewIssues:      []types.Issue{},
 		ResolvedIssues: []types.Issue{},
 		ChangedIssues:  []IssueChange{},
+		Namespaces:     namespaceDeltas(oldReport.Issues, newReport.Issues),
+	}
+
+	// Reports without IDs (older or imported from CSV) can only be matched
+	// by identity, so both sides are then keyed that way
+	keyOf := issueKey
+	if !hasIDs(oldReport.Issues) || !hasIDs(newReport.Issues) {
+		keyOf = identityKey
+	}
+	if oldCluster, newCluster := reportCluster(oldReport), reportCluster(newReport); oldCluster != "" && newCluster != "" && oldCluster != newCluster {
+		result.OldCluster, result.NewCluster = oldCluster, newCluster
+		keyOf = workloadKey
 	}
 
 	// Build maps for quick lookup
 	oldIssuesMap := make(map[string]types.Issue)
 	for _, issue := range oldReport.Issues {
-		key := issueKey(issue)
+		key := keyOf(issue)
 		oldIssuesMap[key] = issue
 	}
 
 	newIssuesMap := make(map[string]types.Issue)
 	for _, issue := range newReport.Issues {
-		key := issueKey(issue)
+		key := keyOf(issue)
 		newIssuesMap[key] = issue
 	}
 
@@ -64,7 +158,7 @@ func DiffReports(oldReport, newReport *ReportData) *DiffResult {
 	// Find changed issues (in both but different)
 	for key, newIssue := range newIssuesMap {
 		if oldIssue, exists := oldIssuesMap[key]; exists {
-			changes := compareIssues(oldIssue, newIssue)
+			changes := compareIssues(oldIssue, newIssue, result.CrossCluster())
 			if len(changes) > 0 {
 				result.ChangedIssues = append(result.ChangedIssues, IssueChange{
 					OldIssue: oldIssue,
@@ -75,11 +169,55 @@ func DiffReports(oldReport, newReport *ReportData) *DiffResult {
 		}
 	}
 
+	// Map iteration order is random; sort for stable output
+	SortIssues(result.NewIssues)
+	SortIssues(result.ResolvedIssues)
+	sort.SliceStable(result.ChangedIssues, func(i, j int) bool {
+		return issueLess(result.ChangedIssues[i].NewIssue, result.ChangedIssues[j].NewIssue)
+	})
+
 	return result
 }
 
-// compareIssues compares two issues and returns a list of what changed
-func compareIssues(old, new types.Issue) []string {
+// namespaceDeltas counts the issues of both reports per namespace and
+// severity and returns the namespaces where a count changed
+func namespaceDeltas(oldIssues, newIssues []types.Issue) []NamespaceDelta {
+	delta := map[string]types.SeveritySummary{}
+	for _, issue := range oldIssues {
+		delta[issue.Namespace] = addSeverity(delta[issue.Namespace], issue.Severity, -1)
+	}
+	for _, issue := range newIssues {
+		delta[issue.Namespace] = addSeverity(delta[issue.Namespace], issue.Severity, 1)
+	}
+	out := []NamespaceDelta{}
+	for _, ns := range SortedNamespaces(delta) {
+		if d := delta[ns]; d != (types.SeveritySummary{}) {
+			out = append(out, NamespaceDelta{Namespace: ns, Delta: d})
+		}
+	}
+	return out
+}
+
+// addSeverity adds n to the count of level in s
+func addSeverity(s types.SeveritySummary, level severity.Level, n int) types.SeveritySummary {
+	switch level {
+	case severity.Critical:
+		s.Critical += n
+	case severity.High:
+		s.High += n
+	case severity.Medium:
+		s.Medium += n
+	case severity.Info:
+		s.Info += n
+	default:
+		s.Low += n
+	}
+	return s
+}
+
+// compareIssues compares two issues and returns a list of what changed.
+// Restart counts and nodes are not compared across clusters.
+func compareIssues(old, new types.Issue, crossCluster bool) []string {
 	var changes []string
 
 	if old.Severity != new.Severity {
@@ -91,13 +229,13 @@ func compareIssues(old, new types.Issue) []string {
 	if old.PodStatus != new.PodStatus {
 		changes = append(changes, fmt.Sprintf("Status: %s → %s", old.PodStatus, new.PodStatus))
 	}
-	if old.RestartCount != new.RestartCount {
+	if old.RestartCount != new.RestartCount && !crossCluster {
 		changes = append(changes, fmt.Sprintf("RestartCount: %d → %d", old.RestartCount, new.RestartCount))
 	}
 	if old.RootCause != new.RootCause {
 		changes = append(changes, fmt.Sprintf("RootCause: %s → %s", old.RootCause, new.RootCause))
 	}
-	if old.NodeName != new.NodeName {
+	if old.NodeName != new.NodeName && !crossCluster {
 		changes = append(changes, fmt.Sprintf("NodeName: %s → %s", old.NodeName, new.NodeName))
 	}
 
@@ -107,26 +245,44 @@ func compareIssues(old, new types.Issue) []string {
 // PrintDiff displays the diff results in a readable format
 func PrintDiff(result *DiffResult, oldReport, newReport *ReportData) {
 	fmt.Println("\n=== Report Comparison ===")
-	fmt.Printf("Old Report: %s (%d issues)\n", oldReport.GeneratedAt, len(oldReport.Issues))
-	fmt.Printf("New Report: %s (%d issues)\n", newReport.GeneratedAt, len(newReport.Issues))
+	newTitle, resolvedTitle := "New Issues", "Resolved Issues"
+	if result.CrossCluster() {
+		fmt.Printf("Old Report: %s from %s (%d issues)\n", oldReport.GeneratedAt, result.OldCluster, len(oldReport.Issues))
+		fmt.Printf("New Report: %s from %s (%d issues)\n", newReport.GeneratedAt, result.NewCluster, len(newReport.Issues))
+		fmt.Println("Issues are matched by namespace/kind/workload across clusters")
+		newTitle = fmt.Sprintf("Only in %s", result.NewCluster)
+		resolvedTitle = fmt.Sprintf("Only in %s", result.OldCluster)
+	} else {
+		fmt.Printf("Old Report: %s (%d issues)\n", oldReport.GeneratedAt, len(oldReport.Issues))
+		fmt.Printf("New Report: %s (%d issues)\n", newReport.GeneratedAt, len(newReport.Issues))
+	}
 	fmt.Println()
 
 	// Summary
 	fmt.Println("=== Summary ===")
-	fmt.Printf("New Issues:      %d\n", len(result.NewIssues))
-	fmt.Printf("Resolved Issues: %d\n", len(result.ResolvedIssues))
-	fmt.Printf("Changed Issues:  %d\n", len(result.ChangedIssues))
+	fmt.Printf("%-17s%d\n", newTitle+":", len(result.NewIssues))
+	fmt.Printf("%-17s%d\n", resolvedTitle+":", len(result.ResolvedIssues))
+	fmt.Printf("%-17s%d\n", "Changed Issues:", len(result.ChangedIssues))
 	fmt.Println()
 
+	if len(result.Namespaces) > 0 {
+		fmt.Println("=== Delta by Namespace ===")
+		fmt.Printf("%-30s | %-8s | %-6s | %-6s | %-6s | %-6s\n", "NAMESPACE", "CRITICAL", "HIGH", "MEDIUM", "LOW", "INFO")
+		fmt.Println(strings.Repeat("-", 80))
+		for _, d := range result.Namespaces {
+			fmt.Printf("%-30s | %-8s | %-6s | %-6s | %-6s | %-6s\n", d.Namespace,
+				signed(d.Delta.Critical), signed(d.Delta.High), signed(d.Delta.Medium), signed(d.Delta.Low), signed(d.Delta.Info))
+		}
+		fmt.Println()
+	}
+
 	// New Issues
 	if len(result.NewIssues) > 0 {
-		fmt.Println("=== New Issues ===")
+		fmt.Printf("=== %s ===\n", newTitle)
 		for _, issue := range result.NewIssues {
-			fmt.Printf("  [%s] %s/%s/%s - %s: %s\n",
-				strings.ToUpper(issue.Severity),
-				issue.Namespace,
-				issue.Kind,
-				issue.Name,
+			fmt.Printf("  [%s] %s - %s: %s\n",
+				strings.ToUpper(string(issue.Severity)),
+				displayName(issue),
 				issue.Reason,
 				issue.RootCause)
 		}
@@ -135,13 +291,11 @@ func PrintDiff(result *DiffResult, oldReport, newReport *ReportData) {
 
 	// Resolved Issues
 	if len(result.ResolvedIssues) > 0 {
-		fmt.Println("=== Resolved Issues ===")
+		fmt.Printf("=== %s ===\n", resolvedTitle)
 		for _, issue := range result.ResolvedIssues {
-			fmt.Printf("  [%s] %s/%s/%s - %s\n",
-				strings.ToUpper(issue.Severity),
-				issue.Namespace,
-				issue.Kind,
-				issue.Name,
+			fmt.Printf("  [%s] %s - %s\n",
+				strings.ToUpper(string(issue.Severity)),
+				displayName(issue),
 				issue.Reason)
 		}
 		fmt.Println()
@@ -151,10 +305,7 @@ func PrintDiff(result *DiffResult, oldReport, newReport *ReportData) {
 	if len(result.ChangedIssues) > 0 {
 		fmt.Println("=== Changed Issues ===")
 		for _, change := range result.ChangedIssues {
-			fmt.Printf("  %s/%s/%s:\n",
-				change.NewIssue.Namespace,
-				change.NewIssue.Kind,
-				change.NewIssue.Name)
+			fmt.Printf("  %s:\n", displayName(change.NewIssue))
 			for _, ch := range change.Changes {
 				fmt.Printf("    - %s\n", ch)
 			}
@@ -167,3 +318,11 @@ func PrintDiff(result *DiffResult, oldReport, newReport *ReportData) {
 	}
 }
 
+
+// signed formats a delta with its sign (+2, -3, 0)
+func signed(n int) string {
+	if n == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%+d", n)
+}
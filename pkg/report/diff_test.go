@@ -0,0 +1,181 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// names returns the namespace/kind/name[/container] of issues
+func names(issues []types.Issue) []string {
+	out := []string{}
+	for _, is := range issues {
+		out = append(out, displayName(is))
+	}
+	return out
+}
+
+func TestDiffReports(t *testing.T) {
+	web := types.Issue{ID: "id-web", Namespace: "shop", Kind: "Pod", Name: "web-0", Container: "app", Severity: severity.High, Reason: "CrashLoopBackOff", RestartCount: 3}
+	api := types.Issue{ID: "id-api", Namespace: "shop", Kind: "Pod", Name: "api-0", Severity: severity.Critical, Reason: "ImagePullBackOff"}
+	job := types.Issue{ID: "id-job", Namespace: "batch", Kind: "Pod", Name: "job-0", Severity: severity.Medium, Reason: "OOMKilled"}
+	noID := func(is types.Issue) types.Issue {
+		is.ID = ""
+		return is
+	}
+	with := func(is types.Issue, f func(*types.Issue)) types.Issue {
+		f(&is)
+		return is
+	}
+
+	tests := []struct {
+		name                string
+		old, new            []types.Issue
+		newIssues, resolved []string
+		changed             map[string][]string
+	}{
+		{
+			name:      "new and resolved",
+			old:       []types.Issue{web, api},
+			new:       []types.Issue{web, job},
+			newIssues: []string{"batch/Pod/job-0"},
+			resolved:  []string{"shop/Pod/api-0"},
+		},
+		{
+			name: "changed fields",
+			old:  []types.Issue{web},
+			new: []types.Issue{with(web, func(is *types.Issue) {
+				is.Severity, is.RestartCount, is.NodeName = severity.Critical, 30, "node-2"
+			})},
+			changed: map[string][]string{"shop/Pod/web-0/app": {"Severity: high → critical", "RestartCount: 3 → 30", "NodeName:  → node-2"}},
+		},
+		{
+			name: "IDs win over names",
+			old:  []types.Issue{web},
+			// Same pod and reason, but another ID: another issue
+			new:       []types.Issue{with(web, func(is *types.Issue) { is.ID = "id-other" })},
+			newIssues: []string{"shop/Pod/web-0/app"},
+			resolved:  []string{"shop/Pod/web-0/app"},
+		},
+		{
+			name: "identity without IDs",
+			old:  []types.Issue{noID(web), noID(api)},
+			new:  []types.Issue{web, with(api, func(is *types.Issue) { is.Container = "sidecar" })},
+			// The container is part of the identity
+			newIssues: []string{"shop/Pod/api-0/sidecar"},
+			resolved:  []string{"shop/Pod/api-0"},
+		},
+		{
+			name:    "identity by check, not reason text",
+			old:     []types.Issue{noID(with(web, func(is *types.Issue) { is.CheckID = "POD001" }))},
+			new:     []types.Issue{noID(with(web, func(is *types.Issue) { is.CheckID, is.Reason = "POD001", "BackOff" }))},
+			changed: map[string][]string{"shop/Pod/web-0/app": {"Reason: CrashLoopBackOff → BackOff"}},
+		},
+		{
+			name: "no differences",
+			old:  []types.Issue{web, api, job},
+			new:  []types.Issue{job, api, web},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := DiffReports(&ReportData{Issues: tt.old}, &ReportData{Issues: tt.new})
+			if got := names(diff.NewIssues); !reflect.DeepEqual(got, nonNil(tt.newIssues)) {
+				t.Errorf("new issues = %v, want %v", got, tt.newIssues)
+			}
+			if got := names(diff.ResolvedIssues); !reflect.DeepEqual(got, nonNil(tt.resolved)) {
+				t.Errorf("resolved issues = %v, want %v", got, tt.resolved)
+			}
+			changed := map[string][]string{}
+			for _, c := range diff.ChangedIssues {
+				changed[displayName(c.NewIssue)] = c.Changes
+			}
+			if len(changed) != len(tt.changed) || len(tt.changed) > 0 && !reflect.DeepEqual(changed, tt.changed) {
+				t.Errorf("changed issues = %v, want %v", changed, tt.changed)
+			}
+			if diff.CrossCluster() {
+				t.Error("CrossCluster() = true for reports without clusters")
+			}
+		})
+	}
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func TestWorkloadName(t *testing.T) {
+	tests := map[string]string{
+		"web-7d9f8b6c5d-x2x4z": "web",
+		"agent-x2x4z":          "agent",
+		"db-0":                 "db-0",
+		"web":                  "web",
+		"my-app-canary":        "my-app-canary",
+		"x2x4z":                "x2x4z",
+	}
+	for pod, want := range tests {
+		if got := workloadName(pod); got != want {
+			t.Errorf("workloadName(%q) = %q, want %q", pod, got, want)
+		}
+	}
+}
+
+func TestDiffReportsCrossCluster(t *testing.T) {
+	staging := &ReportData{Meta: &Meta{Cluster: "staging"}, Issues: []types.Issue{
+		{ID: "s1", Namespace: "shop", Kind: "Pod", Name: "web-7d9f8b6c5d-x2x4z", Container: "app", Severity: severity.High, Reason: "CrashLoopBackOff", RestartCount: 3, NodeName: "s-node"},
+		{ID: "s2", Namespace: "shop", Kind: "Pod", Name: "api-5c6b7d8f9g-abcde", Severity: severity.Critical, Reason: "ImagePullBackOff"},
+	}}
+	prod := &ReportData{Meta: &Meta{Cluster: "prod"}, Issues: []types.Issue{
+		{ID: "p1", Namespace: "shop", Kind: "Pod", Name: "web-6c8d9f7b5c-q9w8z", Container: "app", Severity: severity.High, Reason: "CrashLoopBackOff", RestartCount: 40, NodeName: "p-node"},
+		{ID: "p2", Namespace: "shop", Kind: "Pod", Name: "db-0", Severity: severity.Medium, Reason: "OOMKilled"},
+	}}
+
+	diff := DiffReports(staging, prod)
+	if !diff.CrossCluster() || diff.OldCluster != "staging" || diff.NewCluster != "prod" {
+		t.Fatalf("clusters = %q, %q, want staging, prod", diff.OldCluster, diff.NewCluster)
+	}
+	// web matches by workload; restarts and nodes differ between clusters anyway
+	if got := names(diff.NewIssues); !reflect.DeepEqual(got, []string{"shop/Pod/db-0"}) {
+		t.Errorf("only in prod = %v, want db-0", got)
+	}
+	if got := names(diff.ResolvedIssues); !reflect.DeepEqual(got, []string{"shop/Pod/api-5c6b7d8f9g-abcde"}) {
+		t.Errorf("only in staging = %v, want api", got)
+	}
+	if len(diff.ChangedIssues) != 0 {
+		t.Errorf("changed = %+v, want none", diff.ChangedIssues)
+	}
+
+	// The same cluster is matched by ID
+	again := DiffReports(prod, &ReportData{Meta: &Meta{Cluster: "prod"}, Issues: prod.Issues})
+	if again.CrossCluster() {
+		t.Error("CrossCluster() = true for reports of one cluster")
+	}
+}
+
+func TestNamespaceDeltas(t *testing.T) {
+	old := []types.Issue{
+		{Namespace: "shop", Severity: severity.High},
+		{Namespace: "shop", Severity: severity.Critical},
+		{Namespace: "batch", Severity: severity.Medium},
+		{Namespace: "same", Severity: severity.Low},
+	}
+	new := []types.Issue{
+		{Namespace: "shop", Severity: severity.High},
+		{Namespace: "shop", Severity: severity.High},
+		{Namespace: "web", Severity: severity.Info},
+		{Namespace: "same", Severity: severity.Low},
+	}
+	want := []NamespaceDelta{
+		{Namespace: "batch", Delta: types.SeveritySummary{Medium: -1}},
+		{Namespace: "shop", Delta: types.SeveritySummary{Critical: -1, High: 1}},
+		{Namespace: "web", Delta: types.SeveritySummary{Info: 1}},
+	}
+	if got := DiffReports(&ReportData{Issues: old}, &ReportData{Issues: new}).Namespaces; !reflect.DeepEqual(got, want) {
+		t.Errorf("namespace deltas = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,172 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// WriteDiff writes a diff report in every requested format: md and html for
+// change tickets, json for tools. Other formats are rejected.
+func WriteDiff(outdir, basename string, result *DiffResult, oldReport, newReport *ReportData, kinds []ExportKind) error {
+	for _, k := range kinds {
+		switch k {
+		case ExportJSON, ExportMD, ExportHTML:
+		default:
+			return fmt.Errorf("unsupported diff export: %s (expected md, html or json)", k)
+		}
+	}
+	if err := EnsureDir(outdir); err != nil {
+		return err
+	}
+
+	for _, k := range kinds {
+		var b []byte
+		var err error
+		switch k {
+		case ExportJSON:
+			b, err = json.MarshalIndent(map[string]any{
+				"generated_at": time.Now().Format(time.RFC3339),
+				"old":          diffSide(oldReport),
+				"new":          diffSide(newReport),
+				"diff":         result,
+			}, "", "  ")
+		case ExportMD:
+			b = []byte(mdDiff(result, oldReport, newReport))
+		case ExportHTML:
+			b = []byte(htmlDiff(result, oldReport, newReport))
+		}
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(outdir, fmt.Sprintf("%s.%s", basename, k)), b, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diffSide describes a compared report
+func diffSide(r *ReportData) map[string]any {
+	return map[string]any{"generated_at": r.GeneratedAt, "cluster": reportCluster(r), "issue_count": len(r.Issues)}
+}
+
+// diffTitles returns the titles of the new and resolved sections
+func diffTitles(result *DiffResult) (string, string) {
+	if result.CrossCluster() {
+		return "Only in " + result.NewCluster, "Only in " + result.OldCluster
+	}
+	return "New Issues", "Resolved Issues"
+}
+
+// describeReport is "<time> from <cluster> (n issues)"
+func describeReport(r *ReportData) string {
+	s := r.GeneratedAt
+	if c := reportCluster(r); c != "" {
+		s += " from " + c
+	}
+	return fmt.Sprintf("%s (%d issues)", s, len(r.Issues))
+}
+
+func mdDiff(result *DiffResult, oldReport, newReport *ReportData) string {
+	newTitle, resolvedTitle := diffTitles(result)
+	var sb strings.Builder
+	sb.WriteString("# Kubernetes Issues Diff\n\n")
+	sb.WriteString(fmt.Sprintf("_Generated: %s_\n\n", time.Now().Format(time.RFC3339)))
+	sb.WriteString(fmt.Sprintf("- Old report: %s\n- New report: %s\n", escapeMD(describeReport(oldReport)), escapeMD(describeReport(newReport))))
+	sb.WriteString(fmt.Sprintf("- %s: %d, %s: %d, changed: %d\n\n", newTitle, len(result.NewIssues), resolvedTitle, len(result.ResolvedIssues), len(result.ChangedIssues)))
+
+	if len(result.Namespaces) > 0 {
+		sb.WriteString("## Delta by Namespace\n\n")
+		sb.WriteString("| Namespace | Critical | High | Medium | Low | Info |\n|---|---:|---:|---:|---:|---:|\n")
+		for _, d := range result.Namespaces {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s |\n", d.Namespace,
+				signed(d.Delta.Critical), signed(d.Delta.High), signed(d.Delta.Medium), signed(d.Delta.Low), signed(d.Delta.Info)))
+		}
+		sb.WriteString("\n")
+	}
+
+	mdIssues := func(title string, issues []types.Issue) {
+		sb.WriteString(fmt.Sprintf("## %s (%d)\n\n", escapeMD(title), len(issues)))
+		if len(issues) == 0 {
+			sb.WriteString("_None_\n\n")
+			return
+		}
+		sb.WriteString("| Severity | Namespace | Kind | Name | Container | Reason | RootCause |\n|---|---|---|---|---|---|---|\n")
+		for _, is := range issues {
+			sb.WriteString(fmt.Sprintf("| **%s** | %s | %s | %s | %s | %s | %s |\n", strings.ToUpper(string(is.Severity)),
+				is.Namespace, is.Kind, is.Name, is.Container, escapeMD(is.Reason), escapeMD(is.RootCause)))
+		}
+		sb.WriteString("\n")
+	}
+	mdIssues(newTitle, result.NewIssues)
+	mdIssues(resolvedTitle, result.ResolvedIssues)
+
+	sb.WriteString(fmt.Sprintf("## Changed Issues (%d)\n\n", len(result.ChangedIssues)))
+	if len(result.ChangedIssues) == 0 {
+		sb.WriteString("_None_\n")
+		return sb.String()
+	}
+	sb.WriteString("| Severity | Issue | Changes |\n|---|---|---|\n")
+	for _, c := range result.ChangedIssues {
+		sb.WriteString(fmt.Sprintf("| **%s** | %s | %s |\n", strings.ToUpper(string(c.NewIssue.Severity)),
+			escapeMD(displayName(c.NewIssue)), escapeMD(strings.Join(c.Changes, "; "))))
+	}
+	return sb.String()
+}
+
+func htmlDiff(result *DiffResult, oldReport, newReport *ReportData) string {
+	newTitle, resolvedTitle := diffTitles(result)
+	var sb strings.Builder
+	sb.WriteString("<!doctype html><html><head><meta charset='utf-8'><title>K8s Diff</title>")
+	sb.WriteString(htmlStyle + "</head><body>")
+	sb.WriteString("<h1>Kubernetes Issues Diff</h1>")
+	sb.WriteString(fmt.Sprintf("<div class='small'>Generated: %s</div>", html.EscapeString(time.Now().Format(time.RFC3339))))
+	sb.WriteString(fmt.Sprintf("<p>Old report: %s<br>New report: %s</p>", html.EscapeString(describeReport(oldReport)), html.EscapeString(describeReport(newReport))))
+
+	if len(result.Namespaces) > 0 {
+		sb.WriteString("<h2>Delta by Namespace</h2><table><thead><tr><th>Namespace</th><th>Critical</th><th>High</th><th>Medium</th><th>Low</th><th>Info</th></tr></thead><tbody>")
+		for _, d := range result.Namespaces {
+			sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>", html.EscapeString(d.Namespace),
+				signed(d.Delta.Critical), signed(d.Delta.High), signed(d.Delta.Medium), signed(d.Delta.Low), signed(d.Delta.Info)))
+		}
+		sb.WriteString("</tbody></table>")
+	}
+
+	htmlIssues := func(title string, issues []types.Issue) {
+		sb.WriteString(fmt.Sprintf("<h2>%s (%d)</h2>", html.EscapeString(title), len(issues)))
+		if len(issues) == 0 {
+			sb.WriteString("<p class='small'>None</p>")
+			return
+		}
+		sb.WriteString("<table><thead><tr><th>Severity</th><th>Namespace</th><th>Kind</th><th>Name</th><th>Container</th><th>Reason</th><th>RootCause</th></tr></thead><tbody>")
+		for _, is := range issues {
+			sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>", severityBadge(is.Severity),
+				html.EscapeString(is.Namespace), html.EscapeString(is.Kind), html.EscapeString(is.Name), html.EscapeString(is.Container),
+				html.EscapeString(is.Reason), html.EscapeString(is.RootCause)))
+		}
+		sb.WriteString("</tbody></table>")
+	}
+	htmlIssues(newTitle, result.NewIssues)
+	htmlIssues(resolvedTitle, result.ResolvedIssues)
+
+	sb.WriteString(fmt.Sprintf("<h2>Changed Issues (%d)</h2>", len(result.ChangedIssues)))
+	if len(result.ChangedIssues) == 0 {
+		sb.WriteString("<p class='small'>None</p>")
+	} else {
+		sb.WriteString("<table><thead><tr><th>Severity</th><th>Issue</th><th>Changes</th></tr></thead><tbody>")
+		for _, c := range result.ChangedIssues {
+			sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%s</td><td>%s</td></tr>", severityBadge(c.NewIssue.Severity),
+				html.EscapeString(displayName(c.NewIssue)), html.EscapeString(strings.Join(c.Changes, "; "))))
+		}
+		sb.WriteString("</tbody></table>")
+	}
+	sb.WriteString("</body></html>")
+	return sb.String()
+}
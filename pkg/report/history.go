@@ -3,6 +3,7 @@ package report
 import (
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
@@ -17,62 +18,68 @@ type ReportData struct {
 	GeneratedAt string                           `json:"generated_at"`
 	Issues      []types.Issue                    `json:"issues"`
 	Summary     map[string]types.SeveritySummary `json:"summary"`
+	Meta        *Meta                            `json:"meta,omitempty"`
 }
 
 // ReportInfo contains metadata about a historical report
 type ReportInfo struct {
-	Path        string
-	DirName     string
-	GeneratedAt time.Time
-	IssueCount  int
-	Summary     map[string]types.SeveritySummary
+	Path        string                           `json:"-"`
+	DirName     string                           `json:"name"`
+	Cluster     string                           `json:"cluster,omitempty"`
+	GeneratedAt time.Time                        `json:"generated_at"`
+	IssueCount  int                              `json:"issue_count"`
+	Summary     map[string]types.SeveritySummary `json:"summary"`
 }
 
-// ListHistory scans the reports directory and returns all historical reports
+// ListHistory scans the reports directory and returns all historical reports.
+// Reports written with a filename template may be in subdirectories, so every
+// JSON file (or .json.gz when exported with --compress) that holds a report
+// is listed, except archives, per-tenant reports and CycloneDX inventories.
 func ListHistory(outdir string) ([]ReportInfo, error) {
-	entries, err := os.ReadDir(outdir)
-	if err != nil {
+	if _, err := os.ReadDir(outdir); err != nil {
 		return nil, fmt.Errorf("failed to read reports directory: %w", err)
 	}
 
 	var reports []ReportInfo
-	for _, entry := range entries {
+	_ = filepath.WalkDir(outdir, func(reportPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
 		if entry.IsDir() {
-			continue
+			if reportPath == filepath.Join(outdir, ArchiveDir) || reportPath == filepath.Join(outdir, TenantsDir) {
+				return filepath.SkipDir
+			}
+			return nil
 		}
 
 		fileName := entry.Name()
-		// Check if it matches the pattern: [cluster-name]-k8s-report-YYYYMMDD-HHMMSS.json or k8s-report-YYYYMMDD-HHMMSS.json
-		if !strings.HasSuffix(fileName, ".json") {
-			continue
+		if !strings.HasSuffix(fileName, ".json") && !strings.HasSuffix(fileName, ".json.gz") {
+			return nil
 		}
-		// Must contain "k8s-report-" somewhere in the filename
-		if !strings.Contains(fileName, "k8s-report-") {
-			continue
+		if strings.Contains(fileName, ".cdx.json") {
+			return nil
 		}
 
-		reportPath := filepath.Join(outdir, fileName)
-		if _, err := os.Stat(reportPath); err != nil {
-			// Skip if JSON report doesn't exist
-			continue
-		}
-
-		// Load report to get metadata
+		// Load report to get metadata; other JSON files (baseline, digest,
+		// audit logs, diffs) lack its fields
 		reportData, err := LoadReport(reportPath)
-		if err != nil {
-			continue
+		if err != nil || reportData.GeneratedAt == "" || reportData.Issues == nil {
+			return nil
 		}
 
 		generatedAt, _ := time.Parse(time.RFC3339, reportData.GeneratedAt)
+		name, _ := filepath.Rel(outdir, reportPath)
 
 		reports = append(reports, ReportInfo{
 			Path:        reportPath,
-			DirName:     fileName, // Store full filename for display
+			DirName:     filepath.ToSlash(name), // Path inside outdir for display
+			Cluster:     ClusterOf(fileName, reportData),
 			GeneratedAt: generatedAt,
 			IssueCount:  len(reportData.Issues),
 			Summary:     reportData.Summary,
 		})
-	}
+		return nil
+	})
 
 	// Sort by generated time (newest first)
 	sort.Slice(reports, func(i, j int) bool {
@@ -82,9 +89,47 @@ func ListHistory(outdir string) ([]ReportInfo, error) {
 	return reports, nil
 }
 
-// LoadReport loads a JSON report from the given path
+// ClusterOf returns the cluster of a report: the one recorded in its
+// metadata, or else the cluster prefix of its file name
+// (<cluster>-k8s-report-<timestamp>.json). It is empty when unknown.
+func ClusterOf(path string, r *ReportData) string {
+	if c := reportCluster(r); c != "" {
+		return c
+	}
+	prefix, _, ok := strings.Cut(filepath.Base(path), "-k8s-report-")
+	if !ok {
+		return ""
+	}
+	return prefix
+}
+
+// HistoryFilter selects reports from the history
+type HistoryFilter struct {
+	// Since drops reports generated before it (zero keeps all)
+	Since time.Time
+	// Cluster keeps only the reports of this cluster (empty keeps all)
+	Cluster string
+}
+
+// FilterHistory returns the reports matching f, keeping their order
+func FilterHistory(reports []ReportInfo, f HistoryFilter) []ReportInfo {
+	out := []ReportInfo{}
+	for _, r := range reports {
+		if !f.Since.IsZero() && r.GeneratedAt.Before(f.Since) {
+			continue
+		}
+		if f.Cluster != "" && r.Cluster != f.Cluster {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// LoadReport loads a JSON report from the given path; .gz reports are
+// decompressed
 func LoadReport(path string) (*ReportData, error) {
-	data, err := os.ReadFile(path)
+	data, err := readReportFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read report file: %w", err)
 	}
@@ -104,9 +149,44 @@ func PrintHistory(reports []ReportInfo) {
 		return
 	}
 
-	fmt.Println("\n=== Historical Reports ===")
-	fmt.Printf("%-30s | %-20s | %-8s | %-10s\n", "FILENAME", "GENERATED AT", "ISSUES", "SUMMARY")
-	fmt.Println(strings.Repeat("-", 100))
+	// Reports of clusters sharing the outdir are listed per cluster
+	var clusters []string
+	byCluster := map[string][]ReportInfo{}
+	for _, r := range reports {
+		if _, ok := byCluster[r.Cluster]; !ok {
+			clusters = append(clusters, r.Cluster)
+		}
+		byCluster[r.Cluster] = append(byCluster[r.Cluster], r)
+	}
+	if len(clusters) == 1 {
+		printHistoryTable("Historical Reports", reports)
+		return
+	}
+	// Reports of an unknown cluster come last
+	sort.Slice(clusters, func(i, j int) bool {
+		if (clusters[i] == "") != (clusters[j] == "") {
+			return clusters[j] == ""
+		}
+		return clusters[i] < clusters[j]
+	})
+	for _, c := range clusters {
+		title := "Historical Reports: " + c
+		if c == "" {
+			title = "Historical Reports: unknown cluster"
+		}
+		printHistoryTable(title, byCluster[c])
+	}
+}
+
+func printHistoryTable(title string, reports []ReportInfo) {
+	// Reports in subdirectories have longer names
+	width := 30
+	for _, r := range reports {
+		width = max(width, len(r.DirName))
+	}
+	fmt.Printf("\n=== %s ===\n", title)
+	fmt.Printf("%-*s | %-20s | %-8s | %-10s\n", width, "FILENAME", "GENERATED AT", "ISSUES", "SUMMARY")
+	fmt.Println(strings.Repeat("-", width+70))
 
 	for _, r := range reports {
 		// Calculate total issues by severity
@@ -114,16 +194,18 @@ func PrintHistory(reports []ReportInfo) {
 		totalHigh := 0
 		totalMedium := 0
 		totalLow := 0
+		totalInfo := 0
 		for _, s := range r.Summary {
 			totalCritical += s.Critical
 			totalHigh += s.High
 			totalMedium += s.Medium
 			totalLow += s.Low
+			totalInfo += s.Info
 		}
-		summaryStr := fmt.Sprintf("C:%d H:%d M:%d L:%d", totalCritical, totalHigh, totalMedium, totalLow)
+		summaryStr := fmt.Sprintf("C:%d H:%d M:%d L:%d I:%d", totalCritical, totalHigh, totalMedium, totalLow, totalInfo)
 
-		fmt.Printf("%-30s | %-20s | %-8d | %-10s\n",
-			r.DirName,
+		fmt.Printf("%-*s | %-20s | %-8d | %-10s\n",
+			width, r.DirName,
 			r.GeneratedAt.Format("2006-01-02 15:04:05"),
 			r.IssueCount,
 			summaryStr)
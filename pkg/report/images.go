@@ -0,0 +1,90 @@
+package report
+
+import (
+	"sort"
+
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// imageReasons are the reasons grouped by image: a bad release crashes or
+// fails to pull everywhere it is deployed
+var imageReasons = map[string]bool{
+	"CrashLoopBackOff": true,
+	"ImagePullBackOff": true,
+	"ErrImagePull":     true,
+}
+
+// ImageGroup is one finding for every pod failing with the same image
+type ImageGroup struct {
+	Image    string         `json:"image"`
+	Severity severity.Level `json:"severity"`
+	// Reasons seen for the image, e.g. CrashLoopBackOff
+	Reasons []string `json:"reasons"`
+	// Pods is the number of failing pods
+	Pods       int      `json:"pods"`
+	Namespaces []string `json:"namespaces"`
+	// Workloads are the affected workloads as namespace/name
+	Workloads []string `json:"workloads"`
+}
+
+// GroupByImage groups the CrashLoopBackOff and ImagePullBackOff issues by
+// image, most pods first. It also returns the issues left out: other
+// reasons and issues without an image.
+func GroupByImage(issues []types.Issue) ([]ImageGroup, []types.Issue) {
+	type group struct {
+		ImageGroup
+		pods       map[string]bool
+		reasons    map[string]bool
+		namespaces map[string]bool
+		workloads  map[string]bool
+	}
+	groups := map[string]*group{}
+	var rest []types.Issue
+	for _, is := range issues {
+		if is.Image == "" || !imageReasons[is.Reason] {
+			rest = append(rest, is)
+			continue
+		}
+		g := groups[is.Image]
+		if g == nil {
+			g = &group{
+				ImageGroup: ImageGroup{Image: is.Image, Severity: is.Severity},
+				pods:       map[string]bool{}, reasons: map[string]bool{}, namespaces: map[string]bool{}, workloads: map[string]bool{},
+			}
+			groups[is.Image] = g
+		}
+		if severity.Rank(is.Severity) > severity.Rank(g.Severity) {
+			g.Severity = is.Severity
+		}
+		g.pods[is.Namespace+"/"+is.Name] = true
+		g.reasons[is.Reason] = true
+		g.namespaces[is.Namespace] = true
+		g.workloads[is.Namespace+"/"+workloadName(is.Name)] = true
+	}
+
+	out := make([]ImageGroup, 0, len(groups))
+	for _, g := range groups {
+		g.Pods = len(g.pods)
+		g.Reasons = sortedSet(g.reasons)
+		g.Namespaces = sortedSet(g.namespaces)
+		g.Workloads = sortedSet(g.workloads)
+		out = append(out, g.ImageGroup)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Pods != out[j].Pods {
+			return out[i].Pods > out[j].Pods
+		}
+		return out[i].Image < out[j].Image
+	})
+	return out, rest
+}
+
+func sortedSet(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,50 @@
+package report
+
+import "github.com/ductnn/k8s-scanner/pkg/cloud"
+
+// Meta describes the scope and freshness of a report
+type Meta struct {
+	Cluster           string `json:"cluster,omitempty"`
+	KubernetesVersion string `json:"kubernetes_version,omitempty"`
+	ScannerVersion    string `json:"scanner_version"`
+	ScannerCommit     string `json:"scanner_commit,omitempty"`
+	// Source is "cluster" for live scans or the snapshot file scanned
+	Source     string `json:"source"`
+	StartedAt  string `json:"started_at"`
+	DurationMS int64  `json:"duration_ms"`
+	// Timings lists how long each scanner took, in execution order
+	Timings []ScannerTiming `json:"timings,omitempty"`
+	// Namespaces scanned; empty means all namespaces
+	Namespaces        []string `json:"namespaces"`
+	IgnoredNamespaces []string `json:"ignored_namespaces,omitempty"`
+	// Errors lists what could not be scanned, so missing results are not
+	// mistaken for a healthy cluster
+	Errors []string `json:"errors,omitempty"`
+	// APILatency measures how responsive the API server was during the scan
+	APILatency *APILatency `json:"api_latency,omitempty"`
+	// Baseline is set when accepted issues were hidden from the report
+	Baseline *BaselineInfo `json:"baseline,omitempty"`
+	// Teams is the per-team compliance with the issue budgets
+	Teams []TeamStatus `json:"teams,omitempty"`
+	// Nodes are the issues per node with their drain state
+	Nodes []NodeGroup `json:"nodes,omitempty"`
+	// Managed is the health of the managed control plane and node pools
+	// reported by the cloud provider (upgrades, degraded node pools)
+	Managed *cloud.Status `json:"managed,omitempty"`
+	// Tenant is the namespace or team of a per-tenant report (see
+	// WriteTenants)
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// APILatency summarizes the latency of the scan's API read requests
+type APILatency struct {
+	Requests int     `json:"requests"`
+	P50MS    float64 `json:"p50_ms"`
+	P95MS    float64 `json:"p95_ms"`
+}
+
+// ScannerTiming is the duration of one scanner
+type ScannerTiming struct {
+	Scanner    string `json:"scanner"`
+	DurationMS int64  `json:"duration_ms"`
+}
@@ -0,0 +1,85 @@
+package report
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultFilenameTemplate names exports <cluster>-k8s-report-<timestamp>
+const DefaultFilenameTemplate = `{{if .Cluster}}{{.Cluster}}-{{end}}k8s-report-{{.Timestamp}}`
+
+// FilenameData are the fields of a filename template
+type FilenameData struct {
+	// Cluster is the cluster name, sanitized for file names (may be empty)
+	Cluster string
+	// Date is 2006-01-02, Time is 150405 and Timestamp 20060102-150405
+	Date      string
+	Time      string
+	Timestamp string
+}
+
+// FilenameTemplate renders the name of exported reports, without extension.
+// Names may contain subdirectories of the reports directory, e.g.
+// "{{.Cluster}}/{{.Date}}/report-{{.Time}}".
+type FilenameTemplate struct {
+	t *template.Template
+}
+
+// ParseFilenameTemplate parses a text/template filename; empty uses
+// DefaultFilenameTemplate
+func ParseFilenameTemplate(s string) (*FilenameTemplate, error) {
+	if s == "" {
+		s = DefaultFilenameTemplate
+	}
+	t, err := template.New("filename").Option("missingkey=error").Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filename template: %w", err)
+	}
+	f := &FilenameTemplate{t: t}
+	// Catch unknown fields before the scan rather than after it
+	if _, err := f.Name("cluster", time.Now()); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Name renders the export name of a scan of cluster at the given time
+func (f *FilenameTemplate) Name(cluster string, at time.Time) (string, error) {
+	var sb strings.Builder
+	err := f.t.Execute(&sb, FilenameData{
+		Cluster:   cluster,
+		Date:      at.Format("2006-01-02"),
+		Time:      at.Format("150405"),
+		Timestamp: at.Format("20060102-150405"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("invalid filename template: %w", err)
+	}
+	// An empty leading field (e.g. no cluster name) leaves a leading slash
+	name := filepath.Clean(strings.TrimLeft(strings.TrimSpace(sb.String()), "/"))
+	if name == "." || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid filename template: %q is not a file name inside the reports directory", sb.String())
+	}
+	return name, nil
+}
+
+// SanitizeClusterName makes a cluster name (e.g. an EKS ARN) usable in file
+// names
+func SanitizeClusterName(name string) string {
+	// Replace invalid filename characters with hyphens
+	invalid := []string{"/", "\\", ":", "*", "?", "\"", "<", ">", "|", " "}
+	sanitized := name
+	for _, char := range invalid {
+		sanitized = strings.ReplaceAll(sanitized, char, "-")
+	}
+	// Remove consecutive hyphens
+	for strings.Contains(sanitized, "--") {
+		sanitized = strings.ReplaceAll(sanitized, "--", "-")
+	}
+	// Remove leading/trailing hyphens
+	sanitized = strings.Trim(sanitized, "-")
+	return sanitized
+}
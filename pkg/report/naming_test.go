@@ -0,0 +1,67 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFilenameTemplate(t *testing.T) {
+	at := time.Date(2024, 5, 1, 9, 30, 15, 0, time.UTC)
+	tests := []struct {
+		template string
+		cluster  string
+		want     string
+	}{
+		{"", "prod", "prod-k8s-report-20240501-093015"},
+		{"", "", "k8s-report-20240501-093015"},
+		{"{{.Cluster}}/{{.Date}}/report-{{.Time}}", "prod", "prod/2024-05-01/report-093015"},
+		// No cluster name leaves no leading slash
+		{"{{.Cluster}}/{{.Date}}/report-{{.Time}}", "", "2024-05-01/report-093015"},
+		{"nightly/./{{.Timestamp}}", "prod", "nightly/20240501-093015"},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilenameTemplate(tt.template)
+		if err != nil {
+			t.Fatalf("ParseFilenameTemplate(%q): %v", tt.template, err)
+		}
+		if got, err := f.Name(tt.cluster, at); err != nil || got != tt.want {
+			t.Errorf("%q with cluster %q = %q, %v, want %q", tt.template, tt.cluster, got, err, tt.want)
+		}
+	}
+}
+
+func TestFilenameTemplateErrors(t *testing.T) {
+	for _, tmpl := range []string{"{{.Cluster", "{{.Namespace}}-report", "{{.Cluster | nosuchfunc}}"} {
+		if _, err := ParseFilenameTemplate(tmpl); err == nil || !strings.Contains(err.Error(), "invalid filename template") {
+			t.Errorf("ParseFilenameTemplate(%q) error = %v, want invalid filename template", tmpl, err)
+		}
+	}
+
+	// Names must stay inside the reports directory
+	at := time.Now()
+	for _, tmpl := range []string{"..", "../{{.Timestamp}}", "{{.Cluster}}/../../x", "."} {
+		f, err := ParseFilenameTemplate(tmpl)
+		if err != nil {
+			continue
+		}
+		if name, err := f.Name("prod", at); err == nil {
+			t.Errorf("%q renders %q, want an error", tmpl, name)
+		}
+	}
+}
+
+func TestSanitizeClusterName(t *testing.T) {
+	tests := map[string]string{
+		"prod": "prod",
+		"arn:aws:eks:us-east-1:123456789012:cluster/prod": "arn-aws-eks-us-east-1-123456789012-cluster-prod",
+		"gke_project_zone_name":                           "gke_project_zone_name",
+		" my  cluster ":                                   "my-cluster",
+		"a//b":                                            "a-b",
+	}
+	for name, want := range tests {
+		if got := SanitizeClusterName(name); got != want {
+			t.Errorf("SanitizeClusterName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// NDJSONWriter writes issues as newline-delimited JSON, one issue per line,
+// so they can be emitted while a scan is still running
+type NDJSONWriter struct {
+	enc *json.Encoder
+}
+
+// NewNDJSONWriter returns a writer appending to w
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{enc: json.NewEncoder(w)}
+}
+
+// Write appends one line per issue
+func (w *NDJSONWriter) Write(issues []types.Issue) error {
+	for _, issue := range issues {
+		if err := w.enc.Encode(issue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package report
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// NoNode groups the issues not bound to a node
+const NoNode = "(no node)"
+
+// Drain states of a NodeGroup
+const (
+	DrainSafe    = "safe"
+	DrainBlocked = "blocked"
+)
+
+// NodeGroup is the issues sitting on one node, to decide whether recycling
+// it is worth it and safe
+type NodeGroup struct {
+	Node    string                `json:"node"`
+	Issues  int                   `json:"issues"`
+	Summary types.SeveritySummary `json:"summary"`
+	// Drain is DrainSafe or DrainBlocked; empty when PDBs were not checked
+	Drain string `json:"drain,omitempty"`
+	// DrainBlockers are the PodDisruptionBudgets (namespace/name) allowing no
+	// disruption of a pod on the node
+	DrainBlockers []string `json:"drain_blockers,omitempty"`
+	// NodePool, InstanceType and Zone are set with --node-context
+	NodePool     string `json:"node_pool,omitempty"`
+	InstanceType string `json:"instance_type,omitempty"`
+	Zone         string `json:"zone,omitempty"`
+}
+
+// GroupByNode counts issues per node, most issues first. blockers maps nodes
+// to the PDBs blocking their drain; nodes missing from it (or a nil map)
+// have an unknown drain state.
+func GroupByNode(issues []types.Issue, blockers map[string][]string) []NodeGroup {
+	groups := map[string]*NodeGroup{}
+	for _, is := range issues {
+		node := is.NodeName
+		if node == "" {
+			node = NoNode
+		}
+		g := groups[node]
+		if g == nil {
+			g = &NodeGroup{Node: node}
+			if b, ok := blockers[node]; ok && node != NoNode {
+				g.Drain, g.DrainBlockers = DrainSafe, b
+				if len(b) > 0 {
+					g.Drain = DrainBlocked
+				}
+			}
+			groups[node] = g
+		}
+		if g.NodePool == "" && g.InstanceType == "" && g.Zone == "" {
+			g.NodePool, g.InstanceType, g.Zone = is.NodePool, is.InstanceType, is.Zone
+		}
+		g.Issues++
+		g.Summary = addSeverity(g.Summary, is.Severity, 1)
+	}
+
+	out := make([]NodeGroup, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		// Issues without a node last
+		if (out[i].Node == NoNode) != (out[j].Node == NoNode) {
+			return out[j].Node == NoNode
+		}
+		if out[i].Issues != out[j].Issues {
+			return out[i].Issues > out[j].Issues
+		}
+		return out[i].Node < out[j].Node
+	})
+	return out
+}
+
+// Label is the node name with its pool, instance type and zone when known
+func (g NodeGroup) Label() string {
+	return withContext(g.Node, g.NodePool, g.InstanceType, g.Zone)
+}
+
+// NodeWithContext is the node of an issue with its pool, instance type and
+// zone when known, e.g. "ip-10-0-1-5 (ng-spot, m5.large, us-east-1a)"
+func NodeWithContext(is types.Issue) string {
+	if is.NodeName == "" {
+		return ""
+	}
+	return withContext(is.NodeName, is.NodePool, is.InstanceType, is.Zone)
+}
+
+func withContext(node string, details ...string) string {
+	var known []string
+	for _, d := range details {
+		if d != "" {
+			known = append(known, d)
+		}
+	}
+	if len(known) == 0 {
+		return node
+	}
+	return node + " (" + strings.Join(known, ", ") + ")"
+}
+
+// UnknownPool groups the issues whose node pool is not known
+const UnknownPool = "(unknown pool)"
+
+// PoolGroup is the issues on the nodes of one node pool
+type PoolGroup struct {
+	Pool          string                `json:"pool"`
+	Nodes         int                   `json:"nodes"`
+	Issues        int                   `json:"issues"`
+	Summary       types.SeveritySummary `json:"summary"`
+	InstanceTypes []string              `json:"instance_types,omitempty"`
+	Zones         []string              `json:"zones,omitempty"`
+	// Reasons counts the issues of the pool by reason
+	Reasons map[string]int `json:"reasons"`
+}
+
+// GroupByNodePool counts the issues bound to a node per node pool, most
+// issues first, so capacity and eviction problems can be traced to a pool
+func GroupByNodePool(issues []types.Issue) []PoolGroup {
+	groups := map[string]*PoolGroup{}
+	nodes := map[string]map[string]bool{}
+	for _, is := range issues {
+		if is.NodeName == "" {
+			continue
+		}
+		pool := is.NodePool
+		if pool == "" {
+			pool = UnknownPool
+		}
+		g := groups[pool]
+		if g == nil {
+			g = &PoolGroup{Pool: pool, Reasons: map[string]int{}}
+			groups[pool] = g
+			nodes[pool] = map[string]bool{}
+		}
+		g.Issues++
+		g.Summary = addSeverity(g.Summary, is.Severity, 1)
+		g.Reasons[is.Reason]++
+		nodes[pool][is.NodeName] = true
+		if is.InstanceType != "" && !slices.Contains(g.InstanceTypes, is.InstanceType) {
+			g.InstanceTypes = append(g.InstanceTypes, is.InstanceType)
+		}
+		if is.Zone != "" && !slices.Contains(g.Zones, is.Zone) {
+			g.Zones = append(g.Zones, is.Zone)
+		}
+	}
+
+	out := make([]PoolGroup, 0, len(groups))
+	for pool, g := range groups {
+		g.Nodes = len(nodes[pool])
+		sort.Strings(g.InstanceTypes)
+		sort.Strings(g.Zones)
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if (out[i].Pool == UnknownPool) != (out[j].Pool == UnknownPool) {
+			return out[j].Pool == UnknownPool
+		}
+		if out[i].Issues != out[j].Issues {
+			return out[i].Issues > out[j].Issues
+		}
+		return out[i].Pool < out[j].Pool
+	})
+	return out
+}
+
+// DrainHint describes the drain state of a node for display
+func (g NodeGroup) DrainHint() string {
+	switch g.Drain {
+	case DrainSafe:
+		return "safe to drain"
+	case DrainBlocked:
+		return "drain blocked by PDB " + strings.Join(g.DrainBlockers, ", ")
+	}
+	return "drain not checked"
+}
@@ -0,0 +1,249 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/ductnn/k8s-scanner/pkg/scanner/capacity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// RedactMode is how one kind of name is anonymized
+type RedactMode string
+
+const (
+	// RedactHash replaces names with a salted hash, so the same name maps
+	// to the same value across the report
+	RedactHash RedactMode = "hash"
+	// RedactMask keeps the first characters and masks the rest; distinct
+	// names may become indistinguishable
+	RedactMask RedactMode = "mask"
+	// RedactKeep leaves names unchanged
+	RedactKeep RedactMode = "keep"
+)
+
+// RedactRules selects what --redact anonymizes
+type RedactRules struct {
+	Namespaces RedactMode `json:"namespaces,omitempty"`
+	// Names covers pods, workloads, containers, images, Services, webhooks,
+	// teams and the cluster name
+	Names RedactMode `json:"names,omitempty"`
+	Nodes RedactMode `json:"nodes,omitempty"`
+	// KeepEvents keeps the last event messages, which are stripped by default
+	KeepEvents bool `json:"keepEvents,omitempty"`
+	// KeepNamespaces are well-known namespaces left readable (e.g. kube-system)
+	KeepNamespaces []string `json:"keepNamespaces,omitempty"`
+	// Salt makes hashes (and issue IDs) unguessable from a list of likely
+	// names
+	Salt string `json:"salt,omitempty"`
+}
+
+// DefaultRedactRules hashes every name and strips event messages
+func DefaultRedactRules() RedactRules {
+	return RedactRules{Namespaces: RedactHash, Names: RedactHash, Nodes: RedactHash}
+}
+
+// Redactor anonymizes issues and reports according to RedactRules. Names
+// are also replaced where they appear in root causes, suggestions and
+// warnings. It may be applied to successive batches of issues.
+type Redactor struct {
+	rules RedactRules
+	keep  map[string]bool
+
+	mu sync.Mutex
+	// names maps every name seen so far to its replacement
+	names map[string]string
+}
+
+// NewRedactor validates the rules; empty modes default to hash
+func NewRedactor(rules RedactRules) (*Redactor, error) {
+	for _, m := range []*RedactMode{&rules.Namespaces, &rules.Names, &rules.Nodes} {
+		switch *m {
+		case "":
+			*m = RedactHash
+		case RedactHash, RedactMask, RedactKeep:
+		default:
+			return nil, fmt.Errorf("invalid redact mode %q (expected hash, mask or keep)", *m)
+		}
+	}
+	r := &Redactor{rules: rules, keep: map[string]bool{}, names: map[string]string{}}
+	for _, ns := range rules.KeepNamespaces {
+		r.keep[ns] = true
+	}
+	return r, nil
+}
+
+// name returns the replacement of value and records it for free text
+func (r *Redactor) name(value, prefix string, mode RedactMode) string {
+	if value == "" || mode == RedactKeep {
+		return value
+	}
+	if out, ok := r.names[value]; ok {
+		return out
+	}
+	var out string
+	switch mode {
+	case RedactMask:
+		out = value[:min(3, len(value))] + "***"
+	default:
+		sum := sha256.Sum256([]byte(r.rules.Salt + "/" + value))
+		out = prefix + hex.EncodeToString(sum[:])[:8]
+	}
+	r.names[value] = out
+	return out
+}
+
+// id replaces an issue ID, a fingerprint of the names it was redacted of,
+// with a salted hash of it: IDs stay stable across redacted reports but can
+// no longer be matched against the fingerprints of guessed names
+func (r *Redactor) id(id string) string {
+	if id == "" {
+		return id
+	}
+	sum := sha256.Sum256([]byte(r.rules.Salt + "/id/" + id))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+func (r *Redactor) namespace(ns string) string {
+	if r.keep[ns] {
+		return ns
+	}
+	return r.name(ns, "ns-", r.rules.Namespaces)
+}
+
+// Issues anonymizes issues in place
+func (r *Redactor) Issues(issues []types.Issue) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Map every name first so text mentioning another issue's names is
+	// redacted as well
+	for i := range issues {
+		is := &issues[i]
+		is.Namespace = r.namespace(is.Namespace)
+		is.ID = r.id(is.ID)
+		is.Name = r.name(is.Name, "name-", r.rules.Names)
+		is.Container = r.name(is.Container, "container-", r.rules.Names)
+		is.Image = r.name(is.Image, "image-", r.rules.Names)
+		is.Team = r.name(is.Team, "team-", r.rules.Names)
+		is.NodeName = r.name(is.NodeName, "node-", r.rules.Nodes)
+		is.NodePool = r.name(is.NodePool, "pool-", r.rules.Nodes)
+		for j, svc := range is.ImpactedServices {
+			is.ImpactedServices[j] = r.name(svc, "name-", r.rules.Names)
+		}
+	}
+	for i := range issues {
+		is := &issues[i]
+		is.RootCause = r.text(is.RootCause)
+		is.Suggestion = r.text(is.Suggestion)
+		is.NodeCondition = r.text(is.NodeCondition)
+		for k, v := range is.Labels {
+			is.Labels[k] = r.text(v)
+		}
+		if r.rules.KeepEvents {
+			is.LastEvent = r.text(is.LastEvent)
+		} else {
+			is.LastEvent = ""
+		}
+	}
+}
+
+// Meta anonymizes the scope, warnings, nodes, teams and managed cluster of
+// a report, and the issues its baseline resolved
+func (r *Redactor) Meta(m *Meta) {
+	if m.Baseline != nil {
+		r.Issues(m.Baseline.Resolved)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m.Cluster = r.name(m.Cluster, "cluster-", r.rules.Names)
+	for i, ns := range m.Namespaces {
+		m.Namespaces[i] = r.namespace(ns)
+	}
+	for i, ns := range m.IgnoredNamespaces {
+		m.IgnoredNamespaces[i] = r.namespace(ns)
+	}
+	for i, e := range m.Errors {
+		m.Errors[i] = r.text(e)
+	}
+	for i := range m.Teams {
+		m.Teams[i].Team = r.name(m.Teams[i].Team, "team-", r.rules.Names)
+	}
+	m.Tenant = r.text(m.Tenant)
+	for i := range m.Nodes {
+		g := &m.Nodes[i]
+		if g.Node != NoNode {
+			g.Node = r.name(g.Node, "node-", r.rules.Nodes)
+		}
+		g.NodePool = r.name(g.NodePool, "pool-", r.rules.Nodes)
+		for j, pdb := range g.DrainBlockers {
+			ns, name, _ := strings.Cut(pdb, "/")
+			g.DrainBlockers[j] = r.namespace(ns) + "/" + r.name(name, "name-", r.rules.Names)
+		}
+	}
+	if mc := m.Managed; mc != nil {
+		mc.Cluster = r.name(mc.Cluster, "cluster-", r.rules.Names)
+		for i := range mc.NodePools {
+			mc.NodePools[i].Name = r.name(mc.NodePools[i].Name, "pool-", r.rules.Nodes)
+			mc.NodePools[i].Message = r.text(mc.NodePools[i].Message)
+		}
+		mc.Message = r.text(mc.Message)
+		// Names were registered above, so the context refers to them redacted
+		for i, c := range mc.Context {
+			mc.Context[i] = r.text(c)
+		}
+	}
+}
+
+// Summary returns summary keyed by the anonymized namespaces
+func (r *Redactor) Summary(summary map[string]types.SeveritySummary) map[string]types.SeveritySummary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[string]types.SeveritySummary, len(summary))
+	for ns, s := range summary {
+		out[r.namespace(ns)] = s
+	}
+	return out
+}
+
+// Overview anonymizes the node names of the cluster overview
+func (r *Redactor) Overview(o *capacity.Overview) {
+	if o == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range o.Nodes {
+		o.Nodes[i].Name = r.name(o.Nodes[i].Name, "node-", r.rules.Nodes)
+	}
+}
+
+// wordPattern matches the name-like words of free text
+var wordPattern = regexp.MustCompile(`[A-Za-z0-9][A-Za-z0-9_.-]*`)
+
+// text replaces the known names in s. Words are matched whole, or by their
+// dot-separated parts (e.g. web.shop.svc)
+func (r *Redactor) text(s string) string {
+	if s == "" || len(r.names) == 0 {
+		return s
+	}
+	return wordPattern.ReplaceAllStringFunc(s, func(word string) string {
+		if out, ok := r.names[word]; ok {
+			return out
+		}
+		if !strings.Contains(word, ".") {
+			return word
+		}
+		parts := strings.Split(word, ".")
+		for i, p := range parts {
+			if out, ok := r.names[p]; ok {
+				parts[i] = out
+			}
+		}
+		return strings.Join(parts, ".")
+	})
+}
@@ -6,24 +6,51 @@ import (
 	"encoding/json"
 	"fmt"
 	"html"
+	"maps"
 	"os"
 	"path/filepath"
-	"sort"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/ductnn/k8s-scanner/pkg/checks"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/capacity"
+	"github.com/ductnn/k8s-scanner/pkg/severity"
 	"github.com/ductnn/k8s-scanner/pkg/types"
 )
 
 type ExportKind string
 
 const (
-	ExportJSON ExportKind = "json"
-	ExportCSV  ExportKind = "csv"
-	ExportMD   ExportKind = "md"
-	ExportHTML ExportKind = "html"
+	ExportJSON   ExportKind = "json"
+	ExportNDJSON ExportKind = "ndjson"
+	ExportCSV    ExportKind = "csv"
+	ExportMD     ExportKind = "md"
+	ExportHTML   ExportKind = "html"
+	// ExportInventory is the CycloneDX asset inventory, written by the caller
+	// from the scanned pods rather than by WriteAll
+	ExportInventory ExportKind = "inventory"
 )
 
+// ParseExportKind maps a format name (case-insensitive) to an ExportKind
+func ParseExportKind(name string) (ExportKind, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "json":
+		return ExportJSON, true
+	case "ndjson", "jsonl":
+		return ExportNDJSON, true
+	case "csv":
+		return ExportCSV, true
+	case "md", "markdown":
+		return ExportMD, true
+	case "html":
+		return ExportHTML, true
+	case "inventory", "cyclonedx":
+		return ExportInventory, true
+	}
+	return "", false
+}
+
 func EnsureDir(dir string) error {
 	if dir == "" {
 		return nil
@@ -31,32 +58,25 @@ func EnsureDir(dir string) error {
 	return os.MkdirAll(dir, 0o755)
 }
 
-func WriteAll(outdir string, basename string, issues []types.Issue, summary map[string]types.SeveritySummary, kinds []ExportKind) error {
+// WriteAll writes the report in every requested format. overview is optional;
+// when set, a Cluster Overview section is added. meta is optional and only
+// written to the JSON report, except for its team compliance which every
+// format shows.
+func WriteAll(outdir string, basename string, issues []types.Issue, summary map[string]types.SeveritySummary, overview *capacity.Overview, meta *Meta, kinds []ExportKind) error {
 	if err := EnsureDir(outdir); err != nil {
 		return err
 	}
+
 	for _, k := range kinds {
+		if k == ExportInventory {
+			continue
+		}
 		filename := filepath.Join(outdir, fmt.Sprintf("%s.%s", basename, string(k)))
-		var b []byte
-		var err error
-
-		switch k {
-		case ExportJSON:
-			obj := map[string]any{
-				"generated_at": time.Now().Format(time.RFC3339),
-				"issues":       issues,
-				"summary":      summary,
-			}
-			b, err = json.MarshalIndent(obj, "", "  ")
-		case ExportCSV:
-			b, err = csvReport(issues)
-		case ExportMD:
-			b = []byte(mdReport(issues, summary))
-		case ExportHTML:
-			b = []byte(htmlReport(issues, summary))
-		default:
-			err = fmt.Errorf("unsupported export: %s", k)
+		// basename may contain subdirectories (--filename-template)
+		if err := EnsureDir(filepath.Dir(filename)); err != nil {
+			return err
 		}
+		b, err := Render(k, issues, summary, overview, meta)
 		if err != nil {
 			return err
 		}
@@ -67,6 +87,51 @@ func WriteAll(outdir string, basename string, issues []types.Issue, summary map[
 	return nil
 }
 
+// Render returns the report in one export format, as WriteAll writes it
+func Render(k ExportKind, issues []types.Issue, summary map[string]types.SeveritySummary, overview *capacity.Overview, meta *Meta) ([]byte, error) {
+	// Sort a copy so every export lists issues in the same order
+	sorted := make([]types.Issue, len(issues))
+	copy(sorted, issues)
+	SortIssues(sorted)
+	issues = sorted
+	var teams []TeamStatus
+	nodes := GroupByNode(issues, nil)
+	if meta != nil {
+		teams = meta.Teams
+		if meta.Nodes != nil {
+			nodes = meta.Nodes
+		}
+	}
+
+	switch k {
+	case ExportJSON:
+		obj := map[string]any{
+			"generated_at": time.Now().Format(time.RFC3339),
+			"issues":       issues,
+			"summary":      summary,
+		}
+		if overview != nil {
+			obj["overview"] = overview
+		}
+		if meta != nil {
+			obj["meta"] = meta
+		}
+		return json.MarshalIndent(obj, "", "  ")
+	case ExportNDJSON:
+		buf := &bytes.Buffer{}
+		err := NewNDJSONWriter(buf).Write(issues)
+		return buf.Bytes(), err
+	case ExportCSV:
+		return csvReport(issues)
+	case ExportMD:
+		return []byte(mdReport(issues, summary, overview, teams)), nil
+	case ExportHTML:
+		return []byte(htmlReport(issues, summary, overview, teams, nodes)), nil
+	default:
+		return nil, fmt.Errorf("unsupported export: %s", k)
+	}
+}
+
 func csvReport(issues []types.Issue) ([]byte, error) {
 	buf := &bytes.Buffer{}
 
@@ -75,53 +140,93 @@ func csvReport(issues []types.Issue) ([]byte, error) {
 
 	w := csv.NewWriter(buf)
 	_ = w.Write([]string{
-		"timestamp", "namespace", "kind", "name", "severity", "pod_status",
-		"reason", "root_cause", "node_name", "restart_count", "last_event",
+		"timestamp", "namespace", "kind", "name", "container", "severity", "pod_status",
+		"reason", "check_id", "root_cause", "suggestion", "node_name", "node_condition", "node_pool", "instance_type", "zone", "impacted_services", "team", "restart_count", "last_event", "in_state", "first_seen", "age", "labels",
 	})
 	for _, is := range issues {
 		_ = w.Write([]string{
-			is.Timestamp, is.Namespace, is.Kind, is.Name, is.Severity, is.PodStatus,
-			is.Reason, is.RootCause, is.NodeName, fmt.Sprint(is.RestartCount), is.LastEvent,
+			is.Timestamp, is.Namespace, is.Kind, is.Name, is.Container, string(is.Severity), is.PodStatus,
+			is.Reason, is.CheckID, is.RootCause, is.Suggestion, is.NodeName, is.NodeCondition, is.NodePool, is.InstanceType, is.Zone, strings.Join(is.ImpactedServices, ";"), is.Team, fmt.Sprint(is.RestartCount), is.LastEvent,
+			FormatAge(StateDuration(is)), is.FirstSeen, FormatAge(IssueAge(is)), FormatLabels(is.Labels, ";"),
 		})
 	}
 	w.Flush()
 	return buf.Bytes(), w.Error()
 }
 
-func mdReport(issues []types.Issue, summary map[string]types.SeveritySummary) string {
+func mdReport(issues []types.Issue, summary map[string]types.SeveritySummary, overview *capacity.Overview, teams []TeamStatus) string {
 	var sb strings.Builder
 	sb.WriteString("# Kubernetes Issues Report\n\n")
 	sb.WriteString(fmt.Sprintf("_Generated: %s_\n\n", time.Now().Format(time.RFC3339)))
 
+	// Cluster overview
+	if overview != nil {
+		sb.WriteString("## Cluster Overview\n\n")
+		sb.WriteString(fmt.Sprintf("- Nodes: %d (%d ready)\n", overview.NodeCount, overview.ReadyNodes))
+		sb.WriteString(fmt.Sprintf("- Pods: %d / %d max\n", overview.Pods, overview.MaxPods))
+		sb.WriteString(fmt.Sprintf("- CPU requested: %s / %s allocatable (%.0f%%)\n",
+			capacity.FormatCPU(overview.RequestedCPUMilli), capacity.FormatCPU(overview.AllocatableCPUMilli),
+			capacity.Percent(overview.RequestedCPUMilli, overview.AllocatableCPUMilli)))
+		sb.WriteString(fmt.Sprintf("- Memory requested: %s / %s allocatable (%.0f%%)\n\n",
+			capacity.FormatMemory(overview.RequestedMemoryBytes), capacity.FormatMemory(overview.AllocatableMemoryBytes),
+			capacity.Percent(overview.RequestedMemoryBytes, overview.AllocatableMemoryBytes)))
+		sb.WriteString("| Node | Ready | Pods | CPU Requested | Memory Requested |\n|---|---|---:|---:|---:|\n")
+		for _, n := range overview.Nodes {
+			sb.WriteString(fmt.Sprintf("| %s | %t | %d/%d | %s/%s | %s/%s |\n", n.Name, n.Ready, n.Pods, n.MaxPods,
+				capacity.FormatCPU(n.RequestedCPUMilli), capacity.FormatCPU(n.AllocatableCPUMilli),
+				capacity.FormatMemory(n.RequestedMemoryBytes), capacity.FormatMemory(n.AllocatableMemoryBytes)))
+		}
+		sb.WriteString("\n")
+	}
+
 	// Summary
 	sb.WriteString("## Summary by Namespace\n\n")
-	sb.WriteString("| Namespace | Critical | High | Medium | Low |\n|---|---:|---:|---:|---:|\n")
-	ns := make([]string, 0, len(summary))
-	for k := range summary {
-		ns = append(ns, k)
-	}
-	sort.Strings(ns)
-	for _, n := range ns {
+	sb.WriteString("| Namespace | Critical | High | Medium | Low | Info |\n|---|---:|---:|---:|---:|---:|\n")
+	for _, n := range SortedNamespaces(summary) {
 		s := summary[n]
-		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d |\n", n, s.Critical, s.High, s.Medium, s.Low))
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d | %d |\n", n, s.Critical, s.High, s.Medium, s.Low, s.Info))
 	}
 	sb.WriteString("\n")
 
+	// Team compliance
+	if len(teams) > 0 {
+		sb.WriteString("## Team Compliance\n\n")
+		sb.WriteString("| Team | Critical | High | Medium | Low | Compliant | Violations |\n|---|---:|---:|---:|---:|---|---|\n")
+		for _, t := range teams {
+			sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d | %t | %s |\n", escapeMD(t.Team), t.Summary.Critical, t.Summary.High, t.Summary.Medium, t.Summary.Low,
+				t.Compliant, escapeMD(strings.Join(t.Violations, "; "))))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Top offenders
+	if top := BuildTopOffenders(issues, TopN); !top.Empty() {
+		sb.WriteString(fmt.Sprintf("## Top %d\n\n", TopN))
+		for _, l := range top.Lists() {
+			if len(l.Entries) == 0 {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("### %s\n\n| Name | %s |\n|---|---:|\n", l.Title, l.Column))
+			for _, o := range l.Entries {
+				sb.WriteString(fmt.Sprintf("| %s | %d |\n", escapeMD(o.Name), o.Count))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
 	// Issues
 	sb.WriteString("## Issues\n\n")
-	sb.WriteString("| Time | Namespace | Kind | Name | Severity | PodStatus | Reason | RootCause | Node |\n|---|---|---|---|---|---|---|---|---|\n")
+	sb.WriteString("| Time | Namespace | Kind | Name | Container | Severity | PodStatus | Reason | RootCause | Suggestion | Node | Node Condition | Services | In State | Age |\n|---|---|---|---|---|---|---|---|---|---|---|---|---|---|---|\n")
 	for _, is := range issues {
-		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
-			is.Timestamp, is.Namespace, is.Kind, is.Name, strings.ToUpper(is.Severity), is.PodStatus,
-			escapeMD(is.Reason), escapeMD(is.RootCause), is.NodeName))
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+			is.Timestamp, is.Namespace, is.Kind, is.Name, is.Container, strings.ToUpper(string(is.Severity)), is.PodStatus,
+			escapeMD(reasonWithCheck(is)), escapeMD(is.RootCause), escapeMD(is.Suggestion), escapeMD(NodeWithContext(is)), escapeMD(is.NodeCondition), strings.Join(is.ImpactedServices, ", "), FormatAge(StateDuration(is)), FormatAge(IssueAge(is))))
 	}
 	return sb.String()
 }
 
-func htmlReport(issues []types.Issue, summary map[string]types.SeveritySummary) string {
-	var sb strings.Builder
-	sb.WriteString("<!doctype html><html><head><meta charset='utf-8'><title>K8s Report</title>")
-	sb.WriteString(`<style>
+// htmlStyle is shared by the HTML report and diff report
+const htmlStyle = `<style>
 body{font-family:system-ui,Arial,sans-serif;padding:24px}
 h1,h2{margin:0 0 12px}
 table{border-collapse:collapse;width:100%;margin:12px 0}
@@ -132,54 +237,159 @@ th{background:#f5f5f5;text-align:left}
 .badge.HIGH{background:#ea580c;color:#fff}
 .badge.MEDIUM{background:#ca8a04;color:#fff}
 .badge.LOW{background:#0284c7;color:#fff}
+.badge.INFO{background:#64748b;color:#fff}
 .small{color:#666;font-size:12px}
-</style></head><body>`)
+</style>`
+
+// severityBadge renders a severity as a colored HTML label
+func severityBadge(level severity.Level) string {
+	s := strings.ToUpper(string(level))
+	return fmt.Sprintf("<span class='badge %s'>%s</span>", html.EscapeString(s), html.EscapeString(s))
+}
+
+func htmlReport(issues []types.Issue, summary map[string]types.SeveritySummary, overview *capacity.Overview, teams []TeamStatus, nodes []NodeGroup) string {
+	var sb strings.Builder
+	sb.WriteString("<!doctype html><html><head><meta charset='utf-8'><title>K8s Report</title>")
+	sb.WriteString(htmlStyle + "</head><body>")
 	sb.WriteString("<h1>Kubernetes Issues Report</h1>")
 	sb.WriteString(fmt.Sprintf("<div class='small'>Generated: %s</div>", html.EscapeString(time.Now().Format(time.RFC3339))))
 
-	// Summary
-	sb.WriteString("<h2>Summary by Namespace</h2><table><thead><tr><th>Namespace</th><th>Critical</th><th>High</th><th>Medium</th><th>Low</th></tr></thead><tbody>")
-	ns := make([]string, 0, len(summary))
-	for k := range summary {
-		ns = append(ns, k)
+	// Cluster overview
+	if overview != nil {
+		sb.WriteString("<h2>Cluster Overview</h2>")
+		sb.WriteString(fmt.Sprintf("<p>Nodes: %d (%d ready) &middot; Pods: %d / %d max &middot; CPU requested: %s / %s (%.0f%%) &middot; Memory requested: %s / %s (%.0f%%)</p>",
+			overview.NodeCount, overview.ReadyNodes, overview.Pods, overview.MaxPods,
+			capacity.FormatCPU(overview.RequestedCPUMilli), capacity.FormatCPU(overview.AllocatableCPUMilli),
+			capacity.Percent(overview.RequestedCPUMilli, overview.AllocatableCPUMilli),
+			capacity.FormatMemory(overview.RequestedMemoryBytes), capacity.FormatMemory(overview.AllocatableMemoryBytes),
+			capacity.Percent(overview.RequestedMemoryBytes, overview.AllocatableMemoryBytes)))
+		sb.WriteString("<table><thead><tr><th>Node</th><th>Ready</th><th>Pods</th><th>CPU Requested</th><th>Memory Requested</th></tr></thead><tbody>")
+		for _, n := range overview.Nodes {
+			sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%t</td><td>%d/%d</td><td>%s/%s</td><td>%s/%s</td></tr>",
+				html.EscapeString(n.Name), n.Ready, n.Pods, n.MaxPods,
+				capacity.FormatCPU(n.RequestedCPUMilli), capacity.FormatCPU(n.AllocatableCPUMilli),
+				capacity.FormatMemory(n.RequestedMemoryBytes), capacity.FormatMemory(n.AllocatableMemoryBytes)))
+		}
+		sb.WriteString("</tbody></table>")
 	}
-	sort.Strings(ns)
-	for _, n := range ns {
+
+	// Summary
+	sb.WriteString("<h2>Summary by Namespace</h2><table><thead><tr><th>Namespace</th><th>Critical</th><th>High</th><th>Medium</th><th>Low</th><th>Info</th></tr></thead><tbody>")
+	for _, n := range SortedNamespaces(summary) {
 		s := summary[n]
-		sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td></tr>",
-			html.EscapeString(n), s.Critical, s.High, s.Medium, s.Low))
+		sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td></tr>",
+			html.EscapeString(n), s.Critical, s.High, s.Medium, s.Low, s.Info))
 	}
 	sb.WriteString("</tbody></table>")
 
+	// Team compliance
+	if len(teams) > 0 {
+		sb.WriteString("<h2>Team Compliance</h2><table><thead><tr><th>Team</th><th>Critical</th><th>High</th><th>Medium</th><th>Low</th><th>Compliant</th><th>Violations</th></tr></thead><tbody>")
+		for _, t := range teams {
+			sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%t</td><td>%s</td></tr>",
+				html.EscapeString(t.Team), t.Summary.Critical, t.Summary.High, t.Summary.Medium, t.Summary.Low, t.Compliant, html.EscapeString(strings.Join(t.Violations, "; "))))
+		}
+		sb.WriteString("</tbody></table>")
+	}
+
+	// Issues per node
+	if len(nodes) > 0 {
+		sb.WriteString("<h2>Issues by Node</h2><table><thead><tr><th>Node</th><th>Issues</th><th>Critical</th><th>High</th><th>Medium</th><th>Low</th><th>Info</th><th>Drain</th></tr></thead><tbody>")
+		for _, g := range nodes {
+			sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%s</td></tr>", html.EscapeString(g.Label()), g.Issues,
+				g.Summary.Critical, g.Summary.High, g.Summary.Medium, g.Summary.Low, g.Summary.Info, html.EscapeString(g.DrainHint())))
+		}
+		sb.WriteString("</tbody></table>")
+	}
+
+	// Top offenders
+	if top := BuildTopOffenders(issues, TopN); !top.Empty() {
+		sb.WriteString(fmt.Sprintf("<h2>Top %d</h2>", TopN))
+		for _, l := range top.Lists() {
+			if len(l.Entries) == 0 {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("<h3>%s</h3><table><thead><tr><th>Name</th><th>%s</th></tr></thead><tbody>", l.Title, l.Column))
+			for _, o := range l.Entries {
+				sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%d</td></tr>", html.EscapeString(o.Name), o.Count))
+			}
+			sb.WriteString("</tbody></table>")
+		}
+	}
+
 	// Issues
 	sb.WriteString("<h2>Issues</h2><table><thead><tr>")
-	cols := []string{"Time", "Namespace", "Kind", "Name", "Severity", "PodStatus", "Reason", "RootCause", "Node", "RestartCount", "LastEvent"}
+	cols := []string{"Time", "Namespace", "Kind", "Name", "Container", "Severity", "PodStatus", "Reason", "RootCause", "Suggestion", "Node", "NodeCondition", "ImpactedServices", "RestartCount", "LastEvent", "InState", "FirstSeen", "Age"}
+	// Labels are only captured when configured
+	withLabels := slices.ContainsFunc(issues, func(is types.Issue) bool { return len(is.Labels) > 0 })
+	if withLabels {
+		cols = append(cols, "Labels")
+	}
 	for _, c := range cols {
 		sb.WriteString("<th>" + c + "</th>")
 	}
 	sb.WriteString("</tr></thead><tbody>")
 	for _, is := range issues {
 		sb.WriteString("<tr>")
-		severityBadge := fmt.Sprintf("<span class='badge %s'>%s</span>", strings.ToUpper(is.Severity), strings.ToUpper(is.Severity))
 		sb.WriteString("<td>" + html.EscapeString(is.Timestamp) + "</td>")
 		sb.WriteString("<td>" + html.EscapeString(is.Namespace) + "</td>")
 		sb.WriteString("<td>" + html.EscapeString(is.Kind) + "</td>")
 		sb.WriteString("<td>" + html.EscapeString(is.Name) + "</td>")
-		sb.WriteString("<td>" + severityBadge + "</td>") // Don't escape HTML badge
+		sb.WriteString("<td>" + html.EscapeString(is.Container) + "</td>")
+		sb.WriteString("<td>" + severityBadge(is.Severity) + "</td>") // Don't escape HTML badge
 		sb.WriteString("<td>" + html.EscapeString(is.PodStatus) + "</td>")
-		sb.WriteString("<td>" + html.EscapeString(is.Reason) + "</td>")
+		sb.WriteString(reasonCell(is))
 		sb.WriteString("<td>" + html.EscapeString(is.RootCause) + "</td>")
-		sb.WriteString("<td>" + html.EscapeString(is.NodeName) + "</td>")
+		sb.WriteString("<td>" + html.EscapeString(is.Suggestion) + "</td>")
+		sb.WriteString("<td>" + html.EscapeString(NodeWithContext(is)) + "</td>")
+		sb.WriteString("<td>" + html.EscapeString(is.NodeCondition) + "</td>")
+		sb.WriteString("<td>" + html.EscapeString(strings.Join(is.ImpactedServices, ", ")) + "</td>")
 		sb.WriteString("<td>" + html.EscapeString(fmt.Sprint(is.RestartCount)) + "</td>")
 		sb.WriteString("<td>" + html.EscapeString(is.LastEvent) + "</td>")
+		sb.WriteString("<td>" + html.EscapeString(FormatAge(StateDuration(is))) + "</td>")
+		sb.WriteString("<td>" + html.EscapeString(is.FirstSeen) + "</td>")
+		sb.WriteString("<td>" + html.EscapeString(FormatAge(IssueAge(is))) + "</td>")
+		if withLabels {
+			sb.WriteString("<td>" + html.EscapeString(FormatLabels(is.Labels, ", ")) + "</td>")
+		}
 		sb.WriteString("</tr>")
 	}
 	sb.WriteString("</tbody></table></body></html>")
 	return sb.String()
 }
 
+// FormatLabels joins labels as key=value pairs sorted by key
+func FormatLabels(labels map[string]string, sep string) string {
+	pairs := make([]string, 0, len(labels))
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		pairs = append(pairs, k+"="+labels[k])
+	}
+	return strings.Join(pairs, sep)
+}
+
 func escapeMD(s string) string {
 	s = strings.ReplaceAll(s, "|", "\\|")
 	s = strings.ReplaceAll(s, "\n", " ")
 	return s
 }
+
+// reasonWithCheck is the reason of an issue followed by its check ID
+func reasonWithCheck(is types.Issue) string {
+	if is.CheckID == "" {
+		return is.Reason
+	}
+	return is.Reason + " (" + is.CheckID + ")"
+}
+
+// reasonCell renders the reason of an issue with its check ID, described
+// on hover
+func reasonCell(is types.Issue) string {
+	if is.CheckID == "" {
+		return "<td>" + html.EscapeString(is.Reason) + "</td>"
+	}
+	title := ""
+	if c, ok := checks.Lookup(is.CheckID); ok {
+		title = ` title="` + html.EscapeString(c.Description) + `"`
+	}
+	return "<td" + title + ">" + html.EscapeString(is.Reason) + " <small>" + html.EscapeString(is.CheckID) + "</small></td>"
+}
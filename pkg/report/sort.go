@@ -0,0 +1,45 @@
+package report
+
+import (
+	"sort"
+
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// SortIssues orders issues in place by namespace, kind, name, container and severity
+// (most severe first), so reports are stable across runs
+func SortIssues(issues []types.Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issueLess(issues[i], issues[j])
+	})
+}
+
+func issueLess(a, b types.Issue) bool {
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	if a.Kind != b.Kind {
+		return a.Kind < b.Kind
+	}
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+	if a.Container != b.Container {
+		return a.Container < b.Container
+	}
+	if ra, rb := severity.Rank(a.Severity), severity.Rank(b.Severity); ra != rb {
+		return ra > rb
+	}
+	return a.Reason < b.Reason
+}
+
+// SortedNamespaces returns the namespaces of a summary in alphabetical order
+func SortedNamespaces(summary map[string]types.SeveritySummary) []string {
+	ns := make([]string, 0, len(summary))
+	for k := range summary {
+		ns = append(ns, k)
+	}
+	sort.Strings(ns)
+	return ns
+}
@@ -0,0 +1,87 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// UnassignedTeam groups the issues whose pod and namespace have no team label
+const UnassignedTeam = "(unassigned)"
+
+// TeamBudget is the number of issues a team may have before it is out of
+// compliance; nil fields are not limited
+type TeamBudget struct {
+	MaxCritical *int `json:"maxCritical,omitempty"`
+	MaxHigh     *int `json:"maxHigh,omitempty"`
+}
+
+// TeamBudgets holds the budget of each team; Default applies to the teams
+// (and unassigned issues) without their own
+type TeamBudgets struct {
+	Default TeamBudget            `json:"default"`
+	Teams   map[string]TeamBudget `json:"teams,omitempty"`
+}
+
+// TeamStatus is the compliance of one team with its budget
+type TeamStatus struct {
+	Team       string                `json:"team"`
+	Summary    types.SeveritySummary `json:"summary"`
+	Budget     TeamBudget            `json:"budget"`
+	Compliant  bool                  `json:"compliant"`
+	Violations []string              `json:"violations,omitempty"`
+}
+
+// CountByTeam adds issues to per-team severity counts
+func CountByTeam(counts map[string]types.SeveritySummary, issues []types.Issue) {
+	for _, is := range issues {
+		team := is.Team
+		if team == "" {
+			team = UnassignedTeam
+		}
+		s := counts[team]
+		switch is.Severity {
+		case severity.Critical:
+			s.Critical++
+		case severity.High:
+			s.High++
+		case severity.Medium:
+			s.Medium++
+		case severity.Info:
+			s.Info++
+		default:
+			s.Low++
+		}
+		counts[team] = s
+	}
+}
+
+// CheckTeams compares per-team counts with their budgets, sorted with the
+// teams out of compliance first
+func CheckTeams(counts map[string]types.SeveritySummary, budgets TeamBudgets) []TeamStatus {
+	out := make([]TeamStatus, 0, len(counts))
+	for team, s := range counts {
+		budget, ok := budgets.Teams[team]
+		if !ok {
+			budget = budgets.Default
+		}
+		st := TeamStatus{Team: team, Summary: s, Budget: budget}
+		if budget.MaxCritical != nil && s.Critical > *budget.MaxCritical {
+			st.Violations = append(st.Violations, fmt.Sprintf("%d critical issues (budget %d)", s.Critical, *budget.MaxCritical))
+		}
+		if budget.MaxHigh != nil && s.High > *budget.MaxHigh {
+			st.Violations = append(st.Violations, fmt.Sprintf("%d high issues (budget %d)", s.High, *budget.MaxHigh))
+		}
+		st.Compliant = len(st.Violations) == 0
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Compliant != out[j].Compliant {
+			return !out[i].Compliant
+		}
+		return out[i].Team < out[j].Team
+	})
+	return out
+}
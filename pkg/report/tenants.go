@@ -0,0 +1,118 @@
+package report
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// TenantsDir is the directory of the reports directory holding the
+// per-tenant reports, one subdirectory per tenant
+const TenantsDir = "tenants"
+
+// Ways to split a report between tenants
+const (
+	SplitNamespace = "namespace"
+	SplitTeam      = "team-label"
+)
+
+// SplitModes lists the valid --split-by values
+var SplitModes = []string{SplitNamespace, SplitTeam}
+
+// Directories of the tenants without a name. Namespace and label values
+// cannot start with "_", so they do not clash with real tenants.
+const (
+	clusterTenant    = "_cluster"
+	unassignedTenant = "_unassigned"
+)
+
+// SplitByTenant groups issues by namespace (cluster-scoped issues together)
+// or by team (unassigned issues together), keyed by tenant directory name
+func SplitByTenant(issues []types.Issue, by string) map[string][]types.Issue {
+	out := map[string][]types.Issue{}
+	for _, is := range issues {
+		var tenant string
+		switch by {
+		case SplitTeam:
+			tenant = is.Team
+			if tenant == "" {
+				tenant = unassignedTenant
+			}
+		default:
+			tenant = is.Namespace
+			if tenant == "" {
+				tenant = clusterTenant
+			}
+		}
+		out[tenant] = append(out[tenant], is)
+	}
+	return out
+}
+
+// WriteTenants writes a report of each tenant's own issues in
+// TenantsDir/<tenant>/<base>, so each can be handed to its tenant. The
+// reports leave out the cluster overview and the other tenants' teams and
+// nodes. It returns the base of every report written, relative to outdir.
+func WriteTenants(outdir, base, by string, issues []types.Issue, meta *Meta, kinds []ExportKind) ([]string, error) {
+	tenants := SplitByTenant(issues, by)
+	names := make([]string, 0, len(tenants))
+	for name := range tenants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var bases []string
+	for _, name := range names {
+		list := tenants[name]
+		var m *Meta
+		if meta != nil {
+			m = tenantMeta(*meta, name, by, list)
+		}
+		tenantBase := path.Join(TenantsDir, name, base)
+		if err := WriteAll(outdir, tenantBase, list, summarize(list), nil, m, kinds); err != nil {
+			return bases, fmt.Errorf("tenant %s: %w", name, err)
+		}
+		bases = append(bases, tenantBase)
+	}
+	return bases, nil
+}
+
+// tenantMeta narrows the metadata of a report to one tenant
+func tenantMeta(m Meta, tenant, by string, issues []types.Issue) *Meta {
+	m.Tenant = tenant
+	if by == SplitNamespace && tenant != clusterTenant {
+		m.Namespaces = []string{tenant}
+	}
+	team := tenant
+	if team == unassignedTenant {
+		team = UnassignedTeam
+	}
+	var teams []TeamStatus
+	for _, t := range m.Teams {
+		if by == SplitTeam && t.Team == team {
+			teams = append(teams, t)
+		}
+	}
+	m.Teams = teams
+	if m.Nodes != nil {
+		blockers := map[string][]string{}
+		for _, n := range m.Nodes {
+			if n.Drain != "" {
+				blockers[n.Node] = n.DrainBlockers
+			}
+		}
+		m.Nodes = GroupByNode(issues, blockers)
+	}
+	return &m
+}
+
+// summarize counts issues by namespace and severity
+func summarize(issues []types.Issue) map[string]types.SeveritySummary {
+	out := map[string]types.SeveritySummary{}
+	for _, is := range issues {
+		out[is.Namespace] = addSeverity(out[is.Namespace], is.Severity, 1)
+	}
+	return out
+}
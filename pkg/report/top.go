@@ -0,0 +1,100 @@
+package report
+
+import (
+	"sort"
+
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// TopN is the length of each top offenders list
+const TopN = 10
+
+// Offender is an entry of a top offenders list
+type Offender struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// TopOffenders are the worst pods, namespaces, nodes and reasons of a scan,
+// to start triage on noisy clusters
+type TopOffenders struct {
+	// Restarts are pods (namespace/name) by highest container restart count
+	Restarts []Offender `json:"restarts"`
+	// CriticalNamespaces are namespaces by number of critical issues
+	CriticalNamespaces []Offender `json:"critical_namespaces"`
+	// Nodes are nodes by number of issues they host
+	Nodes []Offender `json:"nodes"`
+	// Reasons are the most frequent issue reasons
+	Reasons []Offender `json:"reasons"`
+}
+
+// Empty reports whether there is nothing to list
+func (t TopOffenders) Empty() bool {
+	return len(t.Restarts) == 0 && len(t.CriticalNamespaces) == 0 && len(t.Nodes) == 0 && len(t.Reasons) == 0
+}
+
+// BuildTopOffenders computes the top n of each list from the issues. Entries
+// with a zero count are left out.
+func BuildTopOffenders(issues []types.Issue, n int) TopOffenders {
+	restarts := map[string]int{}
+	critical := map[string]int{}
+	nodes := map[string]int{}
+	reasons := map[string]int{}
+	for _, is := range issues {
+		if is.Kind == "Pod" && is.RestartCount > 0 {
+			pod := is.Namespace + "/" + is.Name
+			restarts[pod] = max(restarts[pod], int(is.RestartCount))
+		}
+		if is.Severity == severity.Critical {
+			critical[is.Namespace]++
+		}
+		if is.NodeName != "" {
+			nodes[is.NodeName]++
+		}
+		if is.Reason != "" {
+			reasons[is.Reason]++
+		}
+	}
+	return TopOffenders{
+		Restarts:           topOf(restarts, n),
+		CriticalNamespaces: topOf(critical, n),
+		Nodes:              topOf(nodes, n),
+		Reasons:            topOf(reasons, n),
+	}
+}
+
+// topOf returns the n highest counts, ties by name
+func topOf(counts map[string]int, n int) []Offender {
+	out := make([]Offender, 0, len(counts))
+	for name, c := range counts {
+		out = append(out, Offender{Name: name, Count: c})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Name < out[j].Name
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// OffenderList is a titled top offenders list; Column names its counts
+type OffenderList struct {
+	Title   string
+	Column  string
+	Entries []Offender
+}
+
+// Lists returns the top offenders lists in display order
+func (t TopOffenders) Lists() []OffenderList {
+	return []OffenderList{
+		{"Pods with Most Restarts", "Restarts", t.Restarts},
+		{"Namespaces with Most Critical Issues", "Critical", t.CriticalNamespaces},
+		{"Nodes Hosting Most Issues", "Issues", t.Nodes},
+		{"Most Frequent Reasons", "Issues", t.Reasons},
+	}
+}
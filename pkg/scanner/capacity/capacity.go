@@ -0,0 +1,234 @@
+// Package capacity is the opt-in scanner that compares metrics-server usage
+// with node allocatable and namespace requests.
+package capacity
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Options configures the capacity thresholds
+type Options struct {
+	// NodeCPUPercent flags nodes using more than this share of allocatable CPU
+	NodeCPUPercent float64
+	// NodeMemoryPercent flags nodes using more than this share of allocatable memory
+	NodeMemoryPercent float64
+	// UsageRatio flags namespaces whose usage is more than UsageRatio times
+	// their requests, or less than requests divided by UsageRatio
+	UsageRatio float64
+	// OverProvisionRatio flags workloads requesting more than this many times
+	// their usage (0 disables)
+	OverProvisionRatio float64
+	// CPUCostPerCoreHour and MemoryCostPerGiBHour price the estimated waste of
+	// over-provisioned workloads (optional)
+	CPUCostPerCoreHour   float64
+	MemoryCostPerGiBHour float64
+}
+
+// DefaultOptions returns the thresholds used when none are configured
+func DefaultOptions() Options {
+	return Options{
+		NodeCPUPercent:    90,
+		NodeMemoryPercent: 90,
+		UsageRatio:        3,
+	}
+}
+
+// Scan fetches nodes, pods and their usage and returns capacity issues.
+// Namespace checks are limited to namespaces (all when empty).
+func Scan(ctx context.Context, client kubernetes.Interface, namespaces []string, ignoredNamespaces map[string]bool, opts Options) ([]types.Issue, error) {
+	return ScanCluster(ctx, k8s.NewClusterSnapshot(client, namespaces), ignoredNamespaces, opts)
+}
+
+// ScanCluster is Scan reading nodes and pods through a shared ClusterSnapshot.
+// Namespaces whose pods could not be listed are left out.
+func ScanCluster(ctx context.Context, cs *k8s.ClusterSnapshot, ignoredNamespaces map[string]bool, opts Options) ([]types.Issue, error) {
+	nodeUsage, err := FetchNodeUsage(ctx, cs.Client())
+	if err != nil {
+		return nil, err
+	}
+	podUsage, err := FetchPodUsage(ctx, cs.Client())
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := cs.Nodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	pods, _, err := cs.Pods(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	// The snapshot's pods are shared with other scanners, so filter into a new slice
+	filtered := make([]v1.Pod, 0, len(pods))
+	for _, p := range pods {
+		if !ignoredNamespaces[p.Namespace] {
+			filtered = append(filtered, p)
+		}
+	}
+
+	issues := CheckNodes(nodes, nodeUsage, opts)
+	issues = append(issues, CheckNamespaces(filtered, podUsage, opts)...)
+	issues = append(issues, CheckOverProvisioned(filtered, podUsage, opts)...)
+	return issues, nil
+}
+
+// CheckNodes flags nodes whose usage is above the configured share of allocatable
+func CheckNodes(nodes []v1.Node, usage NodeUsage, opts Options) []types.Issue {
+	var issues []types.Issue
+	timestamp := time.Now().Format(time.RFC3339)
+
+	for _, node := range nodes {
+		u, ok := usage[node.Name]
+		if !ok {
+			continue
+		}
+		allocCPU := node.Status.Allocatable.Cpu().MilliValue()
+		allocMem := node.Status.Allocatable.Memory().Value()
+
+		if pct := percent(u.CPUMilli, allocCPU); opts.NodeCPUPercent > 0 && pct > opts.NodeCPUPercent {
+			issues = append(issues, nodeIssue(node.Name, "NodeHighCPU", timestamp,
+				fmt.Sprintf("Node dùng %.0f%% CPU allocatable (ngưỡng %.0f%%) — pod có thể bị throttle, cần scale node hoặc giảm tải.", pct, opts.NodeCPUPercent)))
+		}
+		if pct := percent(u.MemoryBytes, allocMem); opts.NodeMemoryPercent > 0 && pct > opts.NodeMemoryPercent {
+			issues = append(issues, nodeIssue(node.Name, "NodeHighMemory", timestamp,
+				fmt.Sprintf("Node dùng %.0f%% memory allocatable (ngưỡng %.0f%%) — nguy cơ OOMKill và eviction.", pct, opts.NodeMemoryPercent)))
+		}
+	}
+	return issues
+}
+
+// CheckNamespaces compares the summed usage of running pods in each namespace
+// with their summed requests
+func CheckNamespaces(pods []v1.Pod, usage PodUsage, opts Options) []types.Issue {
+	if opts.UsageRatio <= 1 {
+		return nil
+	}
+
+	requested := make(map[string]Usage)
+	used := make(map[string]Usage)
+	for _, p := range pods {
+		if p.Status.Phase != v1.PodRunning {
+			continue
+		}
+		u, ok := usage[p.Namespace+"/"+p.Name]
+		if !ok {
+			continue
+		}
+		r := PodRequests(p)
+		req := requested[p.Namespace]
+		req.CPUMilli += r.CPUMilli
+		req.MemoryBytes += r.MemoryBytes
+		requested[p.Namespace] = req
+
+		cur := used[p.Namespace]
+		cur.CPUMilli += u.CPUMilli
+		cur.MemoryBytes += u.MemoryBytes
+		used[p.Namespace] = cur
+	}
+
+	namespaces := make([]string, 0, len(used))
+	for ns := range used {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+
+	var issues []types.Issue
+	timestamp := time.Now().Format(time.RFC3339)
+	for _, ns := range namespaces {
+		req, cur := requested[ns], used[ns]
+		for _, res := range []struct {
+			name      string
+			used, req int64
+			format    func(int64) string
+		}{
+			{"CPU", cur.CPUMilli, req.CPUMilli, FormatCPU},
+			{"memory", cur.MemoryBytes, req.MemoryBytes, FormatMemory},
+		} {
+			if res.req == 0 {
+				continue
+			}
+			ratio := float64(res.used) / float64(res.req)
+			switch {
+			case ratio > opts.UsageRatio:
+				issues = append(issues, namespaceIssue(ns, "NamespaceUsageAboveRequests", timestamp,
+					fmt.Sprintf("Namespace dùng %s %s, gấp %.1f lần requests (%s) — scheduler xếp pod dựa trên số liệu sai, dễ gây quá tải node.",
+						res.format(res.used), res.name, ratio, res.format(res.req))))
+			case ratio < 1/opts.UsageRatio:
+				issues = append(issues, namespaceIssue(ns, "NamespaceUsageBelowRequests", timestamp,
+					fmt.Sprintf("Namespace chỉ dùng %s %s trên %s requests (%.0f%%) — tài nguyên được giữ chỗ nhưng không dùng.",
+						res.format(res.used), res.name, res.format(res.req), ratio*100)))
+			}
+		}
+	}
+	return issues
+}
+
+// PodRequests sums the CPU and memory requests of a pod's app containers
+func PodRequests(p v1.Pod) Usage {
+	var u Usage
+	for _, c := range p.Spec.Containers {
+		u.CPUMilli += c.Resources.Requests.Cpu().MilliValue()
+		u.MemoryBytes += c.Resources.Requests.Memory().Value()
+	}
+	return u
+}
+
+// FormatCPU renders millicores, e.g. "250m" or "3.5"
+func FormatCPU(milli int64) string {
+	if milli < 1000 {
+		return fmt.Sprintf("%dm", milli)
+	}
+	return fmt.Sprintf("%.1f", float64(milli)/1000)
+}
+
+// FormatMemory renders bytes in Mi or Gi
+func FormatMemory(bytes int64) string {
+	const mi = 1024 * 1024
+	if bytes < 1024*mi {
+		return fmt.Sprintf("%dMi", bytes/mi)
+	}
+	return fmt.Sprintf("%.1fGi", float64(bytes)/(1024*mi))
+}
+
+func percent(used, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(used) * 100 / float64(total)
+}
+
+func nodeIssue(node string, reason string, timestamp string, rootCause string) types.Issue {
+	return types.Issue{
+		Kind:      "Node",
+		Name:      node,
+		Severity:  severity.FromReason(reason),
+		Reason:    reason,
+		RootCause: rootCause,
+		Timestamp: timestamp,
+		NodeName:  node,
+	}
+}
+
+func namespaceIssue(ns string, reason string, timestamp string, rootCause string) types.Issue {
+	return types.Issue{
+		Kind:      "Namespace",
+		Namespace: ns,
+		Name:      ns,
+		Severity:  severity.FromReason(reason),
+		Reason:    reason,
+		RootCause: rootCause,
+		Timestamp: timestamp,
+	}
+}
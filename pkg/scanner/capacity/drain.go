@@ -0,0 +1,102 @@
+package capacity
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// FetchDrainBlockers lists the PodDisruptionBudgets and the pods of the given
+// nodes and returns the PDBs that would block draining each node (see
+// DrainBlockers). Every requested node has an entry, empty when it is safe
+// to drain.
+func FetchDrainBlockers(ctx context.Context, client kubernetes.Interface, nodes []string) (map[string][]string, error) {
+	pdbs, err := client.PolicyV1().PodDisruptionBudgets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pod disruption budgets: %w", err)
+	}
+	var pods []v1.Pod
+	for _, node := range nodes {
+		list, err := client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("spec.nodeName", node).String(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods of node %s: %w", node, err)
+		}
+		pods = append(pods, list.Items...)
+	}
+	blockers := DrainBlockers(pods, pdbs.Items)
+	for _, node := range nodes {
+		if blockers[node] == nil {
+			blockers[node] = []string{}
+		}
+	}
+	return blockers, nil
+}
+
+// DrainBlockers maps nodes to the PDBs (namespace/name) that would block a
+// drain: a PDB selecting one of the node's running pods while allowing no
+// disruption. DaemonSet and mirror pods are not evicted by a drain and are
+// skipped.
+func DrainBlockers(pods []v1.Pod, pdbs []policyv1.PodDisruptionBudget) map[string][]string {
+	type blocking struct {
+		name     string
+		selector labels.Selector
+	}
+	byNamespace := map[string][]blocking{}
+	for _, pdb := range pdbs {
+		if pdb.Status.DisruptionsAllowed > 0 || pdb.Spec.Selector == nil {
+			continue
+		}
+		sel, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || sel.Empty() {
+			continue
+		}
+		byNamespace[pdb.Namespace] = append(byNamespace[pdb.Namespace], blocking{pdb.Namespace + "/" + pdb.Name, sel})
+	}
+
+	seen := map[string]map[string]bool{}
+	for _, p := range pods {
+		if p.Spec.NodeName == "" || p.Status.Phase == v1.PodSucceeded || p.Status.Phase == v1.PodFailed || !evictable(p) {
+			continue
+		}
+		for _, b := range byNamespace[p.Namespace] {
+			if !b.selector.Matches(labels.Set(p.Labels)) {
+				continue
+			}
+			if seen[p.Spec.NodeName] == nil {
+				seen[p.Spec.NodeName] = map[string]bool{}
+			}
+			seen[p.Spec.NodeName][b.name] = true
+		}
+	}
+
+	out := make(map[string][]string, len(seen))
+	for node, names := range seen {
+		for name := range names {
+			out[node] = append(out[node], name)
+		}
+		sort.Strings(out[node])
+	}
+	return out
+}
+
+// evictable reports whether a drain evicts the pod
+func evictable(p v1.Pod) bool {
+	if _, mirror := p.Annotations[v1.MirrorPodAnnotationKey]; mirror {
+		return false
+	}
+	for _, ref := range p.OwnerReferences {
+		if ref.Controller != nil && *ref.Controller && ref.Kind == "DaemonSet" {
+			return false
+		}
+	}
+	return true
+}
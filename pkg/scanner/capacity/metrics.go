@@ -0,0 +1,105 @@
+package capacity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// metricsAPI is the metrics-server aggregated API path
+const metricsAPI = "/apis/metrics.k8s.io/v1beta1"
+
+// ErrMetricsUnavailable is returned when metrics.k8s.io is not served by the cluster
+var ErrMetricsUnavailable = errors.New("metrics.k8s.io is not available (is metrics-server installed?)")
+
+// Usage is the measured CPU (millicores) and memory (bytes) of an object
+type Usage struct {
+	CPUMilli    int64
+	MemoryBytes int64
+}
+
+// NodeUsage maps node name to its current usage
+type NodeUsage map[string]Usage
+
+// PodUsage maps "namespace/name" to the summed usage of the pod's containers
+type PodUsage map[string]Usage
+
+// nodeMetricsList mirrors metrics.k8s.io/v1beta1 NodeMetricsList
+type nodeMetricsList struct {
+	Items []struct {
+		Metadata metav1.ObjectMeta `json:"metadata"`
+		Usage    v1.ResourceList   `json:"usage"`
+	} `json:"items"`
+}
+
+// podMetricsList mirrors metrics.k8s.io/v1beta1 PodMetricsList
+type podMetricsList struct {
+	Items []struct {
+		Metadata   metav1.ObjectMeta `json:"metadata"`
+		Containers []struct {
+			Name  string          `json:"name"`
+			Usage v1.ResourceList `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+// FetchNodeUsage reads node usage from metrics-server
+func FetchNodeUsage(ctx context.Context, client kubernetes.Interface) (NodeUsage, error) {
+	var list nodeMetricsList
+	if err := getMetrics(ctx, client, metricsAPI+"/nodes", &list); err != nil {
+		return nil, err
+	}
+
+	usage := make(NodeUsage, len(list.Items))
+	for _, item := range list.Items {
+		usage[item.Metadata.Name] = usageOf(item.Usage)
+	}
+	return usage, nil
+}
+
+// FetchPodUsage reads pod usage from metrics-server for all namespaces
+func FetchPodUsage(ctx context.Context, client kubernetes.Interface) (PodUsage, error) {
+	var list podMetricsList
+	if err := getMetrics(ctx, client, metricsAPI+"/pods", &list); err != nil {
+		return nil, err
+	}
+
+	usage := make(PodUsage, len(list.Items))
+	for _, item := range list.Items {
+		var total Usage
+		for _, c := range item.Containers {
+			u := usageOf(c.Usage)
+			total.CPUMilli += u.CPUMilli
+			total.MemoryBytes += u.MemoryBytes
+		}
+		usage[item.Metadata.Namespace+"/"+item.Metadata.Name] = total
+	}
+	return usage, nil
+}
+
+func getMetrics(ctx context.Context, client kubernetes.Interface, path string, into any) error {
+	rc := client.Discovery().RESTClient()
+	if rc == nil {
+		return ErrMetricsUnavailable
+	}
+	raw, err := rc.Get().AbsPath(path).DoRaw(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMetricsUnavailable, err)
+	}
+	if err := json.Unmarshal(raw, into); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return nil
+}
+
+func usageOf(rl v1.ResourceList) Usage {
+	return Usage{
+		CPUMilli:    rl.Cpu().MilliValue(),
+		MemoryBytes: rl.Memory().Value(),
+	}
+}
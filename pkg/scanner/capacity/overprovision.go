@@ -0,0 +1,137 @@
+package capacity
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// hoursPerMonth is used to turn hourly prices into a monthly waste estimate
+const hoursPerMonth = 730
+
+// workloadKey identifies the controller that owns a set of pods
+type workloadKey struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// CheckOverProvisioned flags workloads whose CPU or memory requests are more
+// than opts.OverProvisionRatio times their measured usage
+func CheckOverProvisioned(pods []v1.Pod, usage PodUsage, opts Options) []types.Issue {
+	if opts.OverProvisionRatio <= 1 {
+		return nil
+	}
+
+	requested := make(map[workloadKey]Usage)
+	used := make(map[workloadKey]Usage)
+	for _, p := range pods {
+		if p.Status.Phase != v1.PodRunning {
+			continue
+		}
+		u, ok := usage[p.Namespace+"/"+p.Name]
+		if !ok {
+			continue
+		}
+		key := ownerOf(p)
+		r := PodRequests(p)
+
+		req := requested[key]
+		req.CPUMilli += r.CPUMilli
+		req.MemoryBytes += r.MemoryBytes
+		requested[key] = req
+
+		cur := used[key]
+		cur.CPUMilli += u.CPUMilli
+		cur.MemoryBytes += u.MemoryBytes
+		used[key] = cur
+	}
+
+	keys := make([]workloadKey, 0, len(requested))
+	for k := range requested {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Namespace != keys[j].Namespace {
+			return keys[i].Namespace < keys[j].Namespace
+		}
+		return keys[i].Name < keys[j].Name
+	})
+
+	var issues []types.Issue
+	timestamp := time.Now().Format(time.RFC3339)
+	for _, k := range keys {
+		req, cur := requested[k], used[k]
+
+		var details, wasted []string
+		var waste Usage
+		if overProvisioned(req.CPUMilli, cur.CPUMilli, opts.OverProvisionRatio) {
+			waste.CPUMilli = req.CPUMilli - cur.CPUMilli
+			details = append(details, fmt.Sprintf("CPU request %s nhưng chỉ dùng %s", FormatCPU(req.CPUMilli), FormatCPU(cur.CPUMilli)))
+			wasted = append(wasted, FormatCPU(waste.CPUMilli)+" CPU")
+		}
+		if overProvisioned(req.MemoryBytes, cur.MemoryBytes, opts.OverProvisionRatio) {
+			waste.MemoryBytes = req.MemoryBytes - cur.MemoryBytes
+			details = append(details, fmt.Sprintf("memory request %s nhưng chỉ dùng %s", FormatMemory(req.MemoryBytes), FormatMemory(cur.MemoryBytes)))
+			wasted = append(wasted, FormatMemory(waste.MemoryBytes)+" memory")
+		}
+		if len(details) == 0 {
+			continue
+		}
+
+		rootCause := fmt.Sprintf("Workload được cấp dư tài nguyên: %s — lãng phí ước tính %s",
+			strings.Join(details, "; "), strings.Join(wasted, ", "))
+		if cost := monthlyCost(waste, opts); cost > 0 {
+			rootCause += fmt.Sprintf(" (~%.2f/tháng)", cost)
+		}
+		rootCause += "."
+
+		issues = append(issues, types.Issue{
+			Kind:       k.Kind,
+			Namespace:  k.Namespace,
+			Name:       k.Name,
+			Severity:   severity.FromReason("OverProvisioned"),
+			Reason:     "OverProvisioned",
+			RootCause:  rootCause,
+			Suggestion: "Giảm resources.requests về gần mức sử dụng thực tế (có thể dùng VPA ở chế độ recommendation để tham khảo).",
+			Timestamp:  timestamp,
+		})
+	}
+	return issues
+}
+
+// overProvisioned reports whether requests exceed ratio times usage.
+// Workloads without requests cannot be over-provisioned.
+func overProvisioned(requested, used int64, ratio float64) bool {
+	return requested > 0 && float64(requested) > ratio*float64(used)
+}
+
+// monthlyCost estimates the monthly price of wasted resources (0 when no prices are configured)
+func monthlyCost(waste Usage, opts Options) float64 {
+	cores := float64(waste.CPUMilli) / 1000
+	gib := float64(waste.MemoryBytes) / (1024 * 1024 * 1024)
+	return (cores*opts.CPUCostPerCoreHour + gib*opts.MemoryCostPerGiBHour) * hoursPerMonth
+}
+
+// ownerOf resolves the top-level workload of a pod. ReplicaSets created by a
+// Deployment are reported as the Deployment.
+func ownerOf(p v1.Pod) workloadKey {
+	for _, ref := range p.OwnerReferences {
+		if ref.Controller == nil || !*ref.Controller {
+			continue
+		}
+		if ref.Kind == "ReplicaSet" {
+			if hash := p.Labels["pod-template-hash"]; hash != "" && strings.HasSuffix(ref.Name, "-"+hash) {
+				return workloadKey{Kind: "Deployment", Namespace: p.Namespace, Name: strings.TrimSuffix(ref.Name, "-"+hash)}
+			}
+		}
+		return workloadKey{Kind: ref.Kind, Namespace: p.Namespace, Name: ref.Name}
+	}
+	return workloadKey{Kind: "Pod", Namespace: p.Namespace, Name: p.Name}
+}
@@ -0,0 +1,120 @@
+package capacity
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Overview summarizes cluster capacity: allocatable vs requested resources
+// and pods per node vs max pods
+type Overview struct {
+	NodeCount              int            `json:"node_count"`
+	ReadyNodes             int            `json:"ready_nodes"`
+	Pods                   int            `json:"pods"`
+	MaxPods                int64          `json:"max_pods"`
+	AllocatableCPUMilli    int64          `json:"allocatable_cpu_millicores"`
+	AllocatableMemoryBytes int64          `json:"allocatable_memory_bytes"`
+	RequestedCPUMilli      int64          `json:"requested_cpu_millicores"`
+	RequestedMemoryBytes   int64          `json:"requested_memory_bytes"`
+	Nodes                  []NodeOverview `json:"nodes"`
+}
+
+// NodeOverview is the capacity of a single node
+type NodeOverview struct {
+	Name                   string `json:"name"`
+	Ready                  bool   `json:"ready"`
+	Pods                   int    `json:"pods"`
+	MaxPods                int64  `json:"max_pods"`
+	AllocatableCPUMilli    int64  `json:"allocatable_cpu_millicores"`
+	AllocatableMemoryBytes int64  `json:"allocatable_memory_bytes"`
+	RequestedCPUMilli      int64  `json:"requested_cpu_millicores"`
+	RequestedMemoryBytes   int64  `json:"requested_memory_bytes"`
+	// Usage is only set when metrics-server is available
+	Usage *Usage `json:"usage,omitempty"`
+}
+
+// FetchOverview lists nodes and pods cluster-wide and builds the overview.
+// Node usage is added when metrics-server answers.
+func FetchOverview(ctx context.Context, client kubernetes.Interface) (*Overview, error) {
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	usage, _ := FetchNodeUsage(ctx, client)
+	return BuildOverview(nodes.Items, pods.Items, usage), nil
+}
+
+// BuildOverview computes the overview from already-fetched nodes and pods.
+// usage may be nil.
+func BuildOverview(nodes []v1.Node, pods []v1.Pod, usage NodeUsage) *Overview {
+	byNode := make(map[string]*NodeOverview, len(nodes))
+	ov := &Overview{NodeCount: len(nodes)}
+
+	for _, node := range nodes {
+		n := &NodeOverview{
+			Name:                   node.Name,
+			Ready:                  isReady(node),
+			MaxPods:                node.Status.Allocatable.Pods().Value(),
+			AllocatableCPUMilli:    node.Status.Allocatable.Cpu().MilliValue(),
+			AllocatableMemoryBytes: node.Status.Allocatable.Memory().Value(),
+		}
+		if u, ok := usage[node.Name]; ok {
+			n.Usage = &u
+		}
+		byNode[node.Name] = n
+	}
+
+	for _, p := range pods {
+		// Finished pods no longer hold their requests
+		if p.Status.Phase == v1.PodSucceeded || p.Status.Phase == v1.PodFailed {
+			continue
+		}
+		n, ok := byNode[p.Spec.NodeName]
+		if !ok {
+			continue
+		}
+		r := PodRequests(p)
+		n.Pods++
+		n.RequestedCPUMilli += r.CPUMilli
+		n.RequestedMemoryBytes += r.MemoryBytes
+	}
+
+	for _, n := range byNode {
+		if n.Ready {
+			ov.ReadyNodes++
+		}
+		ov.Pods += n.Pods
+		ov.MaxPods += n.MaxPods
+		ov.AllocatableCPUMilli += n.AllocatableCPUMilli
+		ov.AllocatableMemoryBytes += n.AllocatableMemoryBytes
+		ov.RequestedCPUMilli += n.RequestedCPUMilli
+		ov.RequestedMemoryBytes += n.RequestedMemoryBytes
+		ov.Nodes = append(ov.Nodes, *n)
+	}
+	sort.Slice(ov.Nodes, func(i, j int) bool { return ov.Nodes[i].Name < ov.Nodes[j].Name })
+
+	return ov
+}
+
+// Percent returns used as a percentage of total (0 when total is 0)
+func Percent(used, total int64) float64 {
+	return percent(used, total)
+}
+
+func isReady(node v1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == v1.NodeReady {
+			return cond.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ControlPlaneSelector matches the static pods of kubeadm-style
+// (self-hosted) control planes
+const ControlPlaneSelector = "tier=control-plane"
+
+// components are the control plane components checked, by the value of
+// their pods' component label
+var components = []string{"kube-apiserver", "etcd", "kube-scheduler", "kube-controller-manager"}
+
+// ScanComponents checks the control plane: the static pods of self-hosted
+// control planes, the API server's /readyz checks (which cover etcd on
+// managed clusters, where the pods are not visible) and the deprecated
+// componentstatuses. Sources that cannot be read are skipped with a
+// warning; a component failing in several sources is reported once.
+func ScanComponents(ctx context.Context, cs *k8s.ClusterSnapshot) ([]types.Issue, []string, error) {
+	client := cs.Client()
+	var (
+		issues   []types.Issue
+		warnings []string
+	)
+	reported := map[string]bool{}
+	report := func(component string, issue types.Issue) {
+		if reported[component] {
+			return
+		}
+		reported[component] = true
+		issues = append(issues, issue)
+	}
+
+	pods, err := client.CoreV1().Pods(SystemNamespace).List(ctx, metav1.ListOptions{LabelSelector: ControlPlaneSelector})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		warnings = append(warnings, fmt.Sprintf("control plane: cannot list %s pods: %v", SystemNamespace, err))
+	} else {
+		sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+		for i := range pods.Items {
+			p := &pods.Items[i]
+			component := p.Labels["component"]
+			if !isComponent(component) {
+				continue
+			}
+			if cause, ok := podDegraded(p); ok {
+				report(component, types.Issue{
+					Kind:       "Pod",
+					Namespace:  p.Namespace,
+					Name:       p.Name,
+					Reason:     "ControlPlaneDegraded",
+					RootCause:  fmt.Sprintf("%s trên node %s không hoạt động: %s.", component, p.Spec.NodeName, cause),
+					Suggestion: "Kiểm tra manifest /etc/kubernetes/manifests/" + component + ".yaml và log kubelet trên node " + p.Spec.NodeName,
+					PodStatus:  string(p.Status.Phase),
+					NodeName:   p.Spec.NodeName,
+				})
+			}
+		}
+	}
+
+	if failed, err := readyzFailures(ctx, cs); err != nil {
+		warnings = append(warnings, fmt.Sprintf("control plane: cannot read /readyz: %v", err))
+	} else {
+		byComponent := map[string][]string{}
+		for _, check := range failed {
+			component := "kube-apiserver"
+			if strings.HasPrefix(check, "etcd") {
+				component = "etcd"
+			}
+			byComponent[component] = append(byComponent[component], check)
+		}
+		for _, component := range components {
+			checks := byComponent[component]
+			if len(checks) == 0 {
+				continue
+			}
+			report(component, types.Issue{
+				Kind:       "ControlPlane",
+				Namespace:  SystemNamespace,
+				Name:       component,
+				Reason:     "ControlPlaneDegraded",
+				RootCause:  fmt.Sprintf("API server báo check %s thất bại trên /readyz — %s không khỏe.", strings.Join(checks, ", "), component),
+				Suggestion: "kubectl get --raw '/readyz?verbose' để xem chi tiết; trên cluster managed, kiểm tra trang trạng thái của nhà cung cấp",
+			})
+		}
+	}
+
+	// componentstatuses is deprecated, but some distributions still report
+	// the scheduler and controller-manager through it
+	statuses, err := client.CoreV1().ComponentStatuses().List(ctx, metav1.ListOptions{})
+	if err == nil {
+		for _, s := range statuses.Items {
+			if cause, ok := componentUnhealthy(s); ok {
+				report(componentOf(s.Name), types.Issue{
+					Kind:       "ComponentStatus",
+					Namespace:  SystemNamespace,
+					Name:       s.Name,
+					Reason:     "ControlPlaneDegraded",
+					RootCause:  fmt.Sprintf("ComponentStatus %s không Healthy: %s.", s.Name, cause),
+					Suggestion: "Kiểm tra " + componentOf(s.Name) + " trên các node control plane",
+				})
+			}
+		}
+	}
+
+	timestamp := time.Now().Format(time.RFC3339)
+	for i := range issues {
+		issues[i].Severity = severity.FromReason(issues[i].Reason)
+		issues[i].Timestamp = timestamp
+	}
+	return issues, warnings, nil
+}
+
+func isComponent(name string) bool {
+	for _, c := range components {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// podDegraded explains why a control plane pod is not serving
+func podDegraded(p *v1.Pod) (string, bool) {
+	for _, c := range p.Status.ContainerStatuses {
+		if w := c.State.Waiting; w != nil && w.Reason != "" {
+			return fmt.Sprintf("container %s đang %s (restart %d lần)", c.Name, w.Reason, c.RestartCount), true
+		}
+	}
+	if p.Status.Phase != v1.PodRunning {
+		return "pod ở trạng thái " + string(p.Status.Phase), true
+	}
+	for _, c := range p.Status.Conditions {
+		if c.Type == v1.PodReady && c.Status != v1.ConditionTrue {
+			return "pod không Ready", true
+		}
+	}
+	return "", false
+}
+
+// readyzFailures returns the failed checks of the API server's verbose
+// /readyz output (lines like "[-]etcd failed: reason withheld")
+func readyzFailures(ctx context.Context, cs *k8s.ClusterSnapshot) ([]string, error) {
+	rc := cs.Client().Discovery().RESTClient()
+	if rc == nil {
+		return nil, nil
+	}
+	// A failing /readyz answers 500 with the verbose body, so the body is
+	// parsed even when the request errors
+	body, err := rc.Get().AbsPath("/readyz").Param("verbose", "true").DoRaw(ctx)
+	var failed []string
+	for _, line := range strings.Split(string(body), "\n") {
+		if rest, ok := strings.CutPrefix(line, "[-]"); ok {
+			check, _, _ := strings.Cut(rest, " ")
+			failed = append(failed, check)
+		}
+	}
+	if len(failed) == 0 && err != nil {
+		return nil, err
+	}
+	return failed, nil
+}
+
+// componentUnhealthy explains a ComponentStatus whose Healthy condition is not True
+func componentUnhealthy(s v1.ComponentStatus) (string, bool) {
+	for _, c := range s.Conditions {
+		if c.Type != v1.ComponentHealthy || c.Status == v1.ConditionTrue {
+			continue
+		}
+		if c.Error != "" {
+			return c.Error, true
+		}
+		return c.Message, true
+	}
+	return "", false
+}
+
+// componentOf maps a ComponentStatus name (scheduler, etcd-0) to the
+// component label of its pods
+func componentOf(status string) string {
+	switch {
+	case strings.HasPrefix(status, "etcd"):
+		return "etcd"
+	case status == "scheduler":
+		return "kube-scheduler"
+	case status == "controller-manager":
+		return "kube-controller-manager"
+	}
+	return status
+}
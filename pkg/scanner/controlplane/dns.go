@@ -0,0 +1,241 @@
+// Package controlplane checks the cluster add-ons and control plane
+// components every workload depends on: cluster DNS, the API server, etcd,
+// the scheduler, the controller-manager and admission webhooks.
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/pod"
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SystemNamespace holds the DNS add-on and the control plane pods
+const SystemNamespace = metav1.NamespaceSystem
+
+// DNSSelector is the label shared by CoreDNS and kube-dns pods, Deployment
+// and Service
+const DNSSelector = "k8s-app=kube-dns"
+
+// DefaultLookupName resolves through the pod's search path inside any
+// cluster domain
+const DefaultLookupName = "kubernetes.default.svc"
+
+// DNSOptions configures ScanDNS
+type DNSOptions struct {
+	// EventMaxAge ignores older warning events of the DNS pods (0 keeps all)
+	EventMaxAge time.Duration
+	// Lookup resolves LookupName through the pod's resolver. It is only
+	// meaningful when the scanner runs inside the cluster (serve mode).
+	Lookup bool
+	// LookupName is the name resolved by Lookup (default DefaultLookupName)
+	LookupName string
+	// Resolver is used by Lookup (default net.DefaultResolver)
+	Resolver *net.Resolver
+}
+
+// lookupTimeout bounds the in-cluster DNS lookup
+const lookupTimeout = 5 * time.Second
+
+// ScanDNS checks that the cluster DNS Deployment has ready replicas and
+// Service endpoints, reports the recent warning events of its pods and,
+// with Lookup, that names actually resolve
+func ScanDNS(ctx context.Context, cs *k8s.ClusterSnapshot, opts DNSOptions) ([]types.Issue, []string, error) {
+	client := cs.Client()
+	deployments, err := client.AppsV1().Deployments(SystemNamespace).List(ctx, metav1.ListOptions{LabelSelector: DNSSelector})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	now := time.Now()
+	timestamp := now.Format(time.RFC3339)
+	var (
+		issues   []types.Issue
+		warnings []string
+	)
+	if len(deployments.Items) == 0 {
+		warnings = append(warnings, fmt.Sprintf("dns: no CoreDNS/kube-dns Deployment (%s) in %s, skipping the readiness checks", DNSSelector, SystemNamespace))
+	}
+	for i := range deployments.Items {
+		if issue, ok := checkDNSDeployment(&deployments.Items[i]); ok {
+			issues = append(issues, issue)
+		}
+	}
+
+	services, err := client.CoreV1().Services(SystemNamespace).List(ctx, metav1.ListOptions{LabelSelector: DNSSelector})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	for _, svc := range services.Items {
+		ready, err := readyEndpoints(ctx, cs, svc.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ready == 0 {
+			issues = append(issues, types.Issue{
+				Kind:       "Service",
+				Namespace:  svc.Namespace,
+				Name:       svc.Name,
+				Reason:     "DNSUnavailable",
+				RootCause:  fmt.Sprintf("Service DNS %s (ClusterIP %s) không có endpoint ready — mọi truy vấn DNS trong cluster đều thất bại.", svc.Name, svc.Spec.ClusterIP),
+				Suggestion: "kubectl get pods -n " + SystemNamespace + " -l " + DNSSelector + " và kiểm tra vì sao pod DNS không Ready",
+			})
+		}
+	}
+
+	if issue, ok, err := dnsWarningEvents(ctx, cs, now, opts.EventMaxAge); err != nil {
+		warnings = append(warnings, fmt.Sprintf("dns: cannot list events of the DNS pods: %v", err))
+	} else if ok {
+		issues = append(issues, issue)
+	}
+
+	if opts.Lookup {
+		if issue, ok := lookup(ctx, opts); ok {
+			issues = append(issues, issue)
+		}
+	}
+
+	for i := range issues {
+		issues[i].Severity = severity.FromReason(issues[i].Reason)
+		issues[i].Timestamp = timestamp
+	}
+	return issues, warnings, nil
+}
+
+// checkDNSDeployment reports a DNS Deployment without ready replicas
+// (DNSUnavailable) or with fewer than desired (DNSDegraded)
+func checkDNSDeployment(d *appsv1.Deployment) (types.Issue, bool) {
+	desired := int32(1)
+	if d.Spec.Replicas != nil {
+		desired = *d.Spec.Replicas
+	}
+	ready := d.Status.ReadyReplicas
+	if desired == 0 || ready >= desired {
+		return types.Issue{}, false
+	}
+	issue := types.Issue{
+		Kind:       "Deployment",
+		Namespace:  d.Namespace,
+		Name:       d.Name,
+		Reason:     "DNSDegraded",
+		RootCause:  fmt.Sprintf("DNS chỉ có %d/%d replica ready — cluster DNS thiếu dự phòng, truy vấn có thể chậm hoặc timeout.", ready, desired),
+		Suggestion: "kubectl describe deployment " + d.Name + " -n " + d.Namespace + " và xem log các pod DNS",
+	}
+	if ready == 0 {
+		issue.Reason = "DNSUnavailable"
+		issue.RootCause = fmt.Sprintf("Không replica DNS nào ready (0/%d) — phân giải tên trong cluster đang hỏng, workload không gọi được Service.", desired)
+	}
+	return issue, true
+}
+
+// readyEndpoints counts the ready addresses of a Service in SystemNamespace
+func readyEndpoints(ctx context.Context, cs *k8s.ClusterSnapshot, service string) (int, error) {
+	ep, err := cs.Client().CoreV1().Endpoints(SystemNamespace).Get(ctx, service, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get endpoints %s/%s: %w", SystemNamespace, service, err)
+	}
+	ready := 0
+	for _, s := range ep.Subsets {
+		ready += len(s.Addresses)
+	}
+	return ready, nil
+}
+
+// dnsWarningEvents summarizes the recent warning events of the DNS pods
+// (failed probes, restarts, OOM kills) into a single issue
+func dnsWarningEvents(ctx context.Context, cs *k8s.ClusterSnapshot, now time.Time, maxAge time.Duration) (types.Issue, bool, error) {
+	pods, err := cs.Client().CoreV1().Pods(SystemNamespace).List(ctx, metav1.ListOptions{LabelSelector: DNSSelector})
+	if err != nil {
+		return types.Issue{}, false, err
+	}
+	if len(pods.Items) == 0 {
+		return types.Issue{}, false, nil
+	}
+	dnsPods := make(map[string]bool, len(pods.Items))
+	for _, p := range pods.Items {
+		dnsPods[p.Name] = true
+	}
+	events, err := cs.PodEvents(ctx, SystemNamespace)
+	if err != nil {
+		return types.Issue{}, false, err
+	}
+
+	counts := map[string]int32{}
+	var latest *v1.Event
+	for i := range events {
+		ev := &events[i]
+		if ev.Type != v1.EventTypeWarning || !dnsPods[ev.InvolvedObject.Name] {
+			continue
+		}
+		if maxAge > 0 && now.Sub(pod.EventTime(ev)) > maxAge {
+			continue
+		}
+		counts[ev.Reason] += max(ev.Count, 1)
+		if latest == nil || pod.EventTime(ev).After(pod.EventTime(latest)) {
+			latest = ev
+		}
+	}
+	if latest == nil {
+		return types.Issue{}, false, nil
+	}
+
+	reasons := make([]string, 0, len(counts))
+	for r := range counts {
+		reasons = append(reasons, r)
+	}
+	sort.Strings(reasons)
+	summary := make([]string, len(reasons))
+	for i, r := range reasons {
+		summary[i] = fmt.Sprintf("%s×%d", r, counts[r])
+	}
+	return types.Issue{
+		Kind:       "Pod",
+		Namespace:  SystemNamespace,
+		Name:       latest.InvolvedObject.Name,
+		Reason:     "DNSWarningEvents",
+		RootCause:  fmt.Sprintf("Pod DNS có warning event gần đây (%s) — DNS có thể chập chờn.", strings.Join(summary, ", ")),
+		Suggestion: "kubectl logs -n " + SystemNamespace + " -l " + DNSSelector + " và kiểm tra probe, memory limit của CoreDNS",
+		LastEvent:  latest.Message,
+	}, true, nil
+}
+
+// lookup resolves opts.LookupName and reports a failure
+func lookup(ctx context.Context, opts DNSOptions) (types.Issue, bool) {
+	name := opts.LookupName
+	if name == "" {
+		name = DefaultLookupName
+	}
+	resolver := opts.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+	_, err := resolver.LookupHost(ctx, name)
+	if err == nil {
+		return types.Issue{}, false
+	}
+	return types.Issue{
+		Kind:       "Service",
+		Namespace:  SystemNamespace,
+		Name:       "kube-dns",
+		Reason:     "DNSResolutionFailed",
+		RootCause:  fmt.Sprintf("Không phân giải được %s từ trong cluster: %v.", name, err),
+		Suggestion: "Kiểm tra pod CoreDNS, ConfigMap coredns và NetworkPolicy chặn UDP/TCP 53 tới " + SystemNamespace,
+	}, true
+}
@@ -0,0 +1,143 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// webhook is the part of a validating or mutating webhook the check needs
+type webhook struct {
+	configKind string
+	configName string
+	name       string
+	service    *admissionv1.ServiceReference
+	policy     *admissionv1.FailurePolicyType
+	rules      []admissionv1.RuleWithOperations
+	nsSelector *metav1.LabelSelector
+}
+
+// ScanWebhooks reports admission webhooks with failurePolicy Fail whose
+// Service is missing or has no ready endpoints: the API server then
+// rejects every request they intercept, typically "cannot create pods"
+// cluster-wide. Webhooks called by URL cannot be checked and are skipped.
+func ScanWebhooks(ctx context.Context, cs *k8s.ClusterSnapshot, ignoredNamespaces map[string]bool) ([]types.Issue, error) {
+	client := cs.Client()
+	validating, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list validatingwebhookconfigurations: %w", err)
+	}
+	mutating, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mutatingwebhookconfigurations: %w", err)
+	}
+
+	var webhooks []webhook
+	for _, c := range validating.Items {
+		for _, w := range c.Webhooks {
+			webhooks = append(webhooks, webhook{"ValidatingWebhookConfiguration", c.Name, w.Name, w.ClientConfig.Service, w.FailurePolicy, w.Rules, w.NamespaceSelector})
+		}
+	}
+	for _, c := range mutating.Items {
+		for _, w := range c.Webhooks {
+			webhooks = append(webhooks, webhook{"MutatingWebhookConfiguration", c.Name, w.Name, w.ClientConfig.Service, w.FailurePolicy, w.Rules, w.NamespaceSelector})
+		}
+	}
+
+	timestamp := time.Now().Format(time.RFC3339)
+	// Several webhooks often share one Service
+	backends := map[string]string{}
+	var issues []types.Issue
+	for _, w := range webhooks {
+		// failurePolicy defaults to Fail in admissionregistration/v1
+		if w.service == nil || w.policy != nil && *w.policy == admissionv1.Ignore || ignoredNamespaces[w.service.Namespace] {
+			continue
+		}
+		key := w.service.Namespace + "/" + w.service.Name
+		problem, ok := backends[key]
+		if !ok {
+			if problem, err = serviceProblem(ctx, cs, w.service.Namespace, w.service.Name); err != nil {
+				return nil, err
+			}
+			backends[key] = problem
+		}
+		if problem == "" {
+			continue
+		}
+		issues = append(issues, types.Issue{
+			Kind:       w.configKind,
+			Namespace:  w.service.Namespace,
+			Name:       w.configName,
+			Container:  w.name,
+			Reason:     "WebhookUnavailable",
+			Severity:   severity.FromReason("WebhookUnavailable"),
+			RootCause:  fmt.Sprintf("Webhook %s (failurePolicy: Fail) trỏ tới Service %s: %s — API server từ chối mọi request %s.", w.name, key, problem, describeRules(w.rules, w.nsSelector)),
+			Suggestion: fmt.Sprintf("Khôi phục Service %s, hoặc tạm thời đặt failurePolicy: Ignore / xóa %s %s nếu webhook không còn dùng", key, strings.ToLower(w.configKind), w.configName),
+			Timestamp:  timestamp,
+		})
+	}
+	return issues, nil
+}
+
+// serviceProblem explains why the Service cannot serve webhook calls, or
+// returns "" when it has ready endpoints
+func serviceProblem(ctx context.Context, cs *k8s.ClusterSnapshot, namespace, name string) (string, error) {
+	svc, err := cs.Client().CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return "Service không tồn tại", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get service %s/%s: %w", namespace, name, err)
+	}
+	if svc.Spec.Type == v1.ServiceTypeExternalName {
+		return "", nil
+	}
+	ep, err := cs.Client().CoreV1().Endpoints(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "", fmt.Errorf("failed to get endpoints %s/%s: %w", namespace, name, err)
+	}
+	if err == nil {
+		for _, s := range ep.Subsets {
+			if len(s.Addresses) > 0 {
+				return "", nil
+			}
+		}
+	}
+	return "Service không có endpoint ready (pod webhook không chạy hoặc không Ready)", nil
+}
+
+// describeRules summarizes what a webhook intercepts, e.g. "CREATE,UPDATE
+// pods, deployments"
+func describeRules(rules []admissionv1.RuleWithOperations, nsSelector *metav1.LabelSelector) string {
+	var ops, resources []string
+	seen := map[string]bool{}
+	for _, r := range rules {
+		for _, op := range r.Operations {
+			if !seen["op/"+string(op)] {
+				seen["op/"+string(op)] = true
+				ops = append(ops, string(op))
+			}
+		}
+		for _, res := range r.Resources {
+			if !seen["res/"+res] {
+				seen["res/"+res] = true
+				resources = append(resources, res)
+			}
+		}
+	}
+	desc := strings.Join(ops, ",") + " " + strings.Join(resources, ", ")
+	if nsSelector != nil && (len(nsSelector.MatchLabels) > 0 || len(nsSelector.MatchExpressions) > 0) {
+		desc += " (trong các namespace khớp namespaceSelector)"
+	}
+	return strings.TrimSpace(desc)
+}
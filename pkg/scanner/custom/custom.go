@@ -0,0 +1,246 @@
+// Package custom scans arbitrary custom resources (Kafka clusters,
+// cert-manager Certificates, Argo CD Applications, ...) through the dynamic
+// client, with rules declared in the configuration file.
+package custom
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/policy"
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// Resource selects a kind of custom resource and the rules evaluated against it
+type Resource struct {
+	Group    string `json:"group"`
+	Version  string `json:"version"`
+	Resource string `json:"resource"` // plural name, e.g. certificates
+	// Kind is shown in the report (default: the resource name)
+	Kind string `json:"kind,omitempty"`
+	// ClusterScoped resources are listed once instead of per namespace
+	ClusterScoped bool   `json:"clusterScoped,omitempty"`
+	Rules         []Rule `json:"rules"`
+}
+
+// Rule reports unhealthy objects. Set either Condition or Expression.
+type Rule struct {
+	// Condition reports objects whose status condition of this type
+	// (e.g. Ready) is missing or not True
+	Condition string `json:"condition,omitempty"`
+	// Expression (see pkg/policy) is true for unhealthy objects, e.g.
+	// object.status.phase == "Failed"
+	Expression string `json:"expression,omitempty"`
+	// Reason of the issues created (default: <Kind>Not<Condition>)
+	Reason     string `json:"reason,omitempty"`
+	Severity   string `json:"severity,omitempty"` // default: high
+	Message    string `json:"message,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// GVR returns the resource's GroupVersionResource
+func (r Resource) GVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Resource}
+}
+
+func (r Resource) kind() string {
+	if r.Kind != "" {
+		return r.Kind
+	}
+	return r.Resource
+}
+
+// String is resource.group, as in kubectl
+func (r Resource) String() string {
+	if r.Group == "" {
+		return r.Resource
+	}
+	return r.Resource + "." + r.Group
+}
+
+// check is a compiled Rule
+type check struct {
+	Rule
+	expr     *policy.Expression
+	severity severity.Level
+}
+
+// compile validates the rules of r
+func (r Resource) compile() ([]check, error) {
+	if r.Version == "" || r.Resource == "" {
+		return nil, fmt.Errorf("%s: version and resource are required", r)
+	}
+	if len(r.Rules) == 0 {
+		return nil, fmt.Errorf("%s: at least one rule is required", r)
+	}
+	checks := make([]check, 0, len(r.Rules))
+	for i, rule := range r.Rules {
+		c := check{Rule: rule, severity: severity.High}
+		switch {
+		case (rule.Condition == "") == (rule.Expression == ""):
+			return nil, fmt.Errorf("%s: rules[%d]: set either condition or expression", r, i)
+		case rule.Expression != "":
+			expr, err := policy.Compile(rule.Expression)
+			if err != nil {
+				return nil, fmt.Errorf("%s: rules[%d]: %w", r, i, err)
+			}
+			c.expr = expr
+			if c.Reason == "" {
+				return nil, fmt.Errorf("%s: rules[%d]: reason is required with an expression", r, i)
+			}
+		default:
+			if c.Reason == "" {
+				c.Reason = r.kind() + "Not" + rule.Condition
+			}
+		}
+		if rule.Severity != "" {
+			level, err := severity.Parse(rule.Severity)
+			if err != nil {
+				return nil, fmt.Errorf("%s: rules[%d]: %w", r, i, err)
+			}
+			c.severity = level
+		}
+		checks = append(checks, c)
+	}
+	return checks, nil
+}
+
+// Validate checks the resources and compiles their rules
+func Validate(resources []Resource) error {
+	for _, r := range resources {
+		if _, err := r.compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ScanCluster lists each resource in the given namespaces (all when empty)
+// and evaluates its rules. Resources that are not installed or cannot be
+// listed are returned as warnings.
+func ScanCluster(ctx context.Context, dyn dynamic.Interface, namespaces []string, ignoredNamespaces map[string]bool, resources []Resource) ([]types.Issue, []string, error) {
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	var (
+		issues   []types.Issue
+		warnings []string
+	)
+	timestamp := time.Now().Format(time.RFC3339)
+
+	for _, r := range resources {
+		checks, err := r.compile()
+		if err != nil {
+			return nil, nil, err
+		}
+		scopes := namespaces
+		if r.ClusterScoped {
+			scopes = []string{metav1.NamespaceAll}
+		}
+		for _, ns := range scopes {
+			list, err := dyn.Resource(r.GVR()).Namespace(ns).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, nil, ctx.Err()
+				}
+				if apierrors.IsNotFound(err) {
+					warnings = append(warnings, fmt.Sprintf("%s is not installed in the cluster", r))
+					break
+				}
+				warnings = append(warnings, fmt.Sprintf("failed to list %s: %v", r, err))
+				continue
+			}
+			for i := range list.Items {
+				obj := &list.Items[i]
+				if ignoredNamespaces[obj.GetNamespace()] {
+					continue
+				}
+				for _, c := range checks {
+					issue, found, err := c.evaluate(r, obj)
+					if err != nil {
+						warnings = append(warnings, fmt.Sprintf("%s %s/%s: %v", r, obj.GetNamespace(), obj.GetName(), err))
+						continue
+					}
+					if found {
+						issue.Timestamp = timestamp
+						issues = append(issues, issue)
+					}
+				}
+			}
+		}
+	}
+	return issues, warnings, nil
+}
+
+// evaluate applies one check to obj
+func (c check) evaluate(r Resource, obj *unstructured.Unstructured) (types.Issue, bool, error) {
+	issue := types.Issue{
+		Kind:       r.kind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+		Severity:   c.severity,
+		Reason:     c.Reason,
+		RootCause:  c.Message,
+		Suggestion: c.Suggestion,
+	}
+
+	if c.expr != nil {
+		bad, err := c.expr.EvalBool(map[string]any{"object": obj.Object})
+		if err != nil || !bad {
+			return types.Issue{}, false, err
+		}
+		if issue.RootCause == "" {
+			issue.RootCause = fmt.Sprintf("%s %s không healthy (%s).", r.kind(), obj.GetName(), c.expr)
+		}
+		return issue, true, nil
+	}
+
+	cond, ok := findCondition(obj, c.Condition)
+	if ok && cond["status"] == "True" {
+		return types.Issue{}, false, nil
+	}
+	if !ok {
+		if issue.RootCause == "" {
+			issue.RootCause = fmt.Sprintf("%s chưa báo cáo condition %s — controller có thể chưa xử lý object.", r.kind(), c.Condition)
+		}
+		return issue, true, nil
+	}
+
+	if since, _ := cond["lastTransitionTime"].(string); since != "" {
+		issue.InStateSince = since
+	}
+	if issue.RootCause == "" {
+		issue.RootCause = fmt.Sprintf("Condition %s của %s là %v.", c.Condition, r.kind(), cond["status"])
+	}
+	var details []string
+	for _, key := range []string{"reason", "message"} {
+		if s, _ := cond[key].(string); s != "" {
+			details = append(details, s)
+		}
+	}
+	if len(details) > 0 {
+		issue.RootCause += " Chi tiết: " + strings.Join(details, ": ")
+	}
+	return issue, true, nil
+}
+
+// findCondition returns status.conditions[type=condType]
+func findCondition(obj *unstructured.Unstructured, condType string) (map[string]any, bool) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		m, ok := c.(map[string]any)
+		if ok && m["type"] == condType {
+			return m, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,309 @@
+// Package gc finds leftover resources that are safe to garbage collect:
+// orphaned ReplicaSets, finished Jobs past their TTL, ConfigMaps and Secrets
+// that nothing references, and Endpoints without a Service.
+package gc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// KeepAnnotation set to "true" on a ReplicaSet, Job, ConfigMap, Secret or
+// Endpoints keeps it out of garbage collection
+const KeepAnnotation = "scanner.ductnn.io/gc-keep"
+
+// SystemNamespaces hold objects the control plane and add-ons read through
+// the API (cluster-info, extension-apiserver-authentication, aws-auth...);
+// they are only collected when named in the scanned namespaces
+var SystemNamespaces = map[string]bool{
+	metav1.NamespaceSystem: true,
+	metav1.NamespacePublic: true,
+	v1.NamespaceNodeLease:  true,
+}
+
+// kept tells if the object opted out of garbage collection
+func kept(obj metav1.Object) bool {
+	return obj.GetAnnotations()[KeepAnnotation] == "true"
+}
+
+// Options configures the garbage collection checks
+type Options struct {
+	// MinAge is how old a ReplicaSet, ConfigMap or Secret must be before it is reported
+	MinAge time.Duration
+	// JobTTL is how long a finished Job without ttlSecondsAfterFinished may be kept
+	JobTTL time.Duration
+}
+
+// DefaultOptions returns the thresholds used when none are configured
+func DefaultOptions() Options {
+	return Options{
+		MinAge: 7 * 24 * time.Hour,
+		JobTTL: 24 * time.Hour,
+	}
+}
+
+// Resource is a garbage collection candidate
+type Resource struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Reason    string
+	// Since is when the resource became garbage (creation or completion time)
+	Since time.Time
+}
+
+// Find lists the namespaces (all when empty) and returns the resources that
+// can be garbage collected, sorted by namespace, kind and name. System
+// namespaces are skipped unless listed in namespaces.
+func Find(ctx context.Context, client kubernetes.Interface, namespaces []string, ignoredNamespaces map[string]bool, opts Options) ([]Resource, error) {
+	return FindCluster(ctx, k8s.NewClusterSnapshot(client, namespaces), ignoredNamespaces, opts)
+}
+
+// FindCluster is Find reading pods through a shared ClusterSnapshot
+func FindCluster(ctx context.Context, cs *k8s.ClusterSnapshot, ignoredNamespaces map[string]bool, opts Options) ([]Resource, error) {
+	namespaces := cs.ScopedNamespaces()
+	explicit := make(map[string]bool, len(namespaces))
+	for _, ns := range namespaces {
+		explicit[ns] = true
+	}
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+
+	now := time.Now()
+	var found []Resource
+	for _, ns := range namespaces {
+		res, err := findInNamespace(ctx, cs, ns, now, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range res {
+			if !ignoredNamespaces[r.Namespace] && (!SystemNamespaces[r.Namespace] || explicit[r.Namespace]) {
+				found = append(found, r)
+			}
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		a, b := found[i], found[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return found, nil
+}
+
+// Scan returns the garbage collection candidates as report issues
+func Scan(ctx context.Context, client kubernetes.Interface, namespaces []string, ignoredNamespaces map[string]bool, opts Options) ([]types.Issue, error) {
+	return ScanCluster(ctx, k8s.NewClusterSnapshot(client, namespaces), ignoredNamespaces, opts)
+}
+
+// ScanCluster is Scan reading pods through a shared ClusterSnapshot
+func ScanCluster(ctx context.Context, cs *k8s.ClusterSnapshot, ignoredNamespaces map[string]bool, opts Options) ([]types.Issue, error) {
+	found, err := FindCluster(ctx, cs, ignoredNamespaces, opts)
+	if err != nil {
+		return nil, err
+	}
+	issues := make([]types.Issue, 0, len(found))
+	for _, r := range found {
+		issues = append(issues, r.Issue())
+	}
+	return issues, nil
+}
+
+// Issue converts the candidate into a report issue
+func (r Resource) Issue() types.Issue {
+	issue := types.Issue{
+		Kind:      r.Kind,
+		Namespace: r.Namespace,
+		Name:      r.Name,
+		Severity:  severity.FromReason(r.Reason),
+		Reason:    r.Reason,
+		RootCause: rootCause(r.Reason),
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	if !r.Since.IsZero() {
+		issue.InStateSince = r.Since.Format(time.RFC3339)
+	}
+	return issue
+}
+
+// Delete removes the resource from the cluster
+func Delete(ctx context.Context, client kubernetes.Interface, r Resource) error {
+	var err error
+	switch r.Kind {
+	case "ReplicaSet":
+		err = client.AppsV1().ReplicaSets(r.Namespace).Delete(ctx, r.Name, metav1.DeleteOptions{})
+	case "Job":
+		// Background propagation also removes the Job's pods
+		propagation := metav1.DeletePropagationBackground
+		err = client.BatchV1().Jobs(r.Namespace).Delete(ctx, r.Name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+	case "ConfigMap":
+		err = client.CoreV1().ConfigMaps(r.Namespace).Delete(ctx, r.Name, metav1.DeleteOptions{})
+	case "Secret":
+		err = client.CoreV1().Secrets(r.Namespace).Delete(ctx, r.Name, metav1.DeleteOptions{})
+	case "Endpoints":
+		err = client.CoreV1().Endpoints(r.Namespace).Delete(ctx, r.Name, metav1.DeleteOptions{})
+	default:
+		return fmt.Errorf("unsupported kind %s", r.Kind)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete %s %s/%s: %w", strings.ToLower(r.Kind), r.Namespace, r.Name, err)
+	}
+	return nil
+}
+
+func findInNamespace(ctx context.Context, cs *k8s.ClusterSnapshot, ns string, now time.Time, opts Options) ([]Resource, error) {
+	client := cs.Client()
+	deployments, err := client.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	replicaSets, err := client.AppsV1().ReplicaSets(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replicasets: %w", err)
+	}
+	jobs, err := client.BatchV1().Jobs(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	var found []Resource
+	found = append(found, orphanedReplicaSets(replicaSets.Items, deployments.Items, now, opts.MinAge)...)
+	found = append(found, expiredJobs(jobs.Items, now, opts.JobTTL)...)
+
+	unused, err := unusedConfig(ctx, cs, ns, now, opts.MinAge)
+	if err != nil {
+		return nil, err
+	}
+	found = append(found, unused...)
+
+	dangling, err := danglingEndpoints(ctx, client, ns)
+	if err != nil {
+		return nil, err
+	}
+	return append(found, dangling...), nil
+}
+
+// orphanedReplicaSets returns scaled-down ReplicaSets whose owning Deployment is gone
+// (or that never had one). Scaled-down ReplicaSets of a live Deployment are its
+// rollout history and are left to revisionHistoryLimit.
+func orphanedReplicaSets(replicaSets []appsv1.ReplicaSet, deployments []appsv1.Deployment, now time.Time, minAge time.Duration) []Resource {
+	live := make(map[string]bool, len(deployments))
+	for _, d := range deployments {
+		live[d.Namespace+"/"+d.Name] = true
+	}
+
+	var found []Resource
+	for _, rs := range replicaSets {
+		if rs.Spec.Replicas == nil || *rs.Spec.Replicas != 0 || rs.Status.Replicas != 0 {
+			continue
+		}
+		if kept(&rs) || now.Sub(rs.CreationTimestamp.Time) < minAge {
+			continue
+		}
+		owner := metav1.GetControllerOf(&rs)
+		if owner != nil && owner.Kind == "Deployment" && live[rs.Namespace+"/"+owner.Name] {
+			continue
+		}
+		found = append(found, Resource{Kind: "ReplicaSet", Namespace: rs.Namespace, Name: rs.Name, Reason: "OrphanedReplicaSet", Since: rs.CreationTimestamp.Time})
+	}
+	return found
+}
+
+// expiredJobs returns finished Jobs kept longer than their TTL. Jobs created by
+// a CronJob are pruned by its history limits and are skipped.
+func expiredJobs(jobs []batchv1.Job, now time.Time, ttl time.Duration) []Resource {
+	var found []Resource
+	for _, job := range jobs {
+		if owner := metav1.GetControllerOf(&job); (owner != nil && owner.Kind == "CronJob") || kept(&job) {
+			continue
+		}
+		finished := jobFinishedAt(job)
+		if finished.IsZero() {
+			continue
+		}
+		jobTTL := ttl
+		if job.Spec.TTLSecondsAfterFinished != nil {
+			jobTTL = time.Duration(*job.Spec.TTLSecondsAfterFinished) * time.Second
+		}
+		if jobTTL <= 0 || now.Sub(finished) < jobTTL {
+			continue
+		}
+		found = append(found, Resource{Kind: "Job", Namespace: job.Namespace, Name: job.Name, Reason: "ExpiredJob", Since: finished})
+	}
+	return found
+}
+
+// jobFinishedAt returns when the Job completed or failed (zero if still running)
+func jobFinishedAt(job batchv1.Job) time.Time {
+	for _, cond := range job.Status.Conditions {
+		if (cond.Type == batchv1.JobComplete || cond.Type == batchv1.JobFailed) && cond.Status == v1.ConditionTrue {
+			if job.Status.CompletionTime != nil {
+				return job.Status.CompletionTime.Time
+			}
+			return cond.LastTransitionTime.Time
+		}
+	}
+	return time.Time{}
+}
+
+// danglingEndpoints returns Endpoints objects whose Service no longer exists
+func danglingEndpoints(ctx context.Context, client kubernetes.Interface, ns string) ([]Resource, error) {
+	services, err := client.CoreV1().Services(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	endpoints, err := client.CoreV1().Endpoints(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list endpoints: %w", err)
+	}
+
+	exists := make(map[string]bool, len(services.Items))
+	for _, svc := range services.Items {
+		exists[svc.Namespace+"/"+svc.Name] = true
+	}
+
+	var found []Resource
+	for _, ep := range endpoints.Items {
+		if !exists[ep.Namespace+"/"+ep.Name] && !kept(&ep) {
+			found = append(found, Resource{Kind: "Endpoints", Namespace: ep.Namespace, Name: ep.Name, Reason: "DanglingEndpoints", Since: ep.CreationTimestamp.Time})
+		}
+	}
+	return found, nil
+}
+
+// rootCause returns a human-readable explanation for a garbage collection reason
+func rootCause(reason string) string {
+	switch reason {
+	case "OrphanedReplicaSet":
+		return "ReplicaSet có 0 replica và không còn Deployment quản lý — có thể xóa."
+	case "ExpiredJob":
+		return "Job đã kết thúc từ lâu nhưng chưa được dọn — nên đặt ttlSecondsAfterFinished."
+	case "UnusedConfigMap":
+		return "ConfigMap không được workload nào tham chiếu."
+	case "UnusedSecret":
+		return "Secret không được workload, ServiceAccount hay Ingress nào tham chiếu."
+	case "DanglingEndpoints":
+		return "Endpoints không còn Service tương ứng — thường sót lại sau khi xóa Service."
+	default:
+		return "Chưa xác định."
+	}
+}
@@ -0,0 +1,192 @@
+package gc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ignoredConfigMaps are published into every namespace by Kubernetes itself
+var ignoredConfigMaps = map[string]bool{
+	"kube-root-ca.crt": true,
+}
+
+// ignoredSecretTypes are consumed by controllers rather than referenced by
+// pods; TLS secrets are read by Ingress controllers, Gateways, meshes and
+// cert-manager through the API
+var ignoredSecretTypes = map[v1.SecretType]bool{
+	v1.SecretTypeServiceAccountToken: true,
+	v1.SecretTypeBootstrapToken:      true,
+	v1.SecretTypeTLS:                 true,
+	"helm.sh/release.v1":             true,
+}
+
+// refs collects the ConfigMaps and Secrets referenced in a namespace
+// Key format: "namespace/name"
+type refs struct {
+	configMaps map[string]bool
+	secrets    map[string]bool
+}
+
+// unusedConfig returns ConfigMaps and Secrets older than minAge that no pod,
+// workload template, ServiceAccount or Ingress refers to
+func unusedConfig(ctx context.Context, cs *k8s.ClusterSnapshot, ns string, now time.Time, minAge time.Duration) ([]Resource, error) {
+	client := cs.Client()
+	r, err := collectRefs(ctx, cs, ns)
+	if err != nil {
+		return nil, err
+	}
+
+	configMaps, err := client.CoreV1().ConfigMaps(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list configmaps: %w", err)
+	}
+	secrets, err := client.CoreV1().Secrets(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	var found []Resource
+	for _, cm := range configMaps.Items {
+		// Objects owned by a controller are cleaned up together with their owner
+		if ignoredConfigMaps[cm.Name] || len(cm.OwnerReferences) > 0 || kept(&cm) || now.Sub(cm.CreationTimestamp.Time) < minAge {
+			continue
+		}
+		if !r.configMaps[cm.Namespace+"/"+cm.Name] {
+			found = append(found, Resource{Kind: "ConfigMap", Namespace: cm.Namespace, Name: cm.Name, Reason: "UnusedConfigMap", Since: cm.CreationTimestamp.Time})
+		}
+	}
+	for _, s := range secrets.Items {
+		if ignoredSecretTypes[s.Type] || len(s.OwnerReferences) > 0 || kept(&s) || now.Sub(s.CreationTimestamp.Time) < minAge {
+			continue
+		}
+		if !r.secrets[s.Namespace+"/"+s.Name] {
+			found = append(found, Resource{Kind: "Secret", Namespace: s.Namespace, Name: s.Name, Reason: "UnusedSecret", Since: s.CreationTimestamp.Time})
+		}
+	}
+	return found, nil
+}
+
+// collectRefs walks pods, workload templates, ServiceAccounts and Ingresses
+func collectRefs(ctx context.Context, cs *k8s.ClusterSnapshot, ns string) (refs, error) {
+	client := cs.Client()
+	r := refs{configMaps: map[string]bool{}, secrets: map[string]bool{}}
+
+	pods, err := cs.PodsIn(ctx, ns)
+	if err != nil {
+		return r, fmt.Errorf("failed to list pods: %w", err)
+	}
+	for _, p := range pods {
+		r.addPodSpec(p.Namespace, p.Spec)
+	}
+
+	// Templates cover workloads that are scaled to zero or suspended
+	deployments, err := client.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return r, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for _, d := range deployments.Items {
+		r.addPodSpec(d.Namespace, d.Spec.Template.Spec)
+	}
+	statefulSets, err := client.AppsV1().StatefulSets(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return r, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for _, s := range statefulSets.Items {
+		r.addPodSpec(s.Namespace, s.Spec.Template.Spec)
+	}
+	daemonSets, err := client.AppsV1().DaemonSets(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return r, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for _, d := range daemonSets.Items {
+		r.addPodSpec(d.Namespace, d.Spec.Template.Spec)
+	}
+	cronJobs, err := client.BatchV1().CronJobs(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return r, fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+	for _, c := range cronJobs.Items {
+		r.addPodSpec(c.Namespace, c.Spec.JobTemplate.Spec.Template.Spec)
+	}
+
+	serviceAccounts, err := client.CoreV1().ServiceAccounts(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return r, fmt.Errorf("failed to list serviceaccounts: %w", err)
+	}
+	for _, sa := range serviceAccounts.Items {
+		for _, s := range sa.Secrets {
+			r.secrets[sa.Namespace+"/"+s.Name] = true
+		}
+		for _, s := range sa.ImagePullSecrets {
+			r.secrets[sa.Namespace+"/"+s.Name] = true
+		}
+	}
+
+	ingresses, err := client.NetworkingV1().Ingresses(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return r, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+	for _, ing := range ingresses.Items {
+		for _, tls := range ing.Spec.TLS {
+			r.secrets[ing.Namespace+"/"+tls.SecretName] = true
+		}
+	}
+
+	return r, nil
+}
+
+// addPodSpec records every ConfigMap and Secret a pod spec refers to
+func (r refs) addPodSpec(ns string, spec v1.PodSpec) {
+	for _, s := range spec.ImagePullSecrets {
+		r.secrets[ns+"/"+s.Name] = true
+	}
+
+	for _, vol := range spec.Volumes {
+		if vol.ConfigMap != nil {
+			r.configMaps[ns+"/"+vol.ConfigMap.Name] = true
+		}
+		if vol.Secret != nil {
+			r.secrets[ns+"/"+vol.Secret.SecretName] = true
+		}
+		if vol.Projected != nil {
+			for _, src := range vol.Projected.Sources {
+				if src.ConfigMap != nil {
+					r.configMaps[ns+"/"+src.ConfigMap.Name] = true
+				}
+				if src.Secret != nil {
+					r.secrets[ns+"/"+src.Secret.Name] = true
+				}
+			}
+		}
+	}
+
+	containers := append([]v1.Container{}, spec.InitContainers...)
+	containers = append(containers, spec.Containers...)
+	for _, c := range containers {
+		for _, from := range c.EnvFrom {
+			if from.ConfigMapRef != nil {
+				r.configMaps[ns+"/"+from.ConfigMapRef.Name] = true
+			}
+			if from.SecretRef != nil {
+				r.secrets[ns+"/"+from.SecretRef.Name] = true
+			}
+		}
+		for _, env := range c.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				r.configMaps[ns+"/"+env.ValueFrom.ConfigMapKeyRef.Name] = true
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				r.secrets[ns+"/"+env.ValueFrom.SecretKeyRef.Name] = true
+			}
+		}
+	}
+}
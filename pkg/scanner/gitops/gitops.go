@@ -0,0 +1,310 @@
+// Package gitops reports GitOps drift: Argo CD Applications that are
+// degraded or out of sync and Flux Kustomizations/HelmReleases whose
+// reconciliation failed.
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// Finding is a GitOps issue together with the namespace its resources are
+// deployed to, used to correlate it with pod issues
+type Finding struct {
+	Issue           types.Issue
+	TargetNamespace string
+}
+
+// source is a GitOps resource kind, with the API versions tried in order
+type source struct {
+	kind     string
+	versions []schema.GroupVersionResource
+	check    func(obj *unstructured.Unstructured) []Finding
+}
+
+var sources = []source{
+	{
+		kind: "Application",
+		versions: []schema.GroupVersionResource{
+			{Group: "argoproj.io", Version: "v1alpha1", Resource: "applications"},
+		},
+		check: checkArgoApplication,
+	},
+	{
+		kind: "Kustomization",
+		versions: []schema.GroupVersionResource{
+			{Group: "kustomize.toolkit.fluxcd.io", Version: "v1", Resource: "kustomizations"},
+			{Group: "kustomize.toolkit.fluxcd.io", Version: "v1beta2", Resource: "kustomizations"},
+		},
+		check: checkFlux("Kustomization"),
+	},
+	{
+		kind: "HelmRelease",
+		versions: []schema.GroupVersionResource{
+			{Group: "helm.toolkit.fluxcd.io", Version: "v2", Resource: "helmreleases"},
+			{Group: "helm.toolkit.fluxcd.io", Version: "v2beta2", Resource: "helmreleases"},
+			{Group: "helm.toolkit.fluxcd.io", Version: "v2beta1", Resource: "helmreleases"},
+		},
+		check: checkFlux("HelmRelease"),
+	},
+}
+
+// ScanCluster lists Argo CD and Flux resources in the given namespaces (all
+// when empty). Kinds whose CRDs are not installed are skipped; when neither
+// Argo CD nor Flux is found a warning is returned.
+func ScanCluster(ctx context.Context, dyn dynamic.Interface, namespaces []string, ignoredNamespaces map[string]bool) ([]Finding, []string, error) {
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	var (
+		findings  []Finding
+		warnings  []string
+		installed int
+	)
+	timestamp := time.Now().Format(time.RFC3339)
+
+	for _, src := range sources {
+		objs, found, err := list(ctx, dyn, src.versions, namespaces)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, nil, ctx.Err()
+			}
+			warnings = append(warnings, fmt.Sprintf("gitops: failed to list %ss: %v", src.kind, err))
+			continue
+		}
+		if !found {
+			continue
+		}
+		installed++
+		for i := range objs {
+			if ignoredNamespaces[objs[i].GetNamespace()] {
+				continue
+			}
+			for _, f := range src.check(&objs[i]) {
+				f.Issue.Timestamp = timestamp
+				f.Issue.Severity = severity.FromReason(f.Issue.Reason)
+				findings = append(findings, f)
+			}
+		}
+	}
+	if installed == 0 {
+		warnings = append(warnings, "gitops: neither Argo CD nor Flux CRDs are installed in the cluster")
+	}
+	return findings, warnings, nil
+}
+
+// list returns the objects of the first API version served by the cluster
+func list(ctx context.Context, dyn dynamic.Interface, versions []schema.GroupVersionResource, namespaces []string) ([]unstructured.Unstructured, bool, error) {
+	for _, gvr := range versions {
+		var objs []unstructured.Unstructured
+		served := true
+		for _, ns := range namespaces {
+			l, err := dyn.Resource(gvr).Namespace(ns).List(ctx, metav1.ListOptions{})
+			if apierrors.IsNotFound(err) {
+				served = false
+				break
+			}
+			if err != nil {
+				return nil, true, err
+			}
+			objs = append(objs, l.Items...)
+		}
+		if served {
+			return objs, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// checkArgoApplication reports degraded, out-of-sync and failed-to-sync
+// Argo CD Applications
+func checkArgoApplication(obj *unstructured.Unstructured) []Finding {
+	target, _, _ := unstructured.NestedString(obj.Object, "spec", "destination", "namespace")
+	if target == "" {
+		target = obj.GetNamespace()
+	}
+	newFinding := func(reason, rootCause, suggestion string) Finding {
+		return Finding{
+			Issue: types.Issue{
+				Kind:       "Application",
+				Namespace:  obj.GetNamespace(),
+				Name:       obj.GetName(),
+				Reason:     reason,
+				RootCause:  rootCause,
+				Suggestion: suggestion,
+				PodStatus:  argoStatus(obj),
+			},
+			TargetNamespace: target,
+		}
+	}
+
+	var findings []Finding
+	health, _, _ := unstructured.NestedString(obj.Object, "status", "health", "status")
+	switch health {
+	case "Degraded", "Missing":
+		rootCause := fmt.Sprintf("Application có health %s.", health)
+		if msg, _, _ := unstructured.NestedString(obj.Object, "status", "health", "message"); msg != "" {
+			rootCause += " " + sentence(msg)
+		}
+		findings = append(findings, newFinding("ArgoAppDegraded", rootCause,
+			"argocd app get "+obj.GetName()+" để xem resource nào không healthy"))
+	}
+
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "operationState", "phase")
+	if phase == "Failed" || phase == "Error" {
+		rootCause := "Lần sync gần nhất thất bại."
+		if msg, _, _ := unstructured.NestedString(obj.Object, "status", "operationState", "message"); msg != "" {
+			rootCause += " " + sentence(msg)
+		}
+		findings = append(findings, newFinding("ArgoSyncFailed", rootCause,
+			"Kiểm tra manifest trong Git và quyền của Argo CD, sau đó sync lại"))
+	} else if sync, _, _ := unstructured.NestedString(obj.Object, "status", "sync", "status"); sync == "OutOfSync" {
+		rootCause := "Trạng thái cluster khác với Git (OutOfSync)."
+		if rev, _, _ := unstructured.NestedString(obj.Object, "status", "sync", "revision"); rev != "" {
+			rootCause += " Revision: " + rev + "."
+		}
+		if msg := conditionMessages(obj, "ComparisonError", "SyncError"); msg != "" {
+			rootCause += " " + sentence(msg)
+		}
+		findings = append(findings, newFinding("ArgoAppOutOfSync", rootCause,
+			"argocd app diff "+obj.GetName()+" để xem thay đổi ngoài Git; bật auto-sync/self-heal nếu cần"))
+	}
+	return findings
+}
+
+// argoStatus is "<health>/<sync>", e.g. Degraded/Synced
+func argoStatus(obj *unstructured.Unstructured) string {
+	health, _, _ := unstructured.NestedString(obj.Object, "status", "health", "status")
+	sync, _, _ := unstructured.NestedString(obj.Object, "status", "sync", "status")
+	if health == "" && sync == "" {
+		return ""
+	}
+	return health + "/" + sync
+}
+
+// checkFlux reports Flux resources whose Ready condition is False.
+// Suspended resources are skipped.
+func checkFlux(kind string) func(obj *unstructured.Unstructured) []Finding {
+	return func(obj *unstructured.Unstructured) []Finding {
+		if suspended, _, _ := unstructured.NestedBool(obj.Object, "spec", "suspend"); suspended {
+			return nil
+		}
+		ready, ok := findCondition(obj, "Ready")
+		if !ok || ready["status"] != "False" {
+			return nil
+		}
+		target, _, _ := unstructured.NestedString(obj.Object, "spec", "targetNamespace")
+		if target == "" {
+			target = obj.GetNamespace()
+		}
+		reason, _ := ready["reason"].(string)
+		rootCause := fmt.Sprintf("%s reconcile thất bại", kind)
+		if reason != "" {
+			rootCause += " (" + reason + ")"
+		}
+		rootCause += "."
+		if msg, _ := ready["message"].(string); msg != "" {
+			rootCause += " " + sentence(msg)
+		}
+		issue := types.Issue{
+			Kind:       kind,
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+			Reason:     "FluxReconcileFailed",
+			RootCause:  rootCause,
+			Suggestion: fmt.Sprintf("flux get %s %s -n %s; sửa lỗi trong Git rồi flux reconcile", fluxResource(kind), obj.GetName(), obj.GetNamespace()),
+			PodStatus:  reason,
+		}
+		if since, _ := ready["lastTransitionTime"].(string); since != "" {
+			issue.InStateSince = since
+		}
+		return []Finding{{Issue: issue, TargetNamespace: target}}
+	}
+}
+
+// fluxResource is the flux CLI name of kind
+func fluxResource(kind string) string {
+	if kind == "HelmRelease" {
+		return "helmrelease"
+	}
+	return "kustomization"
+}
+
+// findCondition returns status.conditions[type=condType]
+func findCondition(obj *unstructured.Unstructured, condType string) (map[string]any, bool) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		m, ok := c.(map[string]any)
+		if ok && m["type"] == condType {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// conditionMessages joins the messages of the given condition types
+func conditionMessages(obj *unstructured.Unstructured, condTypes ...string) string {
+	var msgs []string
+	for _, t := range condTypes {
+		if c, ok := findCondition(obj, t); ok {
+			if msg, _ := c["message"].(string); msg != "" {
+				msgs = append(msgs, msg)
+			}
+		}
+	}
+	return strings.Join(msgs, " ")
+}
+
+// sentence terminates msg with a period
+func sentence(msg string) string {
+	msg = strings.TrimSpace(msg)
+	if strings.HasSuffix(msg, ".") {
+		return msg
+	}
+	return msg + "."
+}
+
+// Correlate returns the findings' issues, noting in the root cause of each
+// the pod issues found in its target namespace
+func Correlate(findings []Finding, podIssues []types.Issue) []types.Issue {
+	byNamespace := map[string]map[string]int{}
+	for _, is := range podIssues {
+		if is.Kind != "Pod" {
+			continue
+		}
+		if byNamespace[is.Namespace] == nil {
+			byNamespace[is.Namespace] = map[string]int{}
+		}
+		byNamespace[is.Namespace][is.Reason]++
+	}
+
+	issues := make([]types.Issue, len(findings))
+	for i, f := range findings {
+		issues[i] = f.Issue
+		reasons := byNamespace[f.TargetNamespace]
+		if len(reasons) == 0 {
+			continue
+		}
+		total := 0
+		parts := make([]string, 0, len(reasons))
+		for reason, n := range reasons {
+			total += n
+			parts = append(parts, fmt.Sprintf("%s×%d", reason, n))
+		}
+		sort.Strings(parts)
+		issues[i].RootCause += fmt.Sprintf(" Namespace %s đang có %d pod issue (%s).", f.TargetNamespace, total, strings.Join(parts, ", "))
+	}
+	return issues
+}
@@ -0,0 +1,295 @@
+// Package karpenter reports Karpenter provisioning problems: NodeClaims
+// that failed to launch or to register, drifted NodeClaims, and NodePools
+// that are not ready or have reached their limits. The problems are also
+// added to the root cause of the Pending pods waiting for capacity.
+package karpenter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// source is a Karpenter resource kind, with the API versions tried in order.
+// Karpenter before v0.32 named NodeClaims Machines and NodePools Provisioners.
+type source struct {
+	kind     string
+	versions []schema.GroupVersionResource
+	check    func(obj *unstructured.Unstructured) []types.Issue
+}
+
+var sources = []source{
+	{
+		kind: "NodeClaim",
+		versions: []schema.GroupVersionResource{
+			{Group: "karpenter.sh", Version: "v1", Resource: "nodeclaims"},
+			{Group: "karpenter.sh", Version: "v1beta1", Resource: "nodeclaims"},
+		},
+		check: checkNodeClaim("NodeClaim"),
+	},
+	{
+		kind: "Machine",
+		versions: []schema.GroupVersionResource{
+			{Group: "karpenter.sh", Version: "v1alpha5", Resource: "machines"},
+		},
+		check: checkNodeClaim("Machine"),
+	},
+	{
+		kind: "NodePool",
+		versions: []schema.GroupVersionResource{
+			{Group: "karpenter.sh", Version: "v1", Resource: "nodepools"},
+			{Group: "karpenter.sh", Version: "v1beta1", Resource: "nodepools"},
+		},
+		check: checkNodePool("NodePool", "spec", "limits"),
+	},
+	{
+		kind: "Provisioner",
+		versions: []schema.GroupVersionResource{
+			{Group: "karpenter.sh", Version: "v1alpha5", Resource: "provisioners"},
+		},
+		check: checkNodePool("Provisioner", "spec", "limits", "resources"),
+	},
+}
+
+// provisioningReasons are the reasons that keep Pending pods waiting
+var provisioningReasons = map[string]bool{
+	"KarpenterLaunchFailed":         true,
+	"KarpenterNodeNotRegistered":    true,
+	"KarpenterNodePoolNotReady":     true,
+	"KarpenterNodePoolLimitReached": true,
+}
+
+// ScanCluster lists the Karpenter NodeClaims and NodePools (or Machines and
+// Provisioners) of the cluster. Kinds whose CRDs are not installed are
+// skipped; when none is found a warning is returned.
+func ScanCluster(ctx context.Context, dyn dynamic.Interface) ([]types.Issue, []string, error) {
+	var (
+		issues    []types.Issue
+		warnings  []string
+		installed int
+	)
+	timestamp := time.Now().Format(time.RFC3339)
+
+	for _, src := range sources {
+		objs, found, err := list(ctx, dyn, src.versions)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, nil, ctx.Err()
+			}
+			warnings = append(warnings, fmt.Sprintf("karpenter: failed to list %ss: %v", src.kind, err))
+			continue
+		}
+		if !found {
+			continue
+		}
+		installed++
+		for i := range objs {
+			for _, is := range src.check(&objs[i]) {
+				is.Timestamp = timestamp
+				is.Severity = severity.FromReason(is.Reason)
+				issues = append(issues, is)
+			}
+		}
+	}
+	if installed == 0 {
+		warnings = append(warnings, "karpenter: Karpenter CRDs are not installed in the cluster")
+	}
+	return issues, warnings, nil
+}
+
+// list returns the cluster-scoped objects of the first API version served
+func list(ctx context.Context, dyn dynamic.Interface, versions []schema.GroupVersionResource) ([]unstructured.Unstructured, bool, error) {
+	for _, gvr := range versions {
+		l, err := dyn.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, true, err
+		}
+		return l.Items, true, nil
+	}
+	return nil, false, nil
+}
+
+// checkNodeClaim reports NodeClaims (or Machines) that failed to launch, were
+// launched but never joined the cluster, or drifted from their NodePool.
+// NodeClaims being deleted are skipped.
+func checkNodeClaim(kind string) func(obj *unstructured.Unstructured) []types.Issue {
+	return func(obj *unstructured.Unstructured) []types.Issue {
+		if obj.GetDeletionTimestamp() != nil {
+			return nil
+		}
+		nodeName, _, _ := unstructured.NestedString(obj.Object, "status", "nodeName")
+		newIssue := func(reason string, cond map[string]any, rootCause, suggestion string) types.Issue {
+			is := types.Issue{
+				Kind:       kind,
+				Name:       obj.GetName(),
+				Reason:     reason,
+				RootCause:  rootCause + conditionDetail(cond),
+				Suggestion: suggestion,
+				NodeName:   nodeName,
+			}
+			is.PodStatus, _ = cond["reason"].(string)
+			is.InStateSince, _ = cond["lastTransitionTime"].(string)
+			return is
+		}
+		pool := obj.GetLabels()["karpenter.sh/nodepool"]
+		if pool == "" {
+			pool = obj.GetLabels()["karpenter.sh/provisioner-name"]
+		}
+
+		var issues []types.Issue
+		launched, hasLaunched := findCondition(obj, "Launched", "MachineLaunched")
+		registered, _ := findCondition(obj, "Registered", "MachineRegistered")
+		switch {
+		case hasLaunched && launched["status"] == "False":
+			issues = append(issues, newIssue("KarpenterLaunchFailed", launched,
+				fmt.Sprintf("Karpenter không launch được instance cho %s của NodePool %s.", kind, pool),
+				"Kiểm tra capacity/quota của cloud provider và các instance type, zone, capacity type cho phép trong NodePool; kubectl -n kube-system logs deploy/karpenter"))
+		case hasLaunched && launched["status"] == "True" && registered["status"] == "False":
+			issues = append(issues, newIssue("KarpenterNodeNotRegistered", registered,
+				fmt.Sprintf("Instance của %s đã launch nhưng node chưa join cluster.", kind),
+				"Kiểm tra user data/bootstrap, IAM role của node (aws-auth/access entry), security group và subnet của EC2NodeClass/NodeClass"))
+		}
+		if drifted, ok := findCondition(obj, "Drifted", "MachineDrifted"); ok && drifted["status"] == "True" {
+			issues = append(issues, newIssue("KarpenterNodeDrifted", drifted,
+				fmt.Sprintf("%s khác với cấu hình hiện tại của NodePool %s (drift) và chờ được thay thế.", kind, pool),
+				"Drift kéo dài thường do PodDisruptionBudget hoặc disruption budget của NodePool chặn việc thay node; kiểm tra kubectl get pdb -A"))
+		}
+		return issues
+	}
+}
+
+// checkNodePool reports NodePools (or Provisioners) that are not ready or
+// whose usage has reached one of their limits
+func checkNodePool(kind string, limitsPath ...string) func(obj *unstructured.Unstructured) []types.Issue {
+	return func(obj *unstructured.Unstructured) []types.Issue {
+		var issues []types.Issue
+		if ready, ok := findCondition(obj, "Ready"); ok && ready["status"] == "False" {
+			is := types.Issue{
+				Kind:       kind,
+				Name:       obj.GetName(),
+				Reason:     "KarpenterNodePoolNotReady",
+				RootCause:  fmt.Sprintf("%s chưa Ready nên Karpenter không tạo node từ %s này.", kind, kind) + conditionDetail(ready),
+				Suggestion: "kubectl describe nodepool " + obj.GetName() + "; kiểm tra NodeClass được tham chiếu có tồn tại và Ready",
+			}
+			is.PodStatus, _ = ready["reason"].(string)
+			is.InStateSince, _ = ready["lastTransitionTime"].(string)
+			issues = append(issues, is)
+		}
+
+		limits := quantities(obj, limitsPath...)
+		usage := quantities(obj, "status", "resources")
+		if reached := reachedLimits(limits, usage); len(reached) > 0 {
+			issues = append(issues, types.Issue{
+				Kind:       kind,
+				Name:       obj.GetName(),
+				Reason:     "KarpenterNodePoolLimitReached",
+				RootCause:  fmt.Sprintf("%s đã dùng hết limits (%s) — Karpenter không tạo thêm node cho pod Pending.", kind, strings.Join(reached, ", ")),
+				Suggestion: fmt.Sprintf("Tăng spec.limits của %s %s hoặc giảm requests/replicas của workload", strings.ToLower(kind), obj.GetName()),
+			})
+		}
+		return issues
+	}
+}
+
+// reachedLimits returns the resources whose usage reached their limit, as
+// "cpu 100/100", sorted
+func reachedLimits(limits, usage map[string]string) []string {
+	var reached []string
+	for name, l := range limits {
+		limit, err := resource.ParseQuantity(l)
+		if err != nil {
+			continue
+		}
+		used, err := resource.ParseQuantity(usage[name])
+		if err != nil || used.Cmp(limit) < 0 {
+			continue
+		}
+		reached = append(reached, fmt.Sprintf("%s %s/%s", name, used.String(), limit.String()))
+	}
+	sort.Strings(reached)
+	return reached
+}
+
+// quantities reads a resource list, whose values may be strings ("16Gi")
+// or plain numbers (cpu: 100)
+func quantities(obj *unstructured.Unstructured, path ...string) map[string]string {
+	m, _, _ := unstructured.NestedMap(obj.Object, path...)
+	out := make(map[string]string, len(m))
+	for name, v := range m {
+		out[name] = fmt.Sprint(v)
+	}
+	return out
+}
+
+// findCondition returns the first of status.conditions[type] found
+func findCondition(obj *unstructured.Unstructured, condTypes ...string) (map[string]any, bool) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, t := range condTypes {
+		for _, c := range conditions {
+			m, ok := c.(map[string]any)
+			if ok && m["type"] == t {
+				return m, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// conditionDetail formats the reason and message of a condition
+func conditionDetail(cond map[string]any) string {
+	reason, _ := cond["reason"].(string)
+	msg, _ := cond["message"].(string)
+	switch {
+	case reason != "" && msg != "":
+		return fmt.Sprintf(" %s: %s", reason, msg)
+	case msg != "":
+		return " " + msg
+	case reason != "":
+		return " " + reason
+	}
+	return ""
+}
+
+// maxCorrelated bounds how many Karpenter problems a pod root cause lists
+const maxCorrelated = 3
+
+// CorrelatePending adds the Karpenter provisioning problems found among
+// issues to the root cause of the unscheduled Pending pods, which wait for
+// the nodes Karpenter cannot provide
+func CorrelatePending(issues []types.Issue) {
+	var problems []string
+	for _, is := range issues {
+		if provisioningReasons[is.Reason] {
+			problems = append(problems, fmt.Sprintf("%s %s (%s)", is.Kind, is.Name, is.Reason))
+		}
+	}
+	if len(problems) == 0 {
+		return
+	}
+	sort.Strings(problems)
+	summary := strings.Join(problems[:min(len(problems), maxCorrelated)], ", ")
+	if len(problems) > maxCorrelated {
+		summary += fmt.Sprintf(" và %d vấn đề khác", len(problems)-maxCorrelated)
+	}
+	for i := range issues {
+		is := &issues[i]
+		if is.Kind == "Pod" && is.Reason == "Pending" && is.NodeName == "" {
+			is.RootCause += " Karpenter đang không cấp được node: " + summary + "."
+		}
+	}
+}
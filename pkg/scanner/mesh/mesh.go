@@ -0,0 +1,312 @@
+// Package mesh checks Istio service mesh health: istio-proxy sidecars that
+// are not ready, pods missing a sidecar in namespaces with injection enabled,
+// and DestinationRules whose TLS mode conflicts with the PeerAuthentication
+// of the namespace they target.
+package mesh
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// SidecarName is the name of the Istio sidecar container
+const SidecarName = "istio-proxy"
+
+// RootNamespace is the Istio root namespace, whose PeerAuthentication
+// without a selector applies to the whole mesh
+const RootNamespace = "istio-system"
+
+var (
+	peerAuthentications = []schema.GroupVersionResource{
+		{Group: "security.istio.io", Version: "v1", Resource: "peerauthentications"},
+		{Group: "security.istio.io", Version: "v1beta1", Resource: "peerauthentications"},
+	}
+	destinationRules = []schema.GroupVersionResource{
+		{Group: "networking.istio.io", Version: "v1", Resource: "destinationrules"},
+		{Group: "networking.istio.io", Version: "v1beta1", Resource: "destinationrules"},
+	}
+)
+
+// ScanCluster checks the sidecars of the snapshot's pods and, when dyn is
+// set, the mTLS configuration of the mesh
+func ScanCluster(ctx context.Context, cs *k8s.ClusterSnapshot, dyn dynamic.Interface, ignoredNamespaces map[string]bool) ([]types.Issue, []string, error) {
+	pods, _, err := cs.Pods(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var warnings []string
+	injected := map[string]bool{}
+	namespaces, err := cs.NamespaceObjects(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		warnings = append(warnings, fmt.Sprintf("mesh: cannot list namespaces, skipping the missing sidecar check: %v", err))
+		namespaces = nil
+	}
+	for _, ns := range namespaces {
+		injected[ns.Name] = injectionEnabled(ns.Labels)
+	}
+
+	now := time.Now()
+	timestamp := now.Format(time.RFC3339)
+	var issues []types.Issue
+	for i := range pods {
+		p := &pods[i]
+		if ignoredNamespaces[p.Namespace] || p.Status.Phase == v1.PodSucceeded || p.Status.Phase == v1.PodFailed {
+			continue
+		}
+		if issue, ok := checkSidecar(p, injected[p.Namespace], namespaces != nil); ok {
+			issue.Timestamp = timestamp
+			issues = append(issues, issue)
+		}
+	}
+
+	if dyn != nil {
+		found, warns, err := checkMTLS(ctx, dyn, cs.ScopedNamespaces(), ignoredNamespaces)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range found {
+			found[i].Timestamp = timestamp
+		}
+		issues = append(issues, found...)
+		warnings = append(warnings, warns...)
+	}
+	return issues, warnings, nil
+}
+
+// injectionEnabled reports whether sidecar injection is enabled by the
+// labels of a namespace
+func injectionEnabled(labels map[string]string) bool {
+	switch labels["istio-injection"] {
+	case "enabled":
+		return true
+	case "disabled":
+		return false
+	}
+	return labels["istio.io/rev"] != ""
+}
+
+// checkSidecar reports a sidecar that is not ready, or a missing one when
+// injection applies to the pod
+func checkSidecar(p *v1.Pod, nsInjected, nsKnown bool) (types.Issue, bool) {
+	issue := types.Issue{
+		Kind:      "Pod",
+		Namespace: p.Namespace,
+		Name:      p.Name,
+		Container: SidecarName,
+		PodStatus: string(p.Status.Phase),
+		NodeName:  p.Spec.NodeName,
+	}
+
+	status, ok := sidecarStatus(p)
+	if !ok {
+		if !nsKnown || p.Spec.HostNetwork || !wantsInjection(p, nsInjected) {
+			return types.Issue{}, false
+		}
+		issue.Container = ""
+		issue.Reason = "IstioSidecarMissing"
+		issue.RootCause = "Injection được bật nhưng pod không có sidecar istio-proxy — pod được tạo trước khi bật injection hoặc webhook istio-sidecar-injector lỗi."
+		issue.Suggestion = fmt.Sprintf("kubectl rollout restart các workload trong namespace %s; kiểm tra MutatingWebhookConfiguration istio-sidecar-injector", p.Namespace)
+		issue.Severity = severity.FromReason(issue.Reason)
+		return issue, true
+	}
+
+	// A sidecar still starting is reported by the pod scanner's grace periods
+	if status.Ready || p.Status.Phase != v1.PodRunning {
+		return types.Issue{}, false
+	}
+	issue.Reason = "IstioSidecarNotReady"
+	issue.RestartCount = status.RestartCount
+	issue.RootCause = "Sidecar istio-proxy chưa ready — thường do không kết nối được istiod hoặc chưa nhận được config (xDS)."
+	if w := status.State.Waiting; w != nil && w.Reason != "" {
+		issue.RootCause += " Trạng thái: " + w.Reason + "."
+	}
+	if t := status.LastTerminationState.Terminated; t != nil && t.Reason == "OOMKilled" {
+		issue.RootCause += " Lần chạy trước bị OOMKilled — tăng memory limit của proxy."
+	}
+	issue.Suggestion = fmt.Sprintf("kubectl logs %s -n %s -c %s; istioctl proxy-status", p.Name, p.Namespace, SidecarName)
+	issue.Severity = severity.FromReason(issue.Reason)
+	return issue, true
+}
+
+// sidecarStatus returns the status of the istio-proxy container, which is
+// an init container when Istio runs it as a native sidecar
+func sidecarStatus(p *v1.Pod) (v1.ContainerStatus, bool) {
+	declared := false
+	for _, c := range p.Spec.Containers {
+		declared = declared || c.Name == SidecarName
+	}
+	for _, c := range p.Spec.InitContainers {
+		declared = declared || c.Name == SidecarName
+	}
+	if !declared {
+		return v1.ContainerStatus{}, false
+	}
+	for _, statuses := range [][]v1.ContainerStatus{p.Status.ContainerStatuses, p.Status.InitContainerStatuses} {
+		for _, s := range statuses {
+			if s.Name == SidecarName {
+				return s, true
+			}
+		}
+	}
+	// Declared but not started yet
+	return v1.ContainerStatus{Name: SidecarName}, true
+}
+
+// wantsInjection applies the pod's sidecar.istio.io/inject label or
+// annotation on top of the namespace setting
+func wantsInjection(p *v1.Pod, nsInjected bool) bool {
+	for _, m := range []map[string]string{p.Labels, p.Annotations} {
+		switch m["sidecar.istio.io/inject"] {
+		case "true":
+			return true
+		case "false":
+			return false
+		}
+	}
+	return nsInjected
+}
+
+// checkMTLS reports DestinationRules whose client TLS mode does not match
+// the PeerAuthentication mode of the namespace of their host: plaintext
+// towards STRICT, or ISTIO_MUTUAL towards DISABLE, fails every request
+func checkMTLS(ctx context.Context, dyn dynamic.Interface, namespaces []string, ignoredNamespaces map[string]bool) ([]types.Issue, []string, error) {
+	// PeerAuthentications are read in every namespace: the mesh-wide one
+	// lives in the root namespace
+	pas, ok, err := listFirst(ctx, dyn, peerAuthentications, []string{metav1.NamespaceAll})
+	if err != nil || !ok {
+		return nil, mtlsWarning(ctx, err, ok), ctx.Err()
+	}
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	drs, ok, err := listFirst(ctx, dyn, destinationRules, namespaces)
+	if err != nil || !ok {
+		return nil, mtlsWarning(ctx, err, ok), ctx.Err()
+	}
+
+	meshMode := ""
+	nsMode := map[string]string{}
+	for _, pa := range pas {
+		if _, hasSelector, _ := unstructured.NestedMap(pa.Object, "spec", "selector"); hasSelector {
+			continue
+		}
+		mode, _, _ := unstructured.NestedString(pa.Object, "spec", "mtls", "mode")
+		if mode == "" || mode == "UNSET" {
+			continue
+		}
+		if pa.GetNamespace() == RootNamespace {
+			meshMode = mode
+		} else {
+			nsMode[pa.GetNamespace()] = mode
+		}
+	}
+	modeOf := func(ns string) string {
+		if m, ok := nsMode[ns]; ok {
+			return m
+		}
+		return meshMode
+	}
+
+	var issues []types.Issue
+	for _, dr := range drs {
+		if ignoredNamespaces[dr.GetNamespace()] {
+			continue
+		}
+		host, _, _ := unstructured.NestedString(dr.Object, "spec", "host")
+		tlsMode, _, _ := unstructured.NestedString(dr.Object, "spec", "trafficPolicy", "tls", "mode")
+		target := hostNamespace(host, dr.GetNamespace())
+		if tlsMode == "" || target == "" {
+			continue
+		}
+		serverMode := modeOf(target)
+		var rootCause string
+		switch {
+		case serverMode == "STRICT" && tlsMode == "DISABLE":
+			rootCause = fmt.Sprintf("DestinationRule tắt TLS tới %s nhưng PeerAuthentication của namespace %s là STRICT — request bị reset (503).", host, target)
+		case serverMode == "DISABLE" && tlsMode == "ISTIO_MUTUAL":
+			rootCause = fmt.Sprintf("DestinationRule dùng ISTIO_MUTUAL tới %s nhưng PeerAuthentication của namespace %s tắt mTLS — request bị reset (503).", host, target)
+		default:
+			continue
+		}
+		issues = append(issues, types.Issue{
+			Kind:       "DestinationRule",
+			Namespace:  dr.GetNamespace(),
+			Name:       dr.GetName(),
+			Reason:     "IstioMTLSConflict",
+			Severity:   severity.FromReason("IstioMTLSConflict"),
+			RootCause:  rootCause,
+			Suggestion: "Đồng bộ trafficPolicy.tls.mode với PeerAuthentication; kiểm tra bằng istioctl x describe service",
+		})
+	}
+	return issues, nil, nil
+}
+
+// mtlsWarning explains why the mTLS check was skipped
+func mtlsWarning(ctx context.Context, err error, installed bool) []string {
+	switch {
+	case ctx.Err() != nil:
+		return nil
+	case err != nil:
+		return []string{fmt.Sprintf("mesh: skipping the mTLS check: %v", err)}
+	case !installed:
+		return []string{"mesh: Istio CRDs are not installed, skipping the mTLS check"}
+	}
+	return nil
+}
+
+// hostNamespace returns the namespace of a DestinationRule host: short
+// names are relative to the rule's namespace, and wildcards across
+// namespaces return ""
+func hostNamespace(host, ruleNamespace string) string {
+	parts := strings.Split(host, ".")
+	switch {
+	case host == "" || strings.HasPrefix(host, "*") && len(parts) < 3:
+		return ""
+	case len(parts) == 1:
+		return ruleNamespace
+	case len(parts) >= 3 && parts[2] != "svc":
+		// Not a cluster-local service name (e.g. an external host)
+		return ""
+	}
+	return parts[1]
+}
+
+// listFirst lists the first API version served by the cluster
+func listFirst(ctx context.Context, dyn dynamic.Interface, versions []schema.GroupVersionResource, namespaces []string) ([]unstructured.Unstructured, bool, error) {
+	for _, gvr := range versions {
+		var objs []unstructured.Unstructured
+		served := true
+		for _, ns := range namespaces {
+			l, err := dyn.Resource(gvr).Namespace(ns).List(ctx, metav1.ListOptions{})
+			if apierrors.IsNotFound(err) {
+				served = false
+				break
+			}
+			if err != nil {
+				return nil, true, err
+			}
+			objs = append(objs, l.Items...)
+		}
+		if served {
+			return objs, true, nil
+		}
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,108 @@
+package pod
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// The ConfigMap the cluster autoscaler writes its status to
+const (
+	autoscalerStatusNamespace = "kube-system"
+	autoscalerStatusName      = "cluster-autoscaler-status"
+)
+
+var (
+	// The status is plain text up to cluster-autoscaler 1.29 ("Health: Healthy (ready=3 ...)")
+	// and YAML since ("health:\n    status: Healthy"); the cluster-wide section comes first
+	autoscalerHealthPattern  = regexp.MustCompile(`(?m)^\s*Health:\s+(\w+)|health:\s*\n\s*status:\s*(\w+)`)
+	autoscalerScaleUpPattern = regexp.MustCompile(`(?m)^\s*ScaleUp:\s+(\w+)|scaleUp:\s*\n\s*status:\s*(\w+)`)
+)
+
+// AutoscalerStatus is the cluster-wide state of the cluster autoscaler
+type AutoscalerStatus struct {
+	// Health is Healthy or Unhealthy
+	Health string
+	// ScaleUp is NoActivity, InProgress or Backoff
+	ScaleUp string
+}
+
+// ParseAutoscalerStatus reads the cluster-wide state from the status the
+// cluster autoscaler writes, in either of its formats
+func ParseAutoscalerStatus(text string) AutoscalerStatus {
+	return AutoscalerStatus{
+		Health:  firstGroup(autoscalerHealthPattern.FindStringSubmatch(text)),
+		ScaleUp: firstGroup(autoscalerScaleUpPattern.FindStringSubmatch(text)),
+	}
+}
+
+func firstGroup(m []string) string {
+	for _, g := range m[min(len(m), 1):] {
+		if g != "" {
+			return g
+		}
+	}
+	return ""
+}
+
+// AutoscalerStatusFromCluster returns the cluster autoscaler status, or nil
+// when the cluster has no cluster autoscaler or its status cannot be read
+func AutoscalerStatusFromCluster(ctx context.Context, client kubernetes.Interface) *AutoscalerStatus {
+	cm, err := client.CoreV1().ConfigMaps(autoscalerStatusNamespace).Get(ctx, autoscalerStatusName, metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+	status := ParseAutoscalerStatus(cm.Data["status"])
+	if status.Health == "" && status.ScaleUp == "" {
+		return nil
+	}
+	return &status
+}
+
+// ExplainAutoscaler tells in the root cause of unscheduled Pending pods
+// whether the cluster autoscaler is adding capacity for them, so users know
+// if the pod will be scheduled on its own. The latest TriggeredScaleUp or
+// NotTriggerScaleUp event of the pod wins over the cluster-wide status
+// (which may be nil).
+func ExplainAutoscaler(issues []types.Issue, eventMap EventMap, status *AutoscalerStatus) {
+	for i := range issues {
+		is := &issues[i]
+		if is.Reason != "Pending" || is.NodeName != "" {
+			continue
+		}
+		if why := autoscalerDecision(eventMap.Failures[is.Namespace+"/"+is.Name], status); why != "" {
+			is.RootCause += " " + why
+		}
+	}
+}
+
+// autoscalerDecision explains what the cluster autoscaler does for a pod
+func autoscalerDecision(failures []EventFailure, status *AutoscalerStatus) string {
+	var latest *EventFailure
+	for i := range failures {
+		if scaleUpReasons[failures[i].Reason] && (latest == nil || failures[i].At.After(latest.At)) {
+			latest = &failures[i]
+		}
+	}
+	switch {
+	case latest != nil && latest.Reason == "TriggeredScaleUp":
+		return fmt.Sprintf("Cluster autoscaler đã kích hoạt scale-up (%s) — pod sẽ được schedule khi node mới sẵn sàng.", latest.Message)
+	case latest != nil:
+		return fmt.Sprintf("Cluster autoscaler không scale-up cho pod này (%s) — capacity sẽ không tự phục hồi, cần sửa yêu cầu của pod hoặc giới hạn node group.", latest.Message)
+	case status == nil:
+		return ""
+	case status.Health == "Unhealthy":
+		return "Cluster autoscaler đang Unhealthy — không thể trông chờ scale-up, kiểm tra logs của cluster-autoscaler."
+	case status.ScaleUp == "InProgress":
+		return "Cluster autoscaler đang scale-up — capacity có thể tự phục hồi."
+	case status.ScaleUp == "Backoff":
+		return "Cluster autoscaler đang backoff sau lần scale-up thất bại — capacity chưa tự phục hồi ngay."
+	default:
+		return fmt.Sprintf("Cluster autoscaler chưa scale-up cho pod này (ScaleUp: %s).", status.ScaleUp)
+	}
+}
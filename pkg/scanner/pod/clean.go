@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -23,26 +24,68 @@ type PodInfo struct {
 	Name      string
 	Reason    string
 	Severity  string
+	// Category is the clean category the pod matched: evicted, succeeded or failed
+	Category string
+	// FinishedAt is when the pod stopped running
+	FinishedAt time.Time
+}
+
+// Clean categories
+const (
+	CleanEvicted   = "evicted"
+	CleanSucceeded = "succeeded"
+	CleanFailed    = "failed"
+)
+
+// CleanOptions selects which finished pods are cleaned
+type CleanOptions struct {
+	// Categories to clean (CleanEvicted, CleanSucceeded, CleanFailed); empty means evicted and succeeded
+	Categories map[string]bool
+	// OlderThan only selects pods that finished longer ago than this (0 selects all)
+	OlderThan time.Duration
 }
 
 // CleanPods identifies and optionally deletes evicted pods and completed jobs
 // If dryRun is true, it only reports what would be deleted without actually deleting
-func CleanPods(client *kubernetes.Clientset, namespaces []string, ignoredNamespaces map[string]bool, dryRun bool) (*CleanResult, error) {
+func CleanPods(ctx context.Context, client kubernetes.Interface, namespaces []string, ignoredNamespaces map[string]bool, dryRun bool) (*CleanResult, error) {
+	podsToClean, errs, err := FindPodsToClean(ctx, client, namespaces, ignoredNamespaces, CleanOptions{})
+	if err != nil {
+		return nil, err
+	}
+
 	result := &CleanResult{
 		DeletedPods: make([]PodInfo, 0),
 		DryRun:      dryRun,
-		Errors:      make([]error, 0),
+		Errors:      errs,
+	}
+
+	// Delete or report pods
+	for _, podInfo := range podsToClean {
+		if !dryRun {
+			if err := DeletePod(ctx, client, podInfo); err != nil {
+				result.Errors = append(result.Errors, err)
+				continue
+			}
+		}
+		result.DeletedPods = append(result.DeletedPods, podInfo)
 	}
 
-	opts := metav1.ListOptions{}
+	return result, nil
+}
+
+// FindPodsToClean lists pods and returns those matching opts. Namespaces that
+// cannot be listed are returned as errors without aborting the search.
+func FindPodsToClean(ctx context.Context, client kubernetes.Interface, namespaces []string, ignoredNamespaces map[string]bool, opts CleanOptions) ([]PodInfo, []error, error) {
+	listOpts := metav1.ListOptions{}
 
 	var allPods []v1.Pod
+	var errs []error
 
 	// If no namespaces specified, scan all namespaces
 	if len(namespaces) == 0 {
-		pods, err := client.CoreV1().Pods("").List(context.Background(), opts)
+		pods, err := client.CoreV1().Pods("").List(ctx, listOpts)
 		if err != nil {
-			return nil, fmt.Errorf("failed to list pods: %w", err)
+			return nil, nil, fmt.Errorf("failed to list pods: %w", err)
 		}
 		allPods = pods.Items
 	} else {
@@ -52,9 +95,9 @@ func CleanPods(client *kubernetes.Clientset, namespaces []string, ignoredNamespa
 			if ns == "" {
 				continue
 			}
-			pods, err := client.CoreV1().Pods(ns).List(context.Background(), opts)
+			pods, err := client.CoreV1().Pods(ns).List(ctx, listOpts)
 			if err != nil {
-				result.Errors = append(result.Errors, fmt.Errorf("failed to list pods in namespace %s: %w", ns, err))
+				errs = append(errs, fmt.Errorf("failed to list pods in namespace %s: %w", ns, err))
 				continue
 			}
 			allPods = append(allPods, pods.Items...)
@@ -62,39 +105,40 @@ func CleanPods(client *kubernetes.Clientset, namespaces []string, ignoredNamespa
 	}
 
 	// Filter out pods from ignored namespaces
-	if len(ignoredNamespaces) > 0 {
-		filteredPods := make([]v1.Pod, 0, len(allPods))
-		for _, pod := range allPods {
-			if !ignoredNamespaces[pod.Namespace] {
-				filteredPods = append(filteredPods, pod)
-			}
-		}
-		allPods = filteredPods
-	}
+	allPods = FilterIgnoredNamespaces(allPods, ignoredNamespaces)
 
-	// Identify pods to clean
-	podsToClean := identifyPodsToClean(allPods)
+	categories := opts.Categories
+	if len(categories) == 0 {
+		categories = map[string]bool{CleanEvicted: true, CleanSucceeded: true}
+	}
 
-	// Delete or report pods
-	for _, podInfo := range podsToClean {
-		if dryRun {
-			result.DeletedPods = append(result.DeletedPods, podInfo)
-		} else {
-			err := client.CoreV1().Pods(podInfo.Namespace).Delete(context.Background(), podInfo.Name, metav1.DeleteOptions{})
-			if err != nil {
-				result.Errors = append(result.Errors, fmt.Errorf("failed to delete pod %s/%s: %w", podInfo.Namespace, podInfo.Name, err))
-				continue
-			}
-			result.DeletedPods = append(result.DeletedPods, podInfo)
+	now := time.Now()
+	var selected []PodInfo
+	for _, info := range identifyPodsToClean(allPods) {
+		if !categories[info.Category] {
+			continue
+		}
+		if opts.OlderThan > 0 && now.Sub(info.FinishedAt) < opts.OlderThan {
+			continue
 		}
+		selected = append(selected, info)
 	}
+	return selected, errs, nil
+}
 
-	return result, nil
+// DeletePod deletes a pod selected for cleaning
+func DeletePod(ctx context.Context, client kubernetes.Interface, info PodInfo) error {
+	err := client.CoreV1().Pods(info.Namespace).Delete(ctx, info.Name, metav1.DeleteOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to delete pod %s/%s: %w", info.Namespace, info.Name, err)
+	}
+	return nil
 }
 
 // identifyPodsToClean identifies pods that should be cleaned:
 // 1. Evicted pods (Phase == PodFailed && Reason contains "evicted")
-// 2. Completed jobs (Phase == PodSucceeded)
+// 2. Other failed pods (Phase == PodFailed)
+// 3. Completed jobs (Phase == PodSucceeded)
 func identifyPodsToClean(pods []v1.Pod) []PodInfo {
 	podsToClean := make([]PodInfo, 0)
 
@@ -105,10 +149,28 @@ func identifyPodsToClean(pods []v1.Pod) []PodInfo {
 		// Check for evicted pods
 		if phase == v1.PodFailed && strings.Contains(strings.ToLower(reason), "evicted") {
 			podsToClean = append(podsToClean, PodInfo{
-				Namespace: pod.Namespace,
-				Name:      pod.Name,
-				Reason:    reason,
-				Severity:  "medium",
+				Namespace:  pod.Namespace,
+				Name:       pod.Name,
+				Reason:     reason,
+				Severity:   "medium",
+				Category:   CleanEvicted,
+				FinishedAt: podFinishedAt(pod),
+			})
+			continue
+		}
+
+		// Check for other failed pods
+		if phase == v1.PodFailed {
+			if reason == "" {
+				reason = "Failed"
+			}
+			podsToClean = append(podsToClean, PodInfo{
+				Namespace:  pod.Namespace,
+				Name:       pod.Name,
+				Reason:     reason,
+				Severity:   "medium",
+				Category:   CleanFailed,
+				FinishedAt: podFinishedAt(pod),
 			})
 			continue
 		}
@@ -116,10 +178,12 @@ func identifyPodsToClean(pods []v1.Pod) []PodInfo {
 		// Check for completed jobs
 		if phase == v1.PodSucceeded {
 			podsToClean = append(podsToClean, PodInfo{
-				Namespace: pod.Namespace,
-				Name:      pod.Name,
-				Reason:    "Completed",
-				Severity:  "low",
+				Namespace:  pod.Namespace,
+				Name:       pod.Name,
+				Reason:     "Completed",
+				Severity:   "low",
+				Category:   CleanSucceeded,
+				FinishedAt: podFinishedAt(pod),
 			})
 			continue
 		}
@@ -127,3 +191,21 @@ func identifyPodsToClean(pods []v1.Pod) []PodInfo {
 
 	return podsToClean
 }
+
+// podFinishedAt returns when the last container of a finished pod terminated,
+// falling back to the pod start and creation times
+func podFinishedAt(pod v1.Pod) time.Time {
+	var finished time.Time
+	for _, cs := range pod.Status.ContainerStatuses {
+		if t := cs.State.Terminated; t != nil && t.FinishedAt.After(finished) {
+			finished = t.FinishedAt.Time
+		}
+	}
+	if !finished.IsZero() {
+		return finished
+	}
+	if pod.Status.StartTime != nil {
+		return pod.Status.StartTime.Time
+	}
+	return pod.CreationTimestamp.Time
+}
@@ -0,0 +1,66 @@
+package pod
+
+import (
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// nodePoolLabels name the node pool (node group, ASG, agent pool, instance
+// group) of a node, by provider, first match wins
+var nodePoolLabels = []string{
+	"eks.amazonaws.com/nodegroup",
+	"alpha.eksctl.io/nodegroup-name",
+	"karpenter.sh/nodepool",
+	"karpenter.sh/provisioner-name",
+	"cloud.google.com/gke-nodepool",
+	"kubernetes.azure.com/agentpool",
+	"agentpool",
+	"kops.k8s.io/instancegroup",
+	"node.kubernetes.io/pool",
+}
+
+var (
+	instanceTypeLabels = []string{v1.LabelInstanceTypeStable, v1.LabelInstanceType}
+	zoneLabels         = []string{v1.LabelTopologyZone, v1.LabelFailureDomainBetaZone}
+)
+
+// AnnotateNodeContext sets the node pool, instance type and zone of the
+// issues bound to a node, read from the well-known labels of the node, so
+// capacity and eviction problems can be traced to node pools. Node issues
+// (such as those of the capacity scanner) are bound to the node they name.
+func AnnotateNodeContext(issues []types.Issue, nodes []v1.Node) {
+	if len(nodes) == 0 {
+		return
+	}
+	byName := make(map[string]*v1.Node, len(nodes))
+	for i := range nodes {
+		byName[nodes[i].Name] = &nodes[i]
+	}
+	for i := range issues {
+		is := &issues[i]
+		name := is.NodeName
+		if name == "" && is.Kind == "Node" {
+			name = is.Name
+		}
+		node := byName[name]
+		if node == nil {
+			continue
+		}
+		if is.NodeName == "" {
+			is.NodeName = name
+		}
+		is.NodePool = firstLabel(node.Labels, nodePoolLabels)
+		is.InstanceType = firstLabel(node.Labels, instanceTypeLabels)
+		is.Zone = firstLabel(node.Labels, zoneLabels)
+	}
+}
+
+func firstLabel(labels map[string]string, keys []string) string {
+	for _, k := range keys {
+		if v := labels[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
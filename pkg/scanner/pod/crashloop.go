@@ -0,0 +1,45 @@
+package pod
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// backoffPattern matches the kubelet message "back-off 5m0s restarting failed container=..."
+var backoffPattern = regexp.MustCompile(`(?i)back-off (\d[\dhms.]*) restarting`)
+
+// crashLoopContext describes the current back-off and the previous exit of a
+// crash-looping container, e.g. "back-off 5m0s, exit code 137 (OOMKilled)"
+func crashLoopContext(cs v1.ContainerStatus, lastEvent string) string {
+	var parts []string
+
+	backoff := parseBackoff(cs.State.Waiting.Message)
+	if backoff == "" {
+		backoff = parseBackoff(lastEvent)
+	}
+	if backoff != "" {
+		parts = append(parts, "back-off "+backoff)
+	}
+
+	if t := cs.LastTerminationState.Terminated; t != nil {
+		exit := fmt.Sprintf("exit code %d", t.ExitCode)
+		if t.Reason != "" {
+			exit += " (" + t.Reason + ")"
+		}
+		parts = append(parts, exit)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// parseBackoff extracts the back-off duration from a kubelet message
+func parseBackoff(msg string) string {
+	m := backoffPattern.FindStringSubmatch(msg)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
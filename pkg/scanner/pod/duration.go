@@ -0,0 +1,22 @@
+package pod
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// waitingSince estimates when a waiting container entered its current state.
+// The waiting state carries no timestamp, so use the time the pod last became
+// unready, falling back to the pod start and creation times.
+func waitingSince(pod *v1.Pod) time.Time {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == v1.PodReady && cond.Status != v1.ConditionTrue && !cond.LastTransitionTime.IsZero() {
+			return cond.LastTransitionTime.Time
+		}
+	}
+	if pod.Status.StartTime != nil {
+		return pod.Status.StartTime.Time
+	}
+	return pod.CreationTimestamp.Time
+}
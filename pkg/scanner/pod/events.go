@@ -3,58 +3,98 @@ package pod
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+	"github.com/ductnn/k8s-scanner/pkg/tracing"
+
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
-// EventMap stores the latest event message for each pod
+// EventMap stores what the events of each pod tell the scanner.
 // Key format: "namespace/podname"
-type EventMap map[string]string
+type EventMap struct {
+	// Last is the latest event message of each pod, its LastEvent
+	Last map[string]string
+	// Failures are the problems only reported through events, such as
+	// failing probes
+	Failures map[string][]EventFailure
+}
+
+// EventFailure is an event reporting a problem the pod status does not
+// show: a Warning, or a cluster autoscaler decision about a Pending pod
+type EventFailure struct {
+	// Reason of the event, e.g. Unhealthy
+	Reason string
+	// Container is taken from the event's field path; empty for the pod
+	Container string
+	Message   string
+	Count     int32
+	At        time.Time
+}
+
+// failureReasons are the event reasons kept as EventFailures
+var failureReasons = map[string]bool{
+	"Unhealthy":          true,
+	"FailedMount":        true,
+	"FailedAttachVolume": true,
+	"Failed":             true,
+}
+
+// scaleUpReasons are the cluster autoscaler events kept as EventFailures,
+// which are Normal events
+var scaleUpReasons = map[string]bool{
+	"TriggeredScaleUp":  true,
+	"NotTriggerScaleUp": true,
+}
+
+// EventOptions selects which event becomes a pod's LastEvent
+type EventOptions struct {
+	// MaxAge ignores events last seen longer ago than this (0 keeps all)
+	MaxAge time.Duration
+	// Now is the reference time for MaxAge; zero means time.Now()
+	Now time.Time
+}
 
 // BuildEventMap fetches all events for given namespaces and builds a lookup map
 // This is much more efficient than fetching events per pod
-func BuildEventMap(client *kubernetes.Clientset, namespaces []string) EventMap {
-	eventMap := make(EventMap)
+func BuildEventMap(ctx context.Context, client kubernetes.Interface, namespaces []string, opts EventOptions) EventMap {
+	return buildEventMap(ctx, k8s.NewClusterSnapshot(client, namespaces), namespaces, opts, nil)
+}
+
+func buildEventMap(ctx context.Context, cs *k8s.ClusterSnapshot, namespaces []string, opts EventOptions, progress ProgressFunc) EventMap {
+	ctx, span := tracing.Start(ctx, "fetch events")
+	defer span.End()
+	span.SetAttr("k8s.namespaces", len(namespaces))
+
+	eventMap := EventMap{Last: map[string]string{}, Failures: map[string][]EventFailure{}}
 	var mu sync.Mutex
 	var wg sync.WaitGroup
+	done := 0
+	progress.Report(StageEvents, 0, len(namespaces))
 
 	// Process each namespace concurrently
 	for _, ns := range namespaces {
 		wg.Add(1)
 		go func(namespace string) {
 			defer wg.Done()
-			events, err := client.CoreV1().Events(namespace).List(context.Background(), metav1.ListOptions{})
-			if err != nil {
-				return
-			}
-
-			// Build a map of pod -> latest event message for this namespace
-			nsEventMap := make(map[string]struct {
-				msg string
-				ts  time.Time
-			})
-
-			for _, ev := range events.Items {
-				if ev.InvolvedObject.Kind == "Pod" {
-					key := fmt.Sprintf("%s/%s", namespace, ev.InvolvedObject.Name)
-					existing, exists := nsEventMap[key]
-					if !exists || ev.LastTimestamp.Time.After(existing.ts) {
-						nsEventMap[key] = struct {
-							msg string
-							ts  time.Time
-						}{msg: ev.Message, ts: ev.LastTimestamp.Time}
-					}
-				}
-			}
+			// On error keep whatever was listed before it
+			events, _ := cs.PodEvents(ctx, namespace)
+			nsEventMap := BuildEventMapFromEvents(events, opts)
 
 			// Merge into main map (thread-safe)
 			mu.Lock()
-			for k, v := range nsEventMap {
-				eventMap[k] = v.msg
+			for k, v := range nsEventMap.Last {
+				eventMap.Last[k] = v
 			}
+			for k, v := range nsEventMap.Failures {
+				eventMap.Failures[k] = v
+			}
+			done++
+			progress.Report(StageEvents, done, len(namespaces))
 			mu.Unlock()
 		}(ns)
 	}
@@ -63,8 +103,166 @@ func BuildEventMap(client *kubernetes.Clientset, namespaces []string) EventMap {
 	return eventMap
 }
 
+// BuildEventMapFromEvents builds the lookup map from already-fetched events.
+// For each pod it keeps the latest Warning event, or the latest Normal event
+// when the pod has no Warning, and the EventFailures, ignoring events older
+// than opts.MaxAge.
+func BuildEventMapFromEvents(events []v1.Event, opts EventOptions) EventMap {
+	picker := newEventPicker(opts)
+	for i := range events {
+		picker.add(&events[i])
+	}
+	return picker.eventMap()
+}
+
+// pickedEvent is the part of an event kept while choosing a pod's LastEvent
+type pickedEvent struct {
+	message string
+	warning bool
+	at      time.Time
+	// count and first describe the series of identical events deduplicated
+	// into this one by the API server
+	count int32
+	first time.Time
+}
+
+// eventPicker chooses the LastEvent of each pod from a stream of events
+type eventPicker struct {
+	opts     EventOptions
+	now      time.Time
+	chosen   map[string]pickedEvent
+	failures map[string][]EventFailure
+}
+
+func newEventPicker(opts EventOptions) *eventPicker {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	return &eventPicker{opts: opts, now: now, chosen: make(map[string]pickedEvent), failures: make(map[string][]EventFailure)}
+}
+
+func (p *eventPicker) add(ev *v1.Event) {
+	if ev.InvolvedObject.Kind != "Pod" {
+		return
+	}
+	cand := pickedEvent{
+		message: ev.Message,
+		warning: ev.Type == v1.EventTypeWarning,
+		at:      EventTime(ev),
+		count:   ev.Count,
+		first:   ev.FirstTimestamp.Time,
+	}
+	if ev.Series != nil {
+		cand.count = ev.Series.Count
+		cand.first = ev.EventTime.Time
+	}
+	if cand.first.IsZero() {
+		cand.first = ev.EventTime.Time
+	}
+	p.consider(ev.InvolvedObject.Namespace, ev.InvolvedObject.Name, cand)
+	if (cand.warning && failureReasons[ev.Reason]) || scaleUpReasons[ev.Reason] {
+		p.addFailure(ev, cand)
+	}
+}
+
+func (p *eventPicker) addFailure(ev *v1.Event, cand pickedEvent) {
+	if p.opts.MaxAge > 0 && p.now.Sub(cand.at) > p.opts.MaxAge {
+		return
+	}
+	key := ev.InvolvedObject.Namespace + "/" + ev.InvolvedObject.Name
+	p.failures[key] = append(p.failures[key], EventFailure{
+		Reason:    ev.Reason,
+		Container: fieldPathContainer(ev.InvolvedObject.FieldPath),
+		Message:   ev.Message,
+		Count:     cand.count,
+		At:        cand.at,
+	})
+}
+
+// fieldPathContainer extracts the container of a field path such as
+// "spec.containers{app}"
+func fieldPathContainer(path string) string {
+	_, rest, ok := strings.Cut(path, "{")
+	if !ok {
+		return ""
+	}
+	name, _, _ := strings.Cut(rest, "}")
+	return name
+}
+
+func (p *eventPicker) consider(namespace, name string, cand pickedEvent) {
+	if p.opts.MaxAge > 0 && p.now.Sub(cand.at) > p.opts.MaxAge {
+		return
+	}
+	key := namespace + "/" + name
+	if cur, exists := p.chosen[key]; !exists || cand.preferredTo(cur) {
+		p.chosen[key] = cand
+	}
+}
+
+func (p *eventPicker) eventMap() EventMap {
+	eventMap := EventMap{Last: make(map[string]string, len(p.chosen)), Failures: p.failures}
+	for key, ev := range p.chosen {
+		eventMap.Last[key] = ev.String()
+	}
+	return eventMap
+}
+
+// String is the event message, followed by how often it repeated, e.g.
+// "Back-off restarting failed container (x147 in last 1h2m)"
+func (e pickedEvent) String() string {
+	if e.count <= 1 {
+		return e.message
+	}
+	if e.first.IsZero() || !e.at.After(e.first) {
+		return fmt.Sprintf("%s (x%d)", e.message, e.count)
+	}
+	return fmt.Sprintf("%s (x%d in last %s)", e.message, e.count, formatSpan(e.at.Sub(e.first)))
+}
+
+// formatSpan renders a duration at minute precision ("45s", "12m", "3h5m")
+func formatSpan(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	d = d.Round(time.Minute)
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%dm", m)
+	case m == 0:
+		return fmt.Sprintf("%dh", h)
+	}
+	return fmt.Sprintf("%dh%dm", h, m)
+}
+
+// preferredTo reports whether e should replace other as the pod's LastEvent:
+// Warning events win over Normal ones, then the most recent wins
+func (e pickedEvent) preferredTo(other pickedEvent) bool {
+	if e.warning != other.warning {
+		return e.warning
+	}
+	return e.at.After(other.at)
+}
+
+// EventTime is when the event was last observed. LastTimestamp is empty for
+// events written through events.k8s.io, which set EventTime and Series instead.
+func EventTime(ev *v1.Event) time.Time {
+	switch {
+	case ev.Series != nil && !ev.Series.LastObservedTime.IsZero():
+		return ev.Series.LastObservedTime.Time
+	case !ev.LastTimestamp.IsZero():
+		return ev.LastTimestamp.Time
+	case !ev.EventTime.IsZero():
+		return ev.EventTime.Time
+	case !ev.FirstTimestamp.IsZero():
+		return ev.FirstTimestamp.Time
+	}
+	return ev.CreationTimestamp.Time
+}
+
 // GetLatestPodEvent retrieves the latest event message from the pre-built map
 func GetLatestPodEvent(eventMap EventMap, namespace string, podName string) string {
-	key := fmt.Sprintf("%s/%s", namespace, podName)
-	return eventMap[key]
+	return eventMap.Last[namespace+"/"+podName]
 }
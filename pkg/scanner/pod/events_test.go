@@ -0,0 +1,209 @@
+package pod
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var eventNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// event returns a core/v1 event about pod shop/<pod>, last seen ago before eventNow
+func event(pod, eventType, reason, message string, ago time.Duration) v1.Event {
+	return v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "shop", Name: pod + "." + reason},
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: pod},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        message,
+		LastTimestamp:  metav1.NewTime(eventNow.Add(-ago)),
+	}
+}
+
+func TestBuildEventMapFromEvents(t *testing.T) {
+	repeated := event("web-0", v1.EventTypeWarning, "BackOff", "Back-off restarting failed container", time.Minute)
+	repeated.Count = 147
+	repeated.FirstTimestamp = metav1.NewTime(eventNow.Add(-time.Minute - 62*time.Minute))
+
+	deployment := event("web", v1.EventTypeWarning, "ScalingReplicaSet", "not a pod", 0)
+	deployment.InvolvedObject.Kind = "Deployment"
+
+	tests := []struct {
+		name   string
+		events []v1.Event
+		maxAge time.Duration
+		want   string
+	}{
+		{
+			name: "latest event",
+			events: []v1.Event{
+				event("web-0", v1.EventTypeNormal, "Pulling", "Pulling image", 10*time.Minute),
+				event("web-0", v1.EventTypeNormal, "Started", "Started container", time.Minute),
+			},
+			want: "Started container",
+		},
+		{
+			name: "warnings win over newer normal events",
+			events: []v1.Event{
+				event("web-0", v1.EventTypeWarning, "BackOff", "Back-off restarting failed container", 10*time.Minute),
+				event("web-0", v1.EventTypeNormal, "Pulled", "Container image already present", time.Minute),
+			},
+			want: "Back-off restarting failed container",
+		},
+		{
+			name: "stale events are ignored",
+			events: []v1.Event{
+				event("web-0", v1.EventTypeWarning, "BackOff", "Back-off restarting failed container", 3*time.Hour),
+				event("web-0", v1.EventTypeNormal, "Started", "Started container", time.Minute),
+			},
+			maxAge: time.Hour,
+			want:   "Started container",
+		},
+		{
+			name:   "only stale events",
+			events: []v1.Event{event("web-0", v1.EventTypeWarning, "BackOff", "old", 3*time.Hour)},
+			maxAge: time.Hour,
+			want:   "",
+		},
+		{
+			name:   "repeat count",
+			events: []v1.Event{repeated},
+			want:   "Back-off restarting failed container (x147 in last 1h2m)",
+		},
+		{
+			name:   "other kinds are ignored",
+			events: []v1.Event{deployment},
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := BuildEventMapFromEvents(tt.events, EventOptions{MaxAge: tt.maxAge, Now: eventNow})
+			if got := GetLatestPodEvent(m, "shop", "web-0"); got != tt.want {
+				t.Errorf("LastEvent = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildEventMapFromEventsFailures(t *testing.T) {
+	probe := event("web-0", v1.EventTypeWarning, "Unhealthy", "Liveness probe failed: connection refused", time.Minute)
+	probe.InvolvedObject.FieldPath = "spec.containers{app}"
+	probe.Count = 5
+	m := BuildEventMapFromEvents([]v1.Event{
+		probe,
+		event("web-0", v1.EventTypeWarning, "BackOff", "Back-off restarting failed container", time.Minute),
+		event("web-0", v1.EventTypeWarning, "FailedMount", "MountVolume.SetUp failed", 3*time.Hour),
+	}, EventOptions{MaxAge: time.Hour, Now: eventNow})
+
+	failures := m.Failures["shop/web-0"]
+	if len(failures) != 1 {
+		t.Fatalf("failures = %+v, want the Unhealthy event only", failures)
+	}
+	if f := failures[0]; f.Reason != "Unhealthy" || f.Container != "app" || f.Count != 5 {
+		t.Errorf("failure = %+v, want Unhealthy of container app seen 5 times", f)
+	}
+}
+
+func TestEventTime(t *testing.T) {
+	at := func(m int) time.Time { return eventNow.Add(time.Duration(m) * time.Minute) }
+	tests := []struct {
+		name string
+		ev   v1.Event
+		want time.Time
+	}{
+		{"series", v1.Event{Series: &v1.EventSeries{LastObservedTime: metav1.NewMicroTime(at(4))}, LastTimestamp: metav1.NewTime(at(3))}, at(4)},
+		{"last timestamp", v1.Event{LastTimestamp: metav1.NewTime(at(3)), EventTime: metav1.NewMicroTime(at(2))}, at(3)},
+		{"event time", v1.Event{EventTime: metav1.NewMicroTime(at(2)), FirstTimestamp: metav1.NewTime(at(1))}, at(2)},
+		{"first timestamp", v1.Event{FirstTimestamp: metav1.NewTime(at(1))}, at(1)},
+		{"creation", v1.Event{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(at(0))}}, at(0)},
+	}
+	for _, tt := range tests {
+		if got := EventTime(&tt.ev); !got.Equal(tt.want) {
+			t.Errorf("%s: EventTime = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// pagedEvents answers core/v1 event lists two events at a time, checking the
+// field selector
+func pagedEvents(t *testing.T, events []v1.Event) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		opts := action.(k8stesting.ListActionImpl).ListOptions
+		if opts.FieldSelector != "involvedObject.kind=Pod" {
+			t.Errorf("field selector = %q, want involvedObject.kind=Pod", opts.FieldSelector)
+		}
+		if opts.Limit == 0 {
+			t.Error("events are listed without a limit")
+		}
+		start := 0
+		if opts.Continue != "" {
+			start, _ = strconv.Atoi(opts.Continue)
+		}
+		end := min(start+2, len(events))
+		list := &v1.EventList{Items: events[start:end]}
+		if end < len(events) {
+			list.Continue = strconv.Itoa(end)
+		}
+		return true, list, nil
+	}
+}
+
+func TestBuildEventMapPaging(t *testing.T) {
+	var events []v1.Event
+	for i := range 5 {
+		events = append(events, event("web-"+strconv.Itoa(i), v1.EventTypeWarning, "BackOff", "Back-off "+strconv.Itoa(i), time.Minute))
+	}
+	client := fake.NewClientset()
+	client.PrependReactor("list", "events", pagedEvents(t, events))
+
+	m := BuildEventMap(context.Background(), client, []string{"shop"}, EventOptions{Now: eventNow})
+	for i := range 5 {
+		if got, want := GetLatestPodEvent(m, "shop", "web-"+strconv.Itoa(i)), "Back-off "+strconv.Itoa(i); got != want {
+			t.Errorf("web-%d: LastEvent = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestBuildEventMapEventsV1(t *testing.T) {
+	ev := &eventsv1.Event{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web-0.probe"},
+		Regarding:  v1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "web-0"},
+		Type:       v1.EventTypeWarning,
+		Reason:     "BackOff",
+		Note:       "Back-off restarting failed container",
+		EventTime:  metav1.NewMicroTime(eventNow.Add(-20 * time.Minute)),
+		Series:     &eventsv1.EventSeries{Count: 3, LastObservedTime: metav1.NewMicroTime(eventNow.Add(-5 * time.Minute))},
+	}
+	client := fake.NewClientset(ev)
+	client.Resources = []*metav1.APIResourceList{{
+		GroupVersion: eventsv1.SchemeGroupVersion.String(),
+		APIResources: []metav1.APIResource{{Name: "events", Namespaced: true, Kind: "Event"}},
+	}}
+
+	m := BuildEventMap(context.Background(), client, []string{"shop"}, EventOptions{Now: eventNow})
+	if got, want := GetLatestPodEvent(m, "shop", "web-0"), "Back-off restarting failed container (x3 in last 15m)"; got != want {
+		t.Errorf("LastEvent = %q, want %q", got, want)
+	}
+
+	// Servers without events.k8s.io/v1 are read through core/v1
+	client = fake.NewClientset(&v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "shop", Name: "web-0.backoff"},
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "web-0"},
+		Type:           v1.EventTypeWarning,
+		Message:        "core event",
+		LastTimestamp:  metav1.NewTime(eventNow),
+	})
+	m = BuildEventMap(context.Background(), client, []string{"shop"}, EventOptions{Now: eventNow})
+	if got := GetLatestPodEvent(m, "shop", "web-0"); got != "core event" {
+		t.Errorf("LastEvent = %q, want the core/v1 event", got)
+	}
+}
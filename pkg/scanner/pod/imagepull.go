@@ -0,0 +1,204 @@
+package pod
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ductnn/k8s-scanner/pkg/registry"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// AnnotateImagePull asks the registry about the image of every
+// ImagePullBackOff/ErrImagePull issue and replaces the generic root cause
+// with the precise one: missing image or tag, credentials required, or no
+// variant for the node's platform. Images the registry cannot be asked
+// about keep the generic root cause, and issues that already have a
+// suggestion (registry auth errors, configured root causes) are left as is.
+func AnnotateImagePull(ctx context.Context, issues []types.Issue, pods []v1.Pod, nodes []v1.Node, client *registry.Client) {
+	if client == nil {
+		return
+	}
+	byName := map[string]*v1.Pod{}
+	for i := range issues {
+		if isImagePull(issues[i].Reason) {
+			byName[issues[i].Namespace+"/"+issues[i].Name] = nil
+		}
+	}
+	if len(byName) == 0 {
+		return
+	}
+	for i := range pods {
+		key := pods[i].Namespace + "/" + pods[i].Name
+		if _, ok := byName[key]; ok {
+			byName[key] = &pods[i]
+		}
+	}
+	platforms := make(map[string]registry.Platform, len(nodes))
+	for _, n := range nodes {
+		platforms[n.Name] = registry.Platform{OS: n.Status.NodeInfo.OperatingSystem, Architecture: n.Status.NodeInfo.Architecture}
+	}
+
+	for i := range issues {
+		is := &issues[i]
+		if !isImagePull(is.Reason) || ctx.Err() != nil {
+			continue
+		}
+		p := byName[is.Namespace+"/"+is.Name]
+		if p == nil || is.Suggestion != "" {
+			continue
+		}
+		image := containerImage(p, is.Container)
+		if image == "" {
+			continue
+		}
+		platform := platforms[p.Spec.NodeName]
+		res, err := client.Check(ctx, image, platform)
+		if err != nil {
+			continue
+		}
+		cause, suggestion := imagePullCause(image, res, platform, p)
+		generic := DetectPodRootCause(is.Reason)
+		if strings.Contains(is.RootCause, generic) {
+			is.RootCause = strings.Replace(is.RootCause, generic, cause, 1)
+		} else {
+			is.RootCause += " " + cause
+		}
+		is.Suggestion = suggestion
+	}
+}
+
+// pullAuthPattern matches the pull errors of a registry refusing the
+// node's credentials
+var pullAuthPattern = regexp.MustCompile(`(?i)\b(401|403)\b|unauthorized|forbidden|no basic auth credentials|authentication required`)
+
+// explainPullAuth replaces the root cause of the image pull issues whose
+// pull error (container status message or Failed event) shows the registry
+// refused the credentials, and suggests the commands creating the pull
+// secret and attaching it to the pod's ServiceAccount
+func explainPullAuth(pod *v1.Pod, issues []types.Issue, failures []EventFailure) {
+	for i := range issues {
+		is := &issues[i]
+		if !isImagePull(is.Reason) {
+			continue
+		}
+		msg := pullAuthError(pod, is.Container, failures)
+		if msg == "" {
+			continue
+		}
+		image := containerImage(pod, is.Container)
+		server := "<registry>"
+		if ref, err := registry.ParseReference(image); err == nil {
+			server = ref.Registry
+			if server == "docker.io" {
+				server = "https://index.docker.io/v1/"
+			}
+		}
+		secret := "regcred"
+		existing := "chưa có imagePullSecrets"
+		var names []string
+		for _, s := range pod.Spec.ImagePullSecrets {
+			names = append(names, s.Name)
+		}
+		if len(names) > 0 {
+			secret = names[0]
+			existing = "imagePullSecrets " + strings.Join(names, ", ") + " sai, hết hạn hoặc không có quyền"
+		}
+		generic := DetectPodRootCause(is.Reason)
+		cause := fmt.Sprintf("Registry từ chối xác thực khi pull %s (%s) — %s. Lỗi: %s", image, server, existing, msg)
+		if strings.Contains(is.RootCause, generic) {
+			is.RootCause = strings.Replace(is.RootCause, generic, cause, 1)
+		} else {
+			is.RootCause = cause
+		}
+		create := fmt.Sprintf("kubectl create secret docker-registry %s -n %s --docker-server=%s --docker-username=<user> --docker-password=<password> --dry-run=client -o yaml | kubectl apply -f -",
+			secret, pod.Namespace, server)
+		if len(names) > 0 {
+			// The pod's own secrets replace the service account's: patching
+			// the service account would change nothing
+			is.Suggestion = fmt.Sprintf("Cập nhật secret mà pod dùng (%s): %s rồi xóa pod để pull lại", strings.Join(names, ", "), create)
+		} else {
+			// A JSON patch appends: a merge patch would replace the
+			// service account's other pull secrets
+			is.Suggestion = fmt.Sprintf("%s && kubectl patch serviceaccount %s -n %s --type=json -p '[{\"op\":\"add\",\"path\":\"/imagePullSecrets/-\",\"value\":{\"name\":\"%s\"}}]' (nếu SA chưa có imagePullSecrets: path \"/imagePullSecrets\", value [{\"name\":\"%s\"}]) rồi xóa pod để pull lại",
+				create, serviceAccountName(pod), pod.Namespace, secret, secret)
+		}
+	}
+}
+
+// pullAuthError returns the pull error of a container showing a registry
+// auth failure, if any
+func pullAuthError(pod *v1.Pod, container string, failures []EventFailure) string {
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.ContainerStatuses...), pod.Status.InitContainerStatuses...)
+	for _, cs := range statuses {
+		if cs.Name == container && cs.State.Waiting != nil && pullAuthPattern.MatchString(cs.State.Waiting.Message) {
+			return cs.State.Waiting.Message
+		}
+	}
+	for _, f := range failures {
+		if f.Reason == "Failed" && f.Container == container && pullAuthPattern.MatchString(f.Message) {
+			return f.Message
+		}
+	}
+	return ""
+}
+
+func isImagePull(reason string) bool {
+	return reason == "ImagePullBackOff" || reason == "ErrImagePull"
+}
+
+// containerImage returns the image of the named container of any type
+func containerImage(p *v1.Pod, name string) string {
+	for _, c := range p.Spec.Containers {
+		if c.Name == name {
+			return c.Image
+		}
+	}
+	for _, c := range p.Spec.InitContainers {
+		if c.Name == name {
+			return c.Image
+		}
+	}
+	for _, c := range p.Spec.EphemeralContainers {
+		if c.Name == name {
+			return c.Image
+		}
+	}
+	return ""
+}
+
+// imagePullCause explains a registry lookup
+func imagePullCause(image string, res registry.Result, platform registry.Platform, p *v1.Pod) (string, string) {
+	switch res.Status {
+	case registry.NotFound:
+		return fmt.Sprintf("Image %s không tồn tại trên registry — sai tên repository hoặc tag.", image),
+			"Kiểm tra lại tên image/tag (ví dụ: docker manifest inspect " + image + ")"
+	case registry.AuthRequired:
+		secrets := "chưa có imagePullSecrets"
+		if len(p.Spec.ImagePullSecrets) > 0 {
+			names := make([]string, len(p.Spec.ImagePullSecrets))
+			for i, s := range p.Spec.ImagePullSecrets {
+				names[i] = s.Name
+			}
+			secrets = "imagePullSecrets: " + strings.Join(names, ", ")
+		}
+		return fmt.Sprintf("Registry yêu cầu xác thực để pull %s (hoặc repository không tồn tại) — %s.", image, secrets),
+			"Tạo secret docker-registry và thêm vào imagePullSecrets của pod hoặc ServiceAccount " + serviceAccountName(p)
+	case registry.PlatformMismatch:
+		return fmt.Sprintf("Image %s không có bản cho %s của node %s (chỉ có: %s).", image, platform, p.Spec.NodeName, strings.Join(res.Platforms, ", ")),
+			"Build image multi-arch (docker buildx --platform) hoặc dùng nodeSelector kubernetes.io/arch phù hợp"
+	default:
+		return fmt.Sprintf("Image %s tồn tại và pull được công khai — node không tải được, thường do network/DNS/proxy từ node tới registry.", image),
+			"Thử crictl pull " + image + " trên node " + p.Spec.NodeName + " để xem lỗi chi tiết"
+	}
+}
+
+func serviceAccountName(p *v1.Pod) string {
+	if p.Spec.ServiceAccountName != "" {
+		return p.Spec.ServiceAccountName
+	}
+	return "default"
+}
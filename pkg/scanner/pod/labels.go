@@ -0,0 +1,60 @@
+package pod
+
+import (
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// AnnotateLabels copies the given label keys into the Labels of issues, from
+// the labels or else the annotations of the pod they are about or, failing
+// that, of their namespace. Like AnnotateTeams, pods or namespaces may be
+// nil and keys already set are kept.
+func AnnotateLabels(issues []types.Issue, pods []v1.Pod, namespaces []v1.Namespace, keys []string) {
+	if len(keys) == 0 || len(issues) == 0 {
+		return
+	}
+	podMeta := make(map[string]*v1.Pod, len(pods))
+	for i := range pods {
+		podMeta[pods[i].Namespace+"/"+pods[i].Name] = &pods[i]
+	}
+	nsMeta := make(map[string]*v1.Namespace, len(namespaces))
+	for i := range namespaces {
+		nsMeta[namespaces[i].Name] = &namespaces[i]
+	}
+
+	for i := range issues {
+		is := &issues[i]
+		var p *v1.Pod
+		if is.Kind == "Pod" {
+			p = podMeta[is.Namespace+"/"+is.Name]
+		}
+		ns := nsMeta[is.Namespace]
+		for _, key := range keys {
+			if _, ok := is.Labels[key]; ok {
+				continue
+			}
+			value := ""
+			if p != nil {
+				value = labelOrAnnotation(p.Labels, p.Annotations, key)
+			}
+			if value == "" && ns != nil {
+				value = labelOrAnnotation(ns.Labels, ns.Annotations, key)
+			}
+			if value == "" {
+				continue
+			}
+			if is.Labels == nil {
+				is.Labels = map[string]string{}
+			}
+			is.Labels[key] = value
+		}
+	}
+}
+
+func labelOrAnnotation(labels, annotations map[string]string, key string) string {
+	if v := labels[key]; v != "" {
+		return v
+	}
+	return annotations[key]
+}
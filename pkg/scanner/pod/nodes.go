@@ -0,0 +1,82 @@
+package pod
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// NodeConditions stores a description of the unhealthy conditions of each node
+// Key format: node name
+type NodeConditions map[string]string
+
+// BuildNodeConditions lists nodes and collects their unhealthy conditions.
+// Nodes are cluster-scoped, so a namespaced service account may not be allowed
+// to list them; in that case the map is simply empty.
+func BuildNodeConditions(ctx context.Context, client kubernetes.Interface) NodeConditions {
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return NodeConditions{}
+	}
+	return NodeConditionsFromNodes(nodes.Items)
+}
+
+// NodeConditionsFromNodes builds the lookup map from already-fetched nodes
+func NodeConditionsFromNodes(nodes []v1.Node) NodeConditions {
+	nc := make(NodeConditions)
+	for _, node := range nodes {
+		var problems []string
+		for _, cond := range node.Status.Conditions {
+			problem := nodeProblem(cond)
+			if problem == "" {
+				continue
+			}
+			if !cond.LastTransitionTime.IsZero() {
+				problem += " since " + cond.LastTransitionTime.Format(time.RFC3339)
+			}
+			problems = append(problems, problem)
+		}
+		if len(problems) > 0 {
+			nc[node.Name] = fmt.Sprintf("node %s has %s", node.Name, strings.Join(problems, ", "))
+		}
+	}
+	return nc
+}
+
+// nodeProblem returns the name of an unhealthy node condition, or "" if it is healthy
+func nodeProblem(cond v1.NodeCondition) string {
+	switch cond.Type {
+	case v1.NodeReady:
+		switch cond.Status {
+		case v1.ConditionFalse:
+			return "NotReady"
+		case v1.ConditionUnknown:
+			return "Unknown status (kubelet stopped reporting)"
+		}
+	case v1.NodeMemoryPressure, v1.NodeDiskPressure, v1.NodePIDPressure, v1.NodeNetworkUnavailable:
+		if cond.Status == v1.ConditionTrue {
+			return string(cond.Type)
+		}
+	}
+	return ""
+}
+
+// AnnotateNodeConditions attaches the condition of the node each issue runs on,
+// so infrastructure problems can be told apart from application problems
+func AnnotateNodeConditions(issues []types.Issue, nc NodeConditions) {
+	if len(nc) == 0 {
+		return
+	}
+	for i := range issues {
+		if cond, ok := nc[issues[i].NodeName]; ok {
+			issues[i].NodeCondition = cond
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package pod
+
+import (
+	"slices"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Override changes how the pods it matches are evaluated, e.g. to tolerate
+// more restarts in batch namespaces
+type Override struct {
+	// Namespaces the override applies to (empty: every namespace)
+	Namespaces []string
+	// Selector limits the override to pods with matching labels (nil: every pod)
+	Selector labels.Selector
+	// RestartThreshold replaces ScanOptions.RestartThreshold when > 0
+	RestartThreshold int32
+	// PendingGrace replaces ScanOptions.PendingGrace when > 0
+	PendingGrace time.Duration
+	// Severity maps reasons to the severity reported instead of the default
+	Severity map[string]severity.Level
+}
+
+// matches reports whether the override applies to pod
+func (o Override) matches(pod *v1.Pod) bool {
+	if len(o.Namespaces) > 0 && !slices.Contains(o.Namespaces, pod.Namespace) {
+		return false
+	}
+	return o.Selector == nil || o.Selector.Matches(labels.Set(pod.Labels))
+}
+
+// forPod returns opts with every override matching pod applied in order
+// (later ones win), and the resulting reason to severity mapping
+func (opts ScanOptions) forPod(pod *v1.Pod) (ScanOptions, map[string]severity.Level) {
+	var severities map[string]severity.Level
+	for _, o := range opts.Overrides {
+		if !o.matches(pod) {
+			continue
+		}
+		if o.RestartThreshold > 0 {
+			opts.RestartThreshold = o.RestartThreshold
+		}
+		if o.PendingGrace > 0 {
+			opts.PendingGrace = o.PendingGrace
+		}
+		for reason, sev := range o.Severity {
+			if severities == nil {
+				severities = make(map[string]severity.Level)
+			}
+			severities[reason] = sev
+		}
+	}
+	return opts, severities
+}
+
+// applySeverities replaces the default severity of issues whose reason is
+// mapped, keeping any escalation applied on top of the default
+func applySeverities(issues []types.Issue, severities map[string]severity.Level) {
+	for i := range issues {
+		sev, ok := severities[issues[i].Reason]
+		if !ok {
+			continue
+		}
+		if issues[i].Severity != severity.FromReason(issues[i].Reason) {
+			sev = severity.Escalate(sev)
+		}
+		issues[i].Severity = sev
+	}
+}
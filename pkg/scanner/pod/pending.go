@@ -0,0 +1,190 @@
+package pod
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// ExplainPending replaces the generic root cause of unscheduled Pending pods
+// by checking every node against the pod's nodeSelector, required node
+// affinity and tolerations: when they exclude all nodes, the root cause
+// names the constraint excluding each group of nodes; otherwise the pod
+// fits some nodes and is waiting for resources.
+func ExplainPending(issues []types.Issue, pods []v1.Pod, nodes []v1.Node) {
+	if len(nodes) == 0 {
+		return
+	}
+	byName := map[string]*v1.Pod{}
+	for i := range issues {
+		if issues[i].Reason == "Pending" {
+			byName[issues[i].Namespace+"/"+issues[i].Name] = nil
+		}
+	}
+	if len(byName) == 0 {
+		return
+	}
+	for i := range pods {
+		key := pods[i].Namespace + "/" + pods[i].Name
+		if _, ok := byName[key]; ok {
+			byName[key] = &pods[i]
+		}
+	}
+
+	for i := range issues {
+		is := &issues[i]
+		if is.Reason != "Pending" {
+			continue
+		}
+		p := byName[is.Namespace+"/"+is.Name]
+		if p == nil || p.Spec.NodeName != "" {
+			continue
+		}
+		is.RootCause = explainPending(p, nodes)
+	}
+}
+
+// explainPending groups the nodes by the first constraint excluding them
+func explainPending(p *v1.Pod, nodes []v1.Node) string {
+	excluded := map[string]int{}
+	fit := 0
+	for i := range nodes {
+		if why := nodeExclusion(p, &nodes[i]); why != "" {
+			excluded[why]++
+		} else {
+			fit++
+		}
+	}
+
+	schedulerMsg := unschedulableMessage(p)
+	if fit > 0 {
+		cause := fmt.Sprintf("%d/%d node thỏa nodeSelector, affinity và taints — pod Pending do thiếu tài nguyên (CPU/RAM/pods) hoặc ràng buộc khác (PVC, topology, anti-affinity).", fit, len(nodes))
+		if schedulerMsg != "" {
+			cause += " Scheduler: " + schedulerMsg
+		}
+		return cause
+	}
+
+	reasons := make([]string, 0, len(excluded))
+	for why, n := range excluded {
+		reasons = append(reasons, fmt.Sprintf("%d node %s", n, why))
+	}
+	sort.Strings(reasons)
+	return fmt.Sprintf("Không node nào phù hợp với ràng buộc của pod: %s.", strings.Join(reasons, "; "))
+}
+
+// unschedulableMessage returns the scheduler's message, if any
+func unschedulableMessage(p *v1.Pod) string {
+	for _, c := range p.Status.Conditions {
+		if c.Type == v1.PodScheduled && c.Status == v1.ConditionFalse {
+			return c.Message
+		}
+	}
+	return ""
+}
+
+// nodeExclusion returns why the pod cannot run on node, or "" when the
+// node satisfies its placement constraints
+func nodeExclusion(p *v1.Pod, node *v1.Node) string {
+	if node.Spec.Unschedulable && !toleratesAll(p.Spec.Tolerations, []v1.Taint{{Key: v1.TaintNodeUnschedulable, Effect: v1.TaintEffectNoSchedule}}) {
+		return "bị cordon (unschedulable)"
+	}
+	for k, v := range p.Spec.NodeSelector {
+		if node.Labels[k] != v {
+			return fmt.Sprintf("không khớp nodeSelector %s=%s", k, v)
+		}
+	}
+	if a := p.Spec.Affinity; a != nil && a.NodeAffinity != nil && a.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		if !matchesNodeSelector(a.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution, node) {
+			return "không khớp nodeAffinity (required)"
+		}
+	}
+	for _, t := range node.Spec.Taints {
+		if t.Effect == v1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !toleratesAll(p.Spec.Tolerations, []v1.Taint{t}) {
+			return fmt.Sprintf("có taint %s không được tolerate", formatTaint(t))
+		}
+	}
+	return ""
+}
+
+func toleratesAll(tolerations []v1.Toleration, taints []v1.Taint) bool {
+	for i := range taints {
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(&taints[i]) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+func formatTaint(t v1.Taint) string {
+	if t.Value == "" {
+		return fmt.Sprintf("%s:%s", t.Key, t.Effect)
+	}
+	return fmt.Sprintf("%s=%s:%s", t.Key, t.Value, t.Effect)
+}
+
+// matchesNodeSelector evaluates required node affinity: terms are ORed,
+// the requirements of a term ANDed
+func matchesNodeSelector(ns *v1.NodeSelector, node *v1.Node) bool {
+	for _, term := range ns.NodeSelectorTerms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+		if matchesTerm(term, node) {
+			return true
+		}
+	}
+	return false
+}
+
+var nodeSelectorOperators = map[v1.NodeSelectorOperator]selection.Operator{
+	v1.NodeSelectorOpIn:           selection.In,
+	v1.NodeSelectorOpNotIn:        selection.NotIn,
+	v1.NodeSelectorOpExists:       selection.Exists,
+	v1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	v1.NodeSelectorOpGt:           selection.GreaterThan,
+	v1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+func matchesTerm(term v1.NodeSelectorTerm, node *v1.Node) bool {
+	for _, expr := range term.MatchExpressions {
+		if !matchesRequirement(expr, labels.Set(node.Labels)) {
+			return false
+		}
+	}
+	// metadata.name is the only field supported by the scheduler
+	for _, expr := range term.MatchFields {
+		if expr.Key != "metadata.name" || !matchesRequirement(expr, labels.Set{expr.Key: node.Name}) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesRequirement(expr v1.NodeSelectorRequirement, set labels.Set) bool {
+	op, ok := nodeSelectorOperators[expr.Operator]
+	if !ok {
+		return false
+	}
+	req, err := labels.NewRequirement(expr.Key, op, expr.Values)
+	if err != nil {
+		return false
+	}
+	return req.Matches(set)
+}
@@ -0,0 +1,169 @@
+package pod
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// PriorityOptions configures ScanPriority
+type PriorityOptions struct {
+	// MaxAge ignores Preempted events older than this (0 keeps all)
+	MaxAge time.Duration
+	// PendingGrace is how long a pod may stay unschedulable before it is reported
+	PendingGrace time.Duration
+	// NoEvents skips the Preempted events, reporting only pending pods
+	NoEvents bool
+	// Now is the reference time; zero means time.Now()
+	Now time.Time
+}
+
+// ScanPriority reports pods recently preempted by the scheduler and
+// unschedulable pods that lack resources while pods of a higher priority
+// hold them, so capacity shortages can be told apart from scheduling
+// misconfiguration (which the Pending issue reports)
+func ScanPriority(ctx context.Context, cs *k8s.ClusterSnapshot, ignoredNamespaces map[string]bool, opts PriorityOptions) ([]types.Issue, error) {
+	pods, _, err := cs.Pods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pods = FilterIgnoredNamespaces(pods, ignoredNamespaces)
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	timestamp := now.Format(time.RFC3339)
+
+	byName := make(map[string]*v1.Pod, len(pods))
+	for i := range pods {
+		byName[pods[i].Namespace+"/"+pods[i].Name] = &pods[i]
+	}
+
+	namespaces := cs.ScopedNamespaces()
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+	if opts.NoEvents {
+		namespaces = nil
+	}
+	// The latest Preempted event of each pod
+	preempted := map[string]*v1.Event{}
+	for _, ns := range namespaces {
+		events, err := cs.PodEvents(ctx, ns)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list events: %w", err)
+		}
+		for i := range events {
+			ev := &events[i]
+			if ev.Reason != "Preempted" || ev.InvolvedObject.Kind != "Pod" || ignoredNamespaces[ev.InvolvedObject.Namespace] {
+				continue
+			}
+			if opts.MaxAge > 0 && now.Sub(EventTime(ev)) > opts.MaxAge {
+				continue
+			}
+			key := ev.InvolvedObject.Namespace + "/" + ev.InvolvedObject.Name
+			if cur, ok := preempted[key]; !ok || EventTime(ev).After(EventTime(cur)) {
+				preempted[key] = ev
+			}
+		}
+	}
+
+	var issues []types.Issue
+	for key, ev := range preempted {
+		issue := types.Issue{
+			Kind:         "Pod",
+			Namespace:    ev.InvolvedObject.Namespace,
+			Name:         ev.InvolvedObject.Name,
+			Reason:       "Preempted",
+			RootCause:    "Pod bị scheduler preempt để nhường chỗ cho pod có priority cao hơn.",
+			Suggestion:   "Tăng capacity của cluster hoặc gán PriorityClass cao hơn nếu workload này quan trọng",
+			LastEvent:    ev.Message,
+			Timestamp:    timestamp,
+			InStateSince: EventTime(ev).Format(time.RFC3339),
+			PodStatus:    "Deleted",
+		}
+		if p := byName[key]; p != nil {
+			issue.PodStatus = podStatusOf(p)
+			issue.NodeName = p.Spec.NodeName
+			issue.PriorityClass = p.Spec.PriorityClassName
+			issue.RootCause = fmt.Sprintf("Pod (%s) bị scheduler preempt để nhường chỗ cho pod có priority cao hơn.", describePriority(p))
+		}
+		issue.Severity = severity.FromReason(issue.Reason)
+		issues = append(issues, issue)
+	}
+
+	for i := range pods {
+		p := &pods[i]
+		if issue, ok := checkBlockedByPriority(p, pods, now, opts.PendingGrace); ok {
+			issue.Timestamp = timestamp
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// checkBlockedByPriority reports an unschedulable pod short of resources
+// while pods of a higher priority run
+func checkBlockedByPriority(p *v1.Pod, pods []v1.Pod, now time.Time, grace time.Duration) (types.Issue, bool) {
+	if p.Status.Phase != v1.PodPending || p.Spec.NodeName != "" || now.Sub(p.CreationTimestamp.Time) <= grace {
+		return types.Issue{}, false
+	}
+	// A nominated node means the pod is already preempting others
+	if p.Status.NominatedNodeName != "" {
+		return types.Issue{}, false
+	}
+	var message string
+	for _, c := range p.Status.Conditions {
+		if c.Type == v1.PodScheduled && c.Status == v1.ConditionFalse && c.Reason == v1.PodReasonUnschedulable {
+			message = c.Message
+		}
+	}
+	if !strings.Contains(message, "Insufficient") && !strings.Contains(message, "Too many pods") {
+		return types.Issue{}, false
+	}
+
+	priority := priorityOf(p)
+	higher := 0
+	for i := range pods {
+		o := &pods[i]
+		if o.Spec.NodeName != "" && o.Status.Phase != v1.PodSucceeded && o.Status.Phase != v1.PodFailed && priorityOf(o) > priority {
+			higher++
+		}
+	}
+	if higher == 0 {
+		return types.Issue{}, false
+	}
+
+	rootCause := fmt.Sprintf("Pod (%s) thiếu tài nguyên để schedule trong khi %d pod có priority cao hơn đang chạy — đây là vấn đề capacity, pod không thể preempt các pod đó.", describePriority(p), higher)
+	if p.Spec.PreemptionPolicy != nil && *p.Spec.PreemptionPolicy == v1.PreemptNever {
+		rootCause += " PriorityClass có preemptionPolicy: Never nên pod không preempt pod nào."
+	}
+	issue := createIssue(p, "", "PendingBehindHigherPriority", podStatusOf(p), "", message, 0)
+	issue.RootCause = rootCause
+	issue.Suggestion = "Thêm node hoặc bật cluster autoscaler; nếu workload quan trọng hơn, gán PriorityClass cao hơn"
+	issue.InStateSince = p.CreationTimestamp.Format(time.RFC3339)
+	return issue, true
+}
+
+func priorityOf(p *v1.Pod) int32 {
+	if p.Spec.Priority != nil {
+		return *p.Spec.Priority
+	}
+	return 0
+}
+
+// describePriority is "PriorityClass <name>, priority <n>"
+func describePriority(p *v1.Pod) string {
+	class := p.Spec.PriorityClassName
+	if class == "" {
+		class = "không có"
+	}
+	return fmt.Sprintf("PriorityClass %s, priority %d", class, priorityOf(p))
+}
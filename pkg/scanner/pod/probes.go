@@ -0,0 +1,204 @@
+package pod
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// probeReasons maps the probe named by an Unhealthy event to the issue reason
+var probeReasons = map[string]string{
+	"Liveness":  "LivenessProbeFailed",
+	"Readiness": "ReadinessProbeFailed",
+	"Startup":   "StartupProbeFailed",
+}
+
+// probeFailure aggregates the Unhealthy events of one probe of a container
+type probeFailure struct {
+	probe   string
+	message string
+	count   int32
+	latest  EventFailure
+}
+
+// checkProbes reports the probes failing according to the pod's Unhealthy
+// events, with the probe endpoint and the kubelet message, so a crash loop
+// or an unready pod is explained by the probe that caused it. A probe is
+// only reported while the container still shows its effect: restarts or
+// unready for liveness, unready for readiness and not started for startup.
+func checkProbes(pod *v1.Pod, failures []EventFailure, podStatus string, timestamp string, lastEvent string) []types.Issue {
+	byProbe := map[string]*probeFailure{}
+	var order []string
+	for _, f := range failures {
+		if f.Reason != "Unhealthy" || f.Container == "" {
+			continue
+		}
+		probe, message, ok := parseProbeMessage(f.Message)
+		if !ok {
+			continue
+		}
+		key := f.Container + "/" + probe
+		pf, seen := byProbe[key]
+		if !seen {
+			pf = &probeFailure{probe: probe}
+			byProbe[key] = pf
+			order = append(order, key)
+		}
+		pf.count += max(f.Count, 1)
+		if !seen || f.At.After(pf.latest.At) {
+			pf.latest = f
+			pf.message = message
+		}
+	}
+	if len(byProbe) == 0 {
+		return nil
+	}
+
+	statuses := make(map[string]v1.ContainerStatus, len(pod.Status.ContainerStatuses))
+	for _, cs := range pod.Status.ContainerStatuses {
+		statuses[cs.Name] = cs
+	}
+	for _, cs := range pod.Status.InitContainerStatuses {
+		statuses[cs.Name] = cs
+	}
+
+	var issues []types.Issue
+	for _, key := range order {
+		pf := byProbe[key]
+		cs, ok := statuses[pf.latest.Container]
+		if !ok || !probeStillFailing(pf.probe, cs) {
+			continue
+		}
+		reason := probeReasons[pf.probe]
+		issue := createIssue(pod, cs.Name, reason, podStatus, timestamp, lastEvent, cs.RestartCount)
+		cause := DetectPodRootCause(reason)
+		if endpoint := probeEndpoint(containerProbe(pod, cs.Name, pf.probe)); endpoint != "" {
+			cause += " Probe: " + endpoint + "."
+		}
+		issue.RootCause = fmt.Sprintf("%s Thất bại %d lần, lần cuối: %s", cause, pf.count, pf.message)
+		issue.Suggestion = probeSuggestion(pf.probe)
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// parseProbeMessage splits an Unhealthy event message such as
+// "Readiness probe failed: HTTP probe failed with statuscode: 503" into the
+// probe type and the failure message
+func parseProbeMessage(msg string) (string, string, bool) {
+	probe, rest, ok := strings.Cut(msg, " probe ")
+	if !ok || probeReasons[probe] == "" {
+		return "", "", false
+	}
+	_, message, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", "", false
+	}
+	return probe, strings.TrimSpace(message), true
+}
+
+// probeStillFailing tells if the container status still shows the effect of
+// a failing probe, so a probe that recovered is not reported
+func probeStillFailing(probe string, cs v1.ContainerStatus) bool {
+	switch probe {
+	case "Liveness":
+		return cs.RestartCount > 0 || !cs.Ready
+	case "Readiness":
+		return cs.State.Running != nil && !cs.Ready
+	case "Startup":
+		return cs.Started == nil || !*cs.Started
+	}
+	return false
+}
+
+// containerProbe returns the probe of a container from the pod spec
+func containerProbe(pod *v1.Pod, container, probe string) *v1.Probe {
+	containers := append(append([]v1.Container{}, pod.Spec.Containers...), pod.Spec.InitContainers...)
+	for _, c := range containers {
+		if c.Name != container {
+			continue
+		}
+		switch probe {
+		case "Liveness":
+			return c.LivenessProbe
+		case "Readiness":
+			return c.ReadinessProbe
+		case "Startup":
+			return c.StartupProbe
+		}
+	}
+	return nil
+}
+
+// probeEndpoint describes what a probe checks, e.g. "HTTP GET :8080/healthz"
+func probeEndpoint(p *v1.Probe) string {
+	if p == nil {
+		return ""
+	}
+	var endpoint string
+	switch {
+	case p.HTTPGet != nil:
+		scheme := string(p.HTTPGet.Scheme)
+		if scheme == "" {
+			scheme = "HTTP"
+		}
+		endpoint = fmt.Sprintf("%s GET %s:%s%s", scheme, p.HTTPGet.Host, p.HTTPGet.Port.String(), p.HTTPGet.Path)
+	case p.TCPSocket != nil:
+		endpoint = fmt.Sprintf("TCP %s:%s", p.TCPSocket.Host, p.TCPSocket.Port.String())
+	case p.GRPC != nil:
+		endpoint = fmt.Sprintf("gRPC :%d", p.GRPC.Port)
+		if p.GRPC.Service != nil && *p.GRPC.Service != "" {
+			endpoint += " service " + *p.GRPC.Service
+		}
+	case p.Exec != nil:
+		endpoint = "exec " + strings.Join(p.Exec.Command, " ")
+	default:
+		return ""
+	}
+	return fmt.Sprintf("%s (timeoutSeconds=%d, periodSeconds=%d, failureThreshold=%d)", endpoint, p.TimeoutSeconds, p.PeriodSeconds, p.FailureThreshold)
+}
+
+// probeSuggestion returns the fix to try for a failing probe
+func probeSuggestion(probe string) string {
+	switch probe {
+	case "Liveness":
+		return "Kiểm tra endpoint health của app (kubectl exec/port-forward rồi gọi thử); nếu app khởi động chậm hoặc phản hồi chậm thì thêm startupProbe hoặc tăng initialDelaySeconds/timeoutSeconds/failureThreshold."
+	case "Readiness":
+		return "Kiểm tra endpoint readiness và các dependency của app (DB, cache, service khác); tăng timeoutSeconds nếu endpoint phản hồi chậm."
+	default:
+		return "App khởi động lâu hơn failureThreshold×periodSeconds của startupProbe — tăng failureThreshold hoặc kiểm tra logs lúc khởi động."
+	}
+}
+
+// inheritProbeSeverity raises a probe issue to the highest severity reported
+// for its container (e.g. an escalated CrashLoopBackOff), so deduplication
+// keeps the issue explaining the failure
+func inheritProbeSeverity(issues []types.Issue) {
+	highest := map[string]severity.Level{}
+	for _, is := range issues {
+		if severity.Rank(is.Severity) > severity.Rank(highest[is.Container]) {
+			highest[is.Container] = is.Severity
+		}
+	}
+	for i := range issues {
+		if !isProbeReason(issues[i].Reason) {
+			continue
+		}
+		if level := highest[issues[i].Container]; severity.Rank(level) > severity.Rank(issues[i].Severity) {
+			issues[i].Severity = level
+		}
+	}
+}
+
+func isProbeReason(reason string) bool {
+	for _, r := range probeReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package pod
+
+// Stages reported to a ProgressFunc during ScanPods
+const (
+	StageListPods = "listing pods"
+	StageEvents   = "fetching events"
+	StageAnalyze  = "analyzing pods"
+	StageNodes    = "checking nodes"
+)
+
+// ProgressFunc is called as a scan advances: done out of total units of the
+// given stage are complete. Calls are never concurrent.
+type ProgressFunc func(stage string, done, total int)
+
+// Report calls f when it is set
+func (f ProgressFunc) Report(stage string, done, total int) {
+	if f != nil {
+		f(stage, done, total)
+	}
+}
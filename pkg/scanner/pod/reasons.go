@@ -0,0 +1,43 @@
+package pod
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ductnn/k8s-scanner/pkg/checks"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// ReasonFilter selects which issue reasons are reported. Reasons match
+// case-insensitively; the zero value reports everything.
+type ReasonFilter struct {
+	// Only, when not empty, keeps just these reasons
+	Only []string
+	// Ignore drops these reasons, even when listed in Only
+	Ignore []string
+}
+
+// Allows reports whether issues with reason should be reported. The filter
+// lists reasons or check IDs (see the checks package).
+func (f ReasonFilter) Allows(reason string) bool {
+	match := func(r string) bool {
+		if c, ok := checks.Lookup(r); ok {
+			r = c.Reason
+		}
+		return strings.EqualFold(r, reason)
+	}
+	if len(f.Only) > 0 && !slices.ContainsFunc(f.Only, match) {
+		return false
+	}
+	return !slices.ContainsFunc(f.Ignore, match)
+}
+
+// Filter removes the issues f does not allow, reusing the backing array
+func (f ReasonFilter) Filter(issues []types.Issue) []types.Issue {
+	if len(f.Only) == 0 && len(f.Ignore) == 0 {
+		return issues
+	}
+	return slices.DeleteFunc(issues, func(issue types.Issue) bool {
+		return !f.Allows(issue.Reason)
+	})
+}
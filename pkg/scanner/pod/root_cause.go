@@ -1,5 +1,41 @@
 package pod
 
+import (
+	"strings"
+
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// RootCause is the explanation configured for a reason, replacing the
+// built-in one (see DetectPodRootCause)
+type RootCause struct {
+	RootCause  string `json:"rootCause"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// applyRootCauses swaps the built-in root cause of issues with a configured
+// one, keeping the details added around it (exit code, init container, ...).
+// A configured suggestion replaces the issue's.
+func applyRootCauses(issues []types.Issue, causes map[string]RootCause) {
+	for i := range issues {
+		c, ok := causes[issues[i].Reason]
+		if !ok {
+			continue
+		}
+		if c.RootCause != "" {
+			builtin := DetectPodRootCause(issues[i].Reason)
+			if strings.Contains(issues[i].RootCause, builtin) {
+				issues[i].RootCause = strings.Replace(issues[i].RootCause, builtin, c.RootCause, 1)
+			} else {
+				issues[i].RootCause = c.RootCause
+			}
+		}
+		if c.Suggestion != "" {
+			issues[i].Suggestion = c.Suggestion
+		}
+	}
+}
+
 // DetectPodRootCause returns a human-readable root cause for pod issues
 func DetectPodRootCause(reason string) string {
 	switch reason {
@@ -11,8 +47,42 @@ func DetectPodRootCause(reason string) string {
 		return "Pod bị evict do node thiếu tài nguyên (disk pressure, memory pressure) — cần kiểm tra node resources."
 	case "OOMKilled":
 		return "Container bị kill do thiếu bộ nhớ (Out-of-Memory)."
+	case "NodeShutdown":
+		return "Pod bị dừng vì node shutdown (graceful node shutdown) — thường do node spot/preemptible bị thu hồi hoặc node bị tắt."
+	case "TerminatingStuck":
+		return "Pod bị kẹt ở trạng thái Terminating."
+	case "ContainerNotReady":
+		return "Container đang chạy nhưng readinessProbe thất bại — pod bị loại khỏi endpoints của Service."
+	case "LivenessProbeFailed":
+		return "livenessProbe thất bại nên kubelet restart container — app treo, phản hồi chậm hoặc probe cấu hình sai."
+	case "ReadinessProbeFailed":
+		return "readinessProbe thất bại — pod bị loại khỏi endpoints của Service."
+	case "StartupProbeFailed":
+		return "startupProbe thất bại — app chưa khởi động xong trong thời gian cho phép nên bị restart."
+	case "FailedMount":
+		return "Không mount được volume của pod — pod kẹt ở ContainerCreating."
+	case "FailedAttachVolume":
+		return "Không attach được volume vào node — pod kẹt ở ContainerCreating."
+	case "ReadinessGatesNotReady":
+		return "Readiness gate chưa đạt — pod bị loại khỏi endpoints của Service."
+	case "Completed":
+		return "Container đã chạy xong và thoát bình thường (exit code 0)."
+	case "Error":
+		return "Container thoát do lỗi — cần kiểm tra logs container."
 	case "Pending":
 		return "Không đủ tài nguyên (CPU/RAM) hoặc không match node selector/taints."
+	case "HighRestartCount":
+		return "Container bị restart quá nhiều lần (unstable)."
+	case "CreateContainerConfigError":
+		return "Không tạo được cấu hình container — ConfigMap/Secret (hoặc key) được tham chiếu không tồn tại."
+	case "CreateContainerError":
+		return "Container runtime không tạo được container — thường do trùng tên container, mount sai hoặc lỗi runtime."
+	case "RunContainerError", "StartError":
+		return "Container runtime không start được container — thường do command/entrypoint không tồn tại, sai quyền thực thi hoặc mount lỗi."
+	case "InvalidImageName":
+		return "Tên image không hợp lệ — kiểm tra lại registry/repository:tag trong spec."
+	case "ImageInspectError":
+		return "Không đọc được metadata của image trên node — image có thể bị hỏng hoặc sai định dạng."
 	default:
 		return "Chưa xác định — cần kiểm tra logs container."
 	}
@@ -2,153 +2,477 @@ package pod
 
 import (
 	"context"
+	"fmt"
+	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+	"github.com/ductnn/k8s-scanner/pkg/registry"
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/tracing"
 	"github.com/ductnn/k8s-scanner/pkg/types"
 
 	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 )
 
+// DedupMode controls how issues are aggregated
+type DedupMode string
+
+const (
+	// DedupPod keeps only the highest priority issue per pod
+	DedupPod DedupMode = "pod"
+	// DedupContainer keeps only the highest priority issue per container
+	DedupContainer DedupMode = "container"
+	// DedupOff keeps every finding
+	DedupOff DedupMode = "off"
+)
+
+// ParseDedupMode validates a dedup mode name
+func ParseDedupMode(s string) (DedupMode, error) {
+	switch m := DedupMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case DedupPod, DedupContainer, DedupOff:
+		return m, nil
+	case "":
+		return DedupContainer, nil
+	}
+	return "", fmt.Errorf("invalid dedup mode %q (expected pod, container or off)", s)
+}
+
+// ScanOptions tunes how pods are evaluated
+type ScanOptions struct {
+	// RestartThreshold is the restart count above which HighRestartCount is reported
+	RestartThreshold int32
+	// Dedup selects the aggregation granularity (default: container)
+	Dedup DedupMode
+	// EscalateAfter raises the severity of containers stuck in a waiting
+	// state for longer than this duration by one level (0 disables)
+	EscalateAfter time.Duration
+	// PendingGrace is how long a pod may stay Pending before it is reported
+	PendingGrace time.Duration
+	// StartupGrace is how old a pod must be before its containers waiting in
+	// ContainerCreating or PodInitializing are reported, so rollouts do not
+	// flood the report
+	StartupGrace time.Duration
+	// TerminatingMargin is how long past its deletion deadline a pod may
+	// remain before it is reported as stuck in Terminating
+	TerminatingMargin time.Duration
+	// UnreadyAfter is how long a Running pod may stay not Ready before it is reported (0 disables)
+	UnreadyAfter time.Duration
+	// NoEvents skips fetching events (the LastEvent column stays empty)
+	NoEvents bool
+	// EventMaxAge ignores events older than this when picking LastEvent (0 keeps all)
+	EventMaxAge time.Duration
+	// Selector, when set, limits the scan to pods whose labels match it
+	Selector labels.Selector
+	// Overrides adjust thresholds and severities for matching pods
+	Overrides []Override
+	// RootCauses replace the built-in root cause (and suggestion) of reasons
+	RootCauses map[string]RootCause
+	// Reasons limits which reasons are reported. It applies before
+	// deduplication, so an ignored reason never hides another one.
+	Reasons ReasonFilter
+	// NoNodeConditions skips listing nodes to annotate issues with node conditions
+	NoNodeConditions bool
+	// NoServices skips listing Services to fill ImpactedServices
+	NoServices bool
+	// TeamLabel, when set, fills the Team of issues from this pod label
+	TeamLabel string
+	// LabelKeys are pod labels or annotations copied into issues' Labels
+	LabelKeys []string
+	// Registry, when set, is asked about the images of ImagePullBackOff and
+	// ErrImagePull issues to pinpoint their root cause
+	Registry *registry.Client
+	// Progress, when set, is notified as ScanPods advances through its stages
+	Progress ProgressFunc
+	// PageSize, when set, makes ScanCluster list and analyze pods one page
+	// of this size at a time instead of caching them all in the snapshot
+	PageSize int
+	// OnIssues, with PageSize set, receives the issues of each page as soon as
+	// it is analyzed; ScanCluster then returns no issues itself
+	OnIssues func([]types.Issue)
+	// Now is the reference time for durations; zero means time.Now().
+	// Snapshot scans set it to the snapshot creation time.
+	Now time.Time
+}
+
 // ScanPods scans pods in the specified namespaces and returns issues
 // If namespaces is empty or nil, scans all namespaces
-func ScanPods(client *kubernetes.Clientset, namespaces []string, restartThreshold int32, ignoredNamespaces map[string]bool) ([]types.Issue, error) {
-	opts := metav1.ListOptions{}
+func ScanPods(ctx context.Context, client kubernetes.Interface, namespaces []string, ignoredNamespaces map[string]bool, opts ScanOptions) ([]types.Issue, error) {
+	issues, _, err := ScanPodsWithErrors(ctx, client, namespaces, ignoredNamespaces, opts)
+	return issues, err
+}
 
-	var allPods []v1.Pod
+// ScanPodsWithErrors is ScanPods that also returns the namespaces whose pods
+// could not be listed, which ScanPods skips silently
+func ScanPodsWithErrors(ctx context.Context, client kubernetes.Interface, namespaces []string, ignoredNamespaces map[string]bool, opts ScanOptions) ([]types.Issue, []error, error) {
+	return ScanCluster(ctx, k8s.NewClusterSnapshot(client, namespaces), ignoredNamespaces, opts)
+}
 
-	// If no namespaces specified, scan all namespaces
-	if len(namespaces) == 0 {
-		pods, err := client.CoreV1().Pods("").List(context.Background(), opts)
-		if err != nil {
-			return nil, err
-		}
-		allPods = pods.Items
-	} else {
-		// Scan each specified namespace
-		for _, ns := range namespaces {
-			ns = strings.TrimSpace(ns)
-			if ns == "" {
-				continue
-			}
-			pods, err := client.CoreV1().Pods(ns).List(context.Background(), opts)
-			if err != nil {
-				// Log error but continue with other namespaces
-				continue
-			}
-			allPods = append(allPods, pods.Items...)
-		}
+// ScanCluster is ScanPodsWithErrors reading pods, events and nodes through a
+// shared ClusterSnapshot, so other scanners can reuse what it lists
+func ScanCluster(ctx context.Context, cs *k8s.ClusterSnapshot, ignoredNamespaces map[string]bool, opts ScanOptions) ([]types.Issue, []error, error) {
+	if opts.PageSize > 0 {
+		return scanPodsPaged(ctx, cs, ignoredNamespaces, opts)
+	}
+
+	total := max(len(cs.ScopedNamespaces()), 1)
+	opts.Progress.Report(StageListPods, 0, total)
+	allPods, listErrs, err := cs.Pods(ctx)
+	if err != nil {
+		return nil, nil, err
 	}
+	opts.Progress.Report(StageListPods, total, total)
+
+	// Filter out pods from ignored namespaces
+	allPods = FilterIgnoredNamespaces(allPods, ignoredNamespaces)
+	allPods = FilterSelector(allPods, opts.Selector)
 
 	if len(allPods) == 0 {
-		return []types.Issue{}, nil
+		return []types.Issue{}, listErrs, nil
 	}
 
-	// Create a PodList-like structure for compatibility with existing code
-	pods := &v1.PodList{Items: allPods}
+	// Build event map once for all pods (major performance improvement)
+	eventMap := EventMap{}
+	if !opts.NoEvents {
+		eventMap = buildEventMap(ctx, cs, UniqueNamespaces(allPods), EventOptions{MaxAge: opts.EventMaxAge, Now: opts.Now}, opts.Progress)
+	}
 
-	// Filter out pods from ignored namespaces
-	if len(ignoredNamespaces) > 0 {
-		filteredPods := make([]v1.Pod, 0, len(pods.Items))
-		for _, pod := range pods.Items {
-			if !ignoredNamespaces[pod.Namespace] {
-				filteredPods = append(filteredPods, pod)
-			}
+	_, span := tracing.Start(ctx, "analyze pods")
+	span.SetAttr("k8s.pods", len(allPods))
+	issues := ScanPodList(allPods, eventMap, opts)
+	span.End()
+	if !opts.NoNodeConditions {
+		opts.Progress.Report(StageNodes, 0, 1)
+		// Nodes are cluster-scoped, so a namespaced service account may not
+		// be allowed to list them; issues are then left unannotated
+		if nodes, err := cs.Nodes(ctx); err == nil {
+			AnnotateNodeConditions(issues, NodeConditionsFromNodes(nodes))
+			ExplainPending(issues, allPods, nodes)
+			// Tagging renames reasons, so filter them again
+			TagSpotInterruptions(issues, allPods, nodes)
+			issues = opts.Reasons.Filter(issues)
 		}
-		pods.Items = filteredPods
+		opts.Progress.Report(StageNodes, 1, 1)
 	}
-
-	if len(pods.Items) == 0 {
-		return []types.Issue{}, nil
+	if hasReason(issues, "Pending") {
+		ExplainAutoscaler(issues, eventMap, AutoscalerStatusFromCluster(ctx, cs.Client()))
 	}
+	if opts.Registry != nil {
+		AnnotateImagePull(ctx, issues, allPods, imagePullNodes(ctx, cs, opts), opts.Registry)
+	}
+	if !opts.NoServices {
+		// Like nodes, Services are optional: a failed List leaves issues unannotated
+		if services, err := cs.Services(ctx); err == nil {
+			AnnotateImpactedServices(issues, allPods, services)
+		}
+	}
+	AnnotateTeams(issues, allPods, nil, opts.TeamLabel)
+	AnnotateLabels(issues, allPods, nil, opts.LabelKeys)
+	return issues, listErrs, nil
+}
 
-	// Collect unique namespaces for event fetching
-	namespaceSet := make(map[string]bool)
-	for _, pod := range pods.Items {
-		namespaceSet[pod.Namespace] = true
+func hasReason(issues []types.Issue, reason string) bool {
+	for _, is := range issues {
+		if is.Reason == reason {
+			return true
+		}
 	}
-	uniqueNamespaces := make([]string, 0, len(namespaceSet))
-	for ns := range namespaceSet {
-		uniqueNamespaces = append(uniqueNamespaces, ns)
+	return false
+}
+
+// imagePullNodes returns the nodes whose platform is matched against
+// multi-arch images, or none when nodes cannot be listed
+func imagePullNodes(ctx context.Context, cs *k8s.ClusterSnapshot, opts ScanOptions) []v1.Node {
+	if opts.NoNodeConditions {
+		return nil
 	}
+	nodes, _ := cs.Nodes(ctx)
+	return nodes
+}
 
-	// Build event map once for all pods (major performance improvement)
-	eventMap := BuildEventMap(client, uniqueNamespaces)
+// progressEvery is how many pods are analyzed between progress notifications
+const progressEvery = 100
 
-	// Pre-allocate issues slice with estimated capacity
-	estimatedIssues := len(pods.Items) * 2 // rough estimate: 2 issues per pod
-	issues := make([]types.Issue, 0, estimatedIssues)
+// ScanPodList evaluates already-fetched pods and returns deduplicated issues.
+// It does not talk to the API server, so it can run against a snapshot.
+func ScanPodList(allPods []v1.Pod, eventMap EventMap, opts ScanOptions) []types.Issue {
+	// Deduplicate issues: keep only the highest priority issue per pod or container
+	return deduplicateIssues(analyzePods(allPods, eventMap, opts, opts.Progress), opts.Dedup)
+}
+
+// analyzePods runs processPod over every pod, reporting StageAnalyze to
+// progress (which may be nil), and returns the issues before deduplication
+func analyzePods(allPods []v1.Pod, eventMap EventMap, opts ScanOptions, progress ProgressFunc) []types.Issue {
+	// A fixed pool of workers walks the pods by index, so pods are neither
+	// copied nor given a goroutine each; every worker collects its own issues
+	// and they are merged once at the end.
+	workers := min(runtime.GOMAXPROCS(0)*4, len(allPods))
+	perWorker := make([][]types.Issue, workers)
+	var next atomic.Int64
 	var mu sync.Mutex
 	var wg sync.WaitGroup
+	processed := 0
+	progress.Report(StageAnalyze, 0, len(allPods))
 
-	// Process pods concurrently
-	semaphore := make(chan struct{}, 50) // Limit concurrent goroutines to 50
-
-	for i := range pods.Items {
+	for w := 0; w < workers; w++ {
 		wg.Add(1)
-		semaphore <- struct{}{} // Acquire semaphore
-
-		go func(pod v1.Pod) {
+		go func() {
 			defer wg.Done()
-			defer func() { <-semaphore }() // Release semaphore
-
-			podIssues := processPod(pod, restartThreshold, eventMap)
-
-			// Thread-safe append
-			if len(podIssues) > 0 {
-				mu.Lock()
-				issues = append(issues, podIssues...)
-				mu.Unlock()
+			var local []types.Issue
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= len(allPods) {
+					break
+				}
+				local = append(local, processPod(&allPods[i], opts, eventMap)...)
+
+				if progress != nil && (i%progressEvery == 0 || i == len(allPods)-1) {
+					mu.Lock()
+					processed = max(processed, i+1)
+					progress.Report(StageAnalyze, processed, len(allPods))
+					mu.Unlock()
+				}
 			}
-		}(pods.Items[i])
+			perWorker[w] = local
+		}()
 	}
-
 	wg.Wait()
+	progress.Report(StageAnalyze, len(allPods), len(allPods))
+
+	total := 0
+	for _, local := range perWorker {
+		total += len(local)
+	}
+	issues := make([]types.Issue, 0, total)
+	for _, local := range perWorker {
+		issues = append(issues, local...)
+	}
+	return issues
+}
 
-	// Deduplicate issues: keep only the highest priority issue per pod
-	deduplicatedIssues := deduplicateIssues(issues)
+// FilterIgnoredNamespaces drops pods that belong to ignored namespaces
+func FilterIgnoredNamespaces(pods []v1.Pod, ignoredNamespaces map[string]bool) []v1.Pod {
+	if len(ignoredNamespaces) == 0 {
+		return pods
+	}
+	filteredPods := make([]v1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if !ignoredNamespaces[pod.Namespace] {
+			filteredPods = append(filteredPods, pod)
+		}
+	}
+	return filteredPods
+}
 
-	return deduplicatedIssues, nil
+// FilterSelector returns the pods whose labels match sel (all pods if sel is nil)
+func FilterSelector(pods []v1.Pod, sel labels.Selector) []v1.Pod {
+	if sel == nil || sel.Empty() {
+		return pods
+	}
+	filteredPods := make([]v1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if sel.Matches(labels.Set(pod.Labels)) {
+			filteredPods = append(filteredPods, pod)
+		}
+	}
+	return filteredPods
+}
+
+// UniqueNamespaces returns the distinct namespaces of the given pods
+func UniqueNamespaces(pods []v1.Pod) []string {
+	namespaceSet := make(map[string]bool)
+	for _, pod := range pods {
+		namespaceSet[pod.Namespace] = true
+	}
+	uniqueNamespaces := make([]string, 0, len(namespaceSet))
+	for ns := range namespaceSet {
+		uniqueNamespaces = append(uniqueNamespaces, ns)
+	}
+	return uniqueNamespaces
 }
 
 // processPod processes a single pod and returns its issues
-func processPod(pod v1.Pod, restartThreshold int32, eventMap EventMap) []types.Issue {
+func processPod(pod *v1.Pod, opts ScanOptions, eventMap EventMap) []types.Issue {
+	var severities map[string]severity.Level
+	if len(opts.Overrides) > 0 {
+		opts, severities = opts.forPod(pod)
+	}
 	issues := make([]types.Issue, 0, 3)
-	podStatus := GetPodStatus(pod)
-	timestamp := time.Now().Format(time.RFC3339)
+	podStatus := podStatusOf(pod)
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	timestamp := now.Format(time.RFC3339)
 	lastEvent := GetLatestPodEvent(eventMap, pod.Namespace, pod.Name)
 
 	// Check pod-level issues
 	if pod.Status.Phase == v1.PodFailed && pod.Status.Reason == "Evicted" {
-		issues = append(issues, createIssue(pod, "Evicted", podStatus, timestamp, lastEvent, getMaxRestartCount(pod)))
+		issues = append(issues, createIssue(pod, "", "Evicted", podStatus, timestamp, lastEvent, getMaxRestartCount(pod)))
 	}
 
+	// Pods stopped by a graceful node shutdown, usually the reclaim of a spot
+	// node: their containers were killed with the node, so they are not checked
+	if isNodeShutdown(pod) {
+		issues = append(issues, createIssue(pod, "", "NodeShutdown", podStatus, timestamp, lastEvent, getMaxRestartCount(pod)))
+		issues = opts.Reasons.Filter(issues)
+		applySeverities(issues, severities)
+		applyRootCauses(issues, opts.RootCauses)
+		return issues
+	}
+
+	// Pods stuck in Terminating
+	if issue, ok := checkTerminating(pod, now, opts.TerminatingMargin, timestamp, lastEvent); ok {
+		issues = append(issues, issue)
+	}
+
+	// Pods that stay Pending past the grace period (unschedulable, waiting on volumes, ...)
+	if pod.Status.Phase == v1.PodPending && now.Sub(pod.CreationTimestamp.Time) > opts.PendingGrace {
+		issue := createIssue(pod, "", "Pending", podStatus, timestamp, lastEvent, getMaxRestartCount(pod))
+		issue.InStateSince = pod.CreationTimestamp.Format(time.RFC3339)
+		issues = append(issues, issue)
+	}
+
+	// Running pods that have been out of Service endpoints for too long
+	issues = append(issues, checkUnready(pod, now, opts.UnreadyAfter, podStatus, timestamp, lastEvent)...)
+
 	// Check container-level issues
 	for _, cs := range pod.Status.ContainerStatuses {
-		// Check waiting state
-		if cs.State.Waiting != nil {
-			issues = append(issues, createIssue(pod, cs.State.Waiting.Reason, podStatus, timestamp, lastEvent, cs.RestartCount))
+		issues = append(issues, checkContainerStatus(pod, cs, podStatus, opts, now, timestamp, lastEvent)...)
+	}
+
+	// Init containers must complete before the app containers start, so a failing
+	// one otherwise only surfaces as a vague Pending pod
+	for _, cs := range pod.Status.InitContainerStatuses {
+		initStatus, ok := initContainerStatus(cs)
+		if !ok {
+			continue
+		}
+		if initStatus == "" {
+			initStatus = podStatus
 		}
+		for _, issue := range checkContainerStatus(pod, cs, initStatus, opts, now, timestamp, lastEvent) {
+			issue.RootCause = "Init container " + cs.Name + " chưa hoàn thành nên các container chính không thể khởi động: " + issue.RootCause
+			issues = append(issues, issue)
+		}
+	}
+
+	// Ephemeral (debug) containers are expected to exit, so only report ones that cannot start
+	for _, cs := range pod.Status.EphemeralContainerStatuses {
+		if cs.State.Waiting == nil {
+			continue
+		}
+		for _, issue := range checkContainerStatus(pod, cs, podStatus, opts, now, timestamp, lastEvent) {
+			issue.RootCause = "Ephemeral container " + cs.Name + " không khởi động được: " + issue.RootCause
+			issues = append(issues, issue)
+		}
+	}
+
+	failures := eventMap.Failures[pod.Namespace+"/"+pod.Name]
+
+	// Registry auth errors only show in the pull error message
+	explainPullAuth(pod, issues, failures)
+
+	// Volumes that cannot be attached or mounted keep the pod in ContainerCreating
+	issues = append(issues, checkVolumes(pod, failures, podStatus, timestamp, lastEvent)...)
+
+	// Failing probes only show up in events, and explain crash loops and unready containers
+	if probes := checkProbes(pod, failures, podStatus, timestamp, lastEvent); len(probes) > 0 {
+		issues = append(issues, probes...)
+		inheritProbeSeverity(issues)
+	}
+
+	issues = opts.Reasons.Filter(issues)
+	applySeverities(issues, severities)
+	applyRootCauses(issues, opts.RootCauses)
+	return issues
+}
 
-		// Check terminated state
-		if cs.State.Terminated != nil && cs.State.Terminated.Reason != "" {
-			issues = append(issues, createIssue(pod, cs.State.Terminated.Reason, podStatus, timestamp, lastEvent, cs.RestartCount))
+// checkContainerStatus returns the waiting, terminated and restart issues of a single container
+func checkContainerStatus(pod *v1.Pod, cs v1.ContainerStatus, podStatus string, opts ScanOptions, now time.Time, timestamp string, lastEvent string) []types.Issue {
+	var issues []types.Issue
+
+	// Check waiting state, giving starting pods time to pull images and mount volumes
+	if cs.State.Waiting != nil && !(startingReasons[cs.State.Waiting.Reason] && now.Sub(pod.CreationTimestamp.Time) <= opts.StartupGrace) {
+		issue := createIssue(pod, cs.Name, cs.State.Waiting.Reason, podStatus, timestamp, lastEvent, cs.RestartCount)
+		if cs.State.Waiting.Reason == "CrashLoopBackOff" {
+			if ctx := crashLoopContext(cs, lastEvent); ctx != "" {
+				issue.RootCause += " Chi tiết: " + ctx + "."
+			}
+		}
+		if since := waitingSince(pod); !since.IsZero() {
+			issue.InStateSince = since.Format(time.RFC3339)
+			if opts.EscalateAfter > 0 && now.Sub(since) > opts.EscalateAfter {
+				issue.Severity = severity.Escalate(issue.Severity)
+			}
 		}
+		issues = append(issues, issue)
+	}
 
-		// Check high restart count
-		if CheckRestartSeverity(cs.RestartCount, restartThreshold) == "high" {
-			issues = append(issues, createIssue(pod, "HighRestartCount", podStatus, timestamp, lastEvent, cs.RestartCount))
+	// Check terminated state; containers that completed successfully (Job
+	// pods, or ones about to be restarted) are not a problem
+	if t := cs.State.Terminated; t != nil && t.Reason != "" && !completedSuccessfully(t) {
+		issue := createIssue(pod, cs.Name, t.Reason, podStatus, timestamp, lastEvent, cs.RestartCount)
+		if t.ExitCode != 0 {
+			issue.RootCause += fmt.Sprintf(" Exit code: %d.", t.ExitCode)
+		}
+		if !t.FinishedAt.IsZero() {
+			issue.InStateSince = t.FinishedAt.Format(time.RFC3339)
 		}
+		issues = append(issues, issue)
+	}
+
+	// Check high restart count
+	if CheckRestartSeverity(cs.RestartCount, opts.RestartThreshold) == "high" {
+		issues = append(issues, createIssue(pod, cs.Name, "HighRestartCount", podStatus, timestamp, lastEvent, cs.RestartCount))
 	}
 
 	return issues
 }
 
+// completedSuccessfully reports whether a container exited normally
+func completedSuccessfully(t *v1.ContainerStateTerminated) bool {
+	return t.ExitCode == 0 && t.Reason == "Completed"
+}
+
+// startingReasons are the waiting reasons every container goes through
+// while its pod starts
+var startingReasons = map[string]bool{
+	"ContainerCreating": true,
+	"PodInitializing":   true,
+}
+
+// initContainerStatus returns the kubectl-style status (Init:CrashLoopBackOff,
+// Init:Error, ...) of an init container, or false if it needs no attention.
+// Running init containers get an empty status and are only checked for restarts.
+func initContainerStatus(cs v1.ContainerStatus) (string, bool) {
+	switch {
+	case cs.State.Waiting != nil:
+		// Init containers queued behind an earlier one wait with PodInitializing
+		if cs.State.Waiting.Reason == "PodInitializing" {
+			return "", false
+		}
+		return "Init:" + cs.State.Waiting.Reason, true
+	case cs.State.Terminated != nil:
+		if cs.State.Terminated.ExitCode == 0 {
+			return "", false
+		}
+		if cs.State.Terminated.Reason == "" {
+			return fmt.Sprintf("Init:ExitCode:%d", cs.State.Terminated.ExitCode), true
+		}
+		return "Init:" + cs.State.Terminated.Reason, true
+	}
+	// Restartable sidecars keep running for the pod lifetime
+	return "", cs.RestartCount > 0
+}
+
 // getMaxRestartCount returns the maximum restart count from all containers
-func getMaxRestartCount(pod v1.Pod) int32 {
+func getMaxRestartCount(pod *v1.Pod) int32 {
 	maxCount := int32(0)
 	for _, cs := range pod.Status.ContainerStatuses {
 		if cs.RestartCount > maxCount {
@@ -158,33 +482,22 @@ func getMaxRestartCount(pod v1.Pod) int32 {
 	return maxCount
 }
 
-// getSeverityPriority returns a numeric priority for severity (higher = more important)
-func getSeverityPriority(severity string) int {
-	switch severity {
-	case "critical":
-		return 4
-	case "high":
-		return 3
-	case "medium":
-		return 2
-	case "low":
-		return 1
-	default:
-		return 0
-	}
-}
-
 // getReasonPriority returns a numeric priority for reason specificity (higher = more specific)
 // This helps prioritize specific errors (like CrashLoopBackOff) over generic ones (like HighRestartCount)
 func getReasonPriority(reason string) int {
 	// Specific error reasons have higher priority
 	specificReasons := map[string]int{
-		"ImagePullBackOff": 10,
-		"ErrImagePull":     10,
-		"CrashLoopBackOff": 9,
-		"OOMKilled":        8,
-		"Evicted":          7,
-		"Pending":          6,
+		"ImagePullBackOff":     10,
+		"ErrImagePull":         10,
+		"LivenessProbeFailed":  10,
+		"StartupProbeFailed":   10,
+		"CrashLoopBackOff":     9,
+		"OOMKilled":            8,
+		"FailedAttachVolume":   8,
+		"FailedMount":          8,
+		"Evicted":              7,
+		"Pending":              6,
+		"ReadinessProbeFailed": 6,
 	}
 	if priority, ok := specificReasons[reason]; ok {
 		return priority
@@ -197,18 +510,22 @@ func getReasonPriority(reason string) int {
 	return 5
 }
 
-// deduplicateIssues keeps only the highest priority issue per pod
-// Priority is determined by: severity (critical > high > medium > low) > reason specificity
-func deduplicateIssues(issues []types.Issue) []types.Issue {
-	if len(issues) == 0 {
+// deduplicateIssues keeps only the highest priority issue per pod or per container
+// (pod-level issues such as Evicted use an empty container name)
+// Priority is determined by: severity (critical > high > medium > low > info) > reason specificity
+func deduplicateIssues(issues []types.Issue, mode DedupMode) []types.Issue {
+	if len(issues) == 0 || mode == DedupOff {
 		return issues
 	}
 
-	// Map to store the best issue for each pod (key: namespace/name)
-	podIssues := make(map[string]types.Issue)
+	// Map to store the best issue for each pod/container (key: namespace/name[/container])
+	podIssues := make(map[string]types.Issue, len(issues))
 
 	for _, issue := range issues {
 		key := issue.Namespace + "/" + issue.Name
+		if mode != DedupPod {
+			key += "/" + issue.Container
+		}
 		existing, exists := podIssues[key]
 
 		if !exists {
@@ -218,8 +535,8 @@ func deduplicateIssues(issues []types.Issue) []types.Issue {
 		}
 
 		// Compare priorities
-		existingSeverityPriority := getSeverityPriority(existing.Severity)
-		newSeverityPriority := getSeverityPriority(issue.Severity)
+		existingSeverityPriority := severity.Rank(existing.Severity)
+		newSeverityPriority := severity.Rank(issue.Severity)
 
 		// If new issue has higher severity, replace
 		if newSeverityPriority > existingSeverityPriority {
@@ -249,27 +566,23 @@ func deduplicateIssues(issues []types.Issue) []types.Issue {
 }
 
 // createIssue creates an Issue struct with common fields
-func createIssue(pod v1.Pod, reason string, podStatus string, timestamp string, lastEvent string, restartCount int32) types.Issue {
-	severity := SeverityFromReason(reason)
+func createIssue(pod *v1.Pod, container string, reason string, podStatus string, timestamp string, lastEvent string, restartCount int32) types.Issue {
 	rootCause := DetectPodRootCause(reason)
 
-	// Special handling for HighRestartCount
-	if reason == "HighRestartCount" {
-		severity = "high"
-		rootCause = "Container bị restart quá nhiều lần (unstable)."
-	}
-
 	return types.Issue{
-		Kind:         "Pod",
-		Namespace:    pod.Namespace,
-		Name:         pod.Name,
-		Severity:     severity,
-		Reason:       reason,
-		RootCause:    rootCause,
-		PodStatus:    podStatus,
-		NodeName:     pod.Spec.NodeName,
-		Timestamp:    timestamp,
-		RestartCount: restartCount,
-		LastEvent:    lastEvent,
+		Kind:          "Pod",
+		Namespace:     pod.Namespace,
+		Name:          pod.Name,
+		Container:     container,
+		Image:         containerImage(pod, container),
+		Severity:      severity.FromReason(reason),
+		Reason:        reason,
+		RootCause:     rootCause,
+		PodStatus:     podStatus,
+		NodeName:      pod.Spec.NodeName,
+		PriorityClass: pod.Spec.PriorityClassName,
+		Timestamp:     timestamp,
+		RestartCount:  restartCount,
+		LastEvent:     lastEvent,
 	}
 }
@@ -0,0 +1,192 @@
+package pod
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/scanner/scannertest"
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestDeduplicateIssues(t *testing.T) {
+	issue := func(name, container, reason string, level severity.Level) types.Issue {
+		return types.Issue{Namespace: "shop", Name: name, Container: container, Reason: reason, Severity: level}
+	}
+	issues := []types.Issue{
+		// A generic reason loses to a specific one of the same severity
+		issue("web-0", "app", "HighRestartCount", severity.High),
+		issue("web-0", "app", "CrashLoopBackOff", severity.High),
+		// A higher severity wins over a more specific reason
+		issue("web-0", "proxy", "OOMKilled", severity.Medium),
+		issue("web-0", "proxy", "ImagePullBackOff", severity.Critical),
+		// Pod-level issues have no container
+		issue("web-1", "", "Evicted", severity.Medium),
+		issue("web-2", "app", "ReadinessProbeFailed", severity.Medium),
+	}
+	tests := []struct {
+		mode DedupMode
+		want []string
+	}{
+		{DedupContainer, []string{"web-0/app/CrashLoopBackOff", "web-0/proxy/ImagePullBackOff", "web-1//Evicted", "web-2/app/ReadinessProbeFailed"}},
+		{DedupPod, []string{"web-0/proxy/ImagePullBackOff", "web-1//Evicted", "web-2/app/ReadinessProbeFailed"}},
+		{DedupOff, []string{
+			"web-0/app/CrashLoopBackOff", "web-0/app/HighRestartCount", "web-0/proxy/ImagePullBackOff", "web-0/proxy/OOMKilled",
+			"web-1//Evicted", "web-2/app/ReadinessProbeFailed",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			var got []string
+			for _, is := range deduplicateIssues(append([]types.Issue(nil), issues...), tt.mode) {
+				got = append(got, is.Name+"/"+is.Container+"/"+is.Reason)
+			}
+			sort.Strings(got)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Size of the benchmark cluster: a large production cluster, where about one
+// pod in ten has a problem
+const (
+	benchNamespaces = 50
+	benchPods       = 5000
+	benchNodes      = 100
+)
+
+// benchCluster returns the pods, events and nodes of the benchmark cluster
+func benchCluster() ([]*v1.Pod, []v1.Event, []*v1.Node) {
+	var (
+		pods   []*v1.Pod
+		events []v1.Event
+		nodes  []*v1.Node
+	)
+	for i := range benchNodes {
+		nodes = append(nodes, &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i)},
+			Status:     v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}},
+		})
+	}
+	started := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	for i := range benchPods {
+		ns := fmt.Sprintf("team-%d", i%benchNamespaces)
+		name := fmt.Sprintf("app-%d", i)
+		var p *v1.Pod
+		switch i % 40 {
+		case 0:
+			p = scannertest.CrashLoopPod(ns, name, int32(i%30))
+		case 1:
+			p = scannertest.ImagePullBackOffPod(ns, name)
+		case 2:
+			p = scannertest.OOMKilledPod(ns, name, 4)
+		case 3:
+			p = scannertest.EvictedPod(ns, name)
+		default:
+			p = scannertest.RunningPod(ns, name)
+			p.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}
+		}
+		p.CreationTimestamp = started
+		p.Labels = map[string]string{"app": name, "team": ns}
+		p.Spec.NodeName = fmt.Sprintf("node-%d", i%benchNodes)
+		// Sidecars make dedup choose between containers
+		p.Spec.Containers = append(p.Spec.Containers, v1.Container{Name: "proxy", Image: "envoy:1.30"})
+		p.Status.ContainerStatuses = append(p.Status.ContainerStatuses, v1.ContainerStatus{
+			Name:  "proxy",
+			Ready: true,
+			State: v1.ContainerState{Running: &v1.ContainerStateRunning{StartedAt: started}},
+		})
+		pods = append(pods, p)
+		if i%40 < 4 {
+			events = append(events, *scannertest.PodEvent(p, v1.EventTypeWarning, "BackOff", "Back-off restarting failed container"))
+			events = append(events, *scannertest.PodEvent(p, v1.EventTypeWarning, "Unhealthy", "Readiness probe failed: connection refused"))
+		}
+	}
+	return pods, events, nodes
+}
+
+func benchOptions() ScanOptions {
+	return ScanOptions{
+		RestartThreshold:  10,
+		Dedup:             DedupContainer,
+		EscalateAfter:     24 * time.Hour,
+		PendingGrace:      2 * time.Minute,
+		StartupGrace:      time.Minute,
+		TerminatingMargin: 5 * time.Minute,
+		UnreadyAfter:      5 * time.Minute,
+		EventMaxAge:       time.Hour,
+		TeamLabel:         "team",
+	}
+}
+
+func BenchmarkScanPods(b *testing.B) {
+	pods, events, nodes := benchCluster()
+	objects := make([]runtime.Object, 0, len(pods)+len(events)+len(nodes))
+	for _, p := range pods {
+		objects = append(objects, p)
+	}
+	for i := range events {
+		objects = append(objects, &events[i])
+	}
+	for _, n := range nodes {
+		objects = append(objects, n)
+	}
+	client := scannertest.NewClient(objects...)
+	opts := benchOptions()
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		issues, err := ScanPods(ctx, client, nil, nil, opts)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(issues) == 0 {
+			b.Fatal("no issues found")
+		}
+	}
+}
+
+func BenchmarkProcessPod(b *testing.B) {
+	pods, events, _ := benchCluster()
+	eventMap := BuildEventMapFromEvents(events, EventOptions{MaxAge: time.Hour})
+	opts := benchOptions()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		for _, p := range pods {
+			processPod(p, opts, eventMap)
+		}
+	}
+}
+
+func BenchmarkDeduplicateIssues(b *testing.B) {
+	pods, events, _ := benchCluster()
+	eventMap := BuildEventMapFromEvents(events, EventOptions{MaxAge: time.Hour})
+	opts := benchOptions()
+	var issues []types.Issue
+	for _, p := range pods {
+		issues = append(issues, processPod(p, opts, eventMap)...)
+	}
+	if len(issues) == 0 {
+		b.Fatal("no issues to deduplicate")
+	}
+
+	for _, mode := range []DedupMode{DedupContainer, DedupPod} {
+		b.Run(string(mode), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				deduplicateIssues(issues, mode)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package pod
+
+import (
+	"sort"
+
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// AnnotateImpactedServices sets the ImpactedServices of pod issues to the
+// Services whose selector matches the pod, so responders see which
+// user-facing endpoints are affected. Services without a selector (manual
+// Endpoints, ExternalName) never match.
+func AnnotateImpactedServices(issues []types.Issue, pods []v1.Pod, services []v1.Service) {
+	if len(services) == 0 || len(issues) == 0 {
+		return
+	}
+	byNamespace := map[string][]*v1.Service{}
+	for i := range services {
+		s := &services[i]
+		if len(s.Spec.Selector) > 0 {
+			byNamespace[s.Namespace] = append(byNamespace[s.Namespace], s)
+		}
+	}
+	podLabels := make(map[string]labels.Set, len(pods))
+	for i := range pods {
+		podLabels[pods[i].Namespace+"/"+pods[i].Name] = pods[i].Labels
+	}
+
+	// Dedup keeps several issues per pod, so match each pod once
+	matched := map[string][]string{}
+	for i := range issues {
+		is := &issues[i]
+		if is.Kind != "Pod" {
+			continue
+		}
+		key := is.Namespace + "/" + is.Name
+		names, ok := matched[key]
+		if !ok {
+			set, found := podLabels[key]
+			if found {
+				names = selectingServices(byNamespace[is.Namespace], set)
+			}
+			matched[key] = names
+		}
+		if len(names) > 0 {
+			is.ImpactedServices = names
+		}
+	}
+}
+
+func selectingServices(services []*v1.Service, podLabels labels.Set) []string {
+	var names []string
+	for _, s := range services {
+		if labels.SelectorFromSet(s.Spec.Selector).Matches(podLabels) {
+			names = append(names, s.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,15 +1,15 @@
 package pod
 
+import "github.com/ductnn/k8s-scanner/pkg/severity"
+
+// Severity is the severity level of an issue
+//
+// Deprecated: use severity.Level. This alias will be removed in the next release.
+type Severity = severity.Level
+
 // SeverityFromReason maps pod reason to severity level
-func SeverityFromReason(reason string) string {
-	switch reason {
-	case "ImagePullBackOff", "ErrImagePull":
-		return "critical"
-	case "CrashLoopBackOff", "Pending":
-		return "high"
-	case "Evicted", "OOMKilled":
-		return "medium"
-	default:
-		return "low"
-	}
+//
+// Deprecated: use severity.FromReason. This wrapper will be removed in the next release.
+func SeverityFromReason(reason string) Severity {
+	return severity.FromReason(reason)
 }
@@ -0,0 +1,154 @@
+package pod
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// spotLabels are the node labels (and values) marking spot/preemptible
+// capacity on EKS, Karpenter, GKE and AKS
+var spotLabels = map[string]string{
+	"eks.amazonaws.com/capacityType":        "SPOT",
+	"karpenter.sh/capacity-type":            "spot",
+	"cloud.google.com/gke-spot":             "true",
+	"cloud.google.com/gke-preemptible":      "true",
+	"kubernetes.azure.com/scalesetpriority": "spot",
+}
+
+// interruptionTaints are the taints put on a node being reclaimed: by the AWS
+// node termination handler, by Karpenter, by GKE, or by the node lifecycle
+// controller once the reclaimed instance stops answering
+var interruptionTaints = []string{
+	"aws-node-termination-handler/spot-itn",
+	"aws-node-termination-handler/rebalance-recommendation",
+	"karpenter.sh/disrupted",
+	"karpenter.sh/disruption",
+	"cloud.google.com/impending-node-termination",
+	v1.TaintNodeUnreachable,
+	v1.TaintNodeNotReady,
+}
+
+// spotReasons are the reasons a spot interruption shows up as
+var spotReasons = map[string]bool{
+	"NodeShutdown":      true,
+	"Evicted":           true,
+	"TerminatingStuck":  true,
+	"Error":             true,
+	"ContainerNotReady": true,
+}
+
+// isNodeShutdown tells if the kubelet stopped the pod because its node was
+// shutting down (graceful node shutdown, used for spot/preemptible nodes)
+func isNodeShutdown(pod *v1.Pod) bool {
+	if pod.Status.Phase != v1.PodFailed {
+		return false
+	}
+	switch pod.Status.Reason {
+	case "NodeShutdown", "Shutdown":
+		return true
+	case "Terminated":
+		return strings.Contains(strings.ToLower(pod.Status.Message), "node shutdown")
+	}
+	return false
+}
+
+// TagSpotInterruptions gives the issues caused by the reclaim of a spot or
+// preemptible node the reason SpotInterruption, so they can be filtered
+// apart from application problems (--ignore-reasons SpotInterruption). An
+// issue is tagged when its pod ran on a spot node (or, the node being gone,
+// asked for one) and was shut down with the node, or the node is being
+// reclaimed. The original reason is kept in the root cause. Without nodes,
+// a removed node cannot be told apart, so nothing is tagged.
+func TagSpotInterruptions(issues []types.Issue, pods []v1.Pod, nodes []v1.Node) {
+	if len(nodes) == 0 {
+		return
+	}
+	byName := map[string]*v1.Pod{}
+	for i := range issues {
+		if spotReasons[issues[i].Reason] {
+			byName[issues[i].Namespace+"/"+issues[i].Name] = nil
+		}
+	}
+	if len(byName) == 0 {
+		return
+	}
+	for i := range pods {
+		key := pods[i].Namespace + "/" + pods[i].Name
+		if _, ok := byName[key]; ok {
+			byName[key] = &pods[i]
+		}
+	}
+	nodeByName := make(map[string]*v1.Node, len(nodes))
+	for i := range nodes {
+		nodeByName[nodes[i].Name] = &nodes[i]
+	}
+
+	for i := range issues {
+		is := &issues[i]
+		if !spotReasons[is.Reason] || is.Kind != "Pod" {
+			continue
+		}
+		p := byName[is.Namespace+"/"+is.Name]
+		if p == nil || p.Spec.NodeName == "" {
+			continue
+		}
+		node := nodeByName[p.Spec.NodeName]
+		var labels map[string]string
+		if node != nil {
+			labels = node.Labels
+		} else {
+			labels = p.Spec.NodeSelector
+		}
+		capacity := spotCapacity(labels)
+		if capacity == "" {
+			continue
+		}
+		var signal string
+		switch {
+		case isNodeShutdown(p):
+			signal = "pod bị dừng cùng lúc node shutdown"
+		case node == nil:
+			signal = "node đã bị xóa khỏi cluster"
+		default:
+			signal = interruptionTaint(node)
+		}
+		if signal == "" {
+			continue
+		}
+		is.RootCause = fmt.Sprintf("Node %s là %s và đã bị thu hồi (%s) — không phải lỗi của ứng dụng, workload sẽ được schedule lại. Lý do gốc: %s — %s",
+			p.Spec.NodeName, capacity, signal, is.Reason, is.RootCause)
+		is.Reason = "SpotInterruption"
+		is.Severity = severity.FromReason(is.Reason)
+		is.Suggestion = "Chạy nhiều replica trên nhiều node/zone với PodDisruptionBudget, xử lý SIGTERM trong terminationGracePeriodSeconds; đặt workload không chịu được gián đoạn lên node on-demand"
+	}
+}
+
+// spotCapacity names the spot capacity the labels select, or ""
+func spotCapacity(labels map[string]string) string {
+	for key, value := range spotLabels {
+		if strings.EqualFold(labels[key], value) {
+			if strings.Contains(key, "preemptible") {
+				return "node preemptible"
+			}
+			return "node spot"
+		}
+	}
+	return ""
+}
+
+// interruptionTaint returns the taint showing the node is being reclaimed, or ""
+func interruptionTaint(node *v1.Node) string {
+	for _, t := range node.Spec.Taints {
+		for _, key := range interruptionTaints {
+			if t.Key == key {
+				return "taint " + key
+			}
+		}
+	}
+	return ""
+}
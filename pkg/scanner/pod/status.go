@@ -6,6 +6,10 @@ import (
 
 // GetPodStatus extracts the status string from a pod
 func GetPodStatus(pod v1.Pod) string {
+	return podStatusOf(&pod)
+}
+
+func podStatusOf(pod *v1.Pod) string {
 	if pod.Status.Phase != "" {
 		return string(pod.Status.Phase)
 	}
@@ -0,0 +1,133 @@
+package pod
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+	"github.com/ductnn/k8s-scanner/pkg/tracing"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// scanPodsPaged is ScanCluster for ScanOptions.PageSize > 0: pods are listed
+// page by page and each page is analyzed and dropped before the next one is
+// fetched, so memory stays bounded by the page size and the issues found
+// rather than by the number of pods. Events are gathered up front for the
+// scoped namespaces (cluster-wide when scanning all namespaces).
+func scanPodsPaged(ctx context.Context, cs *k8s.ClusterSnapshot, ignoredNamespaces map[string]bool, opts ScanOptions) ([]types.Issue, []error, error) {
+	namespaces := cs.ScopedNamespaces()
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+
+	eventMap := EventMap{}
+	if !opts.NoEvents {
+		eventMap = buildEventMap(ctx, cs, namespaces, EventOptions{MaxAge: opts.EventMaxAge, Now: opts.Now}, opts.Progress)
+	}
+
+	// Streamed issues are annotated as they go, and Pending pods are
+	// explained while their page is held, so look nodes up first
+	var (
+		nodes          []v1.Node
+		nodeConditions NodeConditions
+	)
+	if !opts.NoNodeConditions {
+		opts.Progress.Report(StageNodes, 0, 1)
+		if list, err := cs.Nodes(ctx); err == nil {
+			nodes = list
+			nodeConditions = NodeConditionsFromNodes(nodes)
+		}
+		opts.Progress.Report(StageNodes, 1, 1)
+	}
+	autoscaler := AutoscalerStatusFromCluster(ctx, cs.Client())
+	var services []v1.Service
+	if !opts.NoServices {
+		services, _ = cs.Services(ctx)
+	}
+
+	var issues []types.Issue
+	var listErrs []error
+	processed := 0
+	for _, ns := range namespaces {
+		if ignoredNamespaces[ns] {
+			continue
+		}
+		err := listPodPages(ctx, cs, ns, opts.PageSize, func(pods []v1.Pod, remaining int) {
+			pods = FilterIgnoredNamespaces(pods, ignoredNamespaces)
+			pods = FilterSelector(pods, opts.Selector)
+			found := analyzePods(pods, eventMap, opts, nil)
+			// Pods are dropped with their page, so look images up now
+			AnnotateImagePull(ctx, found, pods, nodes, opts.Registry)
+			ExplainPending(found, pods, nodes)
+			ExplainAutoscaler(found, eventMap, autoscaler)
+			TagSpotInterruptions(found, pods, nodes)
+			found = opts.Reasons.Filter(found)
+			AnnotateImpactedServices(found, pods, services)
+			AnnotateTeams(found, pods, nil, opts.TeamLabel)
+			AnnotateLabels(found, pods, nil, opts.LabelKeys)
+			if opts.OnIssues != nil {
+				// A pod is only ever in one page, so deduplicating per page
+				// gives the same result as deduplicating everything at once
+				found = deduplicateIssues(found, opts.Dedup)
+				AnnotateNodeConditions(found, nodeConditions)
+				if len(found) > 0 {
+					opts.OnIssues(found)
+				}
+			} else {
+				issues = append(issues, found...)
+			}
+			processed += len(pods)
+			// The total is only an estimate: later namespaces are not counted
+			opts.Progress.Report(StageAnalyze, processed, processed+max(remaining, 1))
+		})
+		if err != nil {
+			if ns == "" {
+				return nil, nil, err
+			}
+			listErrs = append(listErrs, fmt.Errorf("failed to list pods in %s: %w", ns, err))
+		}
+	}
+	opts.Progress.Report(StageAnalyze, processed, processed)
+
+	issues = deduplicateIssues(issues, opts.Dedup)
+	if opts.OnIssues == nil {
+		AnnotateNodeConditions(issues, nodeConditions)
+	}
+	if issues == nil {
+		issues = []types.Issue{}
+	}
+	return issues, listErrs, nil
+}
+
+// listPodPages calls page with each page of pods in namespace ("" for all)
+// and the number of pods left after it, when the server reports it
+func listPodPages(ctx context.Context, cs *k8s.ClusterSnapshot, namespace string, pageSize int, page func(pods []v1.Pod, remaining int)) error {
+	ctx, span := tracing.Start(ctx, "list pods")
+	defer span.End()
+	span.SetAttr("k8s.namespace", namespace)
+	span.SetAttr("k8s.page_size", pageSize)
+
+	listOpts := metav1.ListOptions{Limit: int64(pageSize)}
+	pages := 0
+	for {
+		list, err := cs.Client().CoreV1().Pods(namespace).List(ctx, listOpts)
+		if err != nil {
+			span.RecordError(err)
+			return err
+		}
+		pages++
+		span.SetAttr("k8s.pages", pages)
+		remaining := 0
+		if list.RemainingItemCount != nil {
+			remaining = int(*list.RemainingItemCount)
+		}
+		page(list.Items, remaining)
+		if list.Continue == "" {
+			return nil
+		}
+		listOpts.Continue = list.Continue
+	}
+}
@@ -0,0 +1,43 @@
+package pod
+
+import (
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// AnnotateTeams sets the Team of issues without one from the label of the
+// pod they are about or, failing that, of their namespace. Either pods or
+// namespaces may be nil: the pod scanner annotates from pods, scanner.Run
+// then fills the rest from namespaces.
+func AnnotateTeams(issues []types.Issue, pods []v1.Pod, namespaces []v1.Namespace, label string) {
+	if label == "" || len(issues) == 0 {
+		return
+	}
+	podTeams := make(map[string]string, len(pods))
+	for i := range pods {
+		if team := pods[i].Labels[label]; team != "" {
+			podTeams[pods[i].Namespace+"/"+pods[i].Name] = team
+		}
+	}
+	nsTeams := make(map[string]string, len(namespaces))
+	for i := range namespaces {
+		if team := namespaces[i].Labels[label]; team != "" {
+			nsTeams[namespaces[i].Name] = team
+		}
+	}
+
+	for i := range issues {
+		is := &issues[i]
+		if is.Team != "" {
+			continue
+		}
+		if is.Kind == "Pod" {
+			if team, ok := podTeams[is.Namespace+"/"+is.Name]; ok {
+				is.Team = team
+				continue
+			}
+		}
+		is.Team = nsTeams[is.Namespace]
+	}
+}
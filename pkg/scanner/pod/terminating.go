@@ -0,0 +1,38 @@
+package pod
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// checkTerminating reports pods whose deletion did not complete.
+// The API server sets deletionTimestamp to the deletion request time plus the
+// grace period, so a pod still present past deletionTimestamp + margin is stuck.
+func checkTerminating(pod *v1.Pod, now time.Time, margin time.Duration, timestamp string, lastEvent string) (types.Issue, bool) {
+	if pod.DeletionTimestamp == nil {
+		return types.Issue{}, false
+	}
+	if now.Sub(pod.DeletionTimestamp.Time) <= margin {
+		return types.Issue{}, false
+	}
+
+	issue := createIssue(pod, "", "TerminatingStuck", "Terminating", timestamp, lastEvent, getMaxRestartCount(pod))
+	issue.InStateSince = pod.DeletionTimestamp.Format(time.RFC3339)
+
+	if len(pod.Finalizers) > 0 {
+		issue.RootCause = fmt.Sprintf("Pod bị kẹt ở trạng thái Terminating do finalizer chưa được xử lý: %s.", strings.Join(pod.Finalizers, ", "))
+		issue.Suggestion = fmt.Sprintf("Kiểm tra controller sở hữu finalizer; nếu controller không còn tồn tại: kubectl patch pod %s -n %s --type=merge -p '{\"metadata\":{\"finalizers\":null}}'",
+			pod.Name, pod.Namespace)
+	} else {
+		issue.RootCause = "Pod bị kẹt ở trạng thái Terminating — kubelet không xác nhận xóa (node không phản hồi hoặc container không dừng được)."
+		issue.Suggestion = fmt.Sprintf("Kiểm tra trạng thái node %s; nếu node đã mất: kubectl delete pod %s -n %s --grace-period=0 --force",
+			pod.Spec.NodeName, pod.Name, pod.Namespace)
+	}
+
+	return issue, true
+}
@@ -0,0 +1,72 @@
+package pod
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// checkUnready reports Running pods whose Ready condition has been False for
+// longer than unreadyAfter. Such pods are silently removed from Service endpoints.
+func checkUnready(pod *v1.Pod, now time.Time, unreadyAfter time.Duration, podStatus string, timestamp string, lastEvent string) []types.Issue {
+	if unreadyAfter <= 0 || pod.Status.Phase != v1.PodRunning || pod.DeletionTimestamp != nil {
+		return nil
+	}
+
+	var ready *v1.PodCondition
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == v1.PodReady {
+			ready = &pod.Status.Conditions[i]
+			break
+		}
+	}
+	if ready == nil || ready.Status != v1.ConditionFalse {
+		return nil
+	}
+	if ready.LastTransitionTime.IsZero() || now.Sub(ready.LastTransitionTime.Time) <= unreadyAfter {
+		return nil
+	}
+	since := ready.LastTransitionTime.Format(time.RFC3339)
+
+	var issues []types.Issue
+	switch ready.Reason {
+	case "ContainersNotReady":
+		// Waiting and terminated containers are already reported with their own reason
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.Ready || cs.State.Running == nil {
+				continue
+			}
+			issue := createIssue(pod, cs.Name, "ContainerNotReady", podStatus, timestamp, lastEvent, cs.RestartCount)
+			issue.InStateSince = since
+			issues = append(issues, issue)
+		}
+	case "ReadinessGatesNotReady":
+		issue := createIssue(pod, "", "ReadinessGatesNotReady", podStatus, timestamp, lastEvent, getMaxRestartCount(pod))
+		issue.InStateSince = since
+		if gates := pendingReadinessGates(pod); len(gates) > 0 {
+			issue.RootCause = fmt.Sprintf("Readiness gate chưa đạt: %s — pod bị loại khỏi endpoints của Service.", strings.Join(gates, ", "))
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// pendingReadinessGates returns the readiness gate condition types that are not True
+func pendingReadinessGates(pod *v1.Pod) []string {
+	status := make(map[v1.PodConditionType]v1.ConditionStatus, len(pod.Status.Conditions))
+	for _, cond := range pod.Status.Conditions {
+		status[cond.Type] = cond.Status
+	}
+
+	var gates []string
+	for _, gate := range pod.Spec.ReadinessGates {
+		if status[gate.ConditionType] != v1.ConditionTrue {
+			gates = append(gates, string(gate.ConditionType))
+		}
+	}
+	return gates
+}
@@ -0,0 +1,151 @@
+package pod
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+var (
+	// volumeNamePattern finds the volume of "MountVolume.SetUp failed for volume "config" : ..."
+	volumeNamePattern = regexp.MustCompile(`for volume "([^"]+)"`)
+	// unmountedPattern finds the volumes of "Unable to attach or mount volumes: unmounted volumes=[data], ..."
+	unmountedPattern = regexp.MustCompile(`unmounted volumes=\[([^\]]*)\]`)
+	// missingRefPattern finds the object of "secret "db-creds" not found"
+	missingRefPattern = regexp.MustCompile(`(?i)(secret|configmap) "([^"]+)" not found`)
+)
+
+// Kinds of volume failures, from the most to the least specific
+const (
+	volumeMissingRef = iota + 1
+	volumeMultiAttach
+	volumeCSI
+	volumeOther
+)
+
+// checkVolumes reports the FailedMount and FailedAttachVolume events of a
+// pod that is still waiting to start, one issue per event reason, naming the
+// volume and its PVC. The kubelet follows a specific error with a generic
+// "timed out waiting for the condition", so the most specific message is
+// explained rather than the latest.
+func checkVolumes(pod *v1.Pod, failures []EventFailure, podStatus string, timestamp string, lastEvent string) []types.Issue {
+	if pod.Status.Phase != v1.PodPending || pod.DeletionTimestamp != nil {
+		return nil
+	}
+	var issues []types.Issue
+	for _, reason := range []string{"FailedAttachVolume", "FailedMount"} {
+		var (
+			chosen *EventFailure
+			kind   int
+			count  int32
+		)
+		for i := range failures {
+			f := &failures[i]
+			if f.Reason != reason {
+				continue
+			}
+			count += max(f.Count, 1)
+			k := volumeFailureKind(f.Message)
+			if chosen == nil || k < kind || (k == kind && f.At.After(chosen.At)) {
+				chosen, kind = f, k
+			}
+		}
+		if chosen == nil {
+			continue
+		}
+		issue := createIssue(pod, "", reason, podStatus, timestamp, lastEvent, getMaxRestartCount(pod))
+		cause, suggestion := explainVolumeFailure(pod, kind, chosen.Message)
+		if volumes := describeVolumes(pod, failedVolumes(chosen.Message)); volumes != "" {
+			cause += " Volume: " + volumes + "."
+		}
+		issue.RootCause = fmt.Sprintf("%s Lỗi (x%d): %s", cause, count, chosen.Message)
+		issue.Suggestion = suggestion
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// volumeFailureKind classifies a FailedMount or FailedAttachVolume message
+func volumeFailureKind(msg string) int {
+	lower := strings.ToLower(msg)
+	switch {
+	case missingRefPattern.MatchString(msg):
+		return volumeMissingRef
+	case strings.Contains(lower, "multi-attach") || strings.Contains(lower, "already exclusively attached") || strings.Contains(lower, "already used by pod"):
+		return volumeMultiAttach
+	case strings.Contains(lower, "csi") || strings.Contains(lower, "rpc error"):
+		return volumeCSI
+	default:
+		return volumeOther
+	}
+}
+
+// explainVolumeFailure returns the root cause and suggestion of a volume failure
+func explainVolumeFailure(pod *v1.Pod, kind int, msg string) (string, string) {
+	switch kind {
+	case volumeMissingRef:
+		m := missingRefPattern.FindStringSubmatch(msg)
+		object := "Secret"
+		if strings.EqualFold(m[1], "configmap") {
+			object = "ConfigMap"
+		}
+		return fmt.Sprintf("%s %q được mount làm volume không tồn tại trong namespace %s.", object, m[2], pod.Namespace),
+			fmt.Sprintf("Tạo %s %s trong namespace %s hoặc sửa tên trong spec.volumes; đặt optional: true nếu volume không bắt buộc.", object, m[2], pod.Namespace)
+	case volumeMultiAttach:
+		return "Volume đang được attach vào node khác (ReadWriteOnce) — pod cũ hoặc node cũ vẫn giữ volume.",
+			"Xóa pod cũ còn dùng PVC (kubectl get volumeattachment để tìm node đang giữ volume); với Deployment dùng strategy Recreate hoặc chuyển sang ReadWriteMany/ReadWriteOncePod."
+	case volumeCSI:
+		return "CSI driver trả lỗi khi attach/mount volume — driver chưa cài, chưa đăng ký trên node hoặc backend storage lỗi.",
+			"Kiểm tra kubectl get csidriver, các pod controller/node của CSI driver (kubectl -n kube-system get pods) và logs của chúng."
+	default:
+		return "Không attach/mount được volume của pod — pod kẹt ở ContainerCreating.",
+			fmt.Sprintf("kubectl describe pod %s -n %s và kubectl describe pvc để xem chi tiết lỗi volume.", pod.Name, pod.Namespace)
+	}
+}
+
+// failedVolumes returns the volume names found in a volume failure message
+func failedVolumes(msg string) []string {
+	if m := volumeNamePattern.FindStringSubmatch(msg); m != nil {
+		return []string{m[1]}
+	}
+	if m := unmountedPattern.FindStringSubmatch(msg); m != nil {
+		return strings.Fields(m[1])
+	}
+	return nil
+}
+
+// describeVolumes names the volumes with the PVC they mount, e.g.
+// "data (PVC data-web-0)". Attach errors name the PersistentVolume, which is
+// not in the pod spec, so they list the PVCs of the pod instead.
+func describeVolumes(pod *v1.Pod, names []string) string {
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		desc, found := name, false
+		for _, vol := range pod.Spec.Volumes {
+			if vol.Name != name {
+				continue
+			}
+			found = true
+			if vol.PersistentVolumeClaim != nil {
+				desc += " (PVC " + vol.PersistentVolumeClaim.ClaimName + ")"
+			}
+		}
+		if !found {
+			var claims []string
+			for _, vol := range pod.Spec.Volumes {
+				if vol.PersistentVolumeClaim != nil {
+					claims = append(claims, vol.PersistentVolumeClaim.ClaimName)
+				}
+			}
+			if len(claims) > 0 {
+				desc += " (PV của PVC " + strings.Join(claims, "/") + ")"
+			}
+		}
+		out = append(out, desc)
+	}
+	return strings.Join(out, ", ")
+}
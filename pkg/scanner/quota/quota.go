@@ -0,0 +1,254 @@
+// Package quota reports ResourceQuotas close to exhaustion, workloads whose
+// pods were rejected by a quota or a LimitRange, and, in policy mode,
+// namespaces without any ResourceQuota.
+package quota
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// Options configures the quota checks
+type Options struct {
+	// Threshold is the percentage of a quota's hard limit above which it is reported
+	Threshold float64
+	// RequireQuota reports namespaces that have no ResourceQuota
+	RequireQuota bool
+	// EventMaxAge ignores older admission rejections
+	EventMaxAge time.Duration
+}
+
+// DefaultOptions returns the thresholds used when none are configured
+func DefaultOptions() Options {
+	return Options{
+		Threshold:   90,
+		EventMaxAge: time.Hour,
+	}
+}
+
+// ScanCluster checks the ResourceQuotas and admission rejections of the
+// snapshot's namespaces (all namespaces when it is not scoped)
+func ScanCluster(ctx context.Context, cs *k8s.ClusterSnapshot, ignoredNamespaces map[string]bool, opts Options) ([]types.Issue, error) {
+	client := cs.Client()
+	scoped := cs.ScopedNamespaces()
+	listIn := scoped
+	if len(listIn) == 0 {
+		listIn = []string{metav1.NamespaceAll}
+	}
+
+	var (
+		quotas   []v1.ResourceQuota
+		rejected []v1.Event
+	)
+	for _, ns := range listIn {
+		list, err := client.CoreV1().ResourceQuotas(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list resourcequotas: %w", err)
+		}
+		quotas = append(quotas, list.Items...)
+
+		events, err := client.CoreV1().Events(ns).List(ctx, metav1.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("reason", "FailedCreate").String(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list events: %w", err)
+		}
+		rejected = append(rejected, events.Items...)
+	}
+
+	now := time.Now()
+	timestamp := now.Format(time.RFC3339)
+	quotaRejections := map[string][]string{}
+	limitRejected := map[string]bool{}
+	var issues []types.Issue
+
+	for _, ev := range recentRejections(rejected, now, opts.EventMaxAge) {
+		if ignoredNamespaces[ev.Namespace] {
+			continue
+		}
+		obj := ev.InvolvedObject
+		switch {
+		case strings.Contains(ev.Message, "exceeded quota"):
+			key := ev.Namespace + "/" + rejectingQuota(ev.Message)
+			quotaRejections[key] = append(quotaRejections[key], obj.Kind+"/"+obj.Name)
+		case isLimitRangeRejection(ev.Message) && !limitRejected[ev.Namespace+"/"+obj.Kind+"/"+obj.Name]:
+			limitRejected[ev.Namespace+"/"+obj.Kind+"/"+obj.Name] = true
+			issues = append(issues, types.Issue{
+				Kind:         obj.Kind,
+				Namespace:    ev.Namespace,
+				Name:         obj.Name,
+				Reason:       "LimitRangeRejected",
+				Severity:     severity.FromReason("LimitRangeRejected"),
+				RootCause:    "Pod bị LimitRange từ chối khi tạo: " + admissionMessage(ev.Message) + ".",
+				Suggestion:   "kubectl describe limitrange -n " + ev.Namespace + " và chỉnh requests/limits của workload cho phù hợp",
+				LastEvent:    ev.Message,
+				Timestamp:    timestamp,
+				InStateSince: ev.FirstTimestamp.Format(time.RFC3339),
+			})
+		}
+	}
+
+	hasQuota := map[string]bool{}
+	for i := range quotas {
+		q := &quotas[i]
+		hasQuota[q.Namespace] = true
+		if ignoredNamespaces[q.Namespace] {
+			continue
+		}
+		if issue, ok := checkQuota(q, opts.Threshold, quotaRejections[q.Namespace+"/"+q.Name]); ok {
+			issue.Timestamp = timestamp
+			issues = append(issues, issue)
+		}
+	}
+
+	if opts.RequireQuota {
+		namespaces := scoped
+		if len(namespaces) == 0 {
+			all, err := cs.NamespaceObjects(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list namespaces: %w", err)
+			}
+			for _, ns := range all {
+				namespaces = append(namespaces, ns.Name)
+			}
+		}
+		for _, ns := range namespaces {
+			if hasQuota[ns] || ignoredNamespaces[ns] {
+				continue
+			}
+			issues = append(issues, types.Issue{
+				Kind:       "Namespace",
+				Namespace:  ns,
+				Name:       ns,
+				Reason:     "MissingResourceQuota",
+				Severity:   severity.FromReason("MissingResourceQuota"),
+				RootCause:  "Namespace không có ResourceQuota — workload có thể dùng hết tài nguyên của cluster.",
+				Suggestion: "Tạo ResourceQuota giới hạn requests/limits cho namespace " + ns,
+				Timestamp:  timestamp,
+			})
+		}
+	}
+	return issues, nil
+}
+
+// recentRejections keeps the FailedCreate events newer than maxAge (0 keeps all)
+func recentRejections(events []v1.Event, now time.Time, maxAge time.Duration) []v1.Event {
+	var out []v1.Event
+	for _, ev := range events {
+		last := ev.LastTimestamp.Time
+		if last.IsZero() {
+			last = ev.EventTime.Time
+		}
+		if maxAge > 0 && !last.IsZero() && now.Sub(last) > maxAge {
+			continue
+		}
+		out = append(out, ev)
+	}
+	return out
+}
+
+// isLimitRangeRejection matches the messages of the LimitRanger admission plugin
+func isLimitRangeRejection(msg string) bool {
+	for _, s := range []string{"usage per Container", "usage per Pod", "limit to request ratio per"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// rejectingQuota returns the quota named in an `exceeded quota: <name>, ...` message
+func rejectingQuota(msg string) string {
+	_, after, _ := strings.Cut(msg, "exceeded quota: ")
+	name, _, _ := strings.Cut(after, ",")
+	return strings.TrimSpace(name)
+}
+
+// admissionMessage strips the `Error creating: pods "x" is forbidden: `
+// prefix of a FailedCreate message
+func admissionMessage(msg string) string {
+	if i := strings.Index(msg, "forbidden: "); i >= 0 {
+		return msg[i+len("forbidden: "):]
+	}
+	return msg
+}
+
+// checkQuota reports a quota with a resource used above threshold percent
+// of its hard limit
+func checkQuota(q *v1.ResourceQuota, threshold float64, rejections []string) (types.Issue, bool) {
+	var (
+		over      []string
+		exhausted bool
+	)
+	names := make([]string, 0, len(q.Status.Hard))
+	for name := range q.Status.Hard {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		hard := q.Status.Hard[v1.ResourceName(name)]
+		used, ok := q.Status.Used[v1.ResourceName(name)]
+		if !ok || hard.IsZero() {
+			continue
+		}
+		percent := float64(used.MilliValue()) / float64(hard.MilliValue()) * 100
+		if percent < threshold {
+			continue
+		}
+		exhausted = exhausted || used.Cmp(hard) >= 0
+		over = append(over, fmt.Sprintf("%s %s/%s (%.0f%%)", name, used.String(), hard.String(), percent))
+	}
+	// A quota rejecting pods is a problem even below the threshold (a
+	// single large pod may not fit)
+	if len(over) == 0 && len(rejections) == 0 {
+		return types.Issue{}, false
+	}
+
+	reason := "QuotaNearlyExhausted"
+	if exhausted || len(rejections) > 0 {
+		reason = "QuotaExhausted"
+	}
+	var rootCause string
+	if len(over) > 0 {
+		rootCause = fmt.Sprintf("ResourceQuota đã dùng trên %.0f%%: %s.", threshold, strings.Join(over, ", "))
+	} else {
+		rootCause = "ResourceQuota không đủ chỗ cho pod mới."
+	}
+	if len(rejections) > 0 {
+		rootCause += fmt.Sprintf(" %d lần tạo pod bị từ chối do vượt quota (%s) — pod mới không được tạo.", len(rejections), strings.Join(unique(rejections), ", "))
+	}
+	return types.Issue{
+		Kind:       "ResourceQuota",
+		Namespace:  q.Namespace,
+		Name:       q.Name,
+		Reason:     reason,
+		Severity:   severity.FromReason(reason),
+		RootCause:  rootCause,
+		Suggestion: "kubectl describe resourcequota " + q.Name + " -n " + q.Namespace + "; tăng quota hoặc giảm requests/replicas",
+	}, true
+}
+
+// unique returns the distinct values of s, sorted
+func unique(s []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, v := range s {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
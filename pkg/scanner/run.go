@@ -0,0 +1,831 @@
+// Package scanner is the library entrypoint of k8s-scanner.
+//
+// Other Go programs can embed the scanner by passing any kubernetes.Interface
+// (a real clientset or k8s.io/client-go/kubernetes/fake) to Run:
+//
+//	client, _ := k8s.NewK8sClient("")
+//	res, err := scanner.Run(ctx, scanner.Options{
+//		Client:     client,
+//		Namespaces: []string{"default"},
+//	})
+//	for _, issue := range res.Issues {
+//		fmt.Println(issue.Namespace, issue.Name, issue.Reason)
+//	}
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/cloud"
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+	"github.com/ductnn/k8s-scanner/pkg/plugin"
+	"github.com/ductnn/k8s-scanner/pkg/policy"
+	"github.com/ductnn/k8s-scanner/pkg/registry"
+	"github.com/ductnn/k8s-scanner/pkg/report"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/capacity"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/controlplane"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/custom"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/gc"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/gitops"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/karpenter"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/mesh"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/pod"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/quota"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/spec"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/topology"
+	"github.com/ductnn/k8s-scanner/pkg/tracing"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+	"github.com/ductnn/k8s-scanner/pkg/version"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DefaultRestartThreshold is used when Options.RestartThreshold is zero
+	DefaultRestartThreshold = 10
+	// DefaultPendingGrace is used when Options.PendingGrace is zero
+	DefaultPendingGrace = 2 * time.Minute
+	// DefaultStartupGrace is used when Options.StartupGrace is zero
+	DefaultStartupGrace = 2 * time.Minute
+	// DefaultTerminatingMargin is used when Options.TerminatingMargin is zero
+	DefaultTerminatingMargin = 5 * time.Minute
+	// DefaultUnreadyAfter is used when Options.UnreadyAfter is zero
+	DefaultUnreadyAfter = 5 * time.Minute
+	// DefaultEventMaxAge is used when Options.EventMaxAge is zero
+	DefaultEventMaxAge = time.Hour
+	// DefaultSlowAPIThreshold is used when Options.SlowAPIThreshold is zero
+	DefaultSlowAPIThreshold = time.Second
+	// DefaultStreamPageSize is the pod page size used with Options.Stream
+	// when Options.PodPageSize is zero
+	DefaultStreamPageSize = 500
+)
+
+// Options configures a scan
+type Options struct {
+	// Client is the Kubernetes API client to scan with (required)
+	Client kubernetes.Interface
+	// Cluster is the cluster name mixed into issue IDs
+	Cluster string
+	// Namespaces to scan; empty means all namespaces
+	Namespaces []string
+	// IgnoredNamespaces are skipped even when they match Namespaces
+	IgnoredNamespaces []string
+	// PodSelector is a label selector (e.g. "app=web,tier!=cache") limiting
+	// the pod scanner to matching pods; empty scans every pod
+	PodSelector string
+	// RestartThreshold is the restart count above which a container is reported
+	RestartThreshold int32
+	// Overrides adjust the pod scanner's thresholds and severities per
+	// namespace or label selector
+	Overrides []pod.Override
+	// RootCauses replace the pod scanner's explanation of reasons
+	RootCauses map[string]pod.RootCause
+	// Reasons limits which issue reasons every scanner reports
+	Reasons pod.ReasonFilter
+	// Dedup selects how findings are aggregated (default: one issue per container)
+	Dedup pod.DedupMode
+	// EscalateAfter raises the severity of containers stuck waiting longer than this (0 disables)
+	EscalateAfter time.Duration
+	// PendingGrace is how long a pod may stay Pending before it is reported
+	PendingGrace time.Duration
+	// StartupGrace is how old a pod must be before containers waiting in
+	// ContainerCreating or PodInitializing are reported; negative reports them at once
+	StartupGrace time.Duration
+	// TerminatingMargin is how long past its deletion deadline a pod may stay Terminating
+	TerminatingMargin time.Duration
+	// UnreadyAfter is how long a Running pod may stay not Ready before it is reported
+	UnreadyAfter time.Duration
+	// NoEvents skips event enrichment entirely, which is faster on clusters
+	// with many events (LastEvent stays empty)
+	NoEvents bool
+	// EventMaxAge ignores older events when picking an issue's LastEvent;
+	// negative keeps events of any age
+	EventMaxAge time.Duration
+	// Registry, when set, is asked why images of ImagePullBackOff pods
+	// cannot be pulled (missing tag, credentials required, wrong platform)
+	Registry *registry.Client
+	// Capacity enables the metrics-server based capacity scanner; nil disables it
+	Capacity *capacity.Options
+	// GC enables the orphaned/unused resource scanner; nil disables it
+	GC *gc.Options
+	// Priority reports preempted pods and pods pending behind higher
+	// priority workloads; it needs events
+	Priority bool
+	// ControlPlane checks the API server, etcd, scheduler and
+	// controller-manager through their pods, /readyz and componentstatuses
+	ControlPlane bool
+	// Webhooks reports admission webhooks with failurePolicy Fail whose
+	// Service has no ready endpoints
+	Webhooks bool
+	// DNS enables the CoreDNS/kube-dns health scanner; nil disables it
+	DNS *controlplane.DNSOptions
+	// Topology reports Deployments and StatefulSets with every replica on
+	// a single node or in a single zone
+	Topology bool
+	// BestPractices runs the lint checks (probes, resources, image tags,
+	// security context) against the pod templates of live workloads
+	BestPractices bool
+	// Quota enables the ResourceQuota/LimitRange scanner; nil disables it
+	Quota *quota.Options
+	// Policy enables the user-defined rules scanner; nil disables it
+	Policy *policy.Set
+	// CustomResources enables the custom resource scanner; it requires Dynamic
+	CustomResources []custom.Resource
+	// GitOps enables the Argo CD/Flux sync health scanner; it requires Dynamic
+	GitOps bool
+	// Mesh enables the Istio sidecar and mTLS scanner; the mTLS checks
+	// need Dynamic and are skipped without it
+	Mesh bool
+	// Karpenter enables the NodeClaim/NodePool provisioning scanner; it
+	// requires Dynamic
+	Karpenter bool
+	// Cloud, when set, asks the cloud provider for the health of the managed
+	// control plane and node pools (upgrades, degraded node pools) and
+	// reports it in Meta.Managed
+	Cloud *cloud.Cluster
+	// Dynamic is the client used to list CustomResources, GitOps resources,
+	// Istio policies and Karpenter resources
+	Dynamic dynamic.Interface
+	// Plugins are external scanners run alongside the built-in ones
+	Plugins []plugin.Plugin
+	// Kubeconfig is passed to Plugins so they reach the same cluster
+	Kubeconfig string
+	// Preflight checks RBAC permissions with SelfSubjectAccessReviews first and
+	// skips the optional scanners the credentials cannot run, with a warning
+	Preflight bool
+	// Progress, when set, is notified as the scan advances (see the pod.Stage constants)
+	Progress pod.ProgressFunc
+	// PodPageSize, when set, streams pods through the pod scanner one page
+	// at a time to bound memory on very large clusters. The capacity and gc
+	// scanners still need the full pod list.
+	PodPageSize int
+	// Stream, when set, receives issues in batches as they are found (each
+	// pod page, then each optional scanner) instead of collecting them:
+	// Result.Issues is then empty while Result.Summary still counts them.
+	// Batches have IDs assigned but are not sorted; calls are never concurrent.
+	Stream func([]types.Issue)
+	// Baseline, when set, hides the accepted issues it lists from the
+	// results; Meta.Baseline reports how many were hidden and which are gone
+	Baseline *report.BaselineFilter
+	// TeamLabel, when set, attributes issues to the team named by this pod
+	// (or namespace) label; Meta.Teams then reports each team's compliance
+	// with TeamBudgets
+	TeamLabel   string
+	TeamBudgets report.TeamBudgets
+	// LabelKeys are pod (or namespace) labels and annotations copied into
+	// the Labels of issues
+	LabelKeys []string
+	// NodeContext sets the node pool, instance type and zone of the issues
+	// bound to a node, from the node labels
+	NodeContext bool
+	// SlowAPIThreshold is the p95 latency of API read requests above which
+	// the scan warns that the API server is slow; negative never warns
+	SlowAPIThreshold time.Duration
+	// ScannerTimeout bounds how long each scanner may run (0 means no limit).
+	// An optional scanner that times out is reported in Result.Warnings.
+	ScannerTimeout time.Duration
+}
+
+// Result is the outcome of a scan
+type Result struct {
+	Issues  []types.Issue                    `json:"issues"`
+	Summary map[string]types.SeveritySummary `json:"summary"`
+	// Warnings lists the scanners and namespaces that were skipped or incomplete
+	Warnings []string `json:"warnings,omitempty"`
+	// Timings records how long each phase of the scan took. Scanners run
+	// concurrently, so their durations overlap.
+	Timings []PhaseTiming `json:"timings,omitempty"`
+	// Meta describes the scan for the report's meta block
+	Meta report.Meta `json:"meta"`
+}
+
+// PhaseTiming is the duration of one scan phase
+type PhaseTiming struct {
+	Phase    string        `json:"phase"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// Stages reported to Options.Progress by the optional scanners
+const (
+	StageCapacity     = "capacity scan"
+	StageGC           = "gc scan"
+	StageQuota        = "quota scan"
+	StagePriority     = "priority scan"
+	StageTopology     = "topology scan"
+	StageBestPractice = "best-practice scan"
+	StageDNS          = "dns scan"
+	StageControlPlane = "control plane scan"
+	StageWebhooks     = "webhook scan"
+	StagePolicy       = "policy scan"
+	StageCustom       = "custom resource scan"
+	StageGitOps       = "gitops scan"
+	StageMesh         = "mesh scan"
+	StageKarpenter    = "karpenter scan"
+	StageCloud        = "cloud provider check"
+)
+
+// Run scans the cluster according to opts and returns the issues found
+// together with a per-namespace severity summary
+func Run(ctx context.Context, opts Options) (res Result, err error) {
+	if opts.Client == nil {
+		return Result{}, errors.New("scanner: Options.Client is required")
+	}
+	startedAt := time.Now()
+
+	apiLatency := &k8s.LatencyRecorder{}
+	ctx = k8s.WithLatencyRecorder(ctx, apiLatency)
+
+	ctx, span := tracing.Start(ctx, "scan")
+	span.SetAttr("k8s.cluster", opts.Cluster)
+	span.SetAttr("k8s.namespaces", strings.Join(opts.Namespaces, ","))
+	defer func() {
+		span.SetAttr("scanner.issues", len(res.Issues))
+		span.RecordError(err)
+		span.End()
+	}()
+
+	threshold := opts.RestartThreshold
+	if threshold == 0 {
+		threshold = DefaultRestartThreshold
+	}
+
+	pendingGrace := opts.PendingGrace
+	if pendingGrace == 0 {
+		pendingGrace = DefaultPendingGrace
+	}
+
+	startupGrace := opts.StartupGrace
+	if startupGrace == 0 {
+		startupGrace = DefaultStartupGrace
+	} else if startupGrace < 0 {
+		startupGrace = 0
+	}
+
+	terminatingMargin := opts.TerminatingMargin
+	if terminatingMargin == 0 {
+		terminatingMargin = DefaultTerminatingMargin
+	}
+
+	// Scanners run concurrently, so serialize progress notifications
+	progress := syncProgress(opts.Progress)
+
+	unreadyAfter := opts.UnreadyAfter
+	if unreadyAfter == 0 {
+		unreadyAfter = DefaultUnreadyAfter
+	}
+
+	eventMaxAge := opts.EventMaxAge
+	if eventMaxAge == 0 {
+		eventMaxAge = DefaultEventMaxAge
+	} else if eventMaxAge < 0 {
+		eventMaxAge = 0
+	}
+
+	var selector labels.Selector
+	if opts.PodSelector != "" {
+		sel, err := labels.Parse(opts.PodSelector)
+		if err != nil {
+			return Result{}, fmt.Errorf("invalid pod selector %q: %w", opts.PodSelector, err)
+		}
+		selector = sel
+	}
+
+	ignored := make(map[string]bool, len(opts.IgnoredNamespaces))
+	for _, ns := range opts.IgnoredNamespaces {
+		ignored[ns] = true
+	}
+
+	podOpts := pod.ScanOptions{
+		RestartThreshold:  threshold,
+		Dedup:             opts.Dedup,
+		EscalateAfter:     opts.EscalateAfter,
+		PendingGrace:      pendingGrace,
+		StartupGrace:      startupGrace,
+		TerminatingMargin: terminatingMargin,
+		UnreadyAfter:      unreadyAfter,
+		NoEvents:          opts.NoEvents,
+		EventMaxAge:       eventMaxAge,
+		Progress:          progress,
+		PageSize:          opts.PodPageSize,
+		Selector:          selector,
+		Overrides:         opts.Overrides,
+		RootCauses:        opts.RootCauses,
+		Reasons:           opts.Reasons,
+		Registry:          opts.Registry,
+		TeamLabel:         opts.TeamLabel,
+		LabelKeys:         opts.LabelKeys,
+	}
+
+	// Pods, events and nodes are listed once and shared by every scanner
+	cs := k8s.NewClusterSnapshot(opts.Client, opts.Namespaces)
+
+	// The pod scanner attributes issues to the team (and labels) of their
+	// pod; the rest fall back to those of their namespace
+	teams := map[string]types.SeveritySummary{}
+	var teamNSErr error
+	annotateTeams := func(issues []types.Issue) {
+		if opts.TeamLabel == "" && len(opts.LabelKeys) == 0 {
+			return
+		}
+		namespaces, err := cs.NamespaceObjects(ctx)
+		teamNSErr = err
+		pod.AnnotateLabels(issues, nil, namespaces, opts.LabelKeys)
+		if opts.TeamLabel != "" {
+			pod.AnnotateTeams(issues, nil, namespaces, opts.TeamLabel)
+			report.CountByTeam(teams, issues)
+		}
+	}
+
+	// Nodes are listed once by the pod scanner; without the permission the
+	// issues are left without node context
+	annotateNodes := func(issues []types.Issue) {
+		if !opts.NodeContext || podOpts.NoNodeConditions {
+			return
+		}
+		if nodes, err := cs.Nodes(ctx); err == nil {
+			pod.AnnotateNodeContext(issues, nodes)
+		}
+	}
+
+	summary := map[string]types.SeveritySummary{}
+	var emit func([]types.Issue)
+	if opts.Stream != nil {
+		var mu sync.Mutex
+		emit = func(batch []types.Issue) {
+			types.AssignIDs(batch, opts.Cluster)
+			mu.Lock()
+			defer mu.Unlock()
+			if opts.Baseline != nil {
+				if batch = opts.Baseline.Apply(batch); len(batch) == 0 {
+					return
+				}
+			}
+			AddToSummary(summary, batch)
+			annotateTeams(batch)
+			annotateNodes(batch)
+			opts.Stream(batch)
+		}
+		podOpts.OnIssues = emit
+		if podOpts.PageSize == 0 {
+			podOpts.PageSize = DefaultStreamPageSize
+		}
+	}
+
+	var timings []PhaseTiming
+	phase := func(name string, start time.Time) {
+		timings = append(timings, PhaseTiming{Phase: name, Duration: time.Since(start)})
+	}
+
+	var warnings []string
+	if opts.Preflight {
+		start := time.Now()
+		_, preflightSpan := tracing.Start(ctx, "preflight")
+		access := k8s.CheckAccess(ctx, opts.Client, opts.Namespaces, preflightScanners(opts)...)
+		preflightSpan.End()
+		if !k8s.Allowed(access, "pods") {
+			return Result{}, errors.New("scanner: missing permission to list pods (run 'k8s-scanner check-access' for details)")
+		}
+		if !opts.NoEvents && !k8s.Allowed(access, "events") {
+			podOpts.NoEvents = true
+			warnings = append(warnings, "cannot list events: skipping event correlation, LastEvent will be empty")
+		}
+		if !k8s.Allowed(access, "nodes") {
+			podOpts.NoNodeConditions = true
+			warnings = append(warnings, "cannot list nodes: skipping node condition correlation")
+		}
+		if !k8s.Allowed(access, "services") {
+			podOpts.NoServices = true
+			warnings = append(warnings, "cannot list services: impacted services are not reported")
+		}
+		if opts.Capacity != nil && !k8s.Allowed(access, "capacity") {
+			opts.Capacity = nil
+			warnings = append(warnings, "cannot read metrics.k8s.io: skipping the capacity scanner")
+		}
+		if opts.GC != nil && !k8s.Allowed(access, "gc") {
+			opts.GC = nil
+			warnings = append(warnings, "cannot list workloads/config objects: skipping the gc scanner")
+		}
+		if opts.Quota != nil && !k8s.Allowed(access, "quota") {
+			opts.Quota = nil
+			warnings = append(warnings, "cannot list resourcequotas/events: skipping the quota scanner")
+		}
+		if opts.Webhooks && !k8s.Allowed(access, "webhooks") {
+			opts.Webhooks = false
+			warnings = append(warnings, "cannot list webhook configurations: skipping the webhook scanner")
+		}
+		if opts.Topology && !k8s.Allowed(access, "topology") {
+			opts.Topology = false
+			warnings = append(warnings, "cannot list deployments/statefulsets: skipping the topology scanner")
+		}
+		if opts.BestPractices && !k8s.Allowed(access, "best-practices") {
+			opts.BestPractices = false
+			warnings = append(warnings, "cannot list deployments/statefulsets/daemonsets: skipping the best-practice scanner")
+		}
+		if opts.Karpenter && !k8s.Allowed(access, "karpenter") {
+			opts.Karpenter = false
+			warnings = append(warnings, "cannot list karpenter.sh resources: skipping the karpenter scanner")
+		}
+		phase("preflight", start)
+	}
+
+	scanners := []scannerFunc{{
+		name:     "pods",
+		required: true,
+		run: func(ctx context.Context) ([]types.Issue, []string, error) {
+			issues, listErrs, err := pod.ScanCluster(ctx, cs, ignored, podOpts)
+			var warnings []string
+			for _, err := range listErrs {
+				warnings = append(warnings, err.Error())
+			}
+			return issues, warnings, err
+		},
+	}}
+	if opts.Capacity != nil {
+		capOpts := *opts.Capacity
+		scanners = append(scanners, scannerFunc{name: "capacity", stage: StageCapacity, run: func(ctx context.Context) ([]types.Issue, []string, error) {
+			issues, err := capacity.ScanCluster(ctx, cs, ignored, capOpts)
+			return opts.Reasons.Filter(issues), nil, err
+		}})
+	}
+	if opts.GC != nil {
+		gcOpts := *opts.GC
+		scanners = append(scanners, scannerFunc{name: "gc", stage: StageGC, run: func(ctx context.Context) ([]types.Issue, []string, error) {
+			issues, err := gc.ScanCluster(ctx, cs, ignored, gcOpts)
+			return opts.Reasons.Filter(issues), nil, err
+		}})
+	}
+	if opts.Priority {
+		if podOpts.NoEvents {
+			warnings = append(warnings, "events are disabled: preempted pods are not reported")
+		}
+		priorityOpts := pod.PriorityOptions{MaxAge: eventMaxAge, PendingGrace: pendingGrace, NoEvents: podOpts.NoEvents}
+		scanners = append(scanners, scannerFunc{name: "priority", stage: StagePriority, run: func(ctx context.Context) ([]types.Issue, []string, error) {
+			issues, err := pod.ScanPriority(ctx, cs, ignored, priorityOpts)
+			return opts.Reasons.Filter(issues), nil, err
+		}})
+	}
+	if opts.ControlPlane {
+		scanners = append(scanners, scannerFunc{name: "control plane", stage: StageControlPlane, run: func(ctx context.Context) ([]types.Issue, []string, error) {
+			issues, warnings, err := controlplane.ScanComponents(ctx, cs)
+			return opts.Reasons.Filter(issues), warnings, err
+		}})
+	}
+	if opts.Webhooks {
+		scanners = append(scanners, scannerFunc{name: "webhooks", stage: StageWebhooks, run: func(ctx context.Context) ([]types.Issue, []string, error) {
+			issues, err := controlplane.ScanWebhooks(ctx, cs, ignored)
+			return opts.Reasons.Filter(issues), nil, err
+		}})
+	}
+	if opts.DNS != nil {
+		dnsOpts := *opts.DNS
+		if dnsOpts.EventMaxAge == 0 {
+			dnsOpts.EventMaxAge = eventMaxAge
+		}
+		scanners = append(scanners, scannerFunc{name: "dns", stage: StageDNS, run: func(ctx context.Context) ([]types.Issue, []string, error) {
+			issues, warnings, err := controlplane.ScanDNS(ctx, cs, dnsOpts)
+			return opts.Reasons.Filter(issues), warnings, err
+		}})
+	}
+
+	if opts.Topology {
+		scanners = append(scanners, scannerFunc{name: "topology", stage: StageTopology, run: func(ctx context.Context) ([]types.Issue, []string, error) {
+			issues, err := topology.ScanCluster(ctx, cs, ignored)
+			return opts.Reasons.Filter(issues), nil, err
+		}})
+	}
+	if opts.BestPractices {
+		scanners = append(scanners, scannerFunc{name: "best-practices", stage: StageBestPractice, run: func(ctx context.Context) ([]types.Issue, []string, error) {
+			issues, err := spec.ScanCluster(ctx, cs, ignored)
+			return opts.Reasons.Filter(issues), nil, err
+		}})
+	}
+
+	if opts.Quota != nil {
+		quotaOpts := *opts.Quota
+		scanners = append(scanners, scannerFunc{name: "quota", stage: StageQuota, run: func(ctx context.Context) ([]types.Issue, []string, error) {
+			issues, err := quota.ScanCluster(ctx, cs, ignored, quotaOpts)
+			return opts.Reasons.Filter(issues), nil, err
+		}})
+	}
+
+	if opts.Policy != nil {
+		set := opts.Policy
+		scanners = append(scanners, scannerFunc{name: "policy", stage: StagePolicy, run: func(ctx context.Context) ([]types.Issue, []string, error) {
+			issues, warnings, err := policy.ScanCluster(ctx, cs, ignored, set)
+			return opts.Reasons.Filter(issues), warnings, err
+		}})
+	}
+
+	if (len(opts.CustomResources) > 0 || opts.GitOps || opts.Karpenter) && opts.Dynamic == nil {
+		return Result{}, errors.New("scanner: Options.Dynamic is required with CustomResources, GitOps and Karpenter")
+	}
+	if len(opts.CustomResources) > 0 {
+		resources := opts.CustomResources
+		scanners = append(scanners, scannerFunc{name: "custom resources", stage: StageCustom, run: func(ctx context.Context) ([]types.Issue, []string, error) {
+			issues, warnings, err := custom.ScanCluster(ctx, opts.Dynamic, opts.Namespaces, ignored, resources)
+			return opts.Reasons.Filter(issues), warnings, err
+		}})
+	}
+
+	if opts.Mesh {
+		scanners = append(scanners, scannerFunc{name: "mesh", stage: StageMesh, run: func(ctx context.Context) ([]types.Issue, []string, error) {
+			issues, warnings, err := mesh.ScanCluster(ctx, cs, opts.Dynamic, ignored)
+			return opts.Reasons.Filter(issues), warnings, err
+		}})
+	}
+
+	if opts.Karpenter {
+		scanners = append(scanners, scannerFunc{name: "karpenter", stage: StageKarpenter, run: func(ctx context.Context) ([]types.Issue, []string, error) {
+			issues, warnings, err := karpenter.ScanCluster(ctx, opts.Dynamic)
+			return opts.Reasons.Filter(issues), warnings, err
+		}})
+	}
+
+	// The managed cluster status goes to the report metadata, not issues
+	var managed *cloud.Status
+	if opts.Cloud != nil {
+		scanners = append(scanners, scannerFunc{name: "cloud", stage: StageCloud, run: func(ctx context.Context) ([]types.Issue, []string, error) {
+			status, err := opts.Cloud.Check(ctx)
+			managed = status
+			return nil, nil, err
+		}})
+	}
+
+	// GitOps findings are correlated with the pod issues once every scanner
+	// has finished, so the scanner only records them
+	var (
+		gitopsFindings []gitops.Finding
+		podIssues      []types.Issue
+	)
+	if opts.GitOps {
+		scanners = append(scanners, scannerFunc{name: "gitops", stage: StageGitOps, run: func(ctx context.Context) ([]types.Issue, []string, error) {
+			findings, warnings, err := gitops.ScanCluster(ctx, opts.Dynamic, opts.Namespaces, ignored)
+			gitopsFindings = findings
+			return nil, warnings, err
+		}})
+		if podOpts.OnIssues != nil {
+			// Streamed pod issues are not returned, keep what correlation needs
+			onIssues := podOpts.OnIssues
+			podOpts.OnIssues = func(batch []types.Issue) {
+				for _, is := range batch {
+					podIssues = append(podIssues, types.Issue{Kind: is.Kind, Namespace: is.Namespace, Reason: is.Reason})
+				}
+				onIssues(batch)
+			}
+		}
+	}
+
+	for _, p := range opts.Plugins {
+		req := plugin.Request{
+			Cluster:           opts.Cluster,
+			Kubeconfig:        opts.Kubeconfig,
+			Namespaces:        opts.Namespaces,
+			IgnoredNamespaces: opts.IgnoredNamespaces,
+		}
+		scanners = append(scanners, scannerFunc{name: "plugin " + p.Name, stage: "plugin " + p.Name, run: func(ctx context.Context) ([]types.Issue, []string, error) {
+			resp, err := p.Run(ctx, req)
+			if err != nil {
+				return nil, nil, err
+			}
+			issues := slices.DeleteFunc(resp.Issues, func(is types.Issue) bool { return ignored[is.Namespace] })
+			warnings := make([]string, len(resp.Warnings))
+			for i, w := range resp.Warnings {
+				warnings[i] = "plugin " + p.Name + ": " + w
+			}
+			return opts.Reasons.Filter(issues), warnings, nil
+		}})
+	}
+
+	if emit != nil {
+		// The pod scanner streams through podOpts.OnIssues; the others emit
+		// their issues when they finish
+		for i := range scanners[1:] {
+			run := scanners[i+1].run
+			scanners[i+1].run = func(ctx context.Context) ([]types.Issue, []string, error) {
+				issues, warnings, err := run(ctx)
+				if err == nil && len(issues) > 0 {
+					emit(issues)
+				}
+				return nil, warnings, err
+			}
+		}
+	}
+
+	results := runScanners(ctx, scanners, opts.ScannerTimeout, progress)
+	var issues []types.Issue
+	for i, r := range results {
+		sc := scanners[i]
+		timings = append(timings, PhaseTiming{Phase: sc.name, Duration: r.duration})
+		warnings = append(warnings, r.warnings...)
+		if r.err != nil {
+			if sc.required {
+				return Result{}, r.err
+			}
+			// Keep the other scanners' results; the failure is reported as a warning
+			warnings = append(warnings, fmt.Sprintf("%s scan failed: %v", sc.name, r.err))
+			continue
+		}
+		issues = append(issues, r.issues...)
+	}
+	if opts.Karpenter && emit == nil {
+		// Streamed Pending pods are sent before Karpenter is scanned
+		karpenter.CorrelatePending(issues)
+	}
+	if len(gitopsFindings) > 0 {
+		if emit == nil {
+			podIssues = issues
+		}
+		found := opts.Reasons.Filter(gitops.Correlate(gitopsFindings, podIssues))
+		if emit != nil {
+			if len(found) > 0 {
+				emit(found)
+			}
+		} else {
+			issues = append(issues, found...)
+		}
+	}
+
+	types.AssignIDs(issues, opts.Cluster)
+	if opts.Baseline != nil {
+		issues = opts.Baseline.Apply(issues)
+	}
+	report.SortIssues(issues)
+	AddToSummary(summary, issues)
+	annotateTeams(issues)
+	annotateNodes(issues)
+	if teamNSErr != nil {
+		warnings = append(warnings, fmt.Sprintf("teams: cannot list namespaces, issues outside labelled pods are unassigned or unlabelled: %v", teamNSErr))
+	}
+
+	var latency *report.APILatency
+	if n := apiLatency.Count(); n > 0 {
+		p50, p95 := apiLatency.Percentile(50), apiLatency.Percentile(95)
+		latency = &report.APILatency{Requests: n, P50MS: millis(p50), P95MS: millis(p95)}
+		slow := opts.SlowAPIThreshold
+		if slow == 0 {
+			slow = DefaultSlowAPIThreshold
+		}
+		if slow > 0 && p95 > slow {
+			warnings = append(warnings, fmt.Sprintf("API server is slow: p95 latency %s over %d requests (p50 %s); findings may reflect a degraded control plane",
+				p95.Round(time.Millisecond), n, p50.Round(time.Millisecond)))
+		}
+	}
+
+	meta := report.Meta{
+		Cluster:           opts.Cluster,
+		KubernetesVersion: ServerVersion(opts.Client),
+		ScannerVersion:    version.Version,
+		ScannerCommit:     version.Get().Commit,
+		Source:            "cluster",
+		StartedAt:         startedAt.Format(time.RFC3339),
+		DurationMS:        time.Since(startedAt).Milliseconds(),
+		Namespaces:        opts.Namespaces,
+		IgnoredNamespaces: opts.IgnoredNamespaces,
+		Errors:            warnings,
+		APILatency:        latency,
+		Managed:           managed,
+	}
+	if opts.Baseline != nil {
+		meta.Baseline = opts.Baseline.Info()
+	}
+	if opts.TeamLabel != "" {
+		meta.Teams = report.CheckTeams(teams, opts.TeamBudgets)
+	}
+	if meta.Namespaces == nil {
+		meta.Namespaces = []string{}
+	}
+	for _, t := range timings {
+		meta.Timings = append(meta.Timings, report.ScannerTiming{Scanner: t.Phase, DurationMS: t.Duration.Milliseconds()})
+	}
+
+	return Result{
+		Issues:   issues,
+		Summary:  summary,
+		Warnings: warnings,
+		Timings:  timings,
+		Meta:     meta,
+	}, nil
+}
+
+// millis converts d to fractional milliseconds
+func millis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// scannerFunc is one scanner run by Run
+type scannerFunc struct {
+	name string
+	// stage is reported to Options.Progress around run; the pod scanner
+	// reports its own stages
+	stage string
+	// required scanners fail the whole scan; the others only add a warning
+	required bool
+	run      func(ctx context.Context) ([]types.Issue, []string, error)
+}
+
+// scannerResult is the outcome of one scannerFunc
+type scannerResult struct {
+	issues   []types.Issue
+	warnings []string
+	err      error
+	duration time.Duration
+}
+
+// runScanners runs the scanners concurrently, each with its own timeout, and
+// returns their results in the same order
+func runScanners(ctx context.Context, scanners []scannerFunc, timeout time.Duration, progress pod.ProgressFunc) []scannerResult {
+	results := make([]scannerResult, len(scanners))
+	var wg sync.WaitGroup
+	for i, sc := range scanners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scanCtx := ctx
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				scanCtx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			start := time.Now()
+			if sc.stage != "" {
+				progress.Report(sc.stage, 0, 1)
+			}
+			scanCtx, span := tracing.Start(scanCtx, "scanner "+sc.name)
+			issues, warnings, err := sc.run(scanCtx)
+			if err != nil && errors.Is(scanCtx.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf("timed out after %s: %w", timeout, err)
+			}
+			span.SetAttr("scanner.issues", len(issues))
+			span.RecordError(err)
+			span.End()
+			if sc.stage != "" {
+				progress.Report(sc.stage, 1, 1)
+			}
+			results[i] = scannerResult{issues: issues, warnings: warnings, err: err, duration: time.Since(start)}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// syncProgress wraps f so that it is never called concurrently
+func syncProgress(f pod.ProgressFunc) pod.ProgressFunc {
+	if f == nil {
+		return nil
+	}
+	var mu sync.Mutex
+	return func(stage string, done, total int) {
+		mu.Lock()
+		defer mu.Unlock()
+		f(stage, done, total)
+	}
+}
+
+// ServerVersion returns the Kubernetes version of the API server, or "" when
+// it cannot be determined
+func ServerVersion(client kubernetes.Interface) string {
+	info, err := client.Discovery().ServerVersion()
+	if err != nil {
+		return ""
+	}
+	return info.GitVersion
+}
+
+// preflightScanners returns the scanners enabled by opts
+func preflightScanners(opts Options) []string {
+	scanners := []string{"pods", "nodes", "services"}
+	if !opts.NoEvents {
+		scanners = append(scanners, "events")
+	}
+	if opts.Capacity != nil {
+		scanners = append(scanners, "capacity")
+	}
+	if opts.GC != nil {
+		scanners = append(scanners, "gc")
+	}
+	if opts.Quota != nil {
+		scanners = append(scanners, "quota")
+	}
+	if opts.Topology {
+		scanners = append(scanners, "topology")
+	}
+	if opts.BestPractices {
+		scanners = append(scanners, "best-practices")
+	}
+	if opts.Webhooks {
+		scanners = append(scanners, "webhooks")
+	}
+	if opts.Karpenter {
+		scanners = append(scanners, "karpenter")
+	}
+	return scanners
+}
@@ -0,0 +1,171 @@
+package scanner_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ductnn/k8s-scanner/pkg/scanner"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/pod"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/scannertest"
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/snapshot"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// clusterOptions scans a fake cluster with one pod of each kind of failure
+// and a healthy pod
+func clusterOptions() scanner.Options {
+	crash := scannertest.CrashLoopPod("shop", "web-0", 12)
+	client := scannertest.NewClient(
+		crash,
+		scannertest.PodEvent(crash, v1.EventTypeWarning, "BackOff", "Back-off restarting failed container"),
+		scannertest.ImagePullBackOffPod("shop", "api-0"),
+		scannertest.RunningPod("shop", "cache-0"),
+		scannertest.OOMKilledPod("batch", "report-0", 3),
+		scannertest.EvictedPod("batch", "report-1"),
+	)
+	return scanner.Options{Client: client, Cluster: "prod", Preflight: true}
+}
+
+// byName indexes issues by namespace/name
+func byName(issues []types.Issue) map[string]types.Issue {
+	out := make(map[string]types.Issue, len(issues))
+	for _, i := range issues {
+		out[i.Namespace+"/"+i.Name] = i
+	}
+	return out
+}
+
+func TestRun(t *testing.T) {
+	res, err := scanner.Run(context.Background(), clusterOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Warnings) > 0 {
+		t.Errorf("warnings: %v", res.Warnings)
+	}
+
+	want := map[string]struct {
+		reason string
+		level  severity.Level
+	}{
+		"shop/web-0":     {"CrashLoopBackOff", severity.High},
+		"shop/api-0":     {"ImagePullBackOff", severity.Critical},
+		"batch/report-0": {"OOMKilled", severity.Medium},
+		"batch/report-1": {"Evicted", severity.Medium},
+	}
+	got := byName(res.Issues)
+	if len(res.Issues) != len(want) {
+		t.Errorf("got %d issues, want %d: %+v", len(res.Issues), len(want), res.Issues)
+	}
+	for name, w := range want {
+		issue, ok := got[name]
+		if !ok {
+			t.Errorf("%s: no issue", name)
+			continue
+		}
+		if issue.Reason != w.reason || issue.Severity != w.level {
+			t.Errorf("%s: got %s/%s, want %s/%s", name, issue.Reason, issue.Severity, w.reason, w.level)
+		}
+		if issue.ID != types.Fingerprint("prod", issue) {
+			t.Errorf("%s: ID %q is not the fingerprint of the issue", name, issue.ID)
+		}
+	}
+	if e := got["shop/web-0"].LastEvent; e != "Back-off restarting failed container" {
+		t.Errorf("LastEvent = %q, want the BackOff event", e)
+	}
+
+	wantSummary := map[string]types.SeveritySummary{
+		"shop":  {Critical: 1, High: 1},
+		"batch": {Medium: 2},
+	}
+	for ns, w := range wantSummary {
+		if res.Summary[ns] != w {
+			t.Errorf("summary of %s = %+v, want %+v", ns, res.Summary[ns], w)
+		}
+	}
+}
+
+func TestRunScope(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  func(*scanner.Options)
+		names []string
+	}{
+		{"namespaces", func(o *scanner.Options) { o.Namespaces = []string{"batch"} }, []string{"batch/report-0", "batch/report-1"}},
+		{"ignored namespaces", func(o *scanner.Options) { o.IgnoredNamespaces = []string{"batch"} }, []string{"shop/api-0", "shop/web-0"}},
+		{"reasons", func(o *scanner.Options) { o.Reasons = pod.ReasonFilter{Only: []string{"OOMKilled"}} }, []string{"batch/report-0"}},
+		{"ignored reasons", func(o *scanner.Options) {
+			o.Reasons = pod.ReasonFilter{Ignore: []string{"Evicted", "OOMKilled", "ImagePullBackOff"}}
+		}, []string{"shop/web-0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := clusterOptions()
+			tt.opts(&opts)
+			res, err := scanner.Run(context.Background(), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := byName(res.Issues)
+			if len(got) != len(tt.names) {
+				t.Errorf("got %d issues, want %v: %+v", len(got), tt.names, res.Issues)
+			}
+			for _, name := range tt.names {
+				if _, ok := got[name]; !ok {
+					t.Errorf("%s: no issue", name)
+				}
+			}
+		})
+	}
+}
+
+func TestRunNoEvents(t *testing.T) {
+	opts := clusterOptions()
+	opts.NoEvents = true
+	res, err := scanner.Run(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, issue := range res.Issues {
+		if issue.LastEvent != "" {
+			t.Errorf("%s/%s: LastEvent %q with NoEvents", issue.Namespace, issue.Name, issue.LastEvent)
+		}
+	}
+}
+
+func TestRunStream(t *testing.T) {
+	opts := clusterOptions()
+	var streamed []types.Issue
+	opts.Stream = func(batch []types.Issue) { streamed = append(streamed, batch...) }
+	opts.PodPageSize = 2
+	res, err := scanner.Run(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Issues) != 0 {
+		t.Errorf("Result.Issues has %d issues while streaming", len(res.Issues))
+	}
+	if len(streamed) != 4 {
+		t.Errorf("streamed %d issues, want 4", len(streamed))
+	}
+	if res.Summary["shop"].Critical != 1 || res.Summary["batch"].Medium != 2 {
+		t.Errorf("summary = %+v, want the streamed issues counted", res.Summary)
+	}
+}
+
+func TestRunFromSnapshot(t *testing.T) {
+	snap := &snapshot.Snapshot{Pods: []v1.Pod{
+		*scannertest.CrashLoopPod("shop", "web-0", 12),
+		*scannertest.RunningPod("shop", "web-1"),
+	}}
+	res, err := scanner.Run(context.Background(), scanner.Options{Client: scannertest.FromSnapshot(snap), Preflight: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Issues) != 1 || res.Issues[0].Name != "web-0" {
+		t.Errorf("issues = %+v, want web-0 only", res.Issues)
+	}
+}
@@ -0,0 +1,139 @@
+// Package scannertest provides a fake-clientset based harness for exercising
+// the scanner without a real cluster. It is intended for tests and demos:
+//
+//	client := scannertest.NewClient(
+//		scannertest.CrashLoopPod("default", "web-1", 12),
+//		scannertest.RunningPod("default", "web-2"),
+//	)
+//	res, _ := scanner.Run(ctx, scanner.Options{Client: client})
+package scannertest
+
+import (
+	"github.com/ductnn/k8s-scanner/pkg/snapshot"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// NewClient returns a fake clientset pre-populated with the given objects.
+// SelfSubjectAccessReviews are always allowed so preflight checks pass.
+func NewClient(objects ...runtime.Object) kubernetes.Interface {
+	client := fake.NewClientset(objects...)
+	client.PrependReactor("create", "selfsubjectaccessreviews", AllowAll)
+	return client
+}
+
+// AllowAll is a fake reactor answering every SelfSubjectAccessReview with allowed
+func AllowAll(action k8stesting.Action) (bool, runtime.Object, error) {
+	create, ok := action.(k8stesting.CreateAction)
+	if !ok {
+		return false, nil, nil
+	}
+	review, ok := create.GetObject().(*authorizationv1.SelfSubjectAccessReview)
+	if !ok {
+		return false, nil, nil
+	}
+	out := review.DeepCopy()
+	out.Status.Allowed = true
+	return true, out, nil
+}
+
+// FromSnapshot returns a fake clientset populated with a recorded snapshot
+func FromSnapshot(snap *snapshot.Snapshot) kubernetes.Interface {
+	objects := make([]runtime.Object, 0, len(snap.Pods)+len(snap.Events)+len(snap.Nodes))
+	for i := range snap.Pods {
+		objects = append(objects, &snap.Pods[i])
+	}
+	for i := range snap.Events {
+		objects = append(objects, &snap.Events[i])
+	}
+	for i := range snap.Nodes {
+		objects = append(objects, &snap.Nodes[i])
+	}
+	return NewClient(objects...)
+}
+
+// RunningPod returns a healthy running pod with a single container
+func RunningPod(namespace, name string) *v1.Pod {
+	return newPod(namespace, name, v1.PodRunning, v1.ContainerState{
+		Running: &v1.ContainerStateRunning{},
+	}, 0)
+}
+
+// CrashLoopPod returns a pod whose container is in CrashLoopBackOff
+func CrashLoopPod(namespace, name string, restarts int32) *v1.Pod {
+	return WaitingPod(namespace, name, "CrashLoopBackOff", restarts)
+}
+
+// ImagePullBackOffPod returns a pod whose container cannot pull its image
+func ImagePullBackOffPod(namespace, name string) *v1.Pod {
+	return WaitingPod(namespace, name, "ImagePullBackOff", 0)
+}
+
+// OOMKilledPod returns a pod whose container was terminated with OOMKilled
+func OOMKilledPod(namespace, name string, restarts int32) *v1.Pod {
+	return newPod(namespace, name, v1.PodRunning, v1.ContainerState{
+		Terminated: &v1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137},
+	}, restarts)
+}
+
+// EvictedPod returns a failed pod that was evicted from its node
+func EvictedPod(namespace, name string) *v1.Pod {
+	p := newPod(namespace, name, v1.PodFailed, v1.ContainerState{}, 0)
+	p.Status.Reason = "Evicted"
+	p.Status.Message = "The node was low on resource: memory."
+	return p
+}
+
+// WaitingPod returns a pod whose container is waiting with the given reason
+func WaitingPod(namespace, name, reason string, restarts int32) *v1.Pod {
+	return newPod(namespace, name, v1.PodRunning, v1.ContainerState{
+		Waiting: &v1.ContainerStateWaiting{Reason: reason},
+	}, restarts)
+}
+
+// PodEvent returns a core/v1 event attached to the given pod
+func PodEvent(pod *v1.Pod, eventType, reason, message string) *v1.Event {
+	return &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: pod.Namespace,
+			Name:      pod.Name + "." + reason,
+		},
+		InvolvedObject: v1.ObjectReference{
+			Kind:      "Pod",
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+		},
+		Type:          eventType,
+		Reason:        reason,
+		Message:       message,
+		LastTimestamp: metav1.Now(),
+	}
+}
+
+func newPod(namespace, name string, phase v1.PodPhase, state v1.ContainerState, restarts int32) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         namespace,
+			Name:              name,
+			CreationTimestamp: metav1.Now(),
+		},
+		Spec: v1.PodSpec{
+			NodeName:   "node-1",
+			Containers: []v1.Container{{Name: "app", Image: "nginx:1.27"}},
+		},
+		Status: v1.PodStatus{
+			Phase: phase,
+			ContainerStatuses: []v1.ContainerStatus{{
+				Name:         "app",
+				State:        state,
+				RestartCount: restarts,
+			}},
+		},
+	}
+}
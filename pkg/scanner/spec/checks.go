@@ -0,0 +1,149 @@
+package spec
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// Workload identifies the object a pod spec belongs to
+type Workload struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// CheckPodSpec runs the spec-based best-practice checks (probes, resources,
+// image policy, security context) against a pod template and returns issues
+func CheckPodSpec(w Workload, podSpec v1.PodSpec) []types.Issue {
+	issues := make([]types.Issue, 0)
+	timestamp := time.Now().Format(time.RFC3339)
+
+	for _, c := range podSpec.Containers {
+		for _, reason := range checkContainer(c, podSpec.SecurityContext) {
+			issues = append(issues, createIssue(w, c.Name, reason, timestamp))
+		}
+	}
+
+	return issues
+}
+
+// checkContainer returns the reasons a single container fails the checks
+func checkContainer(c v1.Container, podSC *v1.PodSecurityContext) []string {
+	var reasons []string
+
+	// Probes
+	if c.LivenessProbe == nil {
+		reasons = append(reasons, "MissingLivenessProbe")
+	}
+	if c.ReadinessProbe == nil {
+		reasons = append(reasons, "MissingReadinessProbe")
+	}
+
+	// Requests/limits
+	if c.Resources.Requests.Cpu().IsZero() || c.Resources.Requests.Memory().IsZero() {
+		reasons = append(reasons, "MissingResourceRequests")
+	}
+	if c.Resources.Limits.Memory().IsZero() {
+		reasons = append(reasons, "MissingResourceLimits")
+	}
+
+	// Image policy
+	if usesLatestTag(c.Image) {
+		reasons = append(reasons, "ImageTagLatest")
+	}
+
+	// Security context
+	sc := c.SecurityContext
+	if sc != nil && sc.Privileged != nil && *sc.Privileged {
+		reasons = append(reasons, "PrivilegedContainer")
+	}
+	if sc == nil || sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+		reasons = append(reasons, "PrivilegeEscalationAllowed")
+	}
+	if !runsAsNonRoot(sc, podSC) {
+		reasons = append(reasons, "RunAsRootAllowed")
+	}
+
+	return reasons
+}
+
+// usesLatestTag reports whether the image is untagged or tagged "latest"
+// Images pinned by digest are always accepted
+func usesLatestTag(image string) bool {
+	if strings.Contains(image, "@") {
+		return false
+	}
+	// Only look at the last path segment so registry ports (host:5000/app) are ignored
+	name := image[strings.LastIndex(image, "/")+1:]
+	idx := strings.LastIndex(name, ":")
+	if idx == -1 {
+		return true
+	}
+	return name[idx+1:] == "latest"
+}
+
+// runsAsNonRoot reports whether runAsNonRoot is enabled at container or pod level
+func runsAsNonRoot(sc *v1.SecurityContext, podSC *v1.PodSecurityContext) bool {
+	if sc != nil && sc.RunAsNonRoot != nil {
+		return *sc.RunAsNonRoot
+	}
+	if podSC != nil && podSC.RunAsNonRoot != nil {
+		return *podSC.RunAsNonRoot
+	}
+	return false
+}
+
+// createIssue creates an Issue for a spec check finding
+func createIssue(w Workload, container string, reason string, timestamp string) types.Issue {
+	return types.Issue{
+		Kind:      w.Kind,
+		Namespace: w.Namespace,
+		Name:      w.Name,
+		Container: container,
+		Severity:  severity.FromReason(reason),
+		Reason:    reason,
+		RootCause: RootCauseFromReason(reason),
+		Timestamp: timestamp,
+	}
+}
+
+// Severity is the severity level of a spec check finding
+//
+// Deprecated: use severity.Level. This alias will be removed in the next release.
+type Severity = severity.Level
+
+// SeverityFromReason maps a spec check reason to severity level
+//
+// Deprecated: use severity.FromReason. This wrapper will be removed in the next release.
+func SeverityFromReason(reason string) Severity {
+	return severity.FromReason(reason)
+}
+
+// RootCauseFromReason returns a human-readable explanation for a spec check reason
+func RootCauseFromReason(reason string) string {
+	switch reason {
+	case "MissingLivenessProbe":
+		return "Container không có livenessProbe — kubelet không thể tự restart khi app bị treo."
+	case "MissingReadinessProbe":
+		return "Container không có readinessProbe — traffic có thể được gửi tới pod khi app chưa sẵn sàng."
+	case "MissingResourceRequests":
+		return "Container không khai báo resources.requests (CPU/RAM) — scheduler không thể xếp pod hợp lý."
+	case "MissingResourceLimits":
+		return "Container không khai báo memory limit — có thể chiếm hết bộ nhớ của node."
+	case "ImageTagLatest":
+		return "Image dùng tag latest hoặc không có tag — phiên bản deploy không cố định."
+	case "PrivilegedContainer":
+		return "Container chạy ở chế độ privileged — có toàn quyền trên node."
+	case "PrivilegeEscalationAllowed":
+		return "allowPrivilegeEscalation không được tắt — process có thể leo thang đặc quyền."
+	case "RunAsRootAllowed":
+		return "runAsNonRoot không được bật — container có thể chạy với user root."
+	default:
+		return "Chưa xác định."
+	}
+}
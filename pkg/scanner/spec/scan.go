@@ -0,0 +1,54 @@
+package spec
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ScanCluster runs the best-practice checks against the pod templates of the
+// Deployments, StatefulSets and DaemonSets of the snapshot's namespaces (all
+// namespaces when it is not scoped)
+func ScanCluster(ctx context.Context, cs *k8s.ClusterSnapshot, ignoredNamespaces map[string]bool) ([]types.Issue, error) {
+	client := cs.Client()
+	listIn := cs.ScopedNamespaces()
+	if len(listIn) == 0 {
+		listIn = []string{metav1.NamespaceAll}
+	}
+
+	issues := make([]types.Issue, 0)
+	for _, ns := range listIn {
+		deployments, err := client.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deployments: %w", err)
+		}
+		for _, d := range deployments.Items {
+			if !ignoredNamespaces[d.Namespace] {
+				issues = append(issues, CheckPodSpec(Workload{"Deployment", d.Namespace, d.Name}, d.Spec.Template.Spec)...)
+			}
+		}
+		statefulSets, err := client.AppsV1().StatefulSets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+		}
+		for _, s := range statefulSets.Items {
+			if !ignoredNamespaces[s.Namespace] {
+				issues = append(issues, CheckPodSpec(Workload{"StatefulSet", s.Namespace, s.Name}, s.Spec.Template.Spec)...)
+			}
+		}
+		daemonSets, err := client.AppsV1().DaemonSets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+		}
+		for _, d := range daemonSets.Items {
+			if !ignoredNamespaces[d.Namespace] {
+				issues = append(issues, CheckPodSpec(Workload{"DaemonSet", d.Namespace, d.Name}, d.Spec.Template.Spec)...)
+			}
+		}
+	}
+	return issues, nil
+}
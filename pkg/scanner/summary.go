@@ -1,9 +1,18 @@
 package scanner
 
-import "github.com/ductnn/k8s-scanner/pkg/types"
+import (
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
 
 func SummarizeByNamespace(issues []types.Issue) map[string]types.SeveritySummary {
 	result := map[string]types.SeveritySummary{}
+	AddToSummary(result, issues)
+	return result
+}
+
+// AddToSummary counts issues into an existing per-namespace summary
+func AddToSummary(result map[string]types.SeveritySummary, issues []types.Issue) {
 
 	for _, iss := range issues {
 		ns := iss.Namespace
@@ -15,18 +24,18 @@ func SummarizeByNamespace(issues []types.Issue) map[string]types.SeveritySummary
 		summary := result[ns]
 
 		switch iss.Severity {
-		case "critical":
+		case severity.Critical:
 			summary.Critical++
-		case "high":
+		case severity.High:
 			summary.High++
-		case "medium":
+		case severity.Medium:
 			summary.Medium++
+		case severity.Info:
+			summary.Info++
 		default:
 			summary.Low++
 		}
 
 		result[ns] = summary
 	}
-
-	return result
 }
@@ -0,0 +1,257 @@
+// Package topology reports Deployments and StatefulSets whose replicas all
+// run on a single node or in a single zone, so losing that node or zone
+// takes the whole workload down.
+package topology
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// zoneLabels are the node labels holding the zone, newest first
+var zoneLabels = []string{"topology.kubernetes.io/zone", "failure-domain.beta.kubernetes.io/zone"}
+
+// workload is the part of a Deployment or StatefulSet the checks need
+type workload struct {
+	kind      string
+	namespace string
+	name      string
+	replicas  int32
+	selector  *metav1.LabelSelector
+	template  v1.PodSpec
+}
+
+// ScanCluster checks the Deployments and StatefulSets of the snapshot's
+// namespaces (all namespaces when it is not scoped). Workloads with fewer
+// than two running replicas are skipped, and so are the single-node and
+// single-zone checks on clusters with only one node or zone.
+func ScanCluster(ctx context.Context, cs *k8s.ClusterSnapshot, ignoredNamespaces map[string]bool) ([]types.Issue, error) {
+	client := cs.Client()
+	listIn := cs.ScopedNamespaces()
+	if len(listIn) == 0 {
+		listIn = []string{metav1.NamespaceAll}
+	}
+
+	var workloads []workload
+	for _, ns := range listIn {
+		deployments, err := client.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deployments: %w", err)
+		}
+		for _, d := range deployments.Items {
+			workloads = append(workloads, fromDeployment(d))
+		}
+		statefulSets, err := client.AppsV1().StatefulSets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+		}
+		for _, s := range statefulSets.Items {
+			workloads = append(workloads, fromStatefulSet(s))
+		}
+	}
+
+	pods, _, err := cs.Pods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := cs.Nodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	zoneOf := make(map[string]string, len(nodes))
+	zones := map[string]bool{}
+	schedulable := 0
+	for _, n := range nodes {
+		if z := nodeZone(&n); z != "" {
+			zoneOf[n.Name] = z
+			zones[z] = true
+		}
+		if !n.Spec.Unschedulable {
+			schedulable++
+		}
+	}
+
+	byNamespace := map[string][]*v1.Pod{}
+	for i := range pods {
+		p := &pods[i]
+		if p.Spec.NodeName == "" || p.DeletionTimestamp != nil || p.Status.Phase != v1.PodRunning {
+			continue
+		}
+		byNamespace[p.Namespace] = append(byNamespace[p.Namespace], p)
+	}
+
+	timestamp := time.Now().Format(time.RFC3339)
+	var issues []types.Issue
+	for _, w := range workloads {
+		if ignoredNamespaces[w.namespace] || w.replicas < 2 {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(w.selector)
+		if err != nil || selector.Empty() {
+			continue
+		}
+		issue, ok := check(w, selectPods(byNamespace[w.namespace], selector), zoneOf, schedulable, len(zones))
+		if ok {
+			issue.Timestamp = timestamp
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+func fromDeployment(d appsv1.Deployment) workload {
+	return workload{
+		kind:      "Deployment",
+		namespace: d.Namespace,
+		name:      d.Name,
+		replicas:  replicasOf(d.Spec.Replicas),
+		selector:  d.Spec.Selector,
+		template:  d.Spec.Template.Spec,
+	}
+}
+
+func fromStatefulSet(s appsv1.StatefulSet) workload {
+	return workload{
+		kind:      "StatefulSet",
+		namespace: s.Namespace,
+		name:      s.Name,
+		replicas:  replicasOf(s.Spec.Replicas),
+		selector:  s.Spec.Selector,
+		template:  s.Spec.Template.Spec,
+	}
+}
+
+// replicasOf applies the API default of one replica
+func replicasOf(r *int32) int32 {
+	if r == nil {
+		return 1
+	}
+	return *r
+}
+
+func nodeZone(n *v1.Node) string {
+	for _, l := range zoneLabels {
+		if z := n.Labels[l]; z != "" {
+			return z
+		}
+	}
+	return ""
+}
+
+func selectPods(pods []*v1.Pod, selector labels.Selector) []*v1.Pod {
+	var out []*v1.Pod
+	for _, p := range pods {
+		if selector.Matches(labels.Set(p.Labels)) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// check reports a workload whose running replicas share one node, or one
+// zone of a multi-zone cluster
+func check(w workload, pods []*v1.Pod, zoneOf map[string]string, schedulableNodes, zoneCount int) (types.Issue, bool) {
+	if len(pods) < 2 {
+		return types.Issue{}, false
+	}
+	nodes := map[string]bool{}
+	podZones := map[string]bool{}
+	for _, p := range pods {
+		nodes[p.Spec.NodeName] = true
+		if z := zoneOf[p.Spec.NodeName]; z != "" {
+			podZones[z] = true
+		}
+	}
+
+	var reason, rootCause string
+	switch {
+	case len(nodes) == 1 && schedulableNodes > 1:
+		reason = "ReplicasOnSingleNode"
+		rootCause = fmt.Sprintf("Cả %d replica đang chạy trên cùng node %s — node đó gặp sự cố thì toàn bộ %s ngừng hoạt động.", len(pods), pods[0].Spec.NodeName, w.kind)
+	case len(podZones) == 1 && zoneCount > 1:
+		reason = "ReplicasInSingleZone"
+		rootCause = fmt.Sprintf("Cả %d replica đang chạy trong cùng zone %s (cluster có %d zone) — mất zone đó thì toàn bộ %s ngừng hoạt động.", len(pods), keys(podZones)[0], zoneCount, w.kind)
+	default:
+		return types.Issue{}, false
+	}
+	rootCause += " " + describeSpread(w.template, reason)
+
+	return types.Issue{
+		Kind:       w.kind,
+		Namespace:  w.namespace,
+		Name:       w.name,
+		Reason:     reason,
+		Severity:   severity.FromReason(reason),
+		RootCause:  rootCause,
+		Suggestion: suggestion(reason),
+	}, true
+}
+
+// describeSpread explains which spreading constraints the pod template
+// declares for the topology of reason
+func describeSpread(spec v1.PodSpec, reason string) string {
+	topologyKey := v1.LabelHostname
+	if reason == "ReplicasInSingleZone" {
+		topologyKey = v1.LabelTopologyZone
+	}
+
+	var hard, soft bool
+	for _, c := range spec.TopologySpreadConstraints {
+		if c.TopologyKey != topologyKey {
+			continue
+		}
+		if c.WhenUnsatisfiable == v1.DoNotSchedule {
+			hard = true
+		} else {
+			soft = true
+		}
+	}
+	if a := spec.Affinity; a != nil && a.PodAntiAffinity != nil {
+		for _, t := range a.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+			if t.TopologyKey == topologyKey {
+				hard = true
+			}
+		}
+		for _, t := range a.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			if t.PodAffinityTerm.TopologyKey == topologyKey {
+				soft = true
+			}
+		}
+	}
+
+	switch {
+	case hard:
+		return fmt.Sprintf("Pod template có ràng buộc bắt buộc theo %s nhưng replica vẫn bị dồn lại (label selector của ràng buộc không khớp pod?).", topologyKey)
+	case soft:
+		return fmt.Sprintf("Pod template chỉ có ràng buộc mềm (ScheduleAnyway/preferred) theo %s nên scheduler được phép dồn replica.", topologyKey)
+	default:
+		return fmt.Sprintf("Pod template không có topologySpreadConstraints hay podAntiAffinity theo %s.", topologyKey)
+	}
+}
+
+func suggestion(reason string) string {
+	if reason == "ReplicasInSingleZone" {
+		return "Thêm topologySpreadConstraints với topologyKey: " + v1.LabelTopologyZone + " (whenUnsatisfiable: DoNotSchedule nếu cần đảm bảo)"
+	}
+	return "Thêm topologySpreadConstraints với topologyKey: " + v1.LabelHostname + " hoặc podAntiAffinity, rồi rollout restart để phân bố lại"
+}
+
+func keys(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
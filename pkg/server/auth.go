@@ -0,0 +1,70 @@
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// TokenEnv holds the API token when no token file is given
+const TokenEnv = "K8S_SCANNER_TOKEN"
+
+// LoadToken reads the API token from path, or else from $K8S_SCANNER_TOKEN.
+// An empty token leaves the server open.
+func LoadToken(path string) (string, error) {
+	if path == "" {
+		return strings.TrimSpace(os.Getenv(TokenEnv)), nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+	return token, nil
+}
+
+// RequireToken rejects requests without "Authorization: Bearer <token>",
+// except the health probes. The dashboard may also pass the token once as ?token=, which it then sends
+// with its API calls.
+func RequireToken(token string, next http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/healthz", "/readyz":
+			next.ServeHTTP(w, r)
+			return
+		}
+		got := r.Header.Get("Authorization")
+		if got == "" && strings.HasPrefix(r.URL.Path, "/ui") {
+			// Static files only: the API calls carry the header
+			if t := r.URL.Query().Get("token"); t != "" {
+				got = "Bearer " + t
+			}
+		}
+		if subtle.ConstantTimeCompare([]byte(got), want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="k8s-scanner"`)
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// IsLoopback reports whether a listen address only accepts local clients
+func IsLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
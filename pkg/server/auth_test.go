@@ -0,0 +1,67 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireToken(t *testing.T) {
+	h := RequireToken("s3cret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	tests := []struct {
+		name   string
+		target string
+		auth   string
+		want   int
+	}{
+		{"no token", "/api/v1/scan", "", http.StatusUnauthorized},
+		{"wrong token", "/api/v1/scan", "Bearer nope", http.StatusUnauthorized},
+		{"not bearer", "/api/v1/scan", "s3cret", http.StatusUnauthorized},
+		{"bearer token", "/api/v1/scan", "Bearer s3cret", http.StatusNoContent},
+		{"metrics too", "/metrics", "", http.StatusUnauthorized},
+		{"healthz is open", "/healthz", "", http.StatusNoContent},
+		{"readyz is open", "/readyz", "", http.StatusNoContent},
+		{"query token on ui", "/ui/?token=s3cret", "", http.StatusNoContent},
+		{"query token on api", "/api/v1/reports?token=s3cret", "", http.StatusUnauthorized},
+		{"wrong query token on ui", "/ui/?token=nope", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("missing WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"localhost:8080", true},
+		{"127.0.0.1:8080", true},
+		{"[::1]:8080", true},
+		{":8080", false},
+		{"0.0.0.0:8080", false},
+		{"10.0.0.5:8080", false},
+		{"scanner.example.com:8080", false},
+		{"localhost", false},
+	}
+	for _, tt := range tests {
+		if got := IsLoopback(tt.addr); got != tt.want {
+			t.Errorf("IsLoopback(%q) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/notify"
+	"github.com/ductnn/k8s-scanner/pkg/report"
+	"github.com/ductnn/k8s-scanner/pkg/scanner"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DigestFile keeps the last digest in Outdir, so the trend survives restarts
+const DigestFile = "last-digest.json"
+
+// DigestOptions configures the scheduled digest notifications
+type DigestOptions struct {
+	// Schedule is when digests are sent
+	Schedule *notify.Schedule
+	// Notifiers receive the digest of the issues no route matches (all of
+	// them without routes)
+	Notifiers []notify.Notifier
+	// Teams route the issues of each team (Issue.Team) to its own notifiers.
+	// Namespaces annotated with notify.NotifyAnnotation are routed too,
+	// ahead of their team.
+	Teams map[string][]notify.Notifier
+	// Top is the number of namespaces listed (default notify.DefaultTopOffenders)
+	Top int
+}
+
+// RunDigests sends a digest of the latest scan to the notifiers on every
+// tick of the schedule until the context is cancelled. Digests do not scan:
+// they summarize what the last scheduled or triggered scan found. Each
+// route gets a digest of its own issues, with its own trend.
+func (s *Server) RunDigests(ctx context.Context, opts DigestOptions) {
+	top := opts.Top
+	if top <= 0 {
+		top = notify.DefaultTopOffenders
+	}
+	previous := map[string]*notify.Digest{}
+	if d := s.loadDigest(""); d != nil {
+		previous[""] = d
+	}
+
+	for {
+		next := opts.Schedule.Next(time.Now())
+		if next.IsZero() {
+			log.Printf("serve: digest schedule %q never fires", opts.Schedule)
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		data := s.latest()
+		if data == nil {
+			log.Printf("serve: no scan to send a digest of yet")
+			continue
+		}
+		router := &notify.Router{Default: opts.Notifiers, Teams: opts.Teams, Namespaces: s.namespaceRoutes(ctx)}
+		for key, routed := range splitByRoute(data, router) {
+			if _, ok := previous[key]; !ok && key != "" {
+				previous[key] = s.loadDigest(key)
+			}
+			digest := notify.BuildDigest(routed.data, previous[key], top, time.Now())
+			digest.Owner = key
+			msg := digest.Message()
+			for _, n := range routed.notifiers {
+				if err := n.Send(ctx, msg); err != nil {
+					log.Printf("serve: %s digest: %v", n.Name(), err)
+				}
+			}
+			previous[key] = digest
+			if path := s.digestPath(key); path != "" {
+				if err := notify.SaveDigest(path, digest); err != nil {
+					log.Printf("serve: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// routedReport is the part of a report sent to one route
+type routedReport struct {
+	notifiers []notify.Notifier
+	data      *report.ReportData
+}
+
+// splitByRoute divides the issues of a report between the routes. The
+// default route always gets a digest, even without issues, as before routes
+// existed.
+func splitByRoute(data *report.ReportData, router *notify.Router) map[string]routedReport {
+	issues := map[string][]types.Issue{"": {}}
+	notifiers := map[string][]notify.Notifier{"": router.Default}
+	for _, is := range data.Issues {
+		route := router.RouteOf(is.Namespace, is.Team)
+		issues[route.Key] = append(issues[route.Key], is)
+		notifiers[route.Key] = route.Notifiers
+	}
+	out := make(map[string]routedReport, len(issues))
+	for key, list := range issues {
+		part := *data
+		part.Issues = list
+		part.Summary = scanner.SummarizeByNamespace(list)
+		if key == "" && len(list) == len(data.Issues) {
+			// Nothing routed elsewhere: keep the summary of streamed scans
+			part.Summary = data.Summary
+		}
+		out[key] = routedReport{notifiers: notifiers[key], data: &part}
+	}
+	return out
+}
+
+// namespaceRoutes reads the notifiers annotated on namespaces, best effort
+func (s *Server) namespaceRoutes(ctx context.Context) map[string][]notify.Notifier {
+	if s.cfg.Scan.Client == nil {
+		return nil
+	}
+	list, err := s.cfg.Scan.Client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("serve: digest routes: cannot list namespaces: %v", err)
+		return nil
+	}
+	routes, errs := notify.NamespaceRoutes(list.Items)
+	for _, err := range errs {
+		log.Printf("serve: digest routes: %v", err)
+	}
+	return routes
+}
+
+// digestPath is where the last digest of a route is kept: DigestFile for
+// the default route, last-digest-<route>.json for the others
+func (s *Server) digestPath(key string) string {
+	if s.cfg.Outdir == "" {
+		return ""
+	}
+	if key == "" {
+		return filepath.Join(s.cfg.Outdir, DigestFile)
+	}
+	name := strings.NewReplacer(":", "-", "/", "-").Replace(key)
+	return filepath.Join(s.cfg.Outdir, "last-digest-"+name+".json")
+}
+
+func (s *Server) loadDigest(key string) *notify.Digest {
+	path := s.digestPath(key)
+	if path == "" {
+		return nil
+	}
+	d, err := notify.LoadDigest(path)
+	if err != nil {
+		log.Printf("serve: %v", err)
+	}
+	return d
+}
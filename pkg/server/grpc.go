@@ -0,0 +1,182 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/report"
+	"github.com/ductnn/k8s-scanner/pkg/server/scannerpb"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// GRPC returns a gRPC server with the Scanner service (see
+// scannerpb/scanner.proto), which serves the same scans and reports as the
+// HTTP API. A non-empty token is required as "authorization: Bearer
+// <token>" metadata, like RequireToken.
+func (s *Server) GRPC(token string) *grpc.Server {
+	var opts []grpc.ServerOption
+	if token != "" {
+		want := []byte("Bearer " + token)
+		opts = append(opts, grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			if got := md.Get("authorization"); len(got) != 1 || subtle.ConstantTimeCompare([]byte(got[0]), want) != 1 {
+				return nil, status.Error(codes.Unauthenticated, "missing or invalid bearer token")
+			}
+			return handler(ctx, req)
+		}))
+	}
+	g := grpc.NewServer(opts...)
+	scannerpb.RegisterScannerServer(g, &grpcService{s: s})
+	return g
+}
+
+// grpcService implements scannerpb.ScannerServer
+type grpcService struct {
+	scannerpb.UnimplementedScannerServer
+	s *Server
+}
+
+func (g *grpcService) GetLatestReport(ctx context.Context, _ *scannerpb.GetLatestReportRequest) (*scannerpb.Report, error) {
+	latest := g.s.latest()
+	if latest == nil {
+		return nil, status.Error(codes.NotFound, "no scan has completed yet")
+	}
+	out := &scannerpb.Report{
+		GeneratedAt: latest.GeneratedAt,
+		Issues:      toPBIssues(latest.Issues),
+		Summary:     toPBSummaries(latest.Summary),
+	}
+	if latest.Meta != nil {
+		out.Cluster = latest.Meta.Cluster
+	}
+	return out, nil
+}
+
+func (g *grpcService) ListReports(ctx context.Context, req *scannerpb.ListReportsRequest) (*scannerpb.ListReportsResponse, error) {
+	// No reports exported yet lists nothing
+	reports, _ := report.ListHistory(g.s.cfg.Outdir)
+	out := &scannerpb.ListReportsResponse{}
+	for _, info := range report.FilterHistory(reports, report.HistoryFilter{Cluster: req.GetCluster()}) {
+		out.Reports = append(out.Reports, &scannerpb.ReportInfo{
+			Name:        info.DirName,
+			Cluster:     info.Cluster,
+			GeneratedAt: info.GeneratedAt.Format(time.RFC3339),
+			IssueCount:  int32(info.IssueCount),
+			Summary:     toPBSummaries(info.Summary),
+		})
+	}
+	return out, nil
+}
+
+func (g *grpcService) Diff(ctx context.Context, req *scannerpb.DiffRequest) (*scannerpb.DiffResult, error) {
+	diff, err := g.s.diff(req.GetOld(), req.GetNew(), req.GetCrossCluster())
+	if errors.Is(err, errCrossCluster) {
+		return nil, status.Error(codes.InvalidArgument, err.Error()+"; set cross_cluster to compare them")
+	}
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	out := &scannerpb.DiffResult{
+		OldCluster:     diff.OldCluster,
+		NewCluster:     diff.NewCluster,
+		NewIssues:      toPBIssues(diff.NewIssues),
+		ResolvedIssues: toPBIssues(diff.ResolvedIssues),
+	}
+	for _, c := range diff.ChangedIssues {
+		out.ChangedIssues = append(out.ChangedIssues, &scannerpb.IssueChange{
+			OldIssue: toPBIssue(c.OldIssue),
+			NewIssue: toPBIssue(c.NewIssue),
+			Changes:  c.Changes,
+		})
+	}
+	for _, d := range diff.Namespaces {
+		out.NamespaceDeltas = append(out.NamespaceDeltas, &scannerpb.NamespaceDelta{Namespace: d.Namespace, Delta: toPBSummary(d.Delta)})
+	}
+	return out, nil
+}
+
+func (g *grpcService) TriggerScan(ctx context.Context, req *scannerpb.TriggerScanRequest) (*scannerpb.TriggerScanResponse, error) {
+	if sel := req.GetSelector(); sel != "" {
+		if _, err := labels.Parse(sel); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid selector %q: %v", sel, err)
+		}
+	}
+	res, err := g.s.Scan(ctx, ScanRequest{Namespaces: req.GetNamespaces(), Selector: req.GetSelector()})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &scannerpb.TriggerScanResponse{
+		Issues:   toPBIssues(res.Issues),
+		Summary:  toPBSummaries(res.Summary),
+		Warnings: res.Warnings,
+	}, nil
+}
+
+func toPBIssues(issues []types.Issue) []*scannerpb.Issue {
+	out := make([]*scannerpb.Issue, len(issues))
+	for i, issue := range issues {
+		out[i] = toPBIssue(issue)
+	}
+	return out
+}
+
+func toPBIssue(i types.Issue) *scannerpb.Issue {
+	return &scannerpb.Issue{
+		Id:               i.ID,
+		Kind:             i.Kind,
+		Namespace:        i.Namespace,
+		Name:             i.Name,
+		Container:        i.Container,
+		Severity:         string(i.Severity),
+		Reason:           i.Reason,
+		RootCause:        i.RootCause,
+		Suggestion:       i.Suggestion,
+		PodStatus:        i.PodStatus,
+		Timestamp:        i.Timestamp,
+		NodeName:         i.NodeName,
+		NodeCondition:    i.NodeCondition,
+		RestartCount:     i.RestartCount,
+		LastEvent:        i.LastEvent,
+		InStateSince:     i.InStateSince,
+		FirstSeen:        i.FirstSeen,
+		LastSeen:         i.LastSeen,
+		PriorityClass:    i.PriorityClass,
+		ImpactedServices: i.ImpactedServices,
+		Team:             i.Team,
+		Image:            i.Image,
+		Labels:           i.Labels,
+		CheckId:          i.CheckID,
+		NodePool:         i.NodePool,
+		InstanceType:     i.InstanceType,
+		Zone:             i.Zone,
+	}
+}
+
+func toPBSummaries(summary map[string]types.SeveritySummary) map[string]*scannerpb.Summary {
+	out := make(map[string]*scannerpb.Summary, len(summary))
+	for ns, s := range summary {
+		out[ns] = toPBSummary(s)
+	}
+	return out
+}
+
+func toPBSummary(s types.SeveritySummary) *scannerpb.Summary {
+	return &scannerpb.Summary{
+		Critical: int32(s.Critical),
+		High:     int32(s.High),
+		Medium:   int32(s.Medium),
+		Low:      int32(s.Low),
+		Info:     int32(s.Info),
+	}
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/k8s"
+	"github.com/ductnn/k8s-scanner/pkg/report"
+	"github.com/ductnn/k8s-scanner/pkg/scanner"
+	"github.com/ductnn/k8s-scanner/pkg/types"
+)
+
+// IncrementalOptions configures incremental scheduled scans
+type IncrementalOptions struct {
+	// FullRescanEvery forces a scan of every namespace this often, which
+	// also catches changes the watches cannot see (e.g. node conditions)
+	FullRescanEvery time.Duration
+}
+
+// incremental is the state kept between incremental cycles
+type incremental struct {
+	opts     IncrementalOptions
+	changes  *k8s.ChangeWatcher
+	lastFull time.Time
+	issues   map[string][]types.Issue // namespace -> issues found by its latest scan
+}
+
+// startIncremental starts watching for changes. Without watch permission it
+// returns nil and RunEvery falls back to full scans.
+func (s *Server) startIncremental(ctx context.Context) *incremental {
+	changes := k8s.NewChangeWatcher(s.cfg.Scan.Client, s.cfg.Scan.Namespaces)
+	if err := changes.Start(ctx); err != nil {
+		log.Printf("serve: cannot watch pods and events, scanning every namespace each cycle: %v", err)
+		return nil
+	}
+	return &incremental{opts: *s.cfg.Incremental, changes: changes}
+}
+
+// scanIncremental rescans the namespaces that changed since the previous
+// cycle and merges their issues with the ones kept for the others
+func (s *Server) scanIncremental(ctx context.Context, inc *incremental) {
+	changed, lost := inc.changes.Changes()
+	full := lost || inc.issues == nil || time.Since(inc.lastFull) >= inc.opts.FullRescanEvery
+	if !full && len(changed) == 0 {
+		log.Printf("serve: no changes since the last scan, skipping")
+		return
+	}
+
+	req := ScanRequest{}
+	if !full {
+		req.Namespaces = changed
+	}
+	started := time.Now()
+	res, err := s.scan(ctx, req, func(res scanner.Result) scanner.Result {
+		if full {
+			inc.issues = make(map[string][]types.Issue)
+			inc.lastFull = started
+		} else {
+			for _, ns := range changed {
+				delete(inc.issues, ns)
+			}
+		}
+		inScope := make(map[string]bool, len(changed))
+		for _, ns := range changed {
+			inScope[ns] = true
+		}
+		for _, issue := range res.Issues {
+			// A partial scan may also report cluster-scoped findings, which
+			// are kept from the last full scan instead
+			if full || inScope[issue.Namespace] {
+				inc.issues[issue.Namespace] = append(inc.issues[issue.Namespace], issue)
+			}
+		}
+
+		var merged []types.Issue
+		for _, issues := range inc.issues {
+			merged = append(merged, issues...)
+		}
+		report.SortIssues(merged)
+		res.Issues = merged
+		res.Summary = scanner.SummarizeByNamespace(merged)
+		if res.Meta.Teams != nil {
+			counts := map[string]types.SeveritySummary{}
+			report.CountByTeam(counts, merged)
+			res.Meta.Teams = report.CheckTeams(counts, s.cfg.Scan.TeamBudgets)
+		}
+		if !full {
+			res.Meta.Namespaces = s.cfg.Scan.Namespaces
+			if res.Meta.Namespaces == nil {
+				res.Meta.Namespaces = []string{}
+			}
+		}
+		return res
+	})
+	if err != nil {
+		log.Printf("serve: scheduled scan failed: %v", err)
+		if !full {
+			// Retry these namespaces with a full scan next cycle
+			inc.issues = nil
+		}
+		return
+	}
+
+	if full {
+		log.Printf("serve: full scan found %d issue(s)", len(res.Issues))
+	} else {
+		log.Printf("serve: rescanned %d changed namespace(s), %d issue(s) in total", len(changed), len(res.Issues))
+	}
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/report"
+)
+
+// handleReports implements GET /api/v1/reports: the exported JSON reports,
+// newest first
+func (s *Server) handleReports(w http.ResponseWriter, r *http.Request) {
+	reports, err := report.ListHistory(s.cfg.Outdir)
+	if err != nil {
+		// No reports exported yet
+		reports = []report.ReportInfo{}
+	}
+	writeJSON(w, http.StatusOK, reports)
+}
+
+// handleLatest implements GET /api/v1/reports/latest
+func (s *Server) handleLatest(w http.ResponseWriter, r *http.Request) {
+	latest := s.latest()
+	if latest == nil {
+		writeError(w, http.StatusNotFound, "no scan has completed yet")
+		return
+	}
+	writeJSON(w, http.StatusOK, latest)
+}
+
+// handleReport implements GET /api/v1/reports/{name}; name is the path of
+// the report in the reports directory and may contain slashes
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	data, err := s.loadReport(r.PathValue("name"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, data)
+}
+
+// handleDiff implements GET /api/v1/diff?old=<name>&new=<name>. new defaults
+// to the latest scan and old to the newest exported report of the same
+// cluster before it. Reports of different clusters are only compared with
+// cross_cluster=true.
+func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	diff, err := s.diff(q.Get("old"), q.Get("new"), q.Get("cross_cluster") == "true")
+	switch {
+	case errors.Is(err, errCrossCluster):
+		writeError(w, http.StatusBadRequest, err.Error()+"; add cross_cluster=true to compare them")
+	case err != nil:
+		writeError(w, http.StatusNotFound, err.Error())
+	default:
+		writeJSON(w, http.StatusOK, diff)
+	}
+}
+
+// errCrossCluster is returned by diff for reports of different clusters
+var errCrossCluster = errors.New("reports are from different clusters")
+
+// diff compares two reports by name, as handleDiff describes. Errors other
+// than errCrossCluster mean a report was not found.
+func (s *Server) diff(oldName, newName string, crossCluster bool) (*report.DiffResult, error) {
+	var newReport *report.ReportData
+	if newName == "" || newName == "latest" {
+		newReport = s.latest()
+		if newReport == nil {
+			return nil, errors.New("no scan has completed yet")
+		}
+	} else {
+		var err error
+		if newReport, err = s.loadReport(newName); err != nil {
+			return nil, err
+		}
+	}
+
+	newCluster := report.ClusterOf(newName, newReport)
+	if oldName == "" {
+		reports, _ := report.ListHistory(s.cfg.Outdir)
+		for _, info := range report.FilterHistory(reports, report.HistoryFilter{Cluster: newCluster}) {
+			if info.DirName != newName && info.GeneratedAt.Format(time.RFC3339) != newReport.GeneratedAt {
+				oldName = info.DirName
+				break
+			}
+		}
+		if oldName == "" {
+			return nil, errors.New("no earlier report to compare with")
+		}
+	}
+	oldReport, err := s.loadReport(oldName)
+	if err != nil {
+		return nil, err
+	}
+	if oldCluster := report.ClusterOf(oldName, oldReport); oldCluster != "" && newCluster != "" && oldCluster != newCluster && !crossCluster {
+		return nil, fmt.Errorf("%w (%s, %s)", errCrossCluster, oldCluster, newCluster)
+	}
+	return report.DiffReports(oldReport, newReport), nil
+}
+
+// loadReport loads an exported report by file name. Only names listed by
+// report.ListHistory are accepted, so requests cannot read other files.
+func (s *Server) loadReport(name string) (*report.ReportData, error) {
+	reports, _ := report.ListHistory(s.cfg.Outdir)
+	for _, info := range reports {
+		if info.DirName == name {
+			return report.LoadReport(info.Path)
+		}
+	}
+	return nil, fmt.Errorf("report %q not found", name)
+}
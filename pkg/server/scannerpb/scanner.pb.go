@@ -0,0 +1,1204 @@
+// gRPC API of `k8s-scanner serve --grpc-addr`, alongside the HTTP API.
+// Regenerate the Go code with `make proto`.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: scanner.proto
+
+package scannerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Issue struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Kind             string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	Namespace        string                 `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name             string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Container        string                 `protobuf:"bytes,5,opt,name=container,proto3" json:"container,omitempty"`
+	Severity         string                 `protobuf:"bytes,6,opt,name=severity,proto3" json:"severity,omitempty"`
+	Reason           string                 `protobuf:"bytes,7,opt,name=reason,proto3" json:"reason,omitempty"`
+	RootCause        string                 `protobuf:"bytes,8,opt,name=root_cause,json=rootCause,proto3" json:"root_cause,omitempty"`
+	Suggestion       string                 `protobuf:"bytes,9,opt,name=suggestion,proto3" json:"suggestion,omitempty"`
+	PodStatus        string                 `protobuf:"bytes,10,opt,name=pod_status,json=podStatus,proto3" json:"pod_status,omitempty"`
+	Timestamp        string                 `protobuf:"bytes,11,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	NodeName         string                 `protobuf:"bytes,12,opt,name=node_name,json=nodeName,proto3" json:"node_name,omitempty"`
+	NodeCondition    string                 `protobuf:"bytes,13,opt,name=node_condition,json=nodeCondition,proto3" json:"node_condition,omitempty"`
+	RestartCount     int32                  `protobuf:"varint,14,opt,name=restart_count,json=restartCount,proto3" json:"restart_count,omitempty"`
+	LastEvent        string                 `protobuf:"bytes,15,opt,name=last_event,json=lastEvent,proto3" json:"last_event,omitempty"`
+	InStateSince     string                 `protobuf:"bytes,16,opt,name=in_state_since,json=inStateSince,proto3" json:"in_state_since,omitempty"`
+	FirstSeen        string                 `protobuf:"bytes,17,opt,name=first_seen,json=firstSeen,proto3" json:"first_seen,omitempty"`
+	LastSeen         string                 `protobuf:"bytes,18,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	PriorityClass    string                 `protobuf:"bytes,19,opt,name=priority_class,json=priorityClass,proto3" json:"priority_class,omitempty"`
+	ImpactedServices []string               `protobuf:"bytes,20,rep,name=impacted_services,json=impactedServices,proto3" json:"impacted_services,omitempty"`
+	Team             string                 `protobuf:"bytes,21,opt,name=team,proto3" json:"team,omitempty"`
+	Image            string                 `protobuf:"bytes,22,opt,name=image,proto3" json:"image,omitempty"`
+	Labels           map[string]string      `protobuf:"bytes,23,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	CheckId          string                 `protobuf:"bytes,24,opt,name=check_id,json=checkId,proto3" json:"check_id,omitempty"`
+	NodePool         string                 `protobuf:"bytes,25,opt,name=node_pool,json=nodePool,proto3" json:"node_pool,omitempty"`
+	InstanceType     string                 `protobuf:"bytes,26,opt,name=instance_type,json=instanceType,proto3" json:"instance_type,omitempty"`
+	Zone             string                 `protobuf:"bytes,27,opt,name=zone,proto3" json:"zone,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Issue) Reset() {
+	*x = Issue{}
+	mi := &file_scanner_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Issue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Issue) ProtoMessage() {}
+
+func (x *Issue) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Issue.ProtoReflect.Descriptor instead.
+func (*Issue) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Issue) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Issue) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Issue) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *Issue) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Issue) GetContainer() string {
+	if x != nil {
+		return x.Container
+	}
+	return ""
+}
+
+func (x *Issue) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *Issue) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Issue) GetRootCause() string {
+	if x != nil {
+		return x.RootCause
+	}
+	return ""
+}
+
+func (x *Issue) GetSuggestion() string {
+	if x != nil {
+		return x.Suggestion
+	}
+	return ""
+}
+
+func (x *Issue) GetPodStatus() string {
+	if x != nil {
+		return x.PodStatus
+	}
+	return ""
+}
+
+func (x *Issue) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+func (x *Issue) GetNodeName() string {
+	if x != nil {
+		return x.NodeName
+	}
+	return ""
+}
+
+func (x *Issue) GetNodeCondition() string {
+	if x != nil {
+		return x.NodeCondition
+	}
+	return ""
+}
+
+func (x *Issue) GetRestartCount() int32 {
+	if x != nil {
+		return x.RestartCount
+	}
+	return 0
+}
+
+func (x *Issue) GetLastEvent() string {
+	if x != nil {
+		return x.LastEvent
+	}
+	return ""
+}
+
+func (x *Issue) GetInStateSince() string {
+	if x != nil {
+		return x.InStateSince
+	}
+	return ""
+}
+
+func (x *Issue) GetFirstSeen() string {
+	if x != nil {
+		return x.FirstSeen
+	}
+	return ""
+}
+
+func (x *Issue) GetLastSeen() string {
+	if x != nil {
+		return x.LastSeen
+	}
+	return ""
+}
+
+func (x *Issue) GetPriorityClass() string {
+	if x != nil {
+		return x.PriorityClass
+	}
+	return ""
+}
+
+func (x *Issue) GetImpactedServices() []string {
+	if x != nil {
+		return x.ImpactedServices
+	}
+	return nil
+}
+
+func (x *Issue) GetTeam() string {
+	if x != nil {
+		return x.Team
+	}
+	return ""
+}
+
+func (x *Issue) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *Issue) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Issue) GetCheckId() string {
+	if x != nil {
+		return x.CheckId
+	}
+	return ""
+}
+
+func (x *Issue) GetNodePool() string {
+	if x != nil {
+		return x.NodePool
+	}
+	return ""
+}
+
+func (x *Issue) GetInstanceType() string {
+	if x != nil {
+		return x.InstanceType
+	}
+	return ""
+}
+
+func (x *Issue) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+// Summary counts issues per severity
+type Summary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Critical      int32                  `protobuf:"varint,1,opt,name=critical,proto3" json:"critical,omitempty"`
+	High          int32                  `protobuf:"varint,2,opt,name=high,proto3" json:"high,omitempty"`
+	Medium        int32                  `protobuf:"varint,3,opt,name=medium,proto3" json:"medium,omitempty"`
+	Low           int32                  `protobuf:"varint,4,opt,name=low,proto3" json:"low,omitempty"`
+	Info          int32                  `protobuf:"varint,5,opt,name=info,proto3" json:"info,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Summary) Reset() {
+	*x = Summary{}
+	mi := &file_scanner_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Summary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Summary) ProtoMessage() {}
+
+func (x *Summary) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Summary.ProtoReflect.Descriptor instead.
+func (*Summary) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Summary) GetCritical() int32 {
+	if x != nil {
+		return x.Critical
+	}
+	return 0
+}
+
+func (x *Summary) GetHigh() int32 {
+	if x != nil {
+		return x.High
+	}
+	return 0
+}
+
+func (x *Summary) GetMedium() int32 {
+	if x != nil {
+		return x.Medium
+	}
+	return 0
+}
+
+func (x *Summary) GetLow() int32 {
+	if x != nil {
+		return x.Low
+	}
+	return 0
+}
+
+func (x *Summary) GetInfo() int32 {
+	if x != nil {
+		return x.Info
+	}
+	return 0
+}
+
+type Report struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// RFC 3339
+	GeneratedAt string   `protobuf:"bytes,1,opt,name=generated_at,json=generatedAt,proto3" json:"generated_at,omitempty"`
+	Cluster     string   `protobuf:"bytes,2,opt,name=cluster,proto3" json:"cluster,omitempty"`
+	Issues      []*Issue `protobuf:"bytes,3,rep,name=issues,proto3" json:"issues,omitempty"`
+	// Keyed by namespace
+	Summary       map[string]*Summary `protobuf:"bytes,4,rep,name=summary,proto3" json:"summary,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Report) Reset() {
+	*x = Report{}
+	mi := &file_scanner_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Report) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Report) ProtoMessage() {}
+
+func (x *Report) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Report.ProtoReflect.Descriptor instead.
+func (*Report) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Report) GetGeneratedAt() string {
+	if x != nil {
+		return x.GeneratedAt
+	}
+	return ""
+}
+
+func (x *Report) GetCluster() string {
+	if x != nil {
+		return x.Cluster
+	}
+	return ""
+}
+
+func (x *Report) GetIssues() []*Issue {
+	if x != nil {
+		return x.Issues
+	}
+	return nil
+}
+
+func (x *Report) GetSummary() map[string]*Summary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+type ReportInfo struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the report in the reports directory, as accepted by Diff
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// RFC 3339
+	GeneratedAt   string              `protobuf:"bytes,2,opt,name=generated_at,json=generatedAt,proto3" json:"generated_at,omitempty"`
+	IssueCount    int32               `protobuf:"varint,3,opt,name=issue_count,json=issueCount,proto3" json:"issue_count,omitempty"`
+	Summary       map[string]*Summary `protobuf:"bytes,4,rep,name=summary,proto3" json:"summary,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Cluster       string              `protobuf:"bytes,5,opt,name=cluster,proto3" json:"cluster,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportInfo) Reset() {
+	*x = ReportInfo{}
+	mi := &file_scanner_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportInfo) ProtoMessage() {}
+
+func (x *ReportInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportInfo.ProtoReflect.Descriptor instead.
+func (*ReportInfo) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ReportInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ReportInfo) GetGeneratedAt() string {
+	if x != nil {
+		return x.GeneratedAt
+	}
+	return ""
+}
+
+func (x *ReportInfo) GetIssueCount() int32 {
+	if x != nil {
+		return x.IssueCount
+	}
+	return 0
+}
+
+func (x *ReportInfo) GetSummary() map[string]*Summary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+func (x *ReportInfo) GetCluster() string {
+	if x != nil {
+		return x.Cluster
+	}
+	return ""
+}
+
+type GetLatestReportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLatestReportRequest) Reset() {
+	*x = GetLatestReportRequest{}
+	mi := &file_scanner_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLatestReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLatestReportRequest) ProtoMessage() {}
+
+func (x *GetLatestReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLatestReportRequest.ProtoReflect.Descriptor instead.
+func (*GetLatestReportRequest) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{4}
+}
+
+type ListReportsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only list the reports of this cluster
+	Cluster       string `protobuf:"bytes,1,opt,name=cluster,proto3" json:"cluster,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReportsRequest) Reset() {
+	*x = ListReportsRequest{}
+	mi := &file_scanner_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReportsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReportsRequest) ProtoMessage() {}
+
+func (x *ListReportsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReportsRequest.ProtoReflect.Descriptor instead.
+func (*ListReportsRequest) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListReportsRequest) GetCluster() string {
+	if x != nil {
+		return x.Cluster
+	}
+	return ""
+}
+
+type ListReportsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reports       []*ReportInfo          `protobuf:"bytes,1,rep,name=reports,proto3" json:"reports,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReportsResponse) Reset() {
+	*x = ListReportsResponse{}
+	mi := &file_scanner_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReportsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReportsResponse) ProtoMessage() {}
+
+func (x *ListReportsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReportsResponse.ProtoReflect.Descriptor instead.
+func (*ListReportsResponse) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListReportsResponse) GetReports() []*ReportInfo {
+	if x != nil {
+		return x.Reports
+	}
+	return nil
+}
+
+type DiffRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Report names from ListReports; new defaults to the latest scan and old
+	// to the newest report of the same cluster before it
+	Old string `protobuf:"bytes,1,opt,name=old,proto3" json:"old,omitempty"`
+	New string `protobuf:"bytes,2,opt,name=new,proto3" json:"new,omitempty"`
+	// Allow comparing reports of different clusters
+	CrossCluster  bool `protobuf:"varint,3,opt,name=cross_cluster,json=crossCluster,proto3" json:"cross_cluster,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffRequest) Reset() {
+	*x = DiffRequest{}
+	mi := &file_scanner_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffRequest) ProtoMessage() {}
+
+func (x *DiffRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffRequest.ProtoReflect.Descriptor instead.
+func (*DiffRequest) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DiffRequest) GetOld() string {
+	if x != nil {
+		return x.Old
+	}
+	return ""
+}
+
+func (x *DiffRequest) GetNew() string {
+	if x != nil {
+		return x.New
+	}
+	return ""
+}
+
+func (x *DiffRequest) GetCrossCluster() bool {
+	if x != nil {
+		return x.CrossCluster
+	}
+	return false
+}
+
+type IssueChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OldIssue      *Issue                 `protobuf:"bytes,1,opt,name=old_issue,json=oldIssue,proto3" json:"old_issue,omitempty"`
+	NewIssue      *Issue                 `protobuf:"bytes,2,opt,name=new_issue,json=newIssue,proto3" json:"new_issue,omitempty"`
+	Changes       []string               `protobuf:"bytes,3,rep,name=changes,proto3" json:"changes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IssueChange) Reset() {
+	*x = IssueChange{}
+	mi := &file_scanner_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IssueChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssueChange) ProtoMessage() {}
+
+func (x *IssueChange) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssueChange.ProtoReflect.Descriptor instead.
+func (*IssueChange) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *IssueChange) GetOldIssue() *Issue {
+	if x != nil {
+		return x.OldIssue
+	}
+	return nil
+}
+
+func (x *IssueChange) GetNewIssue() *Issue {
+	if x != nil {
+		return x.NewIssue
+	}
+	return nil
+}
+
+func (x *IssueChange) GetChanges() []string {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+type NamespaceDelta struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Namespace string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// New count minus the old one, per severity
+	Delta         *Summary `protobuf:"bytes,2,opt,name=delta,proto3" json:"delta,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NamespaceDelta) Reset() {
+	*x = NamespaceDelta{}
+	mi := &file_scanner_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NamespaceDelta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NamespaceDelta) ProtoMessage() {}
+
+func (x *NamespaceDelta) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NamespaceDelta.ProtoReflect.Descriptor instead.
+func (*NamespaceDelta) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *NamespaceDelta) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *NamespaceDelta) GetDelta() *Summary {
+	if x != nil {
+		return x.Delta
+	}
+	return nil
+}
+
+type DiffResult struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	NewIssues       []*Issue               `protobuf:"bytes,1,rep,name=new_issues,json=newIssues,proto3" json:"new_issues,omitempty"`
+	ResolvedIssues  []*Issue               `protobuf:"bytes,2,rep,name=resolved_issues,json=resolvedIssues,proto3" json:"resolved_issues,omitempty"`
+	ChangedIssues   []*IssueChange         `protobuf:"bytes,3,rep,name=changed_issues,json=changedIssues,proto3" json:"changed_issues,omitempty"`
+	NamespaceDeltas []*NamespaceDelta      `protobuf:"bytes,4,rep,name=namespace_deltas,json=namespaceDeltas,proto3" json:"namespace_deltas,omitempty"`
+	// Set when the reports come from different clusters
+	OldCluster    string `protobuf:"bytes,5,opt,name=old_cluster,json=oldCluster,proto3" json:"old_cluster,omitempty"`
+	NewCluster    string `protobuf:"bytes,6,opt,name=new_cluster,json=newCluster,proto3" json:"new_cluster,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffResult) Reset() {
+	*x = DiffResult{}
+	mi := &file_scanner_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffResult) ProtoMessage() {}
+
+func (x *DiffResult) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffResult.ProtoReflect.Descriptor instead.
+func (*DiffResult) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DiffResult) GetNewIssues() []*Issue {
+	if x != nil {
+		return x.NewIssues
+	}
+	return nil
+}
+
+func (x *DiffResult) GetResolvedIssues() []*Issue {
+	if x != nil {
+		return x.ResolvedIssues
+	}
+	return nil
+}
+
+func (x *DiffResult) GetChangedIssues() []*IssueChange {
+	if x != nil {
+		return x.ChangedIssues
+	}
+	return nil
+}
+
+func (x *DiffResult) GetNamespaceDeltas() []*NamespaceDelta {
+	if x != nil {
+		return x.NamespaceDeltas
+	}
+	return nil
+}
+
+func (x *DiffResult) GetOldCluster() string {
+	if x != nil {
+		return x.OldCluster
+	}
+	return ""
+}
+
+func (x *DiffResult) GetNewCluster() string {
+	if x != nil {
+		return x.NewCluster
+	}
+	return ""
+}
+
+type TriggerScanRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Replaces the configured namespaces for this scan
+	Namespaces []string `protobuf:"bytes,1,rep,name=namespaces,proto3" json:"namespaces,omitempty"`
+	// Limits the pod scanner to pods matching this label selector
+	Selector      string `protobuf:"bytes,2,opt,name=selector,proto3" json:"selector,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerScanRequest) Reset() {
+	*x = TriggerScanRequest{}
+	mi := &file_scanner_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerScanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerScanRequest) ProtoMessage() {}
+
+func (x *TriggerScanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerScanRequest.ProtoReflect.Descriptor instead.
+func (*TriggerScanRequest) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TriggerScanRequest) GetNamespaces() []string {
+	if x != nil {
+		return x.Namespaces
+	}
+	return nil
+}
+
+func (x *TriggerScanRequest) GetSelector() string {
+	if x != nil {
+		return x.Selector
+	}
+	return ""
+}
+
+type TriggerScanResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Issues        []*Issue               `protobuf:"bytes,1,rep,name=issues,proto3" json:"issues,omitempty"`
+	Summary       map[string]*Summary    `protobuf:"bytes,2,rep,name=summary,proto3" json:"summary,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Warnings      []string               `protobuf:"bytes,3,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerScanResponse) Reset() {
+	*x = TriggerScanResponse{}
+	mi := &file_scanner_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerScanResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerScanResponse) ProtoMessage() {}
+
+func (x *TriggerScanResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_scanner_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerScanResponse.ProtoReflect.Descriptor instead.
+func (*TriggerScanResponse) Descriptor() ([]byte, []int) {
+	return file_scanner_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *TriggerScanResponse) GetIssues() []*Issue {
+	if x != nil {
+		return x.Issues
+	}
+	return nil
+}
+
+func (x *TriggerScanResponse) GetSummary() map[string]*Summary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+func (x *TriggerScanResponse) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+var File_scanner_proto protoreflect.FileDescriptor
+
+const file_scanner_proto_rawDesc = "" +
+	"\n" +
+	"\rscanner.proto\x12\rk8sscanner.v1\"\xf9\x06\n" +
+	"\x05Issue\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x1c\n" +
+	"\tnamespace\x18\x03 \x01(\tR\tnamespace\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12\x1c\n" +
+	"\tcontainer\x18\x05 \x01(\tR\tcontainer\x12\x1a\n" +
+	"\bseverity\x18\x06 \x01(\tR\bseverity\x12\x16\n" +
+	"\x06reason\x18\a \x01(\tR\x06reason\x12\x1d\n" +
+	"\n" +
+	"root_cause\x18\b \x01(\tR\trootCause\x12\x1e\n" +
+	"\n" +
+	"suggestion\x18\t \x01(\tR\n" +
+	"suggestion\x12\x1d\n" +
+	"\n" +
+	"pod_status\x18\n" +
+	" \x01(\tR\tpodStatus\x12\x1c\n" +
+	"\ttimestamp\x18\v \x01(\tR\ttimestamp\x12\x1b\n" +
+	"\tnode_name\x18\f \x01(\tR\bnodeName\x12%\n" +
+	"\x0enode_condition\x18\r \x01(\tR\rnodeCondition\x12#\n" +
+	"\rrestart_count\x18\x0e \x01(\x05R\frestartCount\x12\x1d\n" +
+	"\n" +
+	"last_event\x18\x0f \x01(\tR\tlastEvent\x12$\n" +
+	"\x0ein_state_since\x18\x10 \x01(\tR\finStateSince\x12\x1d\n" +
+	"\n" +
+	"first_seen\x18\x11 \x01(\tR\tfirstSeen\x12\x1b\n" +
+	"\tlast_seen\x18\x12 \x01(\tR\blastSeen\x12%\n" +
+	"\x0epriority_class\x18\x13 \x01(\tR\rpriorityClass\x12+\n" +
+	"\x11impacted_services\x18\x14 \x03(\tR\x10impactedServices\x12\x12\n" +
+	"\x04team\x18\x15 \x01(\tR\x04team\x12\x14\n" +
+	"\x05image\x18\x16 \x01(\tR\x05image\x128\n" +
+	"\x06labels\x18\x17 \x03(\v2 .k8sscanner.v1.Issue.LabelsEntryR\x06labels\x12\x19\n" +
+	"\bcheck_id\x18\x18 \x01(\tR\acheckId\x12\x1b\n" +
+	"\tnode_pool\x18\x19 \x01(\tR\bnodePool\x12#\n" +
+	"\rinstance_type\x18\x1a \x01(\tR\finstanceType\x12\x12\n" +
+	"\x04zone\x18\x1b \x01(\tR\x04zone\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"w\n" +
+	"\aSummary\x12\x1a\n" +
+	"\bcritical\x18\x01 \x01(\x05R\bcritical\x12\x12\n" +
+	"\x04high\x18\x02 \x01(\x05R\x04high\x12\x16\n" +
+	"\x06medium\x18\x03 \x01(\x05R\x06medium\x12\x10\n" +
+	"\x03low\x18\x04 \x01(\x05R\x03low\x12\x12\n" +
+	"\x04info\x18\x05 \x01(\x05R\x04info\"\x85\x02\n" +
+	"\x06Report\x12!\n" +
+	"\fgenerated_at\x18\x01 \x01(\tR\vgeneratedAt\x12\x18\n" +
+	"\acluster\x18\x02 \x01(\tR\acluster\x12,\n" +
+	"\x06issues\x18\x03 \x03(\v2\x14.k8sscanner.v1.IssueR\x06issues\x12<\n" +
+	"\asummary\x18\x04 \x03(\v2\".k8sscanner.v1.Report.SummaryEntryR\asummary\x1aR\n" +
+	"\fSummaryEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12,\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.k8sscanner.v1.SummaryR\x05value:\x028\x01\"\x94\x02\n" +
+	"\n" +
+	"ReportInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12!\n" +
+	"\fgenerated_at\x18\x02 \x01(\tR\vgeneratedAt\x12\x1f\n" +
+	"\vissue_count\x18\x03 \x01(\x05R\n" +
+	"issueCount\x12@\n" +
+	"\asummary\x18\x04 \x03(\v2&.k8sscanner.v1.ReportInfo.SummaryEntryR\asummary\x12\x18\n" +
+	"\acluster\x18\x05 \x01(\tR\acluster\x1aR\n" +
+	"\fSummaryEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12,\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.k8sscanner.v1.SummaryR\x05value:\x028\x01\"\x18\n" +
+	"\x16GetLatestReportRequest\".\n" +
+	"\x12ListReportsRequest\x12\x18\n" +
+	"\acluster\x18\x01 \x01(\tR\acluster\"J\n" +
+	"\x13ListReportsResponse\x123\n" +
+	"\areports\x18\x01 \x03(\v2\x19.k8sscanner.v1.ReportInfoR\areports\"V\n" +
+	"\vDiffRequest\x12\x10\n" +
+	"\x03old\x18\x01 \x01(\tR\x03old\x12\x10\n" +
+	"\x03new\x18\x02 \x01(\tR\x03new\x12#\n" +
+	"\rcross_cluster\x18\x03 \x01(\bR\fcrossCluster\"\x8d\x01\n" +
+	"\vIssueChange\x121\n" +
+	"\told_issue\x18\x01 \x01(\v2\x14.k8sscanner.v1.IssueR\boldIssue\x121\n" +
+	"\tnew_issue\x18\x02 \x01(\v2\x14.k8sscanner.v1.IssueR\bnewIssue\x12\x18\n" +
+	"\achanges\x18\x03 \x03(\tR\achanges\"\\\n" +
+	"\x0eNamespaceDelta\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12,\n" +
+	"\x05delta\x18\x02 \x01(\v2\x16.k8sscanner.v1.SummaryR\x05delta\"\xcf\x02\n" +
+	"\n" +
+	"DiffResult\x123\n" +
+	"\n" +
+	"new_issues\x18\x01 \x03(\v2\x14.k8sscanner.v1.IssueR\tnewIssues\x12=\n" +
+	"\x0fresolved_issues\x18\x02 \x03(\v2\x14.k8sscanner.v1.IssueR\x0eresolvedIssues\x12A\n" +
+	"\x0echanged_issues\x18\x03 \x03(\v2\x1a.k8sscanner.v1.IssueChangeR\rchangedIssues\x12H\n" +
+	"\x10namespace_deltas\x18\x04 \x03(\v2\x1d.k8sscanner.v1.NamespaceDeltaR\x0fnamespaceDeltas\x12\x1f\n" +
+	"\vold_cluster\x18\x05 \x01(\tR\n" +
+	"oldCluster\x12\x1f\n" +
+	"\vnew_cluster\x18\x06 \x01(\tR\n" +
+	"newCluster\"P\n" +
+	"\x12TriggerScanRequest\x12\x1e\n" +
+	"\n" +
+	"namespaces\x18\x01 \x03(\tR\n" +
+	"namespaces\x12\x1a\n" +
+	"\bselector\x18\x02 \x01(\tR\bselector\"\xfe\x01\n" +
+	"\x13TriggerScanResponse\x12,\n" +
+	"\x06issues\x18\x01 \x03(\v2\x14.k8sscanner.v1.IssueR\x06issues\x12I\n" +
+	"\asummary\x18\x02 \x03(\v2/.k8sscanner.v1.TriggerScanResponse.SummaryEntryR\asummary\x12\x1a\n" +
+	"\bwarnings\x18\x03 \x03(\tR\bwarnings\x1aR\n" +
+	"\fSummaryEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12,\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.k8sscanner.v1.SummaryR\x05value:\x028\x012\xc5\x02\n" +
+	"\aScanner\x12O\n" +
+	"\x0fGetLatestReport\x12%.k8sscanner.v1.GetLatestReportRequest\x1a\x15.k8sscanner.v1.Report\x12T\n" +
+	"\vListReports\x12!.k8sscanner.v1.ListReportsRequest\x1a\".k8sscanner.v1.ListReportsResponse\x12=\n" +
+	"\x04Diff\x12\x1a.k8sscanner.v1.DiffRequest\x1a\x19.k8sscanner.v1.DiffResult\x12T\n" +
+	"\vTriggerScan\x12!.k8sscanner.v1.TriggerScanRequest\x1a\".k8sscanner.v1.TriggerScanResponseB4Z2github.com/ductnn/k8s-scanner/pkg/server/scannerpbb\x06proto3"
+
+var (
+	file_scanner_proto_rawDescOnce sync.Once
+	file_scanner_proto_rawDescData []byte
+)
+
+func file_scanner_proto_rawDescGZIP() []byte {
+	file_scanner_proto_rawDescOnce.Do(func() {
+		file_scanner_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_scanner_proto_rawDesc), len(file_scanner_proto_rawDesc)))
+	})
+	return file_scanner_proto_rawDescData
+}
+
+var file_scanner_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_scanner_proto_goTypes = []any{
+	(*Issue)(nil),                  // 0: k8sscanner.v1.Issue
+	(*Summary)(nil),                // 1: k8sscanner.v1.Summary
+	(*Report)(nil),                 // 2: k8sscanner.v1.Report
+	(*ReportInfo)(nil),             // 3: k8sscanner.v1.ReportInfo
+	(*GetLatestReportRequest)(nil), // 4: k8sscanner.v1.GetLatestReportRequest
+	(*ListReportsRequest)(nil),     // 5: k8sscanner.v1.ListReportsRequest
+	(*ListReportsResponse)(nil),    // 6: k8sscanner.v1.ListReportsResponse
+	(*DiffRequest)(nil),            // 7: k8sscanner.v1.DiffRequest
+	(*IssueChange)(nil),            // 8: k8sscanner.v1.IssueChange
+	(*NamespaceDelta)(nil),         // 9: k8sscanner.v1.NamespaceDelta
+	(*DiffResult)(nil),             // 10: k8sscanner.v1.DiffResult
+	(*TriggerScanRequest)(nil),     // 11: k8sscanner.v1.TriggerScanRequest
+	(*TriggerScanResponse)(nil),    // 12: k8sscanner.v1.TriggerScanResponse
+	nil,                            // 13: k8sscanner.v1.Issue.LabelsEntry
+	nil,                            // 14: k8sscanner.v1.Report.SummaryEntry
+	nil,                            // 15: k8sscanner.v1.ReportInfo.SummaryEntry
+	nil,                            // 16: k8sscanner.v1.TriggerScanResponse.SummaryEntry
+}
+var file_scanner_proto_depIdxs = []int32{
+	13, // 0: k8sscanner.v1.Issue.labels:type_name -> k8sscanner.v1.Issue.LabelsEntry
+	0,  // 1: k8sscanner.v1.Report.issues:type_name -> k8sscanner.v1.Issue
+	14, // 2: k8sscanner.v1.Report.summary:type_name -> k8sscanner.v1.Report.SummaryEntry
+	15, // 3: k8sscanner.v1.ReportInfo.summary:type_name -> k8sscanner.v1.ReportInfo.SummaryEntry
+	3,  // 4: k8sscanner.v1.ListReportsResponse.reports:type_name -> k8sscanner.v1.ReportInfo
+	0,  // 5: k8sscanner.v1.IssueChange.old_issue:type_name -> k8sscanner.v1.Issue
+	0,  // 6: k8sscanner.v1.IssueChange.new_issue:type_name -> k8sscanner.v1.Issue
+	1,  // 7: k8sscanner.v1.NamespaceDelta.delta:type_name -> k8sscanner.v1.Summary
+	0,  // 8: k8sscanner.v1.DiffResult.new_issues:type_name -> k8sscanner.v1.Issue
+	0,  // 9: k8sscanner.v1.DiffResult.resolved_issues:type_name -> k8sscanner.v1.Issue
+	8,  // 10: k8sscanner.v1.DiffResult.changed_issues:type_name -> k8sscanner.v1.IssueChange
+	9,  // 11: k8sscanner.v1.DiffResult.namespace_deltas:type_name -> k8sscanner.v1.NamespaceDelta
+	0,  // 12: k8sscanner.v1.TriggerScanResponse.issues:type_name -> k8sscanner.v1.Issue
+	16, // 13: k8sscanner.v1.TriggerScanResponse.summary:type_name -> k8sscanner.v1.TriggerScanResponse.SummaryEntry
+	1,  // 14: k8sscanner.v1.Report.SummaryEntry.value:type_name -> k8sscanner.v1.Summary
+	1,  // 15: k8sscanner.v1.ReportInfo.SummaryEntry.value:type_name -> k8sscanner.v1.Summary
+	1,  // 16: k8sscanner.v1.TriggerScanResponse.SummaryEntry.value:type_name -> k8sscanner.v1.Summary
+	4,  // 17: k8sscanner.v1.Scanner.GetLatestReport:input_type -> k8sscanner.v1.GetLatestReportRequest
+	5,  // 18: k8sscanner.v1.Scanner.ListReports:input_type -> k8sscanner.v1.ListReportsRequest
+	7,  // 19: k8sscanner.v1.Scanner.Diff:input_type -> k8sscanner.v1.DiffRequest
+	11, // 20: k8sscanner.v1.Scanner.TriggerScan:input_type -> k8sscanner.v1.TriggerScanRequest
+	2,  // 21: k8sscanner.v1.Scanner.GetLatestReport:output_type -> k8sscanner.v1.Report
+	6,  // 22: k8sscanner.v1.Scanner.ListReports:output_type -> k8sscanner.v1.ListReportsResponse
+	10, // 23: k8sscanner.v1.Scanner.Diff:output_type -> k8sscanner.v1.DiffResult
+	12, // 24: k8sscanner.v1.Scanner.TriggerScan:output_type -> k8sscanner.v1.TriggerScanResponse
+	21, // [21:25] is the sub-list for method output_type
+	17, // [17:21] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
+}
+
+func init() { file_scanner_proto_init() }
+func file_scanner_proto_init() {
+	if File_scanner_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_scanner_proto_rawDesc), len(file_scanner_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_scanner_proto_goTypes,
+		DependencyIndexes: file_scanner_proto_depIdxs,
+		MessageInfos:      file_scanner_proto_msgTypes,
+	}.Build()
+	File_scanner_proto = out.File
+	file_scanner_proto_goTypes = nil
+	file_scanner_proto_depIdxs = nil
+}
@@ -0,0 +1,295 @@
+// Package server serves scans and reports for `k8s-scanner serve`
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ductnn/k8s-scanner/pkg/report"
+	"github.com/ductnn/k8s-scanner/pkg/scanner"
+	"github.com/ductnn/k8s-scanner/pkg/scanner/capacity"
+	"github.com/ductnn/k8s-scanner/pkg/tracing"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Config configures a Server
+type Config struct {
+	// Scan holds the options of every scan; a request may override the
+	// namespaces and add a pod selector
+	Scan scanner.Options
+	// Outdir is where exported reports are written
+	Outdir string
+	// Export lists the report formats written after each scan (none keeps
+	// results in memory only)
+	Export []report.ExportKind
+	// NameTemplate names exported reports (nil uses
+	// report.DefaultFilenameTemplate)
+	NameTemplate *report.FilenameTemplate
+	// Compress gzips exported reports
+	Compress bool
+	// SplitBy, when set (see report.SplitModes), also exports a report per
+	// tenant
+	SplitBy string
+	// OnScan, when set, is called after every published scan
+	OnScan func(scanner.Result)
+	// Tracer, when set, records the phases of each scan and is flushed
+	// after it
+	Tracer *tracing.Tracer
+	// Incremental, when set, makes RunEvery rescan only the namespaces with
+	// pod or event changes; nil rescans everything every cycle
+	Incremental *IncrementalOptions
+}
+
+// ScanRequest is the optional JSON body of POST /api/v1/scan. A request
+// that narrows the scan (namespaces or a selector) is partial: its result is
+// only returned to the caller, and the latest report, exports and metrics
+// keep describing the whole cluster.
+type ScanRequest struct {
+	// Namespaces replaces the configured namespaces for this scan
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Selector limits the pod scanner to pods matching this label selector
+	Selector string `json:"selector,omitempty"`
+}
+
+// Server runs scans on demand and on a schedule. Only one scan runs at a
+// time: overlapping triggers wait for the running scan instead of adding
+// load on the API server.
+type Server struct {
+	cfg      Config
+	scanSlot chan struct{}
+	mux      *http.ServeMux
+
+	mu   sync.Mutex
+	last *report.ReportData // result of the latest cluster-wide scan
+}
+
+// New creates a Server
+func New(cfg Config) *Server {
+	s := &Server{
+		cfg:      cfg,
+		scanSlot: make(chan struct{}, 1),
+		mux:      http.NewServeMux(),
+	}
+	s.mux.HandleFunc("POST /api/v1/scan", s.handleScan)
+	s.mux.HandleFunc("GET /api/v1/reports", s.handleReports)
+	s.mux.HandleFunc("GET /api/v1/reports/latest", s.handleLatest)
+	s.mux.HandleFunc("GET /api/v1/reports/{name...}", s.handleReport)
+	s.mux.HandleFunc("GET /api/v1/diff", s.handleDiff)
+	s.mux.Handle("GET /ui/", uiHandler())
+	s.mux.Handle("GET /ui", http.RedirectHandler("/ui/", http.StatusMovedPermanently))
+	return s
+}
+
+// Handler returns the HTTP API and the dashboard
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// Scan runs one scan after any scan in progress has finished, then tracks
+// issue age and, unless the request is partial, writes the configured
+// exports and publishes the result
+func (s *Server) Scan(ctx context.Context, req ScanRequest) (scanner.Result, error) {
+	return s.scan(ctx, req, nil)
+}
+
+// scan implements Scan. merge, when set, combines the result with those of
+// earlier scans into a cluster-wide result, which is published even when the
+// request is partial.
+func (s *Server) scan(ctx context.Context, req ScanRequest, merge func(scanner.Result) scanner.Result) (scanner.Result, error) {
+	select {
+	case s.scanSlot <- struct{}{}:
+	case <-ctx.Done():
+		return scanner.Result{}, ctx.Err()
+	}
+	defer func() { <-s.scanSlot }()
+
+	if s.cfg.Tracer != nil {
+		ctx = tracing.WithTracer(ctx, s.cfg.Tracer)
+		defer func() {
+			if err := s.cfg.Tracer.Flush(context.WithoutCancel(ctx)); err != nil {
+				log.Printf("serve: %v", err)
+			}
+		}()
+	}
+	ctx, span := tracing.Start(ctx, "serve scan")
+	defer span.End()
+
+	opts := s.cfg.Scan
+	if len(req.Namespaces) > 0 {
+		opts.Namespaces = req.Namespaces
+	}
+	if req.Selector != "" {
+		opts.PodSelector = req.Selector
+	}
+
+	res, err := scanner.Run(ctx, opts)
+	if err != nil {
+		return res, err
+	}
+	for _, w := range res.Warnings {
+		log.Printf("serve: %s", w)
+	}
+	if merge != nil {
+		res = merge(res)
+	}
+
+	previous := s.latest()
+	report.TrackIssueAge(res.Issues, previous)
+	if merge == nil && req.partial() {
+		return res, nil
+	}
+
+	if len(s.cfg.Export) > 0 {
+		ctx, span := tracing.Start(ctx, "export")
+		err := s.export(ctx, res)
+		span.RecordError(err)
+		span.End()
+		if err != nil {
+			return res, fmt.Errorf("export failed: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	s.last = &report.ReportData{
+		GeneratedAt: time.Now().Format(time.RFC3339),
+		Issues:      res.Issues,
+		Summary:     res.Summary,
+		Meta:        &res.Meta,
+	}
+	s.mu.Unlock()
+
+	if s.cfg.OnScan != nil {
+		s.cfg.OnScan(res)
+	}
+	return res, nil
+}
+
+// partial reports whether the request narrows the configured scan
+func (req ScanRequest) partial() bool {
+	return len(req.Namespaces) > 0 || req.Selector != ""
+}
+
+// export writes the configured report formats of a scan, and those of each
+// tenant with SplitBy
+func (s *Server) export(ctx context.Context, res scanner.Result) error {
+	tmpl := s.cfg.NameTemplate
+	if tmpl == nil {
+		tmpl, _ = report.ParseFilenameTemplate("")
+	}
+	base, err := tmpl.Name(report.SanitizeClusterName(s.cfg.Scan.Cluster), time.Now())
+	if err != nil {
+		return err
+	}
+	overview, _ := capacity.FetchOverview(ctx, s.cfg.Scan.Client)
+	if err := report.WriteAll(s.cfg.Outdir, base, res.Issues, res.Summary, overview, &res.Meta, s.cfg.Export); err != nil {
+		return err
+	}
+	bases := []string{base}
+	if s.cfg.SplitBy != "" {
+		tenants, err := report.WriteTenants(s.cfg.Outdir, base, s.cfg.SplitBy, res.Issues, &res.Meta, s.cfg.Export)
+		if err != nil {
+			return err
+		}
+		bases = append(bases, tenants...)
+	}
+	if !s.cfg.Compress {
+		return nil
+	}
+	for _, b := range bases {
+		for _, k := range s.cfg.Export {
+			if err := report.GzipFile(filepath.Join(s.cfg.Outdir, b+"."+string(k))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RunEvery scans every interval until the context is cancelled. A scan in
+// progress when that happens is allowed to finish. With Config.Incremental
+// only the namespaces that changed since the previous cycle are rescanned.
+func (s *Server) RunEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var inc *incremental
+	if s.cfg.Incremental != nil {
+		inc = s.startIncremental(ctx)
+	}
+
+	for {
+		if inc != nil {
+			s.scanIncremental(context.WithoutCancel(ctx), inc)
+		} else if res, err := s.Scan(context.WithoutCancel(ctx), ScanRequest{}); err != nil {
+			log.Printf("serve: scheduled scan failed: %v", err)
+		} else {
+			log.Printf("serve: scheduled scan found %d issue(s)", len(res.Issues))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// latest returns the last cluster-wide scan of this process, or else the
+// newest report in Outdir (nil if there is none)
+func (s *Server) latest() *report.ReportData {
+	s.mu.Lock()
+	last := s.last
+	s.mu.Unlock()
+	if last != nil {
+		return last
+	}
+	previous, _ := report.LatestReport(s.cfg.Outdir, s.cfg.Scan.Cluster)
+	return previous
+}
+
+// handleScan implements POST /api/v1/scan
+func (s *Server) handleScan(w http.ResponseWriter, r *http.Request) {
+	var req ScanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if req.Selector != "" {
+		if _, err := labels.Parse(req.Selector); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid selector %q: %v", req.Selector, err))
+			return
+		}
+	}
+
+	res, err := s.Scan(r.Context(), req)
+	if err != nil {
+		if r.Context().Err() != nil {
+			// The client went away while waiting for its turn
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// ui is the single-page dashboard served at /ui; it only talks to the
+// /api/v1 endpoints
+//
+//go:embed ui
+var ui embed.FS
+
+func uiHandler() http.Handler {
+	return http.StripPrefix("/ui/", http.FileServer(http.FS(UI())))
+}
+
+// UI returns the files of the dashboard
+func UI() fs.FS {
+	root, _ := fs.Sub(ui, "ui")
+	return root
+}
@@ -0,0 +1,196 @@
+// Package severity is the single severity policy shared by all scanners.
+package severity
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level is the severity of an issue
+type Level string
+
+// Severity levels, from most to least important. Info is for benign or
+// best-practice findings that should not count as problems.
+const (
+	Critical Level = "critical"
+	High     Level = "high"
+	Medium   Level = "medium"
+	Low      Level = "low"
+	Info     Level = "info"
+)
+
+// Levels lists the severity levels from most to least important
+var Levels = []Level{Critical, High, Medium, Low, Info}
+
+// Parse validates a severity name (case-insensitive)
+func Parse(s string) (Level, error) {
+	l := Level(strings.ToLower(strings.TrimSpace(s)))
+	if !l.Valid() {
+		return "", fmt.Errorf("invalid severity %q (expected critical, high, medium, low or info)", s)
+	}
+	return l, nil
+}
+
+// Valid reports whether l is one of the known levels
+func (l Level) Valid() bool {
+	return Rank(l) > 0
+}
+
+// UnmarshalText rejects unknown levels; an empty level is kept as is
+func (l *Level) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		*l = ""
+		return nil
+	}
+	parsed, err := Parse(string(b))
+	if err != nil {
+		return err
+	}
+	*l = parsed
+	return nil
+}
+
+// FromReason maps an issue reason (pod state or spec check) to severity level
+func FromReason(reason string) Level {
+	switch reason {
+	// Pod states
+	case "ImagePullBackOff", "ErrImagePull":
+		return Critical
+	case "CrashLoopBackOff", "Pending", "HighRestartCount", "TerminatingStuck":
+		return High
+	case "Evicted", "OOMKilled", "ContainerNotReady", "ReadinessGatesNotReady":
+		return Medium
+
+	// Volumes that cannot be attached or mounted
+	case "FailedMount", "FailedAttachVolume":
+		return High
+
+	// Failing probes, from Unhealthy events
+	case "LivenessProbeFailed", "StartupProbeFailed":
+		return High
+	case "ReadinessProbeFailed":
+		return Medium
+
+	// Pods stopped with their node; spot reclaims are expected
+	case "NodeShutdown":
+		return Medium
+	case "SpotInterruption":
+		return Low
+
+	// Containers that cannot be created or started
+	case "InvalidImageName":
+		return Critical
+	case "CreateContainerConfigError", "CreateContainerError", "RunContainerError", "ImageInspectError", "StartError":
+		return High
+
+	// Scheduling priority
+	case "PendingBehindHigherPriority":
+		return High
+	case "Preempted":
+		return Medium
+
+	// Capacity
+	case "NodeHighMemory":
+		return High
+	case "NodeHighCPU", "NamespaceUsageAboveRequests":
+		return Medium
+
+	// Spec checks
+	case "PrivilegedContainer":
+		return High
+	case "MissingResourceLimits", "ImageTagLatest", "PrivilegeEscalationAllowed":
+		return Medium
+	case "MissingLivenessProbe", "MissingReadinessProbe":
+		return Info
+
+	// Quotas
+	case "QuotaExhausted", "LimitRangeRejected":
+		return High
+	case "QuotaNearlyExhausted":
+		return Medium
+	case "MissingResourceQuota":
+		return Low
+
+	// Control plane and cluster DNS
+	case "ControlPlaneDegraded", "WebhookUnavailable", "DNSUnavailable", "DNSResolutionFailed":
+		return Critical
+	case "DNSDegraded":
+		return High
+	case "DNSWarningEvents":
+		return Medium
+
+	// Topology spread
+	case "ReplicasOnSingleNode":
+		return High
+	case "ReplicasInSingleZone":
+		return Medium
+
+	// GitOps
+	case "ArgoAppDegraded", "ArgoSyncFailed", "FluxReconcileFailed":
+		return High
+	case "ArgoAppOutOfSync":
+		return Medium
+
+	// Karpenter provisioning
+	case "KarpenterLaunchFailed", "KarpenterNodeNotRegistered", "KarpenterNodePoolNotReady", "KarpenterNodePoolLimitReached":
+		return High
+	case "KarpenterNodeDrifted":
+		return Low
+
+	// Service mesh
+	case "IstioSidecarNotReady", "IstioMTLSConflict":
+		return High
+	case "IstioSidecarMissing":
+		return Medium
+
+	// Housekeeping (gc)
+	case "OrphanedReplicaSet", "ExpiredJob", "UnusedConfigMap", "UnusedSecret", "DanglingEndpoints":
+		return Info
+
+	default:
+		return Low
+	}
+}
+
+// Rank returns a numeric priority for severity (higher = more important)
+func Rank(severity Level) int {
+	switch severity {
+	case Critical:
+		return 5
+	case High:
+		return 4
+	case Medium:
+		return 3
+	case Low:
+		return 2
+	case Info:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// AtLeast reports whether severity is at or above min.
+// An unknown min (e.g. "none") never matches.
+func AtLeast(severity, min Level) bool {
+	minRank := Rank(min)
+	if minRank == 0 {
+		return false
+	}
+	return Rank(severity) >= minRank
+}
+
+// Escalate returns the next severity level (critical stays critical, and
+// info stays info since benign findings do not become problems over time)
+func Escalate(severity Level) Level {
+	switch severity {
+	case Low:
+		return Medium
+	case Medium:
+		return High
+	case High, Critical:
+		return Critical
+	default:
+		return severity
+	}
+}
@@ -0,0 +1,107 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Snapshot is a recorded view of the cluster state used for offline scans
+type Snapshot struct {
+	CreatedAt string       `json:"created_at"`
+	Cluster   string       `json:"cluster,omitempty"`
+	Pods      []v1.Pod     `json:"pods"`
+	Events    []v1.Event   `json:"events"`
+	Nodes     []v1.Node    `json:"nodes"`
+	Services  []v1.Service `json:"services,omitempty"`
+}
+
+// Create records pods, events, services and nodes from the cluster.
+// If namespaces is empty, all namespaces are recorded.
+func Create(ctx context.Context, client kubernetes.Interface, namespaces []string, clusterName string) (*Snapshot, error) {
+	snap := &Snapshot{
+		CreatedAt: time.Now().Format(time.RFC3339),
+		Cluster:   clusterName,
+	}
+
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+	for _, ns := range namespaces {
+		ns = strings.TrimSpace(ns)
+
+		pods, err := client.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods: %w", err)
+		}
+		snap.Pods = append(snap.Pods, pods.Items...)
+
+		events, err := client.CoreV1().Events(ns).List(ctx, metav1.ListOptions{FieldSelector: "involvedObject.kind=Pod"})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list events: %w", err)
+		}
+		snap.Events = append(snap.Events, events.Items...)
+
+		services, err := client.CoreV1().Services(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list services: %w", err)
+		}
+		snap.Services = append(snap.Services, services.Items...)
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	snap.Nodes = nodes.Items
+
+	return snap, nil
+}
+
+// Save writes the snapshot as JSON to path
+func (s *Snapshot) Save(path string) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// Load reads a snapshot from a JSON file
+func Load(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot file: %w", err)
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot JSON: %w", err)
+	}
+	return &snap, nil
+}
+
+// PodsIn returns the recorded pods of the given namespaces (all if empty)
+func (s *Snapshot) PodsIn(namespaces []string) []v1.Pod {
+	if len(namespaces) == 0 {
+		return s.Pods
+	}
+	want := make(map[string]bool, len(namespaces))
+	for _, ns := range namespaces {
+		want[ns] = true
+	}
+	pods := make([]v1.Pod, 0, len(s.Pods))
+	for _, p := range s.Pods {
+		if want[p.Namespace] {
+			pods = append(pods, p)
+		}
+	}
+	return pods
+}
@@ -0,0 +1,120 @@
+// Package tracing records spans for the phases of a scan and exports them to
+// an OpenTelemetry collector using OTLP over HTTP (JSON encoding).
+//
+// Spans are started with Start, which does nothing unless a Tracer was put
+// in the context with WithTracer, so instrumented code needs no nil checks.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Tracer collects finished spans until they are flushed
+type Tracer struct {
+	exporter *Exporter
+
+	mu    sync.Mutex
+	spans []*Span
+}
+
+// NewTracer creates a Tracer sending spans to exporter on Flush
+func NewTracer(exporter *Exporter) *Tracer {
+	return &Tracer{exporter: exporter}
+}
+
+// Flush exports the spans that have ended since the last flush
+func (t *Tracer) Flush(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	spans := t.spans
+	t.spans = nil
+	t.mu.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+	return t.exporter.Export(ctx, spans)
+}
+
+// Span is one timed operation. All methods are safe on a nil Span.
+type Span struct {
+	tracer   *Tracer
+	name     string
+	traceID  string
+	spanID   string
+	parentID string
+	start    time.Time
+	end      time.Time
+	attrs    map[string]any
+	err      error
+}
+
+type tracerKey struct{}
+type spanKey struct{}
+
+// WithTracer returns a context in which Start records spans with t
+func WithTracer(ctx context.Context, t *Tracer) context.Context {
+	if t == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, tracerKey{}, t)
+}
+
+// Start begins a span as a child of the span in ctx, if any. The returned
+// context carries the new span for nested calls.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	t, _ := ctx.Value(tracerKey{}).(*Tracer)
+	if t == nil {
+		return ctx, nil
+	}
+	s := &Span{tracer: t, name: name, spanID: randomID(8), start: time.Now()}
+	if parent, _ := ctx.Value(spanKey{}).(*Span); parent != nil {
+		s.traceID = parent.traceID
+		s.parentID = parent.spanID
+	} else {
+		s.traceID = randomID(16)
+	}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// SetAttr records an attribute (string, bool, int or float64)
+func (s *Span) SetAttr(key string, value any) {
+	if s == nil {
+		return
+	}
+	if s.attrs == nil {
+		s.attrs = make(map[string]any)
+	}
+	s.attrs[key] = value
+}
+
+// RecordError marks the span as failed. Cancellation is not an error.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	s.err = err
+}
+
+// End finishes the span
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.end = time.Now()
+	s.tracer.mu.Lock()
+	s.tracer.spans = append(s.tracer.spans, s)
+	s.tracer.mu.Unlock()
+}
+
+func randomID(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -1,16 +1,72 @@
 package types
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/ductnn/k8s-scanner/pkg/checks"
+	"github.com/ductnn/k8s-scanner/pkg/severity"
+)
+
 type Issue struct {
-	Kind         string `json:"kind"`
-	Namespace    string `json:"namespace"`
-	Name         string `json:"name"`
-	Severity     string `json:"severity"`
-	Reason       string `json:"reason"`
-	RootCause    string `json:"root_cause"`
-	PodStatus    string `json:"pod_status"`
-	Timestamp    string `json:"timestamp"`
-	NodeName     string `json:"node_name"`
-	RestartCount int32  `json:"restart_count"`
-	LastEvent    string `json:"last_event"`
-	// Suggestion is not used for now
+	ID               string         `json:"id"`
+	Kind             string         `json:"kind"`
+	Namespace        string         `json:"namespace"`
+	Name             string         `json:"name"`
+	Container        string         `json:"container"`
+	Image            string         `json:"image,omitempty"`
+	Severity         severity.Level `json:"severity"`
+	Reason           string         `json:"reason"`
+	CheckID          string         `json:"check_id,omitempty"`
+	RootCause        string         `json:"root_cause"`
+	Suggestion       string         `json:"suggestion,omitempty"`
+	PodStatus        string         `json:"pod_status"`
+	Timestamp        string         `json:"timestamp"`
+	NodeName         string         `json:"node_name"`
+	NodeCondition    string         `json:"node_condition,omitempty"`
+	NodePool         string         `json:"node_pool,omitempty"`
+	InstanceType     string         `json:"instance_type,omitempty"`
+	Zone             string         `json:"zone,omitempty"`
+	PriorityClass    string         `json:"priority_class,omitempty"`
+	ImpactedServices []string       `json:"impacted_services,omitempty"`
+	Team             string         `json:"team,omitempty"`
+	RestartCount     int32          `json:"restart_count"`
+	LastEvent        string         `json:"last_event"`
+	InStateSince     string         `json:"in_state_since,omitempty"`
+	FirstSeen        string         `json:"first_seen,omitempty"`
+	LastSeen         string         `json:"last_seen,omitempty"`
+	// Labels are the configured pod (or namespace) labels and annotations
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Fingerprint returns a deterministic ID for an issue, derived from
+// cluster/namespace/kind/name/container/reason
+func Fingerprint(cluster string, issue Issue) string {
+	key := strings.Join([]string{cluster, issue.Namespace, issue.Kind, issue.Name, issue.Container, issue.Reason}, "/")
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// AssignIDs sets the ID of every issue for the given cluster, and the
+// CheckID of the issues whose reason is in the check catalog
+func AssignIDs(issues []Issue, cluster string) {
+	for i := range issues {
+		issues[i].ID = Fingerprint(cluster, issues[i])
+		if c, ok := checks.Lookup(issues[i].Reason); ok && issues[i].CheckID == "" {
+			issues[i].CheckID = c.ID
+		}
+	}
+}
+
+// CheckKey identifies the kind of an issue: its CheckID, looked up from the
+// reason for issues written before check IDs existed, else the reason
+func CheckKey(issue Issue) string {
+	if issue.CheckID != "" {
+		return issue.CheckID
+	}
+	if c, ok := checks.Lookup(issue.Reason); ok {
+		return c.ID
+	}
+	return issue.Reason
 }
@@ -5,4 +5,6 @@ type SeveritySummary struct {
 	High     int `json:"high"`
 	Medium   int `json:"medium"`
 	Low      int `json:"low"`
+	// Info counts benign findings, which are not problems
+	Info int `json:"info"`
 }
@@ -0,0 +1,49 @@
+// Package version holds the build version of k8s-scanner
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Version and Commit are set at build time with
+// -ldflags "-X github.com/ductnn/k8s-scanner/pkg/version.Version=v1.2.3"
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+// Info describes the running binary
+type Info struct {
+	Version         string `json:"version"`
+	Commit          string `json:"commit,omitempty"`
+	GoVersion       string `json:"go_version"`
+	ClientGoVersion string `json:"client_go_version,omitempty"`
+}
+
+// Get returns the build information. The commit falls back to the VCS
+// revision stamped by the Go toolchain when it was not set with -ldflags.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		GoVersion: runtime.Version(),
+	}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, dep := range bi.Deps {
+		if dep.Path == "k8s.io/client-go" {
+			info.ClientGoVersion = dep.Version
+		}
+	}
+	if info.Commit == "" {
+		for _, s := range bi.Settings {
+			if s.Key == "vcs.revision" {
+				info.Commit = s.Value
+			}
+		}
+	}
+	return info
+}